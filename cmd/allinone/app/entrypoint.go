@@ -63,6 +63,7 @@ func runControllerManager(stopCh <-chan struct{}) (err error) {
 			LeaderElection:    s.LeaderElection,
 			LeaderElect:       s.LeaderElect,
 			WebhookCertDir:    s.WebhookCertDir,
+			EnableWebhook:     s.EnableWebhook,
 		}
 	} else {
 		klog.Fatal("Failed to load configuration from disk", err)
@@ -28,6 +28,9 @@ import (
 	"k8s.io/klog"
 	"kubesphere.io/devops/pkg/apis"
 	"kubesphere.io/devops/pkg/apiserver"
+	"kubesphere.io/devops/pkg/apiserver/auditing"
+	auditingoptions "kubesphere.io/devops/pkg/apiserver/auditing/options"
+	authzoptions "kubesphere.io/devops/pkg/apiserver/authorization/options"
 	"kubesphere.io/devops/pkg/client/clientset/versioned/scheme"
 	apiserverconfig "kubesphere.io/devops/pkg/config"
 	"kubesphere.io/devops/pkg/informers"
@@ -67,6 +70,14 @@ func (s *ServerRunOptions) Flags() (fss cliflag.NamedFlagSets) {
 	s.JenkinsOptions.AddFlags(fss.FlagSet("devops"), s.JenkinsOptions)
 	s.SonarQubeOptions.AddFlags(fss.FlagSet("sonarqube"), s.SonarQubeOptions)
 	s.S3Options.AddFlags(fss.FlagSet("s3"), s.S3Options)
+	if s.AuthorizationOptions == nil {
+		s.AuthorizationOptions = authzoptions.NewAuthorizationOptions()
+	}
+	s.AuthorizationOptions.AddFlags(fss.FlagSet("authorization"), s.AuthorizationOptions)
+	if s.AuditingOptions == nil {
+		s.AuditingOptions = auditingoptions.NewAuditingOptions()
+	}
+	s.AuditingOptions.AddFlags(fss.FlagSet("auditing"), s.AuditingOptions)
 
 	fs = fss.FlagSet("klog")
 	local := flag.NewFlagSet("klog", flag.ExitOnError)
@@ -142,6 +153,15 @@ func (s *ServerRunOptions) NewAPIServer(stopCh <-chan struct{}) (*apiserver.APIS
 		apiServer.CacheClient = cache.NewSimpleCache()
 	}
 
+	if s.AuditingOptions != nil && s.AuditingOptions.Enable {
+		backend, err := auditing.NewBackend(s.AuditingOptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the auditing backend, error: %v", err)
+		}
+		go backend.Run(stopCh)
+		apiServer.Auditing = auditing.NewAuditing(s.AuditingOptions, backend)
+	}
+
 	server := &http.Server{
 		Addr: fmt.Sprintf(":%d", s.GenericServerRunOptions.InsecurePort),
 	}
@@ -26,6 +26,12 @@ func (s *ServerRunOptions) Validate() []error {
 	errors = append(errors, s.KubernetesOptions.Validate()...)
 	errors = append(errors, s.SonarQubeOptions.Validate()...)
 	errors = append(errors, s.S3Options.Validate()...)
+	if s.AuthorizationOptions != nil {
+		errors = append(errors, s.AuthorizationOptions.Validate()...)
+	}
+	if s.AuditingOptions != nil {
+		errors = append(errors, s.AuditingOptions.Validate()...)
+	}
 
 	return errors
 }
@@ -17,11 +17,14 @@ limitations under the License.
 package app
 
 import (
+	"fmt"
+
 	"github.com/jenkins-zh/jenkins-client/pkg/core"
 	"k8s.io/klog"
 	"kubesphere.io/devops/cmd/controller/app/options"
 	"kubesphere.io/devops/controllers/devopscredential"
 	"kubesphere.io/devops/controllers/devopsproject"
+	"kubesphere.io/devops/controllers/drift"
 	"kubesphere.io/devops/controllers/jenkins/config"
 	"kubesphere.io/devops/controllers/jenkins/pipelinerun"
 	"kubesphere.io/devops/controllers/pipeline"
@@ -30,6 +33,8 @@ import (
 	"kubesphere.io/devops/pkg/client/devops"
 	"kubesphere.io/devops/pkg/client/k8s"
 	"kubesphere.io/devops/pkg/client/s3"
+	"kubesphere.io/devops/pkg/client/sonarqube"
+	"kubesphere.io/devops/pkg/client/vault"
 	"kubesphere.io/devops/pkg/informers"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
@@ -59,20 +64,39 @@ func addControllers(mgr manager.Manager, client k8s.Client, informerFactory info
 			kubesphereInformer.Devops().V1alpha1().S2iBinaries(),
 			kubesphereInformer.Devops().V1alpha1().S2iRuns())
 
-		devopsProjectController = devopsproject.NewController(client.Kubernetes(),
+		driftOptions := drift.Options{
+			Interval: s.JenkinsOptions.DriftDetectionInterval,
+			DryRun:   s.JenkinsOptions.DriftDetectionDryRun,
+		}
+
+		projectController := devopsproject.NewController(client.Kubernetes(),
 			client.KubeSphere(), devopsClient,
 			informerFactory.KubernetesSharedInformerFactory().Core().V1().Namespaces(),
 			informerFactory.KubeSphereSharedInformerFactory().Devops().V1alpha3().DevOpsProjects())
+		projectController.EnableDriftDetection(driftOptions)
+		devopsProjectController = projectController
 
-		devopsPipelineController = pipeline.NewController(client.Kubernetes(),
+		pipelineController := pipeline.NewController(client.Kubernetes(),
 			client.KubeSphere(), devopsClient,
 			informerFactory.KubernetesSharedInformerFactory().Core().V1().Namespaces(),
 			informerFactory.KubeSphereSharedInformerFactory().Devops().V1alpha3().Pipelines())
+		pipelineController.EnableDriftDetection(driftOptions)
+		devopsPipelineController = pipelineController
 
-		devopsCredentialController = devopscredential.NewController(client.Kubernetes(),
+		credentialController := devopscredential.NewController(client.Kubernetes(),
 			devopsClient,
 			informerFactory.KubernetesSharedInformerFactory().Core().V1().Namespaces(),
 			informerFactory.KubernetesSharedInformerFactory().Core().V1().Secrets())
+		credentialController.EnableDriftDetection(driftOptions)
+		credentialController.EnableExpiryCheck(s.JenkinsOptions.CredentialExpiryWarningPeriod)
+		if s.VaultOptions != nil && s.VaultOptions.Address != "" {
+			vaultClient, err := vault.NewVaultClient(s.VaultOptions)
+			if err != nil {
+				return fmt.Errorf("failed to create vault client, error: %v", err)
+			}
+			credentialController.EnableExternalProvider(vault.ProviderName, vaultClient, s.VaultOptions.RefreshInterval)
+		}
+		devopsCredentialController = credentialController
 
 		jenkinsConfigController = config.NewController(&config.ControllerOptions{
 			LimitRangeClient:    client.Kubernetes().CoreV1(),
@@ -87,12 +111,26 @@ func addControllers(mgr manager.Manager, client k8s.Client, informerFactory info
 			ReloadCasCDelay: s.JenkinsOptions.ReloadCasCDelay,
 		}, s.JenkinsOptions)
 
+		// the quality gates of PipelineRuns are collected only if SonarQube is configured
+		var sonarClient sonarqube.SonarInterface
+		if s.SonarQubeOptions != nil && s.SonarQubeOptions.Host != "" {
+			sonarQubeClient, err := sonarqube.NewSonarQubeClient(s.SonarQubeOptions)
+			if err != nil {
+				return fmt.Errorf("failed to create sonarqube client, error: %v", err)
+			}
+			sonarClient = sonarqube.NewSonar(sonarQubeClient.SonarQube())
+		}
+
 		// add PipelineRun controller
 		if err := (&pipelinerun.Reconciler{
-			Client:       mgr.GetClient(),
-			Scheme:       mgr.GetScheme(),
-			DevOpsClient: devopsClient,
-			JenkinsCore:  jenkinsCore,
+			Client:          mgr.GetClient(),
+			Scheme:          mgr.GetScheme(),
+			DevOpsClient:    devopsClient,
+			JenkinsCore:     jenkinsCore,
+			S3Client:        s3Client,
+			SonarClient:     sonarClient,
+			PollInterval:    s.JenkinsOptions.PipelineRunPollInterval,
+			MaxPollInterval: s.JenkinsOptions.PipelineRunMaxPollInterval,
 		}).SetupWithManager(mgr); err != nil {
 			klog.Errorf("unable to create pipelinerun-controller, err: %v", err)
 			return err
@@ -106,6 +144,14 @@ func addControllers(mgr manager.Manager, client k8s.Client, informerFactory info
 			klog.Errorf("unable to create pipelinerun-synchronizer, err: %v", err)
 			return err
 		}
+
+		// add PipelineRun retention controller
+		if err := (&pipelinerun.RetentionReconciler{
+			Client: mgr.GetClient(),
+		}).SetupWithManager(mgr); err != nil {
+			klog.Errorf("unable to create pipelinerun-retention, err: %v", err)
+			return err
+		}
 	}
 
 	controllers := map[string]manager.Runnable{
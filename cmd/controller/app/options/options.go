@@ -21,6 +21,8 @@ import (
 	"kubesphere.io/devops/pkg/client/devops/jenkins"
 	"kubesphere.io/devops/pkg/client/k8s"
 	"kubesphere.io/devops/pkg/client/s3"
+	"kubesphere.io/devops/pkg/client/sonarqube"
+	"kubesphere.io/devops/pkg/client/vault"
 	"strings"
 	"time"
 
@@ -38,7 +40,10 @@ type DevOpsControllerManagerOptions struct {
 	LeaderElect       bool
 	LeaderElection    *leaderelection.LeaderElectionConfig
 	WebhookCertDir    string
+	EnableWebhook     bool
 	S3Options         *s3.Options
+	VaultOptions      *vault.Options
+	SonarQubeOptions  *sonarqube.Options
 
 	// KubeSphere is using sigs.k8s.io/application as fundamental object to implement Application Management.
 	// There are other projects also built on sigs.k8s.io/application, when KubeSphere installed along side
@@ -52,7 +57,9 @@ type DevOpsControllerManagerOptions struct {
 
 func NewDevOpsControllerManagerOptions() *DevOpsControllerManagerOptions {
 	s := &DevOpsControllerManagerOptions{
-		JenkinsOptions: jenkins.NewJenkinsOptions(),
+		JenkinsOptions:   jenkins.NewJenkinsOptions(),
+		VaultOptions:     vault.NewVaultOptions(),
+		SonarQubeOptions: sonarqube.NewSonarQubeOptions(),
 		LeaderElection: &leaderelection.LeaderElectionConfig{
 			LeaseDuration: 30 * time.Second,
 			RenewDeadline: 15 * time.Second,
@@ -71,6 +78,12 @@ func (s *DevOpsControllerManagerOptions) Flags() cliflag.NamedFlagSets {
 
 	s.KubernetesOptions.AddFlags(fss.FlagSet("kubernetes"), s.KubernetesOptions)
 	s.JenkinsOptions.AddFlags(fss.FlagSet("devops"), s.JenkinsOptions)
+	if s.VaultOptions != nil {
+		s.VaultOptions.AddFlags(fss.FlagSet("vault"), s.VaultOptions)
+	}
+	if s.SonarQubeOptions != nil {
+		s.SonarQubeOptions.AddFlags(fss.FlagSet("sonarqube"), s.SonarQubeOptions)
+	}
 
 	fs := fss.FlagSet("leaderelection")
 	s.bindLeaderElectionFlags(s.LeaderElection, fs)
@@ -84,6 +97,10 @@ func (s *DevOpsControllerManagerOptions) Flags() cliflag.NamedFlagSets {
 		"if not set, webhook server would look up the server key and certificate in"+
 		"{TempDir}/k8s-webhook-server/serving-certs")
 
+	fs.BoolVar(&s.EnableWebhook, "enable-webhook", s.EnableWebhook, ""+
+		"Whether to serve the validating and mutating admission webhooks of Pipeline and PipelineRun. "+
+		"The certificate of webhook server is required if enabled.")
+
 	gfs := fss.FlagSet("generic")
 	gfs.StringVar(&s.ApplicationSelector, "application-selector", s.ApplicationSelector, ""+
 		"Only reconcile application(sigs.k8s.io/application) objects match given selector, this could avoid conflicts with "+
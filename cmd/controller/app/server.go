@@ -21,6 +21,7 @@ import (
 
 	"github.com/jenkins-zh/jenkins-client/pkg/core"
 	"kubesphere.io/devops/cmd/controller/app/options"
+	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
 	"kubesphere.io/devops/pkg/apis"
 	"kubesphere.io/devops/pkg/client/devops"
 	"kubesphere.io/devops/pkg/client/devops/jenkins"
@@ -51,9 +52,12 @@ func NewControllerManagerCommand() *cobra.Command {
 			KubernetesOptions: conf.KubernetesOptions,
 			JenkinsOptions:    conf.JenkinsOptions,
 			S3Options:         conf.S3Options,
+			VaultOptions:      conf.VaultOptions,
+			SonarQubeOptions:  conf.SonarQubeOptions,
 			LeaderElection:    s.LeaderElection,
 			LeaderElect:       s.LeaderElect,
 			WebhookCertDir:    s.WebhookCertDir,
+			EnableWebhook:     s.EnableWebhook,
 		}
 	} else {
 		klog.Fatal("Failed to load configuration from disk", err)
@@ -181,6 +185,15 @@ func Run(s *options.DevOpsControllerManagerOptions, stopCh <-chan struct{}) erro
 		return fmt.Errorf("unable to register controllers to the manager: %v", err)
 	}
 
+	if s.EnableWebhook {
+		if err = (&devopsv1alpha3.Pipeline{}).SetupWebhookWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to register the webhooks of pipeline: %v", err)
+		}
+		if err = (&devopsv1alpha3.PipelineRun{}).SetupWebhookWithManager(mgr); err != nil {
+			return fmt.Errorf("unable to register the webhooks of pipelinerun: %v", err)
+		}
+	}
+
 	// Start cache data after all informer is registered
 	klog.V(0).Info("Starting cache resource from apiserver...")
 	informerFactory.Start(stopCh)
@@ -40,12 +40,12 @@ import (
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 
+	"kubesphere.io/devops/controllers/drift"
 	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
 
 	kubesphereclient "kubesphere.io/devops/pkg/client/clientset/versioned"
 	devopsClient "kubesphere.io/devops/pkg/client/devops"
 	"kubesphere.io/devops/pkg/constants"
-	"kubesphere.io/devops/pkg/utils"
 	"kubesphere.io/devops/pkg/utils/k8sutil"
 	"kubesphere.io/devops/pkg/utils/sliceutil"
 )
@@ -72,6 +72,13 @@ type Controller struct {
 	workerLoopPeriod time.Duration
 
 	devopsClient devopsClient.Interface
+
+	driftOptions drift.Options
+
+	expiryWarningPeriod time.Duration
+
+	externalProviders       map[string]ExternalProvider
+	externalRefreshInterval time.Duration
 }
 
 func NewController(client clientset.Interface,
@@ -199,6 +206,15 @@ func (c *Controller) Run(workers int, stopCh <-chan struct{}) error {
 	for i := 0; i < workers; i++ {
 		go wait.Until(c.worker, c.workerLoopPeriod, stopCh)
 	}
+	if c.driftOptions.Enabled() {
+		go wait.Until(c.detectDrift, c.driftOptions.Interval, stopCh)
+	}
+	if c.expiryWarningPeriod > 0 {
+		go wait.Until(c.checkExpiry, expiryCheckInterval, stopCh)
+	}
+	if len(c.externalProviders) > 0 && c.externalRefreshInterval > 0 {
+		go wait.Until(c.refreshExternalCredentials, c.externalRefreshInterval, stopCh)
+	}
 
 	<-stopCh
 	return nil
@@ -246,9 +262,16 @@ func (c *Controller) syncHandler(key string) error {
 			copySecret.Annotations = map[string]string{}
 		}
 
+		// the data of the credentials referring to external providers is never stored in the secret
+		data, specHash, err := c.getCredentialData(copySecret)
+		if err != nil {
+			klog.Errorf("failed to get the data of secret %s, error %v", key, err)
+			c.markSyncFailed(secret, err)
+			return err
+		}
+
 		//If the sync is successful, return handle
 		if state, ok := copySecret.Annotations[devopsv1alpha3.CredentialSyncStatusAnnoKey]; ok && state == constants.StatusSuccessful {
-			specHash := utils.ComputeHash(copySecret.Data)
 			oldHash, _ := copySecret.Annotations[devopsv1alpha3.DevOpsCredentialDataHash] // don't need to check if it's nil, only compare if they're different
 			if specHash == oldHash {
 				// it was synced successfully, and there's any change with the Pipeline spec, skip this round
@@ -262,31 +285,41 @@ func (c *Controller) syncHandler(key string) error {
 		if !sliceutil.HasString(secret.ObjectMeta.Finalizers, devopsv1alpha3.CredentialFinalizerName) {
 			copySecret.ObjectMeta.Finalizers = append(copySecret.ObjectMeta.Finalizers, devopsv1alpha3.CredentialFinalizerName)
 		}
+		_, external := copySecret.Annotations[devopsv1alpha3.CredentialExternalRefAnnoKey]
+		jenkinsCredential := copySecret
+		if external {
+			jenkinsCredential = copySecret.DeepCopy()
+			jenkinsCredential.Data = data
+		}
 		// Check secret config exists, otherwise we will create it.
 		// if secret exists, update config
-		_, err := c.devopsClient.GetCredentialInProject(nsName, copySecret.Name)
+		_, err = c.devopsClient.GetCredentialInProject(nsName, copySecret.Name)
 		if err == nil {
-			if _, ok := copySecret.Annotations[devopsv1alpha3.CredentialAutoSyncAnnoKey]; ok {
-				_, err := c.devopsClient.UpdateCredentialInProject(nsName, copySecret)
+			if _, ok := copySecret.Annotations[devopsv1alpha3.CredentialAutoSyncAnnoKey]; ok || external {
+				_, err := c.devopsClient.UpdateCredentialInProject(nsName, jenkinsCredential)
 				if err != nil {
 					klog.V(8).Info(err, fmt.Sprintf("failed to update secret %s ", key))
+					c.markSyncFailed(secret, err)
 					return err
 				}
 			}
 		} else {
-			_, err = c.devopsClient.CreateCredentialInProject(nsName, copySecret)
+			_, err = c.devopsClient.CreateCredentialInProject(nsName, jenkinsCredential)
 			if err != nil {
 				klog.V(8).Info(err, fmt.Sprintf("failed to create secret %s ", key))
+				c.markSyncFailed(secret, err)
 				return err
 			}
 		}
 		//If there is no early return, then the sync is successful.
 		copySecret.Annotations[devopsv1alpha3.CredentialSyncStatusAnnoKey] = constants.StatusSuccessful
+		delete(copySecret.Annotations, devopsv1alpha3.CredentialSyncMsgAnnoKey)
 	} else {
 		// Finalizers processing logic
 		if sliceutil.HasString(copySecret.ObjectMeta.Finalizers, devopsv1alpha3.CredentialFinalizerName) {
 			delSuccess := false
-			if _, err := c.devopsClient.DeleteCredentialInProject(nsName, secret.Name); err != nil {
+			_, err := c.devopsClient.DeleteCredentialInProject(nsName, secret.Name)
+			if err != nil {
 				// the status code should be 404 if the credential does not exists
 				if srvErr, ok := err.(restful.ServiceError); ok {
 					delSuccess = srvErr.Code == http.StatusNotFound
@@ -308,6 +341,7 @@ func (c *Controller) syncHandler(key string) error {
 			} else {
 				// make sure the corresponding Jenkins credentials can be clean
 				// You can remove the finalizer via kubectl manually in a very special case that Jenkins might be not able to available anymore
+				c.markSyncFailed(secret, err)
 				return fmt.Errorf("failed to remove devops credential finalizer due to bad communication with Jenkins")
 			}
 
@@ -323,6 +357,25 @@ func (c *Controller) syncHandler(key string) error {
 	return nil
 }
 
+// markSyncFailed records the error of synchronizing the credential into Jenkins in the annotations, because Secret has
+// no status. Nothing is updated if the same error was recorded, otherwise the update would trigger another sync.
+func (c *Controller) markSyncFailed(secret *v1.Secret, syncErr error) {
+	if secret.Annotations[devopsv1alpha3.CredentialSyncStatusAnnoKey] == constants.StatusFailed &&
+		secret.Annotations[devopsv1alpha3.CredentialSyncMsgAnnoKey] == syncErr.Error() {
+		return
+	}
+	copySecret := secret.DeepCopy()
+	if copySecret.Annotations == nil {
+		copySecret.Annotations = map[string]string{}
+	}
+	copySecret.Annotations[devopsv1alpha3.CredentialSyncStatusAnnoKey] = constants.StatusFailed
+	copySecret.Annotations[devopsv1alpha3.CredentialSyncMsgAnnoKey] = syncErr.Error()
+	copySecret.Annotations[devopsv1alpha3.CredentialSyncTimeAnnoKey] = time.Now().String()
+	if _, err := c.client.CoreV1().Secrets(secret.Namespace).Update(context.Background(), copySecret, metav1.UpdateOptions{}); err != nil {
+		klog.Errorf("failed to record the sync failure of secret %s/%s, error %v", secret.Namespace, secret.Name, err)
+	}
+}
+
 func isDevOpsProjectAdminNamespace(namespace *v1.Namespace) bool {
 	_, ok := namespace.Labels[constants.DevOpsProjectLabelKey]
 
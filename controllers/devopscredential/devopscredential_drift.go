@@ -0,0 +1,78 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devopscredential
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+
+	"kubesphere.io/devops/controllers/drift"
+	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+)
+
+// EnableDriftDetection makes the controller compare the credentials with the ones in Jenkins periodically.
+func (c *Controller) EnableDriftDetection(options drift.Options) {
+	c.driftOptions = options
+}
+
+// detectDrift finds out the synchronized credentials which don't exist in Jenkins. The missing credentials are
+// created again unless in dry-run mode. Secret has no conditions, so the drift is reported through events and metrics.
+// Jenkins never returns the secret data, so the credentials modified in Jenkins cannot be detected.
+func (c *Controller) detectDrift() {
+	secrets, err := c.secretLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list secrets for drift detection, error %v", err)
+		return
+	}
+
+	drifts := map[drift.Type]int{}
+	for _, secret := range secrets {
+		// the credentials which are not synchronized yet are taken care of by syncHandler
+		if !strings.HasPrefix(string(secret.Type), devopsv1alpha3.DevOpsCredentialPrefix) ||
+			!secret.DeletionTimestamp.IsZero() ||
+			secret.Annotations[devopsv1alpha3.CredentialSyncStatusAnnoKey] != constants.StatusSuccessful {
+			continue
+		}
+		_, err := c.devopsClient.GetCredentialInProject(secret.Namespace, secret.Name)
+		if err == nil {
+			continue
+		} else if !drift.IsNotFound(err) {
+			klog.V(4).Infof("failed to check the drift of secret %s/%s, error %v", secret.Namespace, secret.Name, err)
+			continue
+		}
+
+		drifts[drift.Missing]++
+		klog.Infof("secret %s/%s drifted from Jenkins: %s", secret.Namespace, secret.Name, drift.Missing)
+		if c.driftOptions.DryRun {
+			c.eventRecorder.Event(secret, v1.EventTypeWarning, devopsv1alpha3.Drifted, "The credential does not exist in Jenkins")
+			continue
+		}
+		credential, err := c.getJenkinsCredential(secret)
+		if err == nil {
+			_, err = c.devopsClient.CreateCredentialInProject(secret.Namespace, credential)
+		}
+		if err != nil {
+			klog.Errorf("failed to repair the drift of secret %s/%s, error %v", secret.Namespace, secret.Name, err)
+			c.markSyncFailed(secret, err)
+		}
+	}
+	drift.Report("Credential", drifts)
+}
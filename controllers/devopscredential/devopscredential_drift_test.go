@@ -0,0 +1,66 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devopscredential
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"kubesphere.io/devops/controllers/drift"
+)
+
+func TestController_detectDrift(t *testing.T) {
+	nsName := "test-123"
+	newDriftFixture := func(t *testing.T) *fixture {
+		missing := newSecret(nsName, "missing", nil, true, false, true)
+		synced := newSecret(nsName, "synced", nil, true, false, true)
+		// the pending credential is left to syncHandler
+		pending := newSecret(nsName, "pending", nil, true, false, false)
+
+		f := newFixture(t)
+		f.secretLister = []*v1.Secret{missing, synced, pending}
+		f.kubeobjects = append(f.kubeobjects, missing, synced, pending)
+		f.initDevOpsProject = nsName
+		f.initCredential = []*v1.Secret{synced.DeepCopy()}
+		return f
+	}
+
+	t.Run("Dry run", func(t *testing.T) {
+		f := newDriftFixture(t)
+		c, _, dI := f.newController()
+		recorder := record.NewFakeRecorder(10)
+		c.eventRecorder = recorder
+		c.EnableDriftDetection(drift.Options{DryRun: true})
+		c.detectDrift()
+
+		assert.Nil(t, dI.Credentials[nsName]["missing"])
+		assert.Equal(t, 1, len(recorder.Events))
+	})
+
+	t.Run("Repair", func(t *testing.T) {
+		f := newDriftFixture(t)
+		c, _, dI := f.newController()
+		c.EnableDriftDetection(drift.Options{})
+		c.detectDrift()
+
+		assert.NotNil(t, dI.Credentials[nsName]["missing"])
+		assert.Nil(t, dI.Credentials[nsName]["pending"])
+	})
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devopscredential
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+// Valid values for event reasons of the credential expiry
+const (
+	CredentialExpiring      = "CredentialExpiring"
+	CredentialExpired       = "CredentialExpired"
+	InvalidCredentialExpiry = "InvalidCredentialExpiry"
+)
+
+// expiryCheckInterval is the interval of checking the expiry of credentials
+const expiryCheckInterval = time.Hour
+
+var credentialExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "devops_credential_expiry_timestamp_seconds",
+	Help: "The expiry time of the DevOps credentials in Unix seconds",
+}, []string{"namespace", "name"})
+
+func init() {
+	metrics.Registry.MustRegister(credentialExpiry)
+}
+
+// EnableExpiryCheck makes the controller report the credentials which expire within the warning period.
+func (c *Controller) EnableExpiryCheck(warningPeriod time.Duration) {
+	c.expiryWarningPeriod = warningPeriod
+}
+
+// checkExpiry reports the expiry of credentials through warning events and metrics.
+func (c *Controller) checkExpiry() {
+	secrets, err := c.secretLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list secrets for expiry check, error %v", err)
+		return
+	}
+
+	// the deleted credentials should disappear from the metrics
+	credentialExpiry.Reset()
+	now := time.Now()
+	for _, secret := range secrets {
+		if !strings.HasPrefix(string(secret.Type), devopsv1alpha3.DevOpsCredentialPrefix) || !secret.DeletionTimestamp.IsZero() {
+			continue
+		}
+		expiresAt, err := getExpiresAt(secret)
+		if err != nil {
+			c.eventRecorder.Eventf(secret, v1.EventTypeWarning, InvalidCredentialExpiry,
+				"The expiry time should be in RFC 3339 format, error %v", err)
+			continue
+		} else if expiresAt == nil {
+			continue
+		}
+
+		credentialExpiry.WithLabelValues(secret.Namespace, secret.Name).Set(float64(expiresAt.Unix()))
+		if !expiresAt.After(now) {
+			c.eventRecorder.Eventf(secret, v1.EventTypeWarning, CredentialExpired,
+				"The credential expired at %s, please rotate it", expiresAt.Format(time.RFC3339))
+		} else if expiresAt.Sub(now) <= c.expiryWarningPeriod {
+			c.eventRecorder.Eventf(secret, v1.EventTypeWarning, CredentialExpiring,
+				"The credential will expire at %s, please rotate it", expiresAt.Format(time.RFC3339))
+		}
+	}
+}
+
+// getExpiresAt returns the expiry time of the credential, or nil if the credential never expires.
+func getExpiresAt(secret *v1.Secret) (*time.Time, error) {
+	value, ok := secret.Annotations[devopsv1alpha3.CredentialExpiresAtAnnoKey]
+	if !ok || value == "" {
+		return nil, nil
+	}
+	expiresAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &expiresAt, nil
+}
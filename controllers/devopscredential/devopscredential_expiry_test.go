@@ -0,0 +1,99 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devopscredential
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	devops "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestController_checkExpiry(t *testing.T) {
+	nsName := "test-123"
+	now := time.Now()
+	newExpiringSecret := func(name, expiresAt string) *v1.Secret {
+		secret := newSecret(nsName, name, nil, true, false, true)
+		if expiresAt != "" {
+			secret.Annotations[devops.CredentialExpiresAtAnnoKey] = expiresAt
+		}
+		return secret
+	}
+	expired := newExpiringSecret("expired", now.Add(-time.Hour).Format(time.RFC3339))
+	expiring := newExpiringSecret("expiring", now.Add(24*time.Hour).Format(time.RFC3339))
+	valid := newExpiringSecret("valid", now.Add(30*24*time.Hour).Format(time.RFC3339))
+	permanent := newExpiringSecret("permanent", "")
+	invalid := newExpiringSecret("invalid", "tomorrow")
+
+	f := newFixture(t)
+	f.secretLister = []*v1.Secret{expired, expiring, valid, permanent, invalid}
+	c, _, _ := f.newController()
+	recorder := record.NewFakeRecorder(10)
+	c.eventRecorder = recorder
+	c.EnableExpiryCheck(7 * 24 * time.Hour)
+	c.checkExpiry()
+
+	var reasons []string
+	for len(recorder.Events) > 0 {
+		reasons = append(reasons, strings.Fields(<-recorder.Events)[1])
+	}
+	sort.Strings(reasons)
+	assert.Equal(t, []string{CredentialExpired, CredentialExpiring, InvalidCredentialExpiry}, reasons)
+
+	expectedExpiry, _ := time.Parse(time.RFC3339, valid.Annotations[devops.CredentialExpiresAtAnnoKey])
+	assert.Equal(t, float64(expectedExpiry.Unix()), testutil.ToFloat64(credentialExpiry.WithLabelValues(nsName, "valid")))
+}
+
+func Test_getExpiresAt(t *testing.T) {
+	expiresAt := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        *time.Time
+		wantErr     bool
+	}{{
+		name: "without expiry",
+	}, {
+		name:        "with expiry",
+		annotations: map[string]string{devops.CredentialExpiresAtAnnoKey: "2021-10-01T00:00:00Z"},
+		want:        &expiresAt,
+	}, {
+		name:        "invalid expiry",
+		annotations: map[string]string{devops.CredentialExpiresAtAnnoKey: "2021-10-01"},
+		wantErr:     true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &v1.Secret{}
+			secret.Annotations = tt.annotations
+			got, err := getExpiresAt(secret)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.want == nil {
+				assert.Nil(t, got)
+			} else if assert.NotNil(t, got) {
+				assert.True(t, tt.want.Equal(*got))
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devopscredential
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+
+	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/utils"
+)
+
+// ExternalProvider resolves the data of credentials from an external secret management system, e.g. Vault
+type ExternalProvider interface {
+	// Read returns the data of the secret in the path, and the version of the data which changes whenever the data
+	// changes
+	Read(path string) (map[string][]byte, string, error)
+}
+
+// EnableExternalProvider makes the controller resolve the data of the credentials which refer to the provider. The
+// data is synchronized into Jenkins again in every refresh interval, zero means never.
+func (c *Controller) EnableExternalProvider(name string, provider ExternalProvider, refreshInterval time.Duration) {
+	if c.externalProviders == nil {
+		c.externalProviders = map[string]ExternalProvider{}
+	}
+	c.externalProviders[name] = provider
+	c.externalRefreshInterval = refreshInterval
+}
+
+// getCredentialData returns the data of the credential which should be synchronized into Jenkins, and the revision
+// which tells if the data changed. The revision of the credential referring to an external provider is the reference
+// and the version of the data in the provider, so that no value of the data ever leaks into the annotations.
+func (c *Controller) getCredentialData(secret *v1.Secret) (map[string][]byte, string, error) {
+	ref := secret.Annotations[devopsv1alpha3.CredentialExternalRefAnnoKey]
+	if ref == "" {
+		return secret.Data, utils.ComputeHash(secret.Data), nil
+	}
+	// the reference looks like "vault:project-abc/github"
+	items := strings.SplitN(ref, ":", 2)
+	if len(items) != 2 || items[1] == "" {
+		return nil, "", fmt.Errorf("invalid external reference %s, it should be like <provider>:<path>", ref)
+	}
+	if err := checkExternalPath(secret.Namespace, items[1]); err != nil {
+		return nil, "", err
+	}
+	provider, ok := c.externalProviders[items[0]]
+	if !ok {
+		return nil, "", fmt.Errorf("external provider %s is not enabled", items[0])
+	}
+	data, version, err := provider.Read(items[1])
+	if err != nil {
+		return nil, "", err
+	}
+	return data, ref + "@" + version, nil
+}
+
+// checkExternalPath makes sure the path is inside the scope of the DevOps project, which is the prefix
+// "<namespace>/". The providers read secrets with a global identity, so a credential must never refer to the secrets
+// of other projects.
+func checkExternalPath(namespace, externalPath string) error {
+	if path.Clean(externalPath) != externalPath || !strings.HasPrefix(externalPath, namespace+"/") {
+		return fmt.Errorf("the external path %s is out of the scope of the DevOps project, it should be like %s/<name>",
+			externalPath, namespace)
+	}
+	return nil
+}
+
+// getJenkinsCredential returns a copy of the credential with the data which should be synchronized into Jenkins.
+func (c *Controller) getJenkinsCredential(secret *v1.Secret) (*v1.Secret, error) {
+	data, _, err := c.getCredentialData(secret)
+	if err != nil {
+		return nil, err
+	}
+	credential := secret.DeepCopy()
+	credential.Data = data
+	return credential, nil
+}
+
+// refreshExternalCredentials enqueues the credentials referring to external providers, then the data changed in the
+// providers is synchronized into Jenkins.
+func (c *Controller) refreshExternalCredentials() {
+	secrets, err := c.secretLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list secrets for refreshing external credentials, error %v", err)
+		return
+	}
+	for _, secret := range secrets {
+		if strings.HasPrefix(string(secret.Type), devopsv1alpha3.DevOpsCredentialPrefix) &&
+			secret.Annotations[devopsv1alpha3.CredentialExternalRefAnnoKey] != "" {
+			c.enqueueSecret(secret)
+		}
+	}
+}
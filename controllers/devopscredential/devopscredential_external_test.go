@@ -0,0 +1,144 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devopscredential
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	devops "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+)
+
+type fakeProvider map[string]map[string][]byte
+
+func (p fakeProvider) Read(path string) (map[string][]byte, string, error) {
+	if data, ok := p[path]; ok {
+		return data, "1", nil
+	}
+	return nil, "", fmt.Errorf("secret %s not found", path)
+}
+
+func TestController_syncExternalCredential(t *testing.T) {
+	nsName := "test-123"
+	provider := fakeProvider{
+		"test-123/github":   {devops.BasicAuthPasswordKey: []byte("token")},
+		"another/github":    {devops.BasicAuthPasswordKey: []byte("another")},
+		"test-123-a/github": {devops.BasicAuthPasswordKey: []byte("another")},
+	}
+	newExternalSecret := func(name, ref string) *v1.Secret {
+		secret := newSecret(nsName, name, nil, true, false, false)
+		secret.Annotations[devops.CredentialExternalRefAnnoKey] = ref
+		return secret
+	}
+
+	tests := []struct {
+		name     string
+		ref      string
+		wantData map[string][]byte
+		wantErr  bool
+	}{{
+		name:     "normal case",
+		ref:      "vault:test-123/github",
+		wantData: provider["test-123/github"],
+	}, {
+		name:    "missing secret in provider",
+		ref:     "vault:test-123/gitlab",
+		wantErr: true,
+	}, {
+		name:    "unknown provider",
+		ref:     "aws:test-123/github",
+		wantErr: true,
+	}, {
+		name:    "invalid reference",
+		ref:     "test-123/github",
+		wantErr: true,
+	}, {
+		name:    "path of another project",
+		ref:     "vault:another/github",
+		wantErr: true,
+	}, {
+		name:    "path of a project with the same prefix",
+		ref:     "vault:test-123-a/github",
+		wantErr: true,
+	}, {
+		name:    "path escaping from the project",
+		ref:     "vault:test-123/../another/github",
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := newExternalSecret("test", tt.ref)
+			f := newFixture(t)
+			f.secretLister = append(f.secretLister, secret)
+			f.namespaceLister = append(f.namespaceLister, newNamespace(nsName, "test_project"))
+			f.kubeobjects = append(f.kubeobjects, secret)
+			f.initDevOpsProject = nsName
+
+			c, _, dI := f.newController()
+			c.EnableExternalProvider("vault", provider, 0)
+			err := c.syncHandler(getKey(secret, t))
+			assert.Equal(t, tt.wantErr, err != nil, err)
+
+			updated, getErr := f.kubeclient.CoreV1().Secrets(nsName).Get(context.Background(), "test", metav1.GetOptions{})
+			if !assert.Nil(t, getErr) {
+				return
+			}
+			// the resolved data never reaches the secret
+			assert.Empty(t, updated.Data)
+			if tt.wantErr {
+				assert.Nil(t, dI.Credentials[nsName]["test"])
+				assert.Equal(t, constants.StatusFailed, updated.Annotations[devops.CredentialSyncStatusAnnoKey])
+			} else if assert.NotNil(t, dI.Credentials[nsName]["test"]) {
+				assert.Equal(t, tt.wantData, dI.Credentials[nsName]["test"].Data)
+			}
+		})
+	}
+}
+
+func TestController_syncExternalCredentialRevision(t *testing.T) {
+	nsName := "test-123"
+	provider := fakeProvider{"test-123/github": {devops.BasicAuthPasswordKey: []byte("token")}}
+	secret := newSecret(nsName, "test", nil, true, false, true)
+	secret.Annotations[devops.CredentialExternalRefAnnoKey] = "vault:test-123/github"
+	secret.Annotations[devops.DevOpsCredentialDataHash] = "vault:test-123/github@0"
+
+	f := newFixture(t)
+	f.secretLister = append(f.secretLister, secret)
+	f.namespaceLister = append(f.namespaceLister, newNamespace(nsName, "test_project"))
+	f.kubeobjects = append(f.kubeobjects, secret)
+	f.initDevOpsProject = nsName
+
+	c, _, dI := f.newController()
+	c.EnableExternalProvider("vault", provider, 0)
+	assert.Nil(t, c.syncHandler(getKey(secret, t)))
+
+	updated, err := f.kubeclient.CoreV1().Secrets(nsName).Get(context.Background(), "test", metav1.GetOptions{})
+	if !assert.Nil(t, err) {
+		return
+	}
+	// the version in the provider is recorded instead of anything derived from the data
+	assert.Equal(t, "vault:test-123/github@1", updated.Annotations[devops.DevOpsCredentialDataHash])
+	if assert.NotNil(t, dI.Credentials[nsName]["test"]) {
+		assert.Equal(t, provider["test-123/github"], dI.Credentials[nsName]["test"].Data)
+	}
+}
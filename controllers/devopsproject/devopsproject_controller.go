@@ -40,6 +40,7 @@ import (
 	"k8s.io/klog"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
+	"kubesphere.io/devops/controllers/drift"
 	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
 
 	"kubesphere.io/devops/pkg/client/clientset/versioned/scheme"
@@ -77,6 +78,8 @@ type Controller struct {
 	workerLoopPeriod time.Duration
 
 	devopsClient devopsClient.Interface
+
+	driftOptions drift.Options
 }
 
 func NewController(client clientset.Interface,
@@ -111,7 +114,9 @@ func NewController(client clientset.Interface,
 		UpdateFunc: func(oldObj, newObj interface{}) {
 			oldDevOpsProject := oldObj.(*devopsv1alpha3.DevOpsProject)
 			newDevOpsProject := newObj.(*devopsv1alpha3.DevOpsProject)
-			if oldDevOpsProject.ResourceVersion == newDevOpsProject.ResourceVersion {
+			// the status updated by the controller itself doesn't need to be reconciled again
+			if oldDevOpsProject.ResourceVersion == newDevOpsProject.ResourceVersion ||
+				k8sutil.IsStatusOnlyUpdate(oldDevOpsProject, newDevOpsProject) {
 				return
 			}
 			v.enqueueDevOpsProject(newObj)
@@ -192,6 +197,9 @@ func (c *Controller) Run(workers int, stopCh <-chan struct{}) error {
 	for i := 0; i < workers; i++ {
 		go wait.Until(c.worker, c.workerLoopPeriod, stopCh)
 	}
+	if c.driftOptions.Enabled() {
+		go wait.Until(c.detectDrift, c.driftOptions.Interval, stopCh)
+	}
 
 	<-stopCh
 	return nil
@@ -213,8 +221,11 @@ func (c *Controller) syncHandler(key string) error {
 	copyProject := project.DeepCopy()
 	// DeletionTimestamp.IsZero() means DevOps project has not been deleted.
 	if project.ObjectMeta.DeletionTimestamp.IsZero() {
-		//If the sync is successful, return handle
-		if state, ok := project.Annotations[devopsv1alpha3.DevOpeProjectSyncStatusAnnoKey]; ok && state == constants.StatusSuccessful {
+		//If the sync is successful and the spec has not changed since then, return handle
+		if state, ok := project.Annotations[devopsv1alpha3.DevOpeProjectSyncStatusAnnoKey]; ok && state == constants.StatusSuccessful &&
+			project.Annotations[devopsv1alpha3.DevOpsProjectSpecHashAnnoKey] == getSpecHash(&project.Spec) &&
+			project.Status.ObservedGeneration == project.Generation &&
+			!isImportRequested(project) {
 			return nil
 		}
 
@@ -232,7 +243,7 @@ func (c *Controller) syncHandler(key string) error {
 			} else if errors.IsNotFound(err) {
 				// if admin ns is not found, clean project status, rerun reconcile
 				copyProject.Status.AdminNamespace = ""
-				_, err := c.kubesphereClient.DevopsV1alpha3().DevOpsProjects().Update(context.Background(), copyProject, metav1.UpdateOptions{})
+				err := c.updateDevOpsProject(project, copyProject)
 				if err != nil {
 					klog.V(8).Info(err, fmt.Sprintf("failed to update project %s ", key))
 					return err
@@ -320,28 +331,56 @@ func (c *Controller) syncHandler(key string) error {
 			_, err := c.devopsClient.CreateDevOpsProject(copyProject.Status.AdminNamespace)
 			if err != nil {
 				klog.V(8).Info(err, fmt.Sprintf("failed to get project %s ", key))
+				c.markJenkinsSyncFailed(copyProject, devopsv1alpha3.SyncFailed, err)
 				return err
 			}
 		}
 
+		// reconcile the declarative fields, e.g. quotas and members
+		if err := c.reconcileSpec(copyProject); err != nil {
+			klog.Errorf("failed to reconcile the spec of devopsproject %s, error %v", key, err)
+			c.eventRecorder.Event(project, v1.EventTypeWarning, "ReconcileSpecFailed", err.Error())
+			c.markJenkinsSyncFailed(copyProject, "ReconcileSpecFailed", err)
+			return err
+		}
+
+		// import the existing Jenkins jobs as Pipelines on request
+		if isImportRequested(copyProject) {
+			if err := c.importJenkinsJobs(copyProject); err != nil {
+				klog.Errorf("failed to import the Jenkins jobs of devopsproject %s, error %v", key, err)
+				c.eventRecorder.Event(project, v1.EventTypeWarning, "ImportJenkinsJobsFailed", err.Error())
+				if isImportRetryable(err) {
+					c.markJobsImportFailed(copyProject, err)
+					return err
+				}
+				// e.g. the source folder doesn't exist, the request is dropped since retrying never helps
+				copyProject.Status.Conditions = devopsv1alpha3.SetCondition(copyProject.Status.Conditions,
+					devopsv1alpha3.NewCondition(devopsv1alpha3.ConditionJobsImported, devopsv1alpha3.ConditionFalse, devopsv1alpha3.ImportFailed, err.Error()))
+			}
+			delete(copyProject.Annotations, devopsv1alpha3.DevOpsProjectRequestToImportJobsAnnoKey)
+			delete(copyProject.Annotations, devopsv1alpha3.DevOpsProjectImportSourceFolderAnnoKey)
+		}
+
 		//If there is no early return, then the sync is successful.
 		if copyProject.Annotations == nil {
 			copyProject.Annotations = map[string]string{}
 		}
 		copyProject.Annotations[devopsv1alpha3.DevOpeProjectSyncStatusAnnoKey] = constants.StatusSuccessful
-		if !reflect.DeepEqual(copyProject, project) {
-			copyProject, err = c.kubesphereClient.DevopsV1alpha3().DevOpsProjects().Update(context.Background(), copyProject, metav1.UpdateOptions{})
-			if err != nil {
-				klog.V(8).Info(err, fmt.Sprintf("failed to update ns %s ", key))
-				return err
-			}
+		copyProject.Annotations[devopsv1alpha3.DevOpsProjectSpecHashAnnoKey] = getSpecHash(&copyProject.Spec)
+		copyProject.Status.ObservedGeneration = project.Generation
+		copyProject.Status.Conditions = devopsv1alpha3.SetCondition(copyProject.Status.Conditions,
+			devopsv1alpha3.NewJenkinsSyncedCondition(devopsv1alpha3.ConditionTrue, devopsv1alpha3.Synced, ""))
+		if err := c.updateDevOpsProject(project, copyProject); err != nil {
+			klog.V(8).Info(err, fmt.Sprintf("failed to update ns %s ", key))
+			return err
 		}
 
 	} else {
 		// Finalizers processing logic
 		if sliceutil.HasString(project.ObjectMeta.Finalizers, devopsv1alpha3.DevOpsProjectFinalizerName) {
 			delSuccess := false
-			if err := c.deleteDevOpsProjectInDevOps(project); err != nil {
+			err := c.deleteDevOpsProjectInDevOps(project)
+			if err != nil {
 				// the status code should be 404 if the job does not exists
 				if srvErr, ok := err.(restful.ServiceError); ok {
 					delSuccess = srvErr.Code == http.StatusNotFound
@@ -363,6 +402,7 @@ func (c *Controller) syncHandler(key string) error {
 			} else {
 				// make sure the corresponding Jenkins job can be clean
 				// You can remove the finalizer via kubectl manually in a very special case that Jenkins might be not able to available anymore
+				c.markJenkinsSyncFailed(copyProject, devopsv1alpha3.SyncFailed, err)
 				return fmt.Errorf("failed to remove devopsproject finalizer due to bad communication with Jenkins")
 			}
 
@@ -409,6 +449,38 @@ func (c *Controller) syncHandler(key string) error {
 //	return project, nil
 //}
 
+// updateDevOpsProject updates the DevOpsProject and its status separately, because the status is a subresource.
+func (c *Controller) updateDevOpsProject(project, copyProject *devopsv1alpha3.DevOpsProject) error {
+	if !reflect.DeepEqual(project.ObjectMeta, copyProject.ObjectMeta) || !reflect.DeepEqual(project.Spec, copyProject.Spec) {
+		updated, err := c.kubesphereClient.DevopsV1alpha3().DevOpsProjects().Update(context.Background(), copyProject, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+		copyProject.ResourceVersion = updated.ResourceVersion
+	}
+	if !reflect.DeepEqual(project.Status, copyProject.Status) {
+		if _, err := c.kubesphereClient.DevopsV1alpha3().DevOpsProjects().UpdateStatus(context.Background(), copyProject, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markJenkinsSyncFailed records the failure of synchronizing into Jenkins in the conditions of the DevOpsProject. The
+// status is not updated if the same failure was recorded, otherwise every retry would refresh the probe time.
+func (c *Controller) markJenkinsSyncFailed(project *devopsv1alpha3.DevOpsProject, reason string, syncErr error) {
+	if condition := devopsv1alpha3.FindCondition(project.Status.Conditions, devopsv1alpha3.ConditionJenkinsSynced); condition != nil &&
+		condition.Status == devopsv1alpha3.ConditionFalse && condition.Reason == reason && condition.Message == syncErr.Error() {
+		return
+	}
+	copyProject := project.DeepCopy()
+	copyProject.Status.Conditions = devopsv1alpha3.SetCondition(copyProject.Status.Conditions,
+		devopsv1alpha3.NewJenkinsSyncedCondition(devopsv1alpha3.ConditionFalse, reason, syncErr.Error()))
+	if _, err := c.kubesphereClient.DevopsV1alpha3().DevOpsProjects().UpdateStatus(context.Background(), copyProject, metav1.UpdateOptions{}); err != nil {
+		klog.Errorf("failed to update the status of devopsproject %s, error %v", project.Name, err)
+	}
+}
+
 func (c *Controller) deleteDevOpsProjectInDevOps(project *devopsv1alpha3.DevOpsProject) (err error) {
 	err = c.devopsClient.DeleteDevOpsProject(project.Status.AdminNamespace)
 	return
@@ -17,6 +17,8 @@ limitations under the License.
 package devopsproject
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -355,3 +357,31 @@ func TestDeleteDevOpsProjectsWithNull(t *testing.T) {
 	f.expectUpdateDevOpsProjectAction(expectProject)
 	f.run(getKey(project, t))
 }
+
+func TestMarkJenkinsSyncFailed(t *testing.T) {
+	project := newDevOpsProject("test", "test-123", true, true)
+	client := fake.NewSimpleClientset(project)
+	c := &Controller{kubesphereClient: client}
+
+	c.markJenkinsSyncFailed(project, devops.SyncFailed, errors.New("connection refused"))
+	actual, err := client.DevopsV1alpha3().DevOpsProjects().Get(context.Background(), "test", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get devopsproject: %v", err)
+	}
+	condition := devops.FindCondition(actual.Status.Conditions, devops.ConditionJenkinsSynced)
+	if condition == nil || condition.Status != devops.ConditionFalse || condition.Reason != devops.SyncFailed {
+		t.Errorf("unexpected condition JenkinsSynced: %v", condition)
+	}
+
+	// the same failure should not update the status again
+	client.ClearActions()
+	c.markJenkinsSyncFailed(actual, devops.SyncFailed, errors.New("connection refused"))
+	if actions := client.Actions(); len(actions) != 0 {
+		t.Errorf("unexpected actions: %+v", actions)
+	}
+
+	c.markJenkinsSyncFailed(actual, "ReconcileSpecFailed", errors.New("connection refused"))
+	if actions := client.Actions(); len(actions) != 1 || actions[0].GetSubresource() != "status" {
+		t.Errorf("expected one status update, got %+v", actions)
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devopsproject
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+
+	"kubesphere.io/devops/controllers/drift"
+	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+)
+
+const folderMissingMessage = "The folder of the DevOpsProject does not exist in Jenkins"
+
+// EnableDriftDetection makes the controller compare the DevOpsProjects with the folders in Jenkins periodically.
+func (c *Controller) EnableDriftDetection(options drift.Options) {
+	c.driftOptions = options
+}
+
+// detectDrift finds out the synchronized DevOpsProjects whose folders don't exist in Jenkins. The missing folders are
+// created again unless in dry-run mode, and the drift is reported through the condition JenkinsSynced and metrics.
+func (c *Controller) detectDrift() {
+	projects, err := c.devOpsProjectLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list devopsprojects for drift detection, error %v", err)
+		return
+	}
+
+	drifts := map[drift.Type]int{}
+	for _, project := range projects {
+		// the DevOpsProjects which are not synchronized yet are taken care of by syncHandler
+		if !project.DeletionTimestamp.IsZero() || project.Status.AdminNamespace == "" ||
+			project.Annotations[devopsv1alpha3.DevOpeProjectSyncStatusAnnoKey] != constants.StatusSuccessful {
+			continue
+		}
+		_, err := c.devopsClient.GetDevOpsProject(project.Status.AdminNamespace)
+		if err == nil {
+			if isDriftReported(project) {
+				c.updateSyncedCondition(project, devopsv1alpha3.NewJenkinsSyncedCondition(devopsv1alpha3.ConditionTrue, devopsv1alpha3.Synced, ""))
+			}
+			continue
+		} else if !drift.IsNotFound(err) {
+			klog.V(4).Infof("failed to check the drift of devopsproject %s, error %v", project.Name, err)
+			continue
+		}
+
+		drifts[drift.Missing]++
+		klog.Infof("devopsproject %s drifted from Jenkins: %s", project.Name, drift.Missing)
+		if c.driftOptions.DryRun {
+			if !isDriftReported(project) {
+				c.updateSyncedCondition(project, devopsv1alpha3.NewJenkinsSyncedCondition(devopsv1alpha3.ConditionFalse, devopsv1alpha3.Drifted, folderMissingMessage))
+			}
+			continue
+		}
+		if _, err := c.devopsClient.CreateDevOpsProject(project.Status.AdminNamespace); err != nil {
+			klog.Errorf("failed to repair the drift of devopsproject %s, error %v", project.Name, err)
+			c.markJenkinsSyncFailed(project, devopsv1alpha3.SyncFailed, err)
+		} else if isDriftReported(project) {
+			c.updateSyncedCondition(project, devopsv1alpha3.NewJenkinsSyncedCondition(devopsv1alpha3.ConditionTrue, devopsv1alpha3.Synced, ""))
+		}
+	}
+	drift.Report(devopsv1alpha3.ResourceKindDevOpsProject, drifts)
+}
+
+// updateSyncedCondition updates the condition JenkinsSynced of the DevOpsProject.
+func (c *Controller) updateSyncedCondition(project *devopsv1alpha3.DevOpsProject, condition devopsv1alpha3.Condition) {
+	copyProject := project.DeepCopy()
+	copyProject.Status.Conditions = devopsv1alpha3.SetCondition(copyProject.Status.Conditions, condition)
+	if _, err := c.kubesphereClient.DevopsV1alpha3().DevOpsProjects().UpdateStatus(context.Background(), copyProject, metav1.UpdateOptions{}); err != nil {
+		klog.Errorf("failed to update the status of devopsproject %s, error %v", project.Name, err)
+	}
+}
+
+// isDriftReported indicates if the drift of the DevOpsProject was reported before.
+func isDriftReported(project *devopsv1alpha3.DevOpsProject) bool {
+	condition := devopsv1alpha3.FindCondition(project.Status.Conditions, devopsv1alpha3.ConditionJenkinsSynced)
+	return condition != nil && condition.Status == devopsv1alpha3.ConditionFalse && condition.Reason == devopsv1alpha3.Drifted
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devopsproject
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"kubesphere.io/devops/controllers/drift"
+	devops "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+)
+
+func TestController_detectDrift(t *testing.T) {
+	newDriftFixture := func(t *testing.T) *fixture {
+		missing := newDevOpsProject("missing", "missing-ns", true, true)
+		missing.Annotations = map[string]string{devops.DevOpeProjectSyncStatusAnnoKey: constants.StatusSuccessful}
+		synced := newDevOpsProject("synced", "synced-ns", true, true)
+		synced.Annotations = map[string]string{devops.DevOpeProjectSyncStatusAnnoKey: constants.StatusSuccessful}
+		// the pending DevOpsProject is left to syncHandler
+		pending := newDevOpsProject("pending", "pending-ns", true, true)
+
+		f := newFixture(t)
+		f.devopsProjectLister = []*devops.DevOpsProject{missing, synced, pending}
+		f.objects = append(f.objects, missing, synced, pending)
+		f.initDevOpsProject = []string{"synced-ns"}
+		return f
+	}
+	getCondition := func(t *testing.T, f *fixture, name string) *devops.Condition {
+		project, err := f.client.DevopsV1alpha3().DevOpsProjects().Get(context.Background(), name, metav1.GetOptions{})
+		if !assert.Nil(t, err) {
+			return nil
+		}
+		return devops.FindCondition(project.Status.Conditions, devops.ConditionJenkinsSynced)
+	}
+
+	t.Run("Dry run", func(t *testing.T) {
+		f := newDriftFixture(t)
+		c, _, _, dI := f.newController()
+		c.EnableDriftDetection(drift.Options{DryRun: true})
+		c.detectDrift()
+
+		assert.Nil(t, dI.Projects["missing-ns"])
+		condition := getCondition(t, f, "missing")
+		if assert.NotNil(t, condition) {
+			assert.Equal(t, devops.ConditionFalse, condition.Status)
+			assert.Equal(t, devops.Drifted, condition.Reason)
+		}
+		assert.Nil(t, getCondition(t, f, "synced"))
+	})
+
+	t.Run("Repair", func(t *testing.T) {
+		f := newDriftFixture(t)
+		c, _, _, dI := f.newController()
+		c.EnableDriftDetection(drift.Options{})
+		c.detectDrift()
+
+		assert.NotNil(t, dI.Projects["missing-ns"])
+		assert.Nil(t, dI.Projects["pending-ns"])
+		assert.Nil(t, getCondition(t, f, "missing"))
+	})
+}
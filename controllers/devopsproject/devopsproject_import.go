@@ -0,0 +1,132 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devopsproject
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog"
+
+	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	servererr "kubesphere.io/devops/pkg/server/errors"
+)
+
+// importJenkinsJobs creates Pipelines for the jobs in the Jenkins folder of the DevOpsProject, or in the source folder
+// if it's requested. The jobs which have corresponding Pipelines already are left as they are. The run history of the
+// Pipelines imported from the folder of the DevOpsProject is backfilled by the PipelineRun synchronizer, see also
+// PipelineRequestToSyncRunsAnnoKey. The jobs imported from the source folder are copied into the folder of the
+// DevOpsProject without the run history.
+func (c *Controller) importJenkinsJobs(project *devopsv1alpha3.DevOpsProject) error {
+	nsName := project.Status.AdminNamespace
+	sourceFolder := getImportSourceFolder(project)
+	names, err := c.devopsClient.ListProjectPipelines(sourceFolder)
+	if err != nil {
+		return err
+	}
+
+	pipelineClient := c.kubesphereClient.DevopsV1alpha3().Pipelines(nsName)
+	var imported, skipped int
+	for _, name := range names {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			klog.Warningf("skip importing the Jenkins job %s/%s due to the invalid name: %s", sourceFolder, name, strings.Join(errs, ", "))
+			skipped++
+			continue
+		}
+		if _, err := pipelineClient.Get(context.Background(), name, metav1.GetOptions{}); err == nil {
+			// the job was adopted before
+			continue
+		} else if !errors.IsNotFound(err) {
+			return err
+		}
+
+		// only the Pipeline jobs and multi-branch Pipeline jobs can be parsed
+		jenkinsPipeline, err := c.devopsClient.GetProjectPipelineConfig(sourceFolder, name)
+		if err != nil {
+			klog.Warningf("skip importing the Jenkins job %s/%s, error %v", sourceFolder, name, err)
+			skipped++
+			continue
+		}
+		pipeline := &devopsv1alpha3.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   nsName,
+				Annotations: map[string]string{},
+			},
+			Spec: jenkinsPipeline.Spec,
+		}
+		if sourceFolder == nsName {
+			pipeline.Annotations[devopsv1alpha3.PipelineRequestToSyncRunsAnnoKey] = "true"
+		}
+		if _, err := pipelineClient.Create(context.Background(), pipeline, metav1.CreateOptions{}); err != nil {
+			if errors.IsAlreadyExists(err) {
+				continue
+			}
+			return err
+		}
+		imported++
+	}
+
+	klog.Infof("imported %d Jenkins job(s) from folder %s into devopsproject %s, skipped %d", imported, sourceFolder,
+		project.Name, skipped)
+	message := fmt.Sprintf("Imported %d Jenkins job(s) as Pipelines, skipped %d", imported, skipped)
+	c.eventRecorder.Event(project, v1.EventTypeNormal, "ImportedJenkinsJobs", message)
+	project.Status.Conditions = devopsv1alpha3.SetCondition(project.Status.Conditions,
+		devopsv1alpha3.NewCondition(devopsv1alpha3.ConditionJobsImported, devopsv1alpha3.ConditionTrue, devopsv1alpha3.Imported, message))
+	return nil
+}
+
+// isImportRetryable indicates if importing the Jenkins jobs might succeed by retrying. The source folder which doesn't
+// exist in Jenkins is never going to be imported.
+func isImportRetryable(err error) bool {
+	return servererr.GetServiceErrorCode(err) != http.StatusNotFound
+}
+
+// markJobsImportFailed records the failure of importing the Jenkins jobs in the conditions of the DevOpsProject. The
+// status is not updated if the same failure was recorded, otherwise every retry would refresh the probe time.
+func (c *Controller) markJobsImportFailed(project *devopsv1alpha3.DevOpsProject, importErr error) {
+	if condition := devopsv1alpha3.FindCondition(project.Status.Conditions, devopsv1alpha3.ConditionJobsImported); condition != nil &&
+		condition.Status == devopsv1alpha3.ConditionFalse && condition.Message == importErr.Error() {
+		return
+	}
+	copyProject := project.DeepCopy()
+	copyProject.Status.Conditions = devopsv1alpha3.SetCondition(copyProject.Status.Conditions,
+		devopsv1alpha3.NewCondition(devopsv1alpha3.ConditionJobsImported, devopsv1alpha3.ConditionFalse, devopsv1alpha3.ImportFailed, importErr.Error()))
+	if _, err := c.kubesphereClient.DevopsV1alpha3().DevOpsProjects().UpdateStatus(context.Background(), copyProject, metav1.UpdateOptions{}); err != nil {
+		klog.Errorf("failed to update the status of devopsproject %s, error %v", project.Name, err)
+	}
+}
+
+// getImportSourceFolder returns the Jenkins folder which the jobs of the DevOpsProject are imported from.
+func getImportSourceFolder(project *devopsv1alpha3.DevOpsProject) string {
+	if sourceFolder := project.Annotations[devopsv1alpha3.DevOpsProjectImportSourceFolderAnnoKey]; sourceFolder != "" {
+		return sourceFolder
+	}
+	return project.Status.AdminNamespace
+}
+
+// isImportRequested indicates if the Jenkins jobs of the DevOpsProject are requested to be imported.
+func isImportRequested(project *devopsv1alpha3.DevOpsProject) bool {
+	_, ok := project.Annotations[devopsv1alpha3.DevOpsProjectRequestToImportJobsAnnoKey]
+	return ok
+}
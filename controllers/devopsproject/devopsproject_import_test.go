@@ -0,0 +1,114 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devopsproject
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	devops "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	fakeDevOps "kubesphere.io/devops/pkg/client/devops/fake"
+)
+
+func TestController_importJenkinsJobs(t *testing.T) {
+	nsName := "test-123"
+	newJob := func(name string) *devops.Pipeline {
+		return &devops.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: devops.PipelineSpec{
+				Type:     devops.NoScmPipelineType,
+				Pipeline: &devops.NoScmPipeline{Name: name, Jenkinsfile: "node {}"},
+			},
+		}
+	}
+	existing := &devops.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: nsName},
+		Spec:       devops.PipelineSpec{Type: devops.MultiBranchPipelineType},
+	}
+	project := newDevOpsProject("test", nsName, true, true)
+	project.Annotations = map[string]string{devops.DevOpsProjectRequestToImportJobsAnnoKey: "true"}
+
+	f := newFixture(t)
+	f.objects = append(f.objects, existing)
+	c, _, _, _ := f.newController()
+	c.devopsClient = fakeDevOps.NewWithPipelines(nsName, newJob("existing"), newJob("imported"), newJob("Invalid Name"))
+
+	assert.True(t, isImportRequested(project))
+	assert.Nil(t, c.importJenkinsJobs(project))
+	if condition := devops.FindCondition(project.Status.Conditions, devops.ConditionJobsImported); assert.NotNil(t, condition) {
+		assert.Equal(t, devops.ConditionTrue, condition.Status)
+		assert.Equal(t, "Imported 1 Jenkins job(s) as Pipelines, skipped 1", condition.Message)
+	}
+
+	pipelines, err := f.client.DevopsV1alpha3().Pipelines(nsName).List(context.Background(), metav1.ListOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(pipelines.Items))
+
+	imported, err := f.client.DevopsV1alpha3().Pipelines(nsName).Get(context.Background(), "imported", metav1.GetOptions{})
+	if assert.Nil(t, err) {
+		assert.Equal(t, newJob("imported").Spec, imported.Spec)
+		assert.Equal(t, "true", imported.Annotations[devops.PipelineRequestToSyncRunsAnnoKey])
+	}
+	// the adopted Pipeline is left as it is
+	adopted, err := f.client.DevopsV1alpha3().Pipelines(nsName).Get(context.Background(), "existing", metav1.GetOptions{})
+	if assert.Nil(t, err) {
+		assert.Equal(t, devops.MultiBranchPipelineType, adopted.Spec.Type)
+	}
+
+	// the folder does not exist in Jenkins, retrying never helps
+	c.devopsClient = fakeDevOps.New()
+	err = c.importJenkinsJobs(project)
+	assert.NotNil(t, err)
+	assert.False(t, isImportRetryable(err))
+
+	// import from another folder
+	project.Annotations[devops.DevOpsProjectImportSourceFolderAnnoKey] = "legacy"
+	assert.Equal(t, "legacy", getImportSourceFolder(project))
+	c.devopsClient = fakeDevOps.NewWithPipelines("legacy", newJob("legacy-job"))
+	assert.Nil(t, c.importJenkinsJobs(project))
+	copied, err := f.client.DevopsV1alpha3().Pipelines(nsName).Get(context.Background(), "legacy-job", metav1.GetOptions{})
+	if assert.Nil(t, err) {
+		assert.Equal(t, newJob("legacy-job").Spec, copied.Spec)
+		// the run history stays in the source folder
+		assert.NotContains(t, copied.Annotations, devops.PipelineRequestToSyncRunsAnnoKey)
+	}
+}
+
+func TestController_markJobsImportFailed(t *testing.T) {
+	project := newDevOpsProject("test", "test-123", true, true)
+	f := newFixture(t)
+	f.objects = append(f.objects, project)
+	c, _, _, _ := f.newController()
+
+	importErr := errors.New("connection refused")
+	assert.True(t, isImportRetryable(importErr))
+	c.markJobsImportFailed(project, importErr)
+
+	updated, err := f.client.DevopsV1alpha3().DevOpsProjects().Get(context.Background(), "test", metav1.GetOptions{})
+	if !assert.Nil(t, err) {
+		return
+	}
+	if condition := devops.FindCondition(updated.Status.Conditions, devops.ConditionJobsImported); assert.NotNil(t, condition) {
+		assert.Equal(t, devops.ConditionFalse, condition.Status)
+		assert.Equal(t, devops.ImportFailed, condition.Reason)
+		assert.Equal(t, importErr.Error(), condition.Message)
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devopsproject
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/clientset/versioned/scheme"
+	modelsdevops "kubesphere.io/devops/pkg/models/devops"
+	"kubesphere.io/devops/pkg/utils/k8sutil"
+)
+
+const (
+	// resourceQuotaName is the name of the ResourceQuota created from the template of DevOpsProject
+	resourceQuotaName = "devops-project-quota"
+	// limitRangeName is the name of the LimitRange created from the template of DevOpsProject
+	limitRangeName = "devops-project-limits"
+)
+
+// getSpecHash returns the hash of DevOpsProjectSpec, which helps us to find out whether the spec has changed since
+// the last successful synchronization.
+func getSpecHash(spec *devopsv1alpha3.DevOpsProjectSpec) string {
+	data, _ := json.Marshal(spec)
+	hash := fnv.New32a()
+	_, _ = hash.Write(data)
+	return fmt.Sprintf("%x", hash.Sum32())
+}
+
+// reconcileSpec reconciles the declarative fields of DevOpsProject into the admin namespace and Jenkins.
+func (c *Controller) reconcileSpec(project *devopsv1alpha3.DevOpsProject) error {
+	if err := c.reconcileResourceQuota(project); err != nil {
+		return fmt.Errorf("failed to reconcile ResourceQuota: %v", err)
+	}
+	if err := c.reconcileLimitRange(project); err != nil {
+		return fmt.Errorf("failed to reconcile LimitRange: %v", err)
+	}
+	if err := c.reconcileMembers(project); err != nil {
+		return fmt.Errorf("failed to reconcile members: %v", err)
+	}
+	return nil
+}
+
+func (c *Controller) reconcileResourceQuota(project *devopsv1alpha3.DevOpsProject) error {
+	quotas := c.client.CoreV1().ResourceQuotas(project.Status.AdminNamespace)
+	quota, err := quotas.Get(context.Background(), resourceQuotaName, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if project.Spec.ResourceQuota == nil {
+		// only delete the ResourceQuota which was created by us
+		if exists && k8sutil.IsControlledBy(quota.OwnerReferences, devopsv1alpha3.ResourceKindDevOpsProject, project.Name) {
+			err = quotas.Delete(context.Background(), resourceQuotaName, metav1.DeleteOptions{})
+		}
+		return ignoreNotFound(err)
+	}
+
+	if !exists {
+		quota = &v1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      resourceQuotaName,
+				Namespace: project.Status.AdminNamespace,
+			},
+			Spec: *project.Spec.ResourceQuota.DeepCopy(),
+		}
+		if err := controllerutil.SetControllerReference(project, quota, scheme.Scheme); err != nil {
+			return err
+		}
+		_, err = quotas.Create(context.Background(), quota, metav1.CreateOptions{})
+		return err
+	}
+	if reflect.DeepEqual(quota.Spec, *project.Spec.ResourceQuota) {
+		return nil
+	}
+	quota = quota.DeepCopy()
+	quota.Spec = *project.Spec.ResourceQuota.DeepCopy()
+	_, err = quotas.Update(context.Background(), quota, metav1.UpdateOptions{})
+	return err
+}
+
+func (c *Controller) reconcileLimitRange(project *devopsv1alpha3.DevOpsProject) error {
+	limitRanges := c.client.CoreV1().LimitRanges(project.Status.AdminNamespace)
+	limitRange, err := limitRanges.Get(context.Background(), limitRangeName, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if project.Spec.LimitRange == nil {
+		// only delete the LimitRange which was created by us
+		if exists && k8sutil.IsControlledBy(limitRange.OwnerReferences, devopsv1alpha3.ResourceKindDevOpsProject, project.Name) {
+			err = limitRanges.Delete(context.Background(), limitRangeName, metav1.DeleteOptions{})
+		}
+		return ignoreNotFound(err)
+	}
+
+	if !exists {
+		limitRange = &v1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      limitRangeName,
+				Namespace: project.Status.AdminNamespace,
+			},
+			Spec: *project.Spec.LimitRange.DeepCopy(),
+		}
+		if err := controllerutil.SetControllerReference(project, limitRange, scheme.Scheme); err != nil {
+			return err
+		}
+		_, err = limitRanges.Create(context.Background(), limitRange, metav1.CreateOptions{})
+		return err
+	}
+	if reflect.DeepEqual(limitRange.Spec, *project.Spec.LimitRange) {
+		return nil
+	}
+	limitRange = limitRange.DeepCopy()
+	limitRange.Spec = *project.Spec.LimitRange.DeepCopy()
+	_, err = limitRanges.Update(context.Background(), limitRange, metav1.UpdateOptions{})
+	return err
+}
+
+// reconcileMembers assigns the Jenkins project roles to the members declared in spec, and unassigns the roles of
+// members which have been removed from spec. The members in status are the ones which have been assigned.
+func (c *Controller) reconcileMembers(project *devopsv1alpha3.DevOpsProject) error {
+	if len(project.Spec.Members) == 0 && len(project.Status.Members) == 0 {
+		return nil
+	}
+	projectID := project.Status.AdminNamespace
+
+	// make sure all roles of the project exist
+	for _, role := range modelsdevops.AllRoleSlice {
+		if err := c.devopsClient.AddProjectRole(modelsdevops.GetProjectRoleName(projectID, role),
+			modelsdevops.GetProjectRolePattern(projectID), modelsdevops.JenkinsProjectPermissionMap[role], true); err != nil {
+			return err
+		}
+		if err := c.devopsClient.AddProjectRole(modelsdevops.GetPipelineRoleName(projectID, role),
+			modelsdevops.GetPipelineRolePattern(projectID), modelsdevops.JenkinsPipelinePermissionMap[role], true); err != nil {
+			return err
+		}
+	}
+
+	desired := make(map[devopsv1alpha3.ProjectMember]bool, len(project.Spec.Members))
+	for _, member := range project.Spec.Members {
+		desired[member] = true
+	}
+	assigned := make(map[devopsv1alpha3.ProjectMember]bool, len(project.Status.Members))
+	for _, member := range project.Status.Members {
+		assigned[member] = true
+		if desired[member] {
+			continue
+		}
+		role := string(member.Role)
+		if err := c.devopsClient.UnAssignProjectRole(modelsdevops.GetProjectRoleName(projectID, role), member.Username); err != nil {
+			return err
+		}
+		if err := c.devopsClient.UnAssignProjectRole(modelsdevops.GetPipelineRoleName(projectID, role), member.Username); err != nil {
+			return err
+		}
+	}
+	for _, member := range project.Spec.Members {
+		if assigned[member] {
+			continue
+		}
+		role := string(member.Role)
+		if err := c.devopsClient.AssignProjectRole(modelsdevops.GetProjectRoleName(projectID, role), member.Username); err != nil {
+			return err
+		}
+		if err := c.devopsClient.AssignProjectRole(modelsdevops.GetPipelineRoleName(projectID, role), member.Username); err != nil {
+			return err
+		}
+	}
+
+	project.Status.Members = append([]devopsv1alpha3.ProjectMember(nil), project.Spec.Members...)
+	return nil
+}
+
+func ignoreNotFound(err error) error {
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
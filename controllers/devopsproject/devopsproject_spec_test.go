@@ -0,0 +1,127 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devopsproject
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	fakeDevOps "kubesphere.io/devops/pkg/client/devops/fake"
+)
+
+// roleRecorder records the role assignments in Jenkins
+type roleRecorder struct {
+	*fakeDevOps.Devops
+	assigned   []string
+	unassigned []string
+}
+
+func (r *roleRecorder) AssignProjectRole(roleName string, sid string) error {
+	r.assigned = append(r.assigned, roleName+":"+sid)
+	return nil
+}
+
+func (r *roleRecorder) UnAssignProjectRole(roleName string, sid string) error {
+	r.unassigned = append(r.unassigned, roleName+":"+sid)
+	return nil
+}
+
+func newSpecProject() *devopsv1alpha3.DevOpsProject {
+	return &devopsv1alpha3.DevOpsProject{
+		TypeMeta:   metav1.TypeMeta{APIVersion: devopsv1alpha3.GroupVersion.String(), Kind: devopsv1alpha3.ResourceKindDevOpsProject},
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Status:     devopsv1alpha3.DevOpsProjectStatus{AdminNamespace: "test-ns"},
+	}
+}
+
+func Test_getSpecHash(t *testing.T) {
+	project := newSpecProject()
+	hash := getSpecHash(&project.Spec)
+	assert.Equal(t, hash, getSpecHash(project.Spec.DeepCopy()))
+
+	project.Spec.MaxConcurrentRuns = 3
+	assert.NotEqual(t, hash, getSpecHash(&project.Spec))
+}
+
+func TestController_reconcileResourceQuota(t *testing.T) {
+	project := newSpecProject()
+	project.Spec.ResourceQuota = &v1.ResourceQuotaSpec{
+		Hard: v1.ResourceList{v1.ResourcePods: resource.MustParse("10")},
+	}
+	c := &Controller{client: k8sfake.NewSimpleClientset()}
+	quotas := c.client.CoreV1().ResourceQuotas("test-ns")
+
+	// create
+	assert.Nil(t, c.reconcileResourceQuota(project))
+	quota, err := quotas.Get(context.Background(), resourceQuotaName, metav1.GetOptions{})
+	if assert.Nil(t, err) {
+		assert.Equal(t, *project.Spec.ResourceQuota, quota.Spec)
+		assert.Equal(t, 1, len(quota.OwnerReferences))
+	}
+
+	// update
+	project.Spec.ResourceQuota.Hard[v1.ResourcePods] = resource.MustParse("20")
+	assert.Nil(t, c.reconcileResourceQuota(project))
+	quota, err = quotas.Get(context.Background(), resourceQuotaName, metav1.GetOptions{})
+	if assert.Nil(t, err) {
+		assert.Equal(t, *project.Spec.ResourceQuota, quota.Spec)
+	}
+
+	// delete
+	project.Spec.ResourceQuota = nil
+	assert.Nil(t, c.reconcileResourceQuota(project))
+	_, err = quotas.Get(context.Background(), resourceQuotaName, metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestController_reconcileLimitRange(t *testing.T) {
+	project := newSpecProject()
+	// the LimitRange which was not created by us should be kept
+	c := &Controller{client: k8sfake.NewSimpleClientset(&v1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: limitRangeName, Namespace: "test-ns"},
+	})}
+	assert.Nil(t, c.reconcileLimitRange(project))
+	_, err := c.client.CoreV1().LimitRanges("test-ns").Get(context.Background(), limitRangeName, metav1.GetOptions{})
+	assert.Nil(t, err)
+}
+
+func TestController_reconcileMembers(t *testing.T) {
+	project := newSpecProject()
+	project.Spec.Members = []devopsv1alpha3.ProjectMember{
+		{Username: "alice", Role: devopsv1alpha3.ProjectOwner},
+		{Username: "bob", Role: devopsv1alpha3.ProjectDeveloper},
+	}
+	project.Status.Members = []devopsv1alpha3.ProjectMember{
+		{Username: "alice", Role: devopsv1alpha3.ProjectOwner},
+		{Username: "bob", Role: devopsv1alpha3.ProjectReporter},
+	}
+	recorder := &roleRecorder{Devops: fakeDevOps.New()}
+	c := &Controller{devopsClient: recorder}
+
+	assert.Nil(t, c.reconcileMembers(project))
+	assert.Equal(t, []string{"test-ns-reporter-project:bob", "test-ns-reporter-pipeline:bob"}, recorder.unassigned)
+	assert.Equal(t, []string{"test-ns-developer-project:bob", "test-ns-developer-pipeline:bob"}, recorder.assigned)
+	assert.Equal(t, project.Spec.Members, project.Status.Members)
+}
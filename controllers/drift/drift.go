@@ -0,0 +1,82 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package drift contains the common parts of detecting the drift between the resources in Kubernetes and Jenkins.
+// Someone might edit or delete the jobs, folders or credentials inside Jenkins directly, the controllers compare
+// them with the resources periodically, then repair or report the drift.
+package drift
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/prometheus/client_golang/prometheus"
+	"kubesphere.io/devops/pkg/client/devops"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Options is the configuration of drift detection.
+type Options struct {
+	// Interval is the interval of detecting drift. Zero means the detection is disabled.
+	Interval time.Duration
+	// DryRun reports the drift without repairing it.
+	DryRun bool
+}
+
+// Enabled indicates if the drift detection is enabled.
+func (o Options) Enabled() bool {
+	return o.Interval > 0
+}
+
+// Type is the type of drift.
+type Type string
+
+const (
+	// None indicates that there is no drift.
+	None Type = ""
+	// Missing indicates that the resource doesn't exist in Jenkins.
+	Missing Type = "Missing"
+	// Modified indicates that the resource in Jenkins is different from the one in Kubernetes.
+	Modified Type = "Modified"
+)
+
+var driftedResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "devops_jenkins_drifted_resources",
+	Help: "Number of resources which drift from Jenkins in the latest round of drift detection",
+}, []string{"kind", "type"})
+
+func init() {
+	metrics.Registry.MustRegister(driftedResources)
+}
+
+// Report records the number of drifted resources of the kind, grouped by the type of drift.
+func Report(kind string, drifts map[Type]int) {
+	for _, driftType := range []Type{Missing, Modified} {
+		driftedResources.WithLabelValues(kind, string(driftType)).Set(float64(drifts[driftType]))
+	}
+}
+
+// IsNotFound indicates if the error from Jenkins means the resource doesn't exist.
+func IsNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	if srvErr, ok := err.(restful.ServiceError); ok {
+		return srvErr.Code == http.StatusNotFound
+	}
+	return devops.GetDevOpsStatusCode(err) == http.StatusNotFound
+}
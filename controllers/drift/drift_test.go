@@ -0,0 +1,73 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drift
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"kubesphere.io/devops/pkg/client/devops"
+)
+
+func TestIsNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{{
+		name: "No error",
+		want: false,
+	}, {
+		name: "Service error",
+		err:  restful.NewError(http.StatusNotFound, "not found"),
+		want: true,
+	}, {
+		name: "Service error of other code",
+		err:  restful.NewError(http.StatusInternalServerError, "internal error"),
+		want: false,
+	}, {
+		name: "Error response",
+		err:  &devops.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}},
+		want: true,
+	}, {
+		name: "Status code",
+		err:  errors.New("404"),
+		want: true,
+	}, {
+		name: "Other error",
+		err:  errors.New("connection refused"),
+		want: false,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsNotFound(tt.err))
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	Report("Pipeline", map[Type]int{Missing: 2})
+	assert.Equal(t, float64(2), testutil.ToFloat64(driftedResources.WithLabelValues("Pipeline", string(Missing))))
+	assert.Equal(t, float64(0), testutil.ToFloat64(driftedResources.WithLabelValues("Pipeline", string(Modified))))
+
+	Report("Pipeline", map[Type]int{})
+	assert.Equal(t, float64(0), testutil.ToFloat64(driftedResources.WithLabelValues("Pipeline", string(Missing))))
+}
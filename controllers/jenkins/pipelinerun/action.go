@@ -0,0 +1,313 @@
+package pipelinerun
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// jenkinsRun identifies a Jenkins run which a PipelineRun relates with.
+type jenkinsRun struct {
+	projectName  string
+	pipelineName string
+	// branch is only for multi-branch Pipeline
+	branch string
+	runID  string
+}
+
+// pendingInput is an input step which is waiting for submission.
+type pendingInput struct {
+	nodeID  string
+	stepID  string
+	inputID string
+	message string
+}
+
+func newJenkinsRun(projectName, pipelineName string, pr *v1alpha3.PipelineRun) (*jenkinsRun, error) {
+	runID, exists := pr.GetPipelineRunID()
+	if !exists {
+		return nil, fmt.Errorf("unable to find Jenkins run ID of PipelineRun %s/%s", pr.Namespace, pr.Name)
+	}
+	branch, err := getSCMRefName(&pr.Spec)
+	if err != nil {
+		return nil, err
+	}
+	return &jenkinsRun{
+		projectName:  projectName,
+		pipelineName: pipelineName,
+		branch:       branch,
+		runID:        runID,
+	}, nil
+}
+
+func newHTTPParameters(method string, query url.Values, body interface{}) (*devops.HttpParameters, error) {
+	httpParameters := &devops.HttpParameters{
+		Method: method,
+		Header: http.Header{},
+		Url:    &url.URL{RawQuery: query.Encode()},
+	}
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		httpParameters.Header.Set("Content-Type", "application/json")
+		httpParameters.Body = ioutil.NopCloser(bytes.NewReader(data))
+	}
+	return httpParameters, nil
+}
+
+// stop aborts the Jenkins run.
+func (r *Reconciler) stop(run *jenkinsRun) (err error) {
+	query := url.Values{}
+	query.Set("blocking", "true")
+	query.Set("timeOutInSecs", "10")
+	httpParameters, _ := newHTTPParameters(http.MethodPut, query, nil)
+	if run.branch != "" {
+		_, err = r.DevOpsClient.StopBranchPipeline(run.projectName, run.pipelineName, run.branch, run.runID, httpParameters)
+	} else {
+		_, err = r.DevOpsClient.StopPipeline(run.projectName, run.pipelineName, run.runID, httpParameters)
+	}
+	return
+}
+
+// getPendingInputs returns all input steps which are waiting for submission.
+func (r *Reconciler) getPendingInputs(run *jenkinsRun) ([]pendingInput, error) {
+	var pausedNodeIDs []string
+	httpParameters, _ := newHTTPParameters(http.MethodGet, url.Values{}, nil)
+	if run.branch != "" {
+		nodes, err := r.DevOpsClient.GetBranchPipelineRunNodes(run.projectName, run.pipelineName, run.branch, run.runID, httpParameters)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			if node.State == devops.StatePaused {
+				pausedNodeIDs = append(pausedNodeIDs, node.ID)
+			}
+		}
+	} else {
+		nodes, err := r.DevOpsClient.GetPipelineRunNodes(run.projectName, run.pipelineName, run.runID, httpParameters)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			if node.State == devops.StatePaused {
+				pausedNodeIDs = append(pausedNodeIDs, node.ID)
+			}
+		}
+	}
+
+	var inputs []pendingInput
+	for _, nodeID := range pausedNodeIDs {
+		var (
+			steps []devops.NodeSteps
+			err   error
+		)
+		httpParameters, _ = newHTTPParameters(http.MethodGet, url.Values{}, nil)
+		if run.branch != "" {
+			steps, err = r.DevOpsClient.GetBranchNodeSteps(run.projectName, run.pipelineName, run.branch, run.runID, nodeID, httpParameters)
+		} else {
+			steps, err = r.DevOpsClient.GetNodeSteps(run.projectName, run.pipelineName, run.runID, nodeID, httpParameters)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, step := range steps {
+			if step.State == devops.StatePaused && step.Input != nil {
+				inputs = append(inputs, pendingInput{
+					nodeID:  nodeID,
+					stepID:  step.ID,
+					inputID: step.Input.ID,
+					message: step.Input.Message,
+				})
+			}
+		}
+	}
+	return inputs, nil
+}
+
+// proceed submits the pending input step with its default parameters.
+func (r *Reconciler) proceed(run *jenkinsRun, input pendingInput) error {
+	httpParameters, err := newHTTPParameters(http.MethodPost, url.Values{}, devops.CheckPlayload{
+		ID:         input.inputID,
+		Parameters: []devops.CheckPlayloadParameters{},
+	})
+	if err != nil {
+		return err
+	}
+	if run.branch != "" {
+		_, err = r.DevOpsClient.SubmitBranchInputStep(run.projectName, run.pipelineName, run.branch, run.runID,
+			input.nodeID, input.stepID, httpParameters)
+	} else {
+		_, err = r.DevOpsClient.SubmitInputStep(run.projectName, run.pipelineName, run.runID,
+			input.nodeID, input.stepID, httpParameters)
+	}
+	return err
+}
+
+// togglePause pauses the Jenkins run before its next step, or resumes it if it has been paused.
+func (r *Reconciler) togglePause(run *jenkinsRun) error {
+	if run.branch != "" {
+		return r.DevOpsClient.TogglePauseBranchPipeline(run.projectName, run.pipelineName, run.branch, run.runID)
+	}
+	return r.DevOpsClient.TogglePausePipeline(run.projectName, run.pipelineName, run.runID)
+}
+
+// isHeld indicates if the PipelineRun is held by a Pause action which has not been resumed.
+func isHeld(status *v1alpha3.PipelineRunStatus) bool {
+	condition := v1alpha3.FindCondition(status.Conditions, v1alpha3.ConditionPaused)
+	return condition != nil && condition.Status == v1alpha3.ConditionTrue
+}
+
+// handleAction acts on the Action of a started PipelineRun, then returns the condition which records the result. Every
+// action is recorded in its own condition type, so that the conditions refreshed from Jenkins never overwrite it.
+func (r *Reconciler) handleAction(run *jenkinsRun, action v1alpha3.Action, status *v1alpha3.PipelineRunStatus) (*v1alpha3.Condition, error) {
+	condition := &v1alpha3.Condition{
+		LastProbeTime:      v1.Now(),
+		LastTransitionTime: v1.Now(),
+	}
+	switch action {
+	case v1alpha3.Stop:
+		if err := r.stop(run); err != nil {
+			return nil, err
+		}
+		condition.Type = v1alpha3.ConditionStopped
+		condition.Status = v1alpha3.ConditionTrue
+		condition.Reason = v1alpha3.Stopped
+		condition.Message = "The PipelineRun was stopped by action"
+	case v1alpha3.Pause:
+		// toggling twice would resume the PipelineRun
+		if !isHeld(status) {
+			if err := r.togglePause(run); err != nil {
+				return nil, err
+			}
+		}
+		condition.Type = v1alpha3.ConditionPaused
+		condition.Status = v1alpha3.ConditionTrue
+		condition.Reason = v1alpha3.Paused
+		condition.Message = "The PipelineRun is held before its next step, and its pending input steps stay held until it's resumed"
+	case v1alpha3.Resume:
+		held := isHeld(status)
+		if held {
+			if err := r.togglePause(run); err != nil {
+				return nil, err
+			}
+		}
+		inputs, err := r.getPendingInputs(run)
+		if err != nil {
+			return nil, err
+		}
+		for _, input := range inputs {
+			if err := r.proceed(run, input); err != nil {
+				return nil, err
+			}
+		}
+		condition.Type = v1alpha3.ConditionPaused
+		condition.Status = v1alpha3.ConditionFalse
+		condition.Reason = v1alpha3.Resumed
+		switch {
+		case len(inputs) != 0:
+			condition.Message = "The PipelineRun was resumed, and the pending input step(s) were released: " + inputMessages(inputs)
+		case held:
+			condition.Message = "The PipelineRun was resumed from the hold of Pause"
+		default:
+			condition.Message = "The PipelineRun was neither paused nor waiting for input, nothing was released"
+		}
+	default:
+		return nil, fmt.Errorf("unsupported action: %s", action)
+	}
+	return condition, nil
+}
+
+func inputMessages(inputs []pendingInput) string {
+	messages := make([]string, 0, len(inputs))
+	for _, input := range inputs {
+		messages = append(messages, fmt.Sprintf("%q", input.message))
+	}
+	return strings.Join(messages, ", ")
+}
+
+// clearAction removes the Action from the PipelineRun, which means the Action has been handled.
+func (r *Reconciler) clearAction(ctx context.Context, key client.ObjectKey) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		prToUpdate := &v1alpha3.PipelineRun{}
+		if err := r.Get(ctx, key, prToUpdate); err != nil {
+			return err
+		}
+		if prToUpdate.Spec.Action == nil {
+			return nil
+		}
+		prToUpdate.Spec.Action = nil
+		return r.Update(ctx, prToUpdate)
+	})
+}
+
+// reconcileAction acts on the Action of a started PipelineRun, then clears the Action and records the result.
+func (r *Reconciler) reconcileAction(ctx context.Context, pr *v1alpha3.PipelineRun, projectName, pipelineName string) (ctrl.Result, error) {
+	action := *pr.Spec.Action
+	key := client.ObjectKey{Namespace: pr.Namespace, Name: pr.Name}
+	log := r.log.WithValues("PipelineRun", key, "action", action)
+
+	var condition *v1alpha3.Condition
+	run, err := newJenkinsRun(projectName, pipelineName, pr)
+	if err == nil {
+		condition, err = r.handleAction(run, action, &pr.Status)
+	}
+	if err != nil {
+		log.Error(err, "unable to act on the action of PipelineRun")
+		r.recorder.Eventf(pr, corev1.EventTypeWarning, v1alpha3.ActionFailed, "Failed to act on action %s of PipelineRun %s, and error was %s", action, key, err)
+		return ctrl.Result{}, err
+	}
+
+	if err := r.clearAction(ctx, key); err != nil {
+		log.Error(err, "unable to clear the action of PipelineRun")
+		return ctrl.Result{}, err
+	}
+	status := pr.Status.DeepCopy()
+	status.AddCondition(condition)
+	if err := r.updateStatus(ctx, status, key); err != nil {
+		log.Error(err, "unable to update PipelineRun status.")
+		return ctrl.Result{}, err
+	}
+	r.recorder.Event(pr, corev1.EventTypeNormal, condition.Reason, condition.Message)
+	return ctrl.Result{}, nil
+}
+
+// cancelPipelineRun completes a PipelineRun which is not going to be triggered.
+func (r *Reconciler) cancelPipelineRun(ctx context.Context, pr *v1alpha3.PipelineRun, message string) error {
+	key := client.ObjectKey{Namespace: pr.Namespace, Name: pr.Name}
+	if err := r.clearAction(ctx, key); err != nil {
+		return err
+	}
+	status := pr.Status.DeepCopy()
+	status.AddCondition(&v1alpha3.Condition{
+		Type:               v1alpha3.ConditionSucceeded,
+		Status:             v1alpha3.ConditionFalse,
+		Reason:             v1alpha3.Cancelled,
+		Message:            message,
+		LastProbeTime:      v1.Now(),
+		LastTransitionTime: v1.Now(),
+	})
+	status.Phase = v1alpha3.Failed
+	status.MarkCompleted(time.Now())
+	status.UpdateTime = &v1.Time{Time: time.Now()}
+	if err := r.updateStatus(ctx, status, key); err != nil {
+		return err
+	}
+	r.recorder.Eventf(pr, corev1.EventTypeNormal, v1alpha3.Cancelled, "Cancelled PipelineRun %s: %s", key, message)
+	return nil
+}
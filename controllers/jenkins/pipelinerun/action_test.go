@@ -0,0 +1,175 @@
+package pipelinerun
+
+import (
+	"reflect"
+	"testing"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/devops/fake"
+)
+
+func newFakeDevOpsClientWithNodes() *fake.Devops {
+	devopsClient := fake.New("project1")
+	devopsClient.Data = map[string]interface{}{
+		"project1-pipeline1-1": []devops.PipelineRunNodes{{
+			ID:    "1",
+			State: "FINISHED",
+		}, {
+			ID:    "2",
+			State: devops.StatePaused,
+		}},
+		"project1-pipeline1-1-2": []devops.NodeSteps{{
+			ID:    "3",
+			State: "FINISHED",
+		}, {
+			ID:    "4",
+			State: devops.StatePaused,
+			Input: &devops.Input{
+				ID:      "input-id",
+				Message: "Deploy to production?",
+			},
+		}},
+	}
+	return devopsClient
+}
+
+func TestReconciler_getPendingInputs(t *testing.T) {
+	r := &Reconciler{DevOpsClient: newFakeDevOpsClientWithNodes()}
+	inputs, err := r.getPendingInputs(&jenkinsRun{
+		projectName:  "project1",
+		pipelineName: "pipeline1",
+		runID:        "1",
+	})
+	if err != nil {
+		t.Fatalf("getPendingInputs() error = %v", err)
+	}
+	want := []pendingInput{{
+		nodeID:  "2",
+		stepID:  "4",
+		inputID: "input-id",
+		message: "Deploy to production?",
+	}}
+	if !reflect.DeepEqual(inputs, want) {
+		t.Errorf("getPendingInputs() got = %v, want %v", inputs, want)
+	}
+}
+
+func TestReconciler_handleAction(t *testing.T) {
+	run := &jenkinsRun{
+		projectName:  "project1",
+		pipelineName: "pipeline1",
+		runID:        "1",
+	}
+	heldStatus := &v1alpha3.PipelineRunStatus{
+		Conditions: []v1alpha3.Condition{{
+			Type:   v1alpha3.ConditionPaused,
+			Status: v1alpha3.ConditionTrue,
+			Reason: v1alpha3.Paused,
+		}},
+	}
+	tests := []struct {
+		name                 string
+		action               v1alpha3.Action
+		status               *v1alpha3.PipelineRunStatus
+		wantType             v1alpha3.ConditionType
+		wantStatus           v1alpha3.ConditionStatus
+		wantReason           string
+		noPendingInput       bool
+		wantStoppedRuns      []string
+		wantPauseToggledRuns []string
+		wantSubmittedInputs  []string
+		wantErr              bool
+	}{{
+		name:            "Stop",
+		action:          v1alpha3.Stop,
+		status:          &v1alpha3.PipelineRunStatus{},
+		wantType:        v1alpha3.ConditionStopped,
+		wantStatus:      v1alpha3.ConditionTrue,
+		wantReason:      v1alpha3.Stopped,
+		wantStoppedRuns: []string{"project1/pipeline1/1"},
+	}, {
+		name:                 "Pause",
+		action:               v1alpha3.Pause,
+		status:               &v1alpha3.PipelineRunStatus{},
+		wantType:             v1alpha3.ConditionPaused,
+		wantStatus:           v1alpha3.ConditionTrue,
+		wantReason:           v1alpha3.Paused,
+		wantPauseToggledRuns: []string{"project1/pipeline1/1"},
+	}, {
+		name:       "Pause a held PipelineRun",
+		action:     v1alpha3.Pause,
+		status:     heldStatus,
+		wantType:   v1alpha3.ConditionPaused,
+		wantStatus: v1alpha3.ConditionTrue,
+		wantReason: v1alpha3.Paused,
+	}, {
+		name:                 "Resume a held PipelineRun",
+		action:               v1alpha3.Resume,
+		status:               heldStatus,
+		noPendingInput:       true,
+		wantType:             v1alpha3.ConditionPaused,
+		wantStatus:           v1alpha3.ConditionFalse,
+		wantReason:           v1alpha3.Resumed,
+		wantPauseToggledRuns: []string{"project1/pipeline1/1"},
+	}, {
+		name:                 "Resume a held PipelineRun with pending input steps",
+		action:               v1alpha3.Resume,
+		status:               heldStatus,
+		wantType:             v1alpha3.ConditionPaused,
+		wantStatus:           v1alpha3.ConditionFalse,
+		wantReason:           v1alpha3.Resumed,
+		wantPauseToggledRuns: []string{"project1/pipeline1/1"},
+		wantSubmittedInputs:  []string{"project1/pipeline1/1/2/4"},
+	}, {
+		name:                "Resume a PipelineRun which is waiting for input",
+		action:              v1alpha3.Resume,
+		status:              &v1alpha3.PipelineRunStatus{},
+		wantType:            v1alpha3.ConditionPaused,
+		wantStatus:          v1alpha3.ConditionFalse,
+		wantReason:          v1alpha3.Resumed,
+		wantSubmittedInputs: []string{"project1/pipeline1/1/2/4"},
+	}, {
+		name:           "Resume a PipelineRun which is neither held nor waiting for input",
+		action:         v1alpha3.Resume,
+		status:         &v1alpha3.PipelineRunStatus{},
+		noPendingInput: true,
+		wantType:       v1alpha3.ConditionPaused,
+		wantStatus:     v1alpha3.ConditionFalse,
+		wantReason:     v1alpha3.Resumed,
+	}, {
+		name:    "Unsupported action",
+		action:  "Restart",
+		status:  &v1alpha3.PipelineRunStatus{},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devopsClient := newFakeDevOpsClientWithNodes()
+			if tt.noPendingInput {
+				devopsClient.Data["project1-pipeline1-1"] = []devops.PipelineRunNodes{{ID: "1", State: "FINISHED"}}
+			}
+			r := &Reconciler{DevOpsClient: devopsClient}
+			condition, err := r.handleAction(run, tt.action, tt.status)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handleAction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if condition.Type != tt.wantType || condition.Status != tt.wantStatus || condition.Reason != tt.wantReason {
+				t.Errorf("handleAction() got condition = %+v", condition)
+			}
+			if !reflect.DeepEqual(devopsClient.StoppedRuns, tt.wantStoppedRuns) {
+				t.Errorf("handleAction() stopped runs = %v, want %v", devopsClient.StoppedRuns, tt.wantStoppedRuns)
+			}
+			if !reflect.DeepEqual(devopsClient.PauseToggledRuns, tt.wantPauseToggledRuns) {
+				t.Errorf("handleAction() pause toggled runs = %v, want %v", devopsClient.PauseToggledRuns, tt.wantPauseToggledRuns)
+			}
+			// the pending input steps are only released by Resume
+			if !reflect.DeepEqual(devopsClient.SubmittedInputs, tt.wantSubmittedInputs) {
+				t.Errorf("handleAction() submitted input steps = %v, want %v", devopsClient.SubmittedInputs, tt.wantSubmittedInputs)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// getConcurrencyPolicy returns the concurrency policy of the Pipeline which the PipelineRun belongs to.
+func getConcurrencyPolicy(prSpec *v1alpha3.PipelineRunSpec) v1alpha3.ConcurrencyPolicy {
+	if prSpec.PipelineSpec == nil {
+		return v1alpha3.AllowConcurrent
+	}
+	return prSpec.PipelineSpec.GetConcurrencyPolicy()
+}
+
+// isActive indicates if the PipelineRun has been triggered and is running in Jenkins.
+func isActive(pr *v1alpha3.PipelineRun) bool {
+	return pr.HasStarted() && !pr.HasCompleted() && pr.DeletionTimestamp.IsZero()
+}
+
+// isQueued indicates if the PipelineRun is waiting to be triggered.
+func isQueued(pr *v1alpha3.PipelineRun) bool {
+	return !pr.HasStarted() && pr.Buildable() && pr.DeletionTimestamp.IsZero()
+}
+
+// createdBefore indicates if the PipelineRun a was created before the PipelineRun b.
+func createdBefore(a, b *v1alpha3.PipelineRun) bool {
+	if a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.Name < b.Name
+	}
+	return a.CreationTimestamp.Before(&b.CreationTimestamp)
+}
+
+// isSibling indicates if the other PipelineRun belongs to the same Pipeline, or the same branch of a multi-branch
+// Pipeline, as the PipelineRun.
+func isSibling(pr, other *v1alpha3.PipelineRun) bool {
+	if other.Name == pr.Name || pr.Spec.PipelineRef == nil || other.Spec.PipelineRef == nil ||
+		other.Spec.PipelineRef.Name != pr.Spec.PipelineRef.Name {
+		return false
+	}
+	refName, _ := getSCMRefName(&pr.Spec)
+	otherRefName, _ := getSCMRefName(&other.Spec)
+	return otherRefName == refName
+}
+
+// findConcurrentRuns finds the active PipelineRuns and the older queued PipelineRuns among the PipelineRuns of the
+// same Pipeline, or the same branch of a multi-branch Pipeline.
+func findConcurrentRuns(pr *v1alpha3.PipelineRun, prs []v1alpha3.PipelineRun) (active, olderQueued []*v1alpha3.PipelineRun) {
+	for i := range prs {
+		other := &prs[i]
+		if !isSibling(pr, other) {
+			continue
+		}
+		if isActive(other) {
+			active = append(active, other)
+		} else if isQueued(other) && createdBefore(other, pr) {
+			olderQueued = append(olderQueued, other)
+		}
+	}
+	return
+}
+
+// getConcurrencyLabels returns the labels which select the PipelineRuns of the same Pipeline, or the same branch of a
+// multi-branch Pipeline.
+func getConcurrencyLabels(pr *v1alpha3.PipelineRun) client.MatchingLabels {
+	matchingLabels := client.MatchingLabels{v1alpha3.PipelineNameLabelKey: pr.Spec.PipelineRef.Name}
+	if refName, _ := getSCMRefName(&pr.Spec); refName != "" {
+		matchingLabels[v1alpha3.SCMRefNameLabelKey] = refName
+	}
+	return matchingLabels
+}
+
+// countActiveRuns counts the active PipelineRuns except the given one.
+func countActiveRuns(pr *v1alpha3.PipelineRun, prs []v1alpha3.PipelineRun) (count int) {
+	for i := range prs {
+		if prs[i].Name != pr.Name && isActive(&prs[i]) {
+			count++
+		}
+	}
+	return
+}
+
+// getMaxConcurrentRuns returns the maximum number of active PipelineRuns of the DevOpsProject which owns the namespace.
+// Zero means no limit.
+func (r *Reconciler) getMaxConcurrentRuns(ctx context.Context, namespace string) (int, error) {
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		return 0, client.IgnoreNotFound(err)
+	}
+	projectName := ns.Labels[constants.DevOpsProjectLabelKey]
+	if projectName == "" {
+		return 0, nil
+	}
+	project := &v1alpha3.DevOpsProject{}
+	if err := r.Get(ctx, client.ObjectKey{Name: projectName}, project); err != nil {
+		return 0, client.IgnoreNotFound(err)
+	}
+	return project.Spec.MaxConcurrentRuns, nil
+}
+
+// reader returns the reader which reads PipelineRuns from the API server directly. The cache might not have seen that
+// a PipelineRun was triggered by the previous reconcile, then two queued PipelineRuns would both be triggered.
+func (r *Reconciler) reader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// reconcileConcurrency makes the PipelineRun wait in queue if other PipelineRuns of the same Pipeline, or the same
+// branch of a multi-branch Pipeline, are active or queued before it. The PipelineRun also waits if the DevOpsProject
+// has reached its limit of concurrent PipelineRuns. It returns true if the PipelineRun is queued.
+func (r *Reconciler) reconcileConcurrency(ctx context.Context, pr *v1alpha3.PipelineRun) (bool, error) {
+	maxConcurrentRuns, err := r.getMaxConcurrentRuns(ctx, pr.Namespace)
+	if err != nil {
+		return false, err
+	}
+	policy := getConcurrencyPolicy(&pr.Spec)
+	if policy == v1alpha3.AllowConcurrent && maxConcurrentRuns <= 0 {
+		return false, nil
+	}
+
+	// persist the labels, so that the queued PipelineRun can be found by the later ones
+	if err := r.updateLabelsAndAnnotations(ctx, pr); err != nil {
+		return false, err
+	}
+
+	if maxConcurrentRuns > 0 {
+		var prList v1alpha3.PipelineRunList
+		if err := r.reader().List(ctx, &prList, client.InNamespace(pr.Namespace)); err != nil {
+			return false, err
+		}
+		if countActiveRuns(pr, prList.Items) >= maxConcurrentRuns {
+			return true, r.markQueued(ctx, pr, fmt.Sprintf("Waiting for the DevOpsProject to run less than %d PipelineRun(s)", maxConcurrentRuns))
+		}
+	}
+	if policy == v1alpha3.AllowConcurrent {
+		return false, nil
+	}
+	var prList v1alpha3.PipelineRunList
+	if err := r.reader().List(ctx, &prList, client.InNamespace(pr.Namespace), getConcurrencyLabels(pr)); err != nil {
+		return false, err
+	}
+	active, olderQueued := findConcurrentRuns(pr, prList.Items)
+
+	if policy == v1alpha3.CancelOlderQueued {
+		// only the latest queued PipelineRun is worth to be triggered
+		for _, older := range olderQueued {
+			if err := r.cancelPipelineRun(ctx, older, fmt.Sprintf("The PipelineRun was superseded by PipelineRun %s", pr.Name)); err != nil {
+				return false, err
+			}
+		}
+		olderQueued = nil
+	}
+
+	waitFor := append(active, olderQueued...)
+	if len(waitFor) == 0 {
+		return false, nil
+	}
+	names := make([]string, 0, len(waitFor))
+	for _, other := range waitFor {
+		names = append(names, other.Name)
+	}
+	return true, r.markQueued(ctx, pr, "Waiting for PipelineRun(s) to complete: "+strings.Join(names, ", "))
+}
+
+// wakeUpQueuedRuns enqueues the oldest queued PipelineRun of the same Pipeline, or the same branch of a multi-branch
+// Pipeline, and the oldest queued PipelineRun of the namespace once the PipelineRun completed. So that they are
+// triggered in time rather than on the next poll.
+func (r *Reconciler) wakeUpQueuedRuns(ctx context.Context, pr *v1alpha3.PipelineRun) error {
+	if r.queuedRuns == nil {
+		return nil
+	}
+	var prList v1alpha3.PipelineRunList
+	if err := r.reader().List(ctx, &prList, client.InNamespace(pr.Namespace)); err != nil {
+		return err
+	}
+	var oldestSibling, oldest *v1alpha3.PipelineRun
+	for i := range prList.Items {
+		other := &prList.Items[i]
+		if other.Name == pr.Name || !isQueued(other) {
+			continue
+		}
+		if oldest == nil || createdBefore(other, oldest) {
+			oldest = other
+		}
+		if isSibling(pr, other) && (oldestSibling == nil || createdBefore(other, oldestSibling)) {
+			oldestSibling = other
+		}
+	}
+	if oldestSibling != nil {
+		r.enqueue(oldestSibling)
+	}
+	if oldest != nil && oldest != oldestSibling {
+		r.enqueue(oldest)
+	}
+	return nil
+}
+
+// enqueue asks the controller to reconcile the PipelineRun.
+func (r *Reconciler) enqueue(pr *v1alpha3.PipelineRun) {
+	select {
+	case r.queuedRuns <- event.GenericEvent{Meta: pr, Object: pr}:
+	default:
+		// the PipelineRun will be checked on the next poll
+	}
+}
+
+// markQueued marks the PipelineRun as queued.
+func (r *Reconciler) markQueued(ctx context.Context, pr *v1alpha3.PipelineRun, message string) error {
+	if condition := getCondition(&pr.Status, v1alpha3.ConditionQueued); condition != nil &&
+		condition.Status == v1alpha3.ConditionTrue && condition.Message == message {
+		// avoid updating status while nothing changed
+		return nil
+	}
+	status := pr.Status.DeepCopy()
+	status.Phase = v1alpha3.Pending
+	status.AddCondition(&v1alpha3.Condition{
+		Type:               v1alpha3.ConditionQueued,
+		Status:             v1alpha3.ConditionTrue,
+		Reason:             v1alpha3.Queued,
+		Message:            message,
+		LastProbeTime:      v1.Now(),
+		LastTransitionTime: v1.Now(),
+	})
+	return r.updateStatus(ctx, status, client.ObjectKey{Namespace: pr.Namespace, Name: pr.Name})
+}
+
+// dequeue marks the queued PipelineRun as triggered.
+func dequeue(status *v1alpha3.PipelineRunStatus) {
+	if condition := getCondition(status, v1alpha3.ConditionQueued); condition == nil || condition.Status != v1alpha3.ConditionTrue {
+		return
+	}
+	status.AddCondition(&v1alpha3.Condition{
+		Type:               v1alpha3.ConditionQueued,
+		Status:             v1alpha3.ConditionFalse,
+		Reason:             v1alpha3.Dequeued,
+		Message:            "The PipelineRun has left the queue",
+		LastProbeTime:      v1.Now(),
+		LastTransitionTime: v1.Now(),
+	})
+}
+
+func getCondition(status *v1alpha3.PipelineRunStatus, conditionType v1alpha3.ConditionType) *v1alpha3.Condition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == conditionType {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package pipelinerun
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func newConcurrentPipelineRun(name, branch string, age time.Duration, started, completed bool,
+	policy v1alpha3.ConcurrencyPolicy) *v1alpha3.PipelineRun {
+	pr := &v1alpha3.PipelineRun{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace:         "ns",
+			Name:              name,
+			CreationTimestamp: v1.NewTime(time.Now().Add(-age)),
+			Labels: map[string]string{
+				v1alpha3.PipelineNameLabelKey: "pipeline",
+				v1alpha3.SCMRefNameLabelKey:   branch,
+			},
+			Annotations: map[string]string{},
+		},
+		Spec: v1alpha3.PipelineRunSpec{
+			PipelineRef: &corev1.ObjectReference{Name: "pipeline"},
+			PipelineSpec: &v1alpha3.PipelineSpec{
+				Type:              v1alpha3.MultiBranchPipelineType,
+				ConcurrencyPolicy: policy,
+			},
+			SCM: &v1alpha3.SCM{RefName: branch},
+		},
+	}
+	if started {
+		pr.Annotations[v1alpha3.JenkinsPipelineRunIDKey] = "1"
+	}
+	if completed {
+		pr.Status.CompletionTime = &pr.CreationTimestamp
+	}
+	return pr
+}
+
+func Test_findConcurrentRuns(t *testing.T) {
+	policy := v1alpha3.QueueConcurrent
+	pr := newConcurrentPipelineRun("pr-current", "main", 2*time.Minute, false, false, policy)
+	prs := []v1alpha3.PipelineRun{
+		*pr,
+		*newConcurrentPipelineRun("pr-active", "main", time.Hour, true, false, policy),
+		*newConcurrentPipelineRun("pr-completed", "main", time.Hour, true, true, policy),
+		*newConcurrentPipelineRun("pr-older-queued", "main", 3*time.Minute, false, false, policy),
+		*newConcurrentPipelineRun("pr-newer-queued", "main", time.Minute, false, false, policy),
+		*newConcurrentPipelineRun("pr-other-branch", "dev", time.Hour, true, false, policy),
+	}
+	active, olderQueued := findConcurrentRuns(pr, prs)
+	if assert.Equal(t, 1, len(active)) {
+		assert.Equal(t, "pr-active", active[0].Name)
+	}
+	if assert.Equal(t, 1, len(olderQueued)) {
+		assert.Equal(t, "pr-older-queued", olderQueued[0].Name)
+	}
+}
+
+func TestReconciler_reconcileConcurrency(t *testing.T) {
+	sch := runtime.NewScheme()
+	if err := v1alpha3.AddToScheme(sch); err != nil {
+		t.Fatalf("unable to add v1alpha3 into scheme, err = %v", err)
+	}
+	if err := corev1.AddToScheme(sch); err != nil {
+		t.Fatalf("unable to add core v1 into scheme, err = %v", err)
+	}
+	getPipelineRun := func(r *Reconciler, name string) *v1alpha3.PipelineRun {
+		pr := &v1alpha3.PipelineRun{}
+		assert.Nil(t, r.Get(context.Background(), client.ObjectKey{Namespace: "ns", Name: name}, pr))
+		return pr
+	}
+
+	t.Run("Allow concurrent PipelineRuns", func(t *testing.T) {
+		pr := newConcurrentPipelineRun("pr-current", "main", time.Minute, false, false, v1alpha3.AllowConcurrent)
+		r := &Reconciler{
+			Client: fake.NewFakeClientWithScheme(sch, pr.DeepCopy(),
+				newConcurrentPipelineRun("pr-active", "main", time.Hour, true, false, v1alpha3.AllowConcurrent)),
+		}
+		queued, err := r.reconcileConcurrency(context.Background(), pr)
+		assert.Nil(t, err)
+		assert.False(t, queued)
+	})
+
+	t.Run("Queue while another PipelineRun is active", func(t *testing.T) {
+		pr := newConcurrentPipelineRun("pr-current", "main", time.Minute, false, false, v1alpha3.QueueConcurrent)
+		r := &Reconciler{
+			Client: fake.NewFakeClientWithScheme(sch, pr.DeepCopy(),
+				newConcurrentPipelineRun("pr-active", "main", time.Hour, true, false, v1alpha3.QueueConcurrent)),
+		}
+		queued, err := r.reconcileConcurrency(context.Background(), pr)
+		assert.Nil(t, err)
+		assert.True(t, queued)
+
+		queuedPR := getPipelineRun(r, "pr-current")
+		assert.Equal(t, v1alpha3.Pending, queuedPR.Status.Phase)
+		condition := getCondition(&queuedPR.Status, v1alpha3.ConditionQueued)
+		if assert.NotNil(t, condition) {
+			assert.Equal(t, v1alpha3.ConditionTrue, condition.Status)
+			assert.Equal(t, v1alpha3.Queued, condition.Reason)
+		}
+	})
+
+	t.Run("Ignore the PipelineRuns of other Pipelines and branches", func(t *testing.T) {
+		pr := newConcurrentPipelineRun("pr-current", "main", time.Minute, false, false, v1alpha3.QueueConcurrent)
+		otherPipeline := newConcurrentPipelineRun("pr-other-pipeline", "main", time.Hour, true, false, v1alpha3.QueueConcurrent)
+		otherPipeline.Labels[v1alpha3.PipelineNameLabelKey] = "other-pipeline"
+		r := &Reconciler{
+			Client: fake.NewFakeClientWithScheme(sch, pr.DeepCopy(), otherPipeline,
+				newConcurrentPipelineRun("pr-other-branch", "dev", time.Hour, true, false, v1alpha3.QueueConcurrent)),
+		}
+		queued, err := r.reconcileConcurrency(context.Background(), pr)
+		assert.Nil(t, err)
+		assert.False(t, queued)
+	})
+
+	t.Run("Persist labels of the queued PipelineRun", func(t *testing.T) {
+		pr := newConcurrentPipelineRun("pr-current", "main", time.Minute, false, false, v1alpha3.QueueConcurrent)
+		unlabeled := pr.DeepCopy()
+		unlabeled.Labels = nil
+		r := &Reconciler{
+			Client: fake.NewFakeClientWithScheme(sch, unlabeled,
+				newConcurrentPipelineRun("pr-active", "main", time.Hour, true, false, v1alpha3.QueueConcurrent)),
+		}
+		queued, err := r.reconcileConcurrency(context.Background(), pr)
+		assert.Nil(t, err)
+		assert.True(t, queued)
+		assert.Equal(t, pr.Labels, getPipelineRun(r, "pr-current").Labels)
+	})
+
+	t.Run("Cancel older queued PipelineRuns", func(t *testing.T) {
+		pr := newConcurrentPipelineRun("pr-current", "main", time.Minute, false, false, v1alpha3.CancelOlderQueued)
+		r := &Reconciler{
+			Client: fake.NewFakeClientWithScheme(sch, pr.DeepCopy(),
+				newConcurrentPipelineRun("pr-older-queued", "main", time.Hour, false, false, v1alpha3.CancelOlderQueued)),
+			recorder: record.NewFakeRecorder(10),
+		}
+		queued, err := r.reconcileConcurrency(context.Background(), pr)
+		assert.Nil(t, err)
+		assert.False(t, queued)
+
+		cancelledPR := getPipelineRun(r, "pr-older-queued")
+		assert.Equal(t, v1alpha3.Failed, cancelledPR.Status.Phase)
+		assert.True(t, cancelledPR.HasCompleted())
+	})
+
+	t.Run("Queue while the DevOpsProject reached its limit", func(t *testing.T) {
+		ns := &corev1.Namespace{
+			ObjectMeta: v1.ObjectMeta{
+				Name:   "ns",
+				Labels: map[string]string{constants.DevOpsProjectLabelKey: "project"},
+			},
+		}
+		project := &v1alpha3.DevOpsProject{
+			ObjectMeta: v1.ObjectMeta{Name: "project"},
+			Spec:       v1alpha3.DevOpsProjectSpec{MaxConcurrentRuns: 1},
+		}
+		pr := newConcurrentPipelineRun("pr-current", "main", time.Minute, false, false, v1alpha3.AllowConcurrent)
+		r := &Reconciler{
+			Client: fake.NewFakeClientWithScheme(sch, ns, project, pr.DeepCopy(),
+				newConcurrentPipelineRun("pr-other-branch", "dev", time.Hour, true, false, v1alpha3.AllowConcurrent)),
+		}
+		queued, err := r.reconcileConcurrency(context.Background(), pr)
+		assert.Nil(t, err)
+		assert.True(t, queued)
+
+		queuedPR := getPipelineRun(r, "pr-current")
+		condition := getCondition(&queuedPR.Status, v1alpha3.ConditionQueued)
+		if assert.NotNil(t, condition) {
+			assert.Equal(t, v1alpha3.ConditionTrue, condition.Status)
+		}
+
+		// the cache has not seen that the other PipelineRun was triggered
+		r = &Reconciler{
+			Client: fake.NewFakeClientWithScheme(sch, ns, project, pr.DeepCopy(),
+				newConcurrentPipelineRun("pr-triggered", "dev", time.Hour, false, false, v1alpha3.AllowConcurrent)),
+			APIReader: fake.NewFakeClientWithScheme(sch, pr.DeepCopy(),
+				newConcurrentPipelineRun("pr-triggered", "dev", time.Hour, true, false, v1alpha3.AllowConcurrent)),
+		}
+		queued, err = r.reconcileConcurrency(context.Background(), pr)
+		assert.Nil(t, err)
+		assert.True(t, queued)
+
+		project.Spec.MaxConcurrentRuns = 2
+		r = &Reconciler{
+			Client: fake.NewFakeClientWithScheme(sch, ns, project, pr.DeepCopy(),
+				newConcurrentPipelineRun("pr-other-branch", "dev", time.Hour, true, false, v1alpha3.AllowConcurrent)),
+		}
+		queued, err = r.reconcileConcurrency(context.Background(), pr)
+		assert.Nil(t, err)
+		assert.False(t, queued)
+	})
+}
+
+func TestReconciler_wakeUpQueuedRuns(t *testing.T) {
+	sch := runtime.NewScheme()
+	if err := v1alpha3.AddToScheme(sch); err != nil {
+		t.Fatalf("unable to add v1alpha3 into scheme, err = %v", err)
+	}
+	policy := v1alpha3.QueueConcurrent
+	pr := newConcurrentPipelineRun("pr-completed", "main", 2*time.Hour, true, true, policy)
+	r := &Reconciler{
+		Client: fake.NewFakeClientWithScheme(sch, pr.DeepCopy(),
+			newConcurrentPipelineRun("pr-active", "main", time.Hour, true, false, policy),
+			newConcurrentPipelineRun("pr-older-queued", "main", time.Hour, false, false, policy),
+			newConcurrentPipelineRun("pr-newer-queued", "main", time.Minute, false, false, policy),
+			newConcurrentPipelineRun("pr-other-branch", "dev", 3*time.Hour, false, false, policy)),
+		queuedRuns: make(chan event.GenericEvent, 10),
+	}
+	assert.Nil(t, r.wakeUpQueuedRuns(context.Background(), pr))
+
+	var names []string
+	for len(r.queuedRuns) > 0 {
+		names = append(names, (<-r.queuedRuns).Meta.GetName())
+	}
+	// the oldest queued PipelineRun of the same branch, and the oldest one of the namespace
+	assert.Equal(t, []string{"pr-older-queued", "pr-other-branch"}, names)
+
+	// never block the reconciler if there are too many PipelineRuns to wake up
+	r.queuedRuns = make(chan event.GenericEvent)
+	assert.Nil(t, r.wakeUpQueuedRuns(context.Background(), pr))
+}
+
+func Test_dequeue(t *testing.T) {
+	status := &v1alpha3.PipelineRunStatus{}
+	dequeue(status)
+	assert.Nil(t, getCondition(status, v1alpha3.ConditionQueued))
+
+	status.AddCondition(&v1alpha3.Condition{
+		Type:   v1alpha3.ConditionQueued,
+		Status: v1alpha3.ConditionTrue,
+		Reason: v1alpha3.Queued,
+	})
+	dequeue(status)
+	condition := getCondition(status, v1alpha3.ConditionQueued)
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, v1alpha3.ConditionFalse, condition.Status)
+		assert.Equal(t, v1alpha3.Dequeued, condition.Reason)
+	}
+}
@@ -0,0 +1,94 @@
+package pipelinerun
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+)
+
+// logArchiveWaitTimeout is how long we keep trying to persist the logs after the PipelineRun completed. Jenkins might
+// have discarded the run after that.
+const logArchiveWaitTimeout = 24 * time.Hour
+
+// waitingForLogArchive indicates if the logs of the completed PipelineRun are still to be persisted.
+func (r *Reconciler) waitingForLogArchive(status *v1alpha3.PipelineRunStatus) bool {
+	return r.S3Client != nil && status.CompletionTime != nil && status.LogArchive == nil &&
+		time.Since(status.CompletionTime.Time) < logArchiveWaitTimeout
+}
+
+// getLogKey returns the object key of the full log of PipelineRun.
+func getLogKey(pr *v1alpha3.PipelineRun) string {
+	return fmt.Sprintf("pipelineruns/%s/%s/%s.log", pr.Namespace, pr.Name, pr.UID)
+}
+
+// getStepLogKey returns the object key of the log of a step in PipelineRun.
+func getStepLogKey(pr *v1alpha3.PipelineRun, nodeID, stepID string) string {
+	return fmt.Sprintf("pipelineruns/%s/%s/%s/nodes/%s/steps/%s.log", pr.Namespace, pr.Name, pr.UID, nodeID, stepID)
+}
+
+// archiveLogs persists the full log and the logs of all steps of the completed PipelineRun into the object storage.
+func (r *Reconciler) archiveLogs(pr *v1alpha3.PipelineRun, run *jenkinsRun, stages []v1alpha3.StageStatus) (*v1alpha3.LogArchive, error) {
+	options := devops.LogOptions{
+		ProjectName:  run.projectName,
+		PipelineName: run.pipelineName,
+		BranchName:   run.branch,
+		RunID:        run.runID,
+	}
+	logArchive := &v1alpha3.LogArchive{Key: getLogKey(pr)}
+	if err := r.archiveLog(logArchive.Key, pr.Name+".log", options); err != nil {
+		return nil, err
+	}
+
+	for i := range stages {
+		for j := range stages[i].Steps {
+			options.NodeID = stages[i].ID
+			options.StepID = stages[i].Steps[j].ID
+			key := getStepLogKey(pr, options.NodeID, options.StepID)
+			fileName := fmt.Sprintf("%s-%s-%s.log", pr.Name, options.NodeID, options.StepID)
+			if err := r.archiveLog(key, fileName, options); err != nil {
+				return nil, err
+			}
+			logArchive.Steps = append(logArchive.Steps, v1alpha3.StepLogArchive{
+				NodeID: options.NodeID,
+				StepID: options.StepID,
+				Key:    key,
+			})
+		}
+	}
+	logArchive.ArchiveTime = &v1.Time{Time: time.Now()}
+	return logArchive, nil
+}
+
+// archiveLog streams the log into the object storage, so that a huge log is never held in memory.
+func (r *Reconciler) archiveLog(key, fileName string, options devops.LogOptions) error {
+	reader, writer := io.Pipe()
+	go func() {
+		_ = writer.CloseWithError(r.writeLog(writer, options))
+	}()
+	err := r.S3Client.Upload(key, fileName, reader)
+	// stop writing the log if the upload failed halfway
+	_ = reader.CloseWithError(err)
+	return err
+}
+
+// writeLog writes the log from the start offset part by part, until Jenkins has no more log.
+func (r *Reconciler) writeLog(writer io.Writer, options devops.LogOptions) error {
+	for {
+		progressiveLog, err := r.DevOpsClient.GetProgressiveLog(options)
+		if err != nil {
+			return err
+		}
+		if _, err = writer.Write(progressiveLog.Text); err != nil {
+			return err
+		}
+		// never loop forever in case Jenkins doesn't move forward
+		if !progressiveLog.HasMore || progressiveLog.NextStart <= options.Start {
+			return nil
+		}
+		options.Start = progressiveLog.NextStart
+	}
+}
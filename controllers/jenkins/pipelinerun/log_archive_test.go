@@ -0,0 +1,119 @@
+package pipelinerun
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/devops/fake"
+	fakes3 "kubesphere.io/devops/pkg/client/s3/fake"
+)
+
+// pagedLogClient returns the log part by part, and fails once the failAt offset is reached
+type pagedLogClient struct {
+	*fake.Devops
+	log      string
+	pageSize int64
+	failAt   int64
+}
+
+func (c *pagedLogClient) GetProgressiveLog(options devops.LogOptions) (*devops.ProgressiveLog, error) {
+	if c.failAt > 0 && options.Start >= c.failAt {
+		return nil, errors.New("connection reset")
+	}
+	end := options.Start + c.pageSize
+	if end > int64(len(c.log)) {
+		end = int64(len(c.log))
+	}
+	return &devops.ProgressiveLog{
+		Text:      []byte(c.log[options.Start:end]),
+		NextStart: end,
+		HasMore:   end < int64(len(c.log)),
+	}, nil
+}
+
+func TestReconciler_archiveLogs(t *testing.T) {
+	pr := &v1alpha3.PipelineRun{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace: "project1",
+			Name:      "pipeline1-abc",
+			UID:       "uid",
+		},
+	}
+	run := &jenkinsRun{
+		projectName:  "project1",
+		pipelineName: "pipeline1",
+		runID:        "1",
+	}
+	stages := []v1alpha3.StageStatus{{
+		ID:    "3",
+		Steps: []v1alpha3.StepStatus{{ID: "4"}, {ID: "5"}},
+	}, {
+		ID: "6",
+	}}
+	s3Client := fakes3.NewFakeS3()
+	r := &Reconciler{
+		DevOpsClient: fake.New("project1"),
+		S3Client:     s3Client,
+	}
+
+	logArchive, err := r.archiveLogs(pr, run, stages)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "pipelineruns/project1/pipeline1-abc/uid.log", logArchive.Key)
+	assert.Equal(t, []v1alpha3.StepLogArchive{{
+		NodeID: "3",
+		StepID: "4",
+		Key:    "pipelineruns/project1/pipeline1-abc/uid/nodes/3/steps/4.log",
+	}, {
+		NodeID: "3",
+		StepID: "5",
+		Key:    "pipelineruns/project1/pipeline1-abc/uid/nodes/3/steps/5.log",
+	}}, logArchive.Steps)
+	assert.NotNil(t, logArchive.ArchiveTime)
+	assert.Equal(t, 3, len(s3Client.Storage))
+	assert.Equal(t, "pipeline1-abc.log", s3Client.Storage[logArchive.Key].FileName)
+}
+
+func TestReconciler_archiveLog(t *testing.T) {
+	log := strings.Repeat("0123456789", 10)
+	s3Client := fakes3.NewFakeS3()
+	r := &Reconciler{
+		DevOpsClient: &pagedLogClient{Devops: fake.New("project1"), log: log, pageSize: 7},
+		S3Client:     s3Client,
+	}
+	options := devops.LogOptions{ProjectName: "project1", PipelineName: "pipeline1", RunID: "1"}
+
+	// all parts of the log are persisted
+	assert.Nil(t, r.archiveLog("run.log", "run.log", options))
+	data, err := s3Client.Read("run.log")
+	assert.Nil(t, err)
+	assert.Equal(t, log, string(data))
+
+	// the upload fails if any part of the log cannot be fetched
+	r.DevOpsClient = &pagedLogClient{Devops: fake.New("project1"), log: log, pageSize: 7, failAt: 50}
+	assert.NotNil(t, r.archiveLog("failed.log", "failed.log", options))
+}
+
+func TestReconciler_waitingForLogArchive(t *testing.T) {
+	completedAgo := func(d time.Duration) *v1alpha3.PipelineRunStatus {
+		return &v1alpha3.PipelineRunStatus{CompletionTime: &v1.Time{Time: time.Now().Add(-d)}}
+	}
+	r := &Reconciler{S3Client: fakes3.NewFakeS3()}
+	assert.False(t, r.waitingForLogArchive(&v1alpha3.PipelineRunStatus{}))
+	assert.True(t, r.waitingForLogArchive(completedAgo(time.Hour)))
+	assert.False(t, r.waitingForLogArchive(completedAgo(logArchiveWaitTimeout+time.Hour)))
+
+	archived := completedAgo(time.Hour)
+	archived.LogArchive = &v1alpha3.LogArchive{Key: "run.log"}
+	assert.False(t, r.waitingForLogArchive(archived))
+
+	// logs are not persisted without object storage
+	assert.False(t, (&Reconciler{}).waitingForLogArchive(completedAgo(time.Hour)))
+}
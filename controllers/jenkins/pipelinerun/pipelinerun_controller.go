@@ -17,6 +17,7 @@ limitations under the License.
 package pipelinerun
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -36,23 +37,58 @@ import (
 	"k8s.io/klog"
 	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
 	devopsClient "kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/s3"
+	"kubesphere.io/devops/pkg/client/sonarqube"
 	"kubesphere.io/devops/pkg/utils/sliceutil"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// defaultPollInterval is the poll interval used when PollInterval of Reconciler is not set.
+	defaultPollInterval = 30 * time.Second
+	// pollBackoffFactor makes the poll interval grow by one tenth of the elapsed time of a PipelineRun.
+	pollBackoffFactor = 10
+	// maxStagesStatusAnnotationSize is the max size of the deprecated annotation of stages status. The total size of
+	// annotations is limited to 256 KiB.
+	maxStagesStatusAnnotationSize = 128 * 1024
+	// queuedRunsBufferSize is the number of queued PipelineRuns which can be waiting to be woken up.
+	queuedRunsBufferSize = 100
 )
 
 // Reconciler reconciles a PipelineRun object
 type Reconciler struct {
 	client.Client
+	// APIReader reads from the API server directly, it's used where the cache might be stale. The client is used if
+	// it's nil.
+	APIReader    client.Reader
 	log          logr.Logger
 	Scheme       *runtime.Scheme
 	DevOpsClient devopsClient.Interface
 	JenkinsCore  core.JenkinsCore
-	recorder     record.EventRecorder
+	// S3Client persists the logs of completed PipelineRuns. Logs are not persisted if it's nil.
+	S3Client s3.Interface
+	// SonarClient collects the quality gates of completed PipelineRuns. Quality gates are not collected if it's nil.
+	SonarClient sonarqube.SonarInterface
+	recorder    record.EventRecorder
+
+	// PollInterval is the initial interval of polling a running PipelineRun from Jenkins.
+	PollInterval time.Duration
+	// MaxPollInterval is the upper limit of the poll interval. Zero means no limit.
+	MaxPollInterval time.Duration
+
+	// queuedRuns wakes up the queued PipelineRuns once a concurrent PipelineRun completed.
+	queuedRuns chan event.GenericEvent
 }
 
 //+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=devops.kubesphere.io,resources=pipelineruns/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=devops.kubesphere.io,resources=devopsprojects,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -85,8 +121,8 @@ func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		return ctrl.Result{}, err
 	}
 
-	// the PipelineRun cannot allow building
-	if !pr.Buildable() {
+	// the PipelineRun cannot allow building, unless its results are still being collected after it completed
+	if !pr.Buildable() && !r.hasPendingResults(&pr) {
 		return ctrl.Result{}, nil
 	}
 
@@ -117,8 +153,30 @@ func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 
 	log = log.WithValues("namespace", namespaceName, "Pipeline", pipelineName)
 
+	// act on the action of PipelineRun, there is nothing to act on once it completed
+	if pr.Spec.Action != nil && !pr.HasCompleted() {
+		if pr.HasStarted() {
+			return r.reconcileAction(ctx, &pr, namespaceName, pipelineName)
+		}
+		if *pr.Spec.Action == v1alpha3.Stop {
+			// there is no need to trigger a PipelineRun which is going to be stopped
+			return ctrl.Result{}, r.cancelPipelineRun(ctx, &pr, "The PipelineRun was stopped before it was triggered")
+		}
+		// other actions will be handled once the PipelineRun has started
+	}
+
 	// check PipelineRun status
 	if pr.HasStarted() {
+		// abort the PipelineRun which runs out of time, no matter what Jenkins says
+		if remaining, ok := getRemainingTime(&pr, time.Now()); ok && remaining <= 0 {
+			log.Info("PipelineRun timed out, and we are aborting it.")
+			if err := r.timeoutPipelineRun(ctx, &pr, namespaceName, pipelineName); err != nil {
+				log.Error(err, "unable to abort the PipelineRun which timed out.")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		}
+
 		log.V(5).Info("pipeline has already started, and we are retrieving run data from Jenkins.")
 		pipelineBuild, err := r.getPipelineRunResult(namespaceName, pipelineName, &pr)
 		if err != nil {
@@ -127,19 +185,25 @@ func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 			return ctrl.Result{}, err
 		}
 
-		prNodes, err := r.getPipelineNodes(namespaceName, pipelineName, &pr)
+		run, err := newJenkinsRun(namespaceName, pipelineName, &pr)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		stages, nodes, err := r.getStages(run, pr.Status.Stages)
 		if err != nil {
-			log.Error(err, "unable to get PipelineRun nodes detail")
-			r.recorder.Eventf(&pr, corev1.EventTypeWarning, v1alpha3.RetrieveFailed, "Failed to retrieve nodes detail from Jenkins, and error was %s", err)
+			log.Error(err, "unable to get PipelineRun stages detail")
+			r.recorder.Eventf(&pr, corev1.EventTypeWarning, v1alpha3.RetrieveFailed, "Failed to retrieve stages detail from Jenkins, and error was %s", err)
 			return ctrl.Result{}, err
 		}
 
-		// set the latest run result into annotations
+		// set the latest run result into annotations for backward compatibility
 		runResultJSON, err := json.Marshal(pipelineBuild)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
-		prNodesJSON, err := json.Marshal(prNodes)
+		// the status of stages has been moved into status, but the annotation is still read by the v1alpha2 API and
+		// the console. Keep writing it from the same nodes until it's removed in a future release.
+		prNodesJSON, err := marshalNodes(nodes, maxStagesStatusAnnotationSize)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
@@ -156,8 +220,64 @@ func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		}
 
 		status := pr.Status.DeepCopy()
-		pbApplier := pipelineBuildApplier{pipelineBuild}
+		pbApplier := pipelineBuildApplier{PipelineRun: pipelineBuild, stages: stages}
 		pbApplier.apply(status)
+		keepTimeout(&pr.Status, status)
+		qualityGatePending, testReportPending := false, false
+		var logArchiveErr error
+		if status.CompletionTime != nil {
+			if r.SonarClient != nil && status.QualityGate == nil {
+				// failing to collect the quality gate should not block the completion of PipelineRun
+				if qualityGate, pending, err := r.collectQualityGate(run, status.CompletionTime.Time); err != nil {
+					log.Error(err, "unable to collect the quality gate of PipelineRun.")
+					r.recorder.Eventf(&pr, corev1.EventTypeWarning, v1alpha3.QualityGateCollectFailed, "Failed to collect the quality gate of PipelineRun, and error was %s", err)
+					// the error might be temporary, try again until the quality gate times out
+					qualityGatePending = r.waitingForQualityGate(status)
+				} else if qualityGate != nil {
+					status.QualityGate = qualityGate
+					if len(qualityGate.Analyses) > 0 {
+						r.recorder.Eventf(&pr, corev1.EventTypeNormal, v1alpha3.QualityGateCollected, "Collected the quality gate of PipelineRun, and the status was %s", qualityGate.Status)
+					}
+				} else {
+					qualityGatePending = pending
+				}
+			}
+			if status.TestReport == nil {
+				// failing to collect the test results should not block the completion of PipelineRun
+				if testReport, err := r.collectTestReport(run); err != nil {
+					log.Error(err, "unable to collect the test results of PipelineRun.")
+					r.recorder.Eventf(&pr, corev1.EventTypeWarning, v1alpha3.TestReportCollectFailed, "Failed to collect the test results of PipelineRun, and error was %s", err)
+					// the error might be temporary, try again until the test report times out
+					testReportPending = waitingForTestReport(status)
+				} else {
+					status.TestReport = testReport
+					if testReport.Total > 0 {
+						r.recorder.Eventf(&pr, corev1.EventTypeNormal, v1alpha3.TestReportCollected, "Collected the test results of PipelineRun, %d passed, %d failed and %d skipped",
+							testReport.Passed, testReport.Failed, testReport.Skipped)
+					}
+				}
+			}
+			// the status has been refreshed from Jenkins, so the policy has to be applied again
+			enforceQualityGate(&pr, status)
+			if err := r.retry(ctx, &pr, status, pipelineBuild.Result); err != nil {
+				log.Error(err, "unable to retry PipelineRun.")
+				return ctrl.Result{}, err
+			}
+			if r.S3Client != nil && status.LogArchive == nil {
+				// failing to persist logs should not block the completion of PipelineRun
+				if logArchive, err := r.archiveLogs(&pr, run, status.Stages); err != nil {
+					log.Error(err, "unable to persist the logs of PipelineRun.")
+					r.recorder.Eventf(&pr, corev1.EventTypeWarning, v1alpha3.LogArchiveFailed, "Failed to persist the logs of PipelineRun, and error was %s", err)
+					if r.waitingForLogArchive(status) {
+						// try again with backoff after the status is updated
+						logArchiveErr = err
+					}
+				} else {
+					status.LogArchive = logArchive
+					r.recorder.Eventf(&pr, corev1.EventTypeNormal, v1alpha3.LogArchived, "Persisted the logs of PipelineRun into %s", logArchive.Key)
+				}
+			}
+		}
 
 		// Because the status is a subresource of PipelineRun, we have to update status separately.
 		// See also: https://book-v1.book.kubebuilder.io/basics/status_subresource.html
@@ -166,9 +286,48 @@ func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 			return ctrl.Result{RequeueAfter: time.Second}, err
 		}
 		r.recorder.Eventf(&pr, corev1.EventTypeNormal, v1alpha3.Updated, "Updated running data for PipelineRun %s", req.NamespacedName)
-		// until the status is okay
-		// TODO make the RequeueAfter configurable
-		return ctrl.Result{RequeueAfter: 3 * time.Second}, nil
+		if pr.Status.CompletionTime == nil && status.CompletionTime != nil {
+			if err := r.wakeUpQueuedRuns(ctx, &pr); err != nil {
+				log.Error(err, "unable to wake up the queued PipelineRuns.")
+			}
+		}
+		if status.CompletionTime != nil {
+			if logArchiveErr != nil {
+				return ctrl.Result{}, logArchiveErr
+			}
+			if qualityGatePending || testReportPending {
+				// wait for the code analyses which are still running in SonarQube, or the results we failed to collect
+				return ctrl.Result{RequeueAfter: r.nextPollInterval(nil)}, nil
+			}
+			// no need to poll a completed PipelineRun
+			return ctrl.Result{}, nil
+		}
+		// Jenkins events will wake us up in time, polling is only a fallback
+		requeueAfter := r.nextPollInterval(status)
+		if remaining, ok := getRemainingTime(&pr, time.Now()); ok && remaining < requeueAfter {
+			// make sure we check the PipelineRun once it times out
+			requeueAfter = remaining
+			if requeueAfter < time.Second {
+				requeueAfter = time.Second
+			}
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	// wait for the backoff of a retry PipelineRun
+	if retryAfter, ok := getRetryAfter(&pr); ok {
+		if backoff := time.Until(retryAfter); backoff > 0 {
+			return ctrl.Result{RequeueAfter: backoff}, nil
+		}
+	}
+
+	// honor the concurrency policy of Pipeline
+	if queued, err := r.reconcileConcurrency(ctx, &pr); err != nil {
+		log.Error(err, "unable to check concurrent PipelineRuns.")
+		return ctrl.Result{}, err
+	} else if queued {
+		log.V(5).Info("PipelineRun is queued due to concurrent PipelineRuns.")
+		return ctrl.Result{RequeueAfter: r.nextPollInterval(nil)}, nil
 	}
 
 	// first run
@@ -194,6 +353,13 @@ func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 
 	pr.Status.StartTime = &v1.Time{Time: time.Now()}
 	pr.Status.UpdateTime = &v1.Time{Time: time.Now()}
+	dequeue(&pr.Status)
+	if _, ok := pr.Labels[v1alpha3.PipelineRunRetryOfLabelKey]; ok {
+		pr.Status.Retry = &v1alpha3.RetryStatus{
+			Attempt: getAttempt(&pr),
+			RetryOf: getRetryOf(&pr),
+		}
+	}
 	// due to the status is subresource of PipelineRun, we have to update status separately.
 	// see also: https://book-v1.book.kubebuilder.io/basics/status_subresource.html
 
@@ -206,6 +372,24 @@ func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	return ctrl.Result{RequeueAfter: 1 * time.Second}, nil
 }
 
+// nextPollInterval returns the interval of the next fallback polling of a running PipelineRun. The interval grows with
+// the elapsed time of the PipelineRun, so that long-running PipelineRuns are polled less frequently.
+func (r *Reconciler) nextPollInterval(status *v1alpha3.PipelineRunStatus) time.Duration {
+	interval := r.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	if status != nil && status.StartTime != nil {
+		if backoff := time.Since(status.StartTime.Time) / pollBackoffFactor; backoff > interval {
+			interval = backoff
+		}
+	}
+	if r.MaxPollInterval > 0 && interval > r.MaxPollInterval {
+		interval = r.MaxPollInterval
+	}
+	return interval
+}
+
 func (r *Reconciler) deleteJenkinsJobHistory(pipelineRun *v1alpha3.PipelineRun) (err error) {
 	var buildNum int
 	if buildNum = getJenkinsBuildNumber(pipelineRun); buildNum < 0 {
@@ -294,21 +478,30 @@ func (r *Reconciler) getPipelineRunResult(devopsProjectName, pipelineName string
 	})
 }
 
-func (r *Reconciler) getPipelineNodes(devopsProjectName, pipelineName string, pr *v1alpha3.PipelineRun) ([]job.Node, error) {
-	runID, exists := pr.GetPipelineRunID()
-	if !exists {
-		return nil, fmt.Errorf("unable to get PipelineRun result due to not found run ID")
-	}
-	c := job.BlueOceanClient{JenkinsCore: r.JenkinsCore, Organization: "jenkins"}
-	branch, err := getSCMRefName(&pr.Spec)
-	if err != nil {
-		return nil, err
+// marshalNodes marshals the nodes into a JSON array which is not larger than the given size. The trailing nodes are
+// dropped if it's too large, so that a big Pipeline never exceeds the size limit of annotations.
+func marshalNodes(nodes []interface{}, maxSize int) ([]byte, error) {
+	buf := bytes.NewBufferString("[")
+	for _, node := range nodes {
+		data, err := json.Marshal(node)
+		if err != nil {
+			return nil, err
+		}
+		separator := 0
+		if buf.Len() > 1 {
+			separator = 1
+		}
+		// leave room for the closing bracket
+		if buf.Len()+separator+len(data)+1 > maxSize {
+			break
+		}
+		if separator > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(data)
 	}
-	return c.GetNodes(job.GetNodesOption{
-		Pipelines: []string{devopsProjectName, pipelineName},
-		Branch:    branch,
-		RunID:     runID,
-	})
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
 }
 
 func (r *Reconciler) updateLabelsAndAnnotations(ctx context.Context, pr *v1alpha3.PipelineRun) error {
@@ -331,6 +524,15 @@ func (r *Reconciler) updateLabelsAndAnnotations(ctx context.Context, pr *v1alpha
 	return r.Update(ctx, &prToUpdate)
 }
 
+// hasPendingResults indicates if the completed PipelineRun still has results to collect, which keeps it reconciled
+// even though it cannot be built anymore.
+func (r *Reconciler) hasPendingResults(pr *v1alpha3.PipelineRun) bool {
+	if !pr.HasCompleted() || pr.Labels[v1alpha3.PipelineRunOrphanKey] == "true" {
+		return false
+	}
+	return r.waitingForQualityGate(&pr.Status) || waitingForTestReport(&pr.Status) || r.waitingForLogArchive(&pr.Status)
+}
+
 func (r *Reconciler) updateStatus(ctx context.Context, desiredStatus *v1alpha3.PipelineRunStatus, prKey client.ObjectKey) error {
 	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		prToUpdate := v1alpha3.PipelineRun{}
@@ -372,7 +574,27 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// the name should obey Kubernetes naming convention: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/
 	r.recorder = mgr.GetEventRecorderFor("pipelinerun-controller")
 	r.log = ctrl.Log.WithName("pipelinerun-controller")
+	if r.APIReader == nil {
+		r.APIReader = mgr.GetAPIReader()
+	}
+	r.queuedRuns = make(chan event.GenericEvent, queuedRunsBufferSize)
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha3.PipelineRun{}).
+		Watches(&source.Channel{Source: r.queuedRuns}, &handler.EnqueueRequestForObject{}).
+		WithEventFilter(predicate.Or(predicate.GenerationChangedPredicate{}, jenkinsEventPredicate())).
 		Complete(r)
 }
+
+// jenkinsEventPredicate accepts the update events of PipelineRuns which received a new Jenkins event. The status
+// updates made by the reconciler itself are ignored, so that we don't retrieve running data from Jenkins in a loop.
+func jenkinsEventPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.MetaOld == nil || e.MetaNew == nil {
+				return false
+			}
+			return e.MetaOld.GetAnnotations()[v1alpha3.JenkinsPipelineRunEventKey] !=
+				e.MetaNew.GetAnnotations()[v1alpha3.JenkinsPipelineRunEventKey]
+		},
+	}
+}
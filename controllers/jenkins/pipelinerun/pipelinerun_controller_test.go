@@ -2,11 +2,14 @@ package pipelinerun
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/jenkins-zh/jenkins-client/pkg/core"
@@ -16,6 +19,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	fakes3 "kubesphere.io/devops/pkg/client/s3/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
 func Test_getBranch(t *testing.T) {
@@ -291,3 +297,220 @@ var _ = Describe("Test deleteJenkinsJobHistory", func() {
 		ctrl.Finish()
 	})
 })
+
+func Test_marshalNodes(t *testing.T) {
+	var nodes []interface{}
+	for i := 0; i < 10; i++ {
+		nodes = append(nodes, &devops.PipelineRunNodes{ID: strconv.Itoa(i), DisplayName: "stage"})
+	}
+	full, err := json.Marshal(nodes)
+	if err != nil {
+		t.Fatalf("unable to marshal nodes, err = %v", err)
+	}
+
+	data, err := marshalNodes(nodes, len(full))
+	if err != nil || !bytes.Equal(data, full) {
+		t.Errorf("marshalNodes() got = %s, err = %v, want %s", data, err, full)
+	}
+
+	// the trailing nodes are dropped
+	data, err = marshalNodes(nodes, len(full)-1)
+	var got []devops.PipelineRunNodes
+	if err != nil || len(data) > len(full)-1 || json.Unmarshal(data, &got) != nil {
+		t.Fatalf("marshalNodes() got = %s, err = %v", data, err)
+	}
+	if len(got) != len(nodes)-1 {
+		t.Errorf("marshalNodes() got %d nodes, want %d", len(got), len(nodes)-1)
+	}
+
+	// there is always a valid JSON array
+	data, err = marshalNodes(nil, 2)
+	if err != nil || string(data) != "[]" {
+		t.Errorf("marshalNodes() got = %s, err = %v, want []", data, err)
+	}
+}
+
+func TestReconciler_hasPendingResults(t *testing.T) {
+	newCompletedRun := func(completedAgo time.Duration, labels map[string]string) *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{
+			ObjectMeta: v1.ObjectMeta{Labels: labels},
+			Status: v1alpha3.PipelineRunStatus{
+				CompletionTime: &v1.Time{Time: time.Now().Add(-completedAgo)},
+				TestReport:     &v1alpha3.TestReport{},
+				LogArchive:     &v1alpha3.LogArchive{},
+			},
+		}
+	}
+	withQualityGate := newCompletedRun(time.Minute, nil)
+	withQualityGate.Status.QualityGate = &v1alpha3.QualityGate{Status: v1alpha3.QualityGateStatusOK}
+	withoutAnalysis := newCompletedRun(time.Minute, nil)
+	withoutAnalysis.Status.QualityGate = &v1alpha3.QualityGate{Status: v1alpha3.QualityGateStatusNone}
+	withoutTestReport := newCompletedRun(time.Minute, nil)
+	withoutTestReport.Status.TestReport = nil
+	testReportTimedOut := newCompletedRun(testReportWaitTimeout+time.Minute, nil)
+	testReportTimedOut.Status.TestReport = nil
+
+	withoutLogArchive := newCompletedRun(time.Minute, nil)
+	withoutLogArchive.Status.LogArchive = nil
+
+	tests := []struct {
+		name        string
+		sonarClient bool
+		s3Client    bool
+		pr          *v1alpha3.PipelineRun
+		want        bool
+	}{{
+		name:        "Running PipelineRun",
+		sonarClient: true,
+		pr:          &v1alpha3.PipelineRun{},
+		want:        false,
+	}, {
+		name:        "Quality gate is pending",
+		sonarClient: true,
+		pr:          newCompletedRun(time.Minute, nil),
+		want:        true,
+	}, {
+		name:        "Quality gate was collected",
+		sonarClient: true,
+		pr:          withQualityGate,
+		want:        false,
+	}, {
+		name:        "Without analysis",
+		sonarClient: true,
+		pr:          withoutAnalysis,
+		want:        false,
+	}, {
+		name:        "Quality gate timed out",
+		sonarClient: true,
+		pr:          newCompletedRun(qualityGateWaitTimeout+time.Minute, nil),
+		want:        false,
+	}, {
+		name:        "Without SonarQube",
+		sonarClient: false,
+		pr:          newCompletedRun(time.Minute, nil),
+		want:        false,
+	}, {
+		name:        "Test report is pending",
+		sonarClient: false,
+		pr:          withoutTestReport,
+		want:        true,
+	}, {
+		name:        "Test report timed out",
+		sonarClient: false,
+		pr:          testReportTimedOut,
+		want:        false,
+	}, {
+		name:     "Logs are not persisted",
+		s3Client: true,
+		pr:       withoutLogArchive,
+		want:     true,
+	}, {
+		name:     "Without object storage",
+		s3Client: false,
+		pr:       withoutLogArchive,
+		want:     false,
+	}, {
+		name:        "Orphan PipelineRun",
+		sonarClient: true,
+		pr:          newCompletedRun(time.Minute, map[string]string{v1alpha3.PipelineRunOrphanKey: "true"}),
+		want:        false,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Reconciler{}
+			if tt.sonarClient {
+				r.SonarClient = &fakeSonar{}
+			}
+			if tt.s3Client {
+				r.S3Client = fakes3.NewFakeS3()
+			}
+			if got := r.hasPendingResults(tt.pr); got != tt.want {
+				t.Errorf("hasPendingResults() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconciler_nextPollInterval(t *testing.T) {
+	startedAgo := func(d time.Duration) *v1alpha3.PipelineRunStatus {
+		return &v1alpha3.PipelineRunStatus{
+			StartTime: &v1.Time{Time: time.Now().Add(-d)},
+		}
+	}
+	tests := []struct {
+		name       string
+		reconciler *Reconciler
+		status     *v1alpha3.PipelineRunStatus
+		want       time.Duration
+	}{{
+		name:       "Default poll interval",
+		reconciler: &Reconciler{},
+		status:     &v1alpha3.PipelineRunStatus{},
+		want:       defaultPollInterval,
+	}, {
+		name:       "Just started",
+		reconciler: &Reconciler{PollInterval: 10 * time.Second, MaxPollInterval: time.Minute},
+		status:     startedAgo(time.Second),
+		want:       10 * time.Second,
+	}, {
+		name:       "Backoff with elapsed time",
+		reconciler: &Reconciler{PollInterval: 10 * time.Second, MaxPollInterval: time.Hour},
+		status:     startedAgo(10 * time.Minute),
+		want:       time.Minute,
+	}, {
+		name:       "Limited by max poll interval",
+		reconciler: &Reconciler{PollInterval: 10 * time.Second, MaxPollInterval: time.Minute},
+		status:     startedAgo(time.Hour),
+		want:       time.Minute,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.reconciler.nextPollInterval(tt.status)
+			// tolerate the time spent by test itself
+			if got < tt.want || got > tt.want+time.Second {
+				t.Errorf("nextPollInterval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_jenkinsEventPredicate(t *testing.T) {
+	createPipelineRun := func(eventAnnotation string) *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{
+			ObjectMeta: v1.ObjectMeta{
+				Annotations: map[string]string{
+					v1alpha3.JenkinsPipelineRunEventKey: eventAnnotation,
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name   string
+		oldObj *v1alpha3.PipelineRun
+		newObj *v1alpha3.PipelineRun
+		want   bool
+	}{{
+		name:   "Received a new event",
+		oldObj: createPipelineRun(""),
+		newObj: createPipelineRun("run.started/2021-08-01T00:00:00Z"),
+		want:   true,
+	}, {
+		name:   "No new event",
+		oldObj: createPipelineRun("run.started/2021-08-01T00:00:00Z"),
+		newObj: createPipelineRun("run.started/2021-08-01T00:00:00Z"),
+		want:   false,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := jenkinsEventPredicate().Update(event.UpdateEvent{
+				MetaOld:   tt.oldObj,
+				ObjectOld: tt.oldObj,
+				MetaNew:   tt.newObj,
+				ObjectNew: tt.newObj,
+			})
+			if got != tt.want {
+				t.Errorf("jenkinsEventPredicate().Update() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
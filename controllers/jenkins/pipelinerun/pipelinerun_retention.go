@@ -0,0 +1,190 @@
+package pipelinerun
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Valid values for event reasons(new reasons could be added in the future)
+const (
+	PipelineRunsDiscarded     = "PipelineRunsDiscarded"
+	FailedPipelineRunsDiscard = "FailedPipelineRunsDiscard"
+)
+
+// RetentionReconciler deletes the PipelineRuns which are out of the retention of the Pipeline. The retention comes
+// from DiscarderProperty of the Pipeline. PipelineRuns are deleted through the PipelineRun finalizer, so that the
+// Jenkins build records are deleted together with them.
+type RetentionReconciler struct {
+	client.Client
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *RetentionReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.log.WithValues("Pipeline", req.NamespacedName)
+	pipeline := &v1alpha3.Pipeline{}
+	if err := r.Client.Get(ctx, req.NamespacedName, pipeline); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !pipeline.DeletionTimestamp.IsZero() {
+		// all PipelineRuns will be deleted together with the Pipeline
+		return ctrl.Result{}, nil
+	}
+
+	policy := newRetentionPolicy(getDiscarder(pipeline))
+	if policy == nil {
+		return ctrl.Result{}, nil
+	}
+
+	var prList v1alpha3.PipelineRunList
+	if err := r.Client.List(ctx, &prList, client.InNamespace(pipeline.Namespace), client.MatchingLabels{
+		v1alpha3.PipelineNameLabelKey: pipeline.Name,
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	discarded, nextExpiration := policy.discard(prList.Items, time.Now())
+	for _, pr := range discarded {
+		if err := r.Client.Delete(ctx, pr); err != nil && !apierrors.IsNotFound(err) {
+			log.Error(err, "failed to delete PipelineRun", "PipelineRun", pr.Name)
+			r.recorder.Eventf(pipeline, v1.EventTypeWarning, FailedPipelineRunsDiscard,
+				"Failed to delete PipelineRun %s, and error was %s", pr.Name, err)
+			return ctrl.Result{}, err
+		}
+	}
+	if len(discarded) > 0 {
+		log.Info("discarded PipelineRuns", "count", len(discarded))
+		r.recorder.Eventf(pipeline, v1.EventTypeNormal, PipelineRunsDiscarded,
+			"Successfully discarded %d PipelineRun(s)", len(discarded))
+	}
+	if nextExpiration > 0 {
+		return ctrl.Result{RequeueAfter: nextExpiration}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// getDiscarder returns the DiscarderProperty of the Pipeline, or nil if there is no DiscarderProperty.
+func getDiscarder(pipeline *v1alpha3.Pipeline) *v1alpha3.DiscarderProperty {
+	switch pipeline.Spec.Type {
+	case v1alpha3.NoScmPipelineType:
+		if pipeline.Spec.Pipeline != nil {
+			return pipeline.Spec.Pipeline.Discarder
+		}
+	case v1alpha3.MultiBranchPipelineType:
+		if pipeline.Spec.MultiBranchPipeline != nil {
+			return pipeline.Spec.MultiBranchPipeline.Discarder
+		}
+	}
+	return nil
+}
+
+// retentionPolicy decides which PipelineRuns should be discarded.
+type retentionPolicy struct {
+	// numToKeep is the number of PipelineRuns to keep. Zero means no limit.
+	numToKeep int
+	// daysToKeep is the number of days to keep PipelineRuns. Zero means no limit.
+	daysToKeep int
+	// keepLastSuccessful indicates if the last successful PipelineRun is always kept.
+	keepLastSuccessful bool
+}
+
+// newRetentionPolicy creates a retentionPolicy from DiscarderProperty. It returns nil if nothing needs to be discarded.
+func newRetentionPolicy(discarder *v1alpha3.DiscarderProperty) *retentionPolicy {
+	if discarder == nil {
+		return nil
+	}
+	policy := &retentionPolicy{
+		keepLastSuccessful: discarder.KeepLastSuccessful,
+	}
+	// empty or invalid values, like "-1", mean no limit, which is the same as Jenkins
+	if numToKeep, err := strconv.Atoi(discarder.NumToKeep); err == nil && numToKeep > 0 {
+		policy.numToKeep = numToKeep
+	}
+	if daysToKeep, err := strconv.Atoi(discarder.DaysToKeep); err == nil && daysToKeep > 0 {
+		policy.daysToKeep = daysToKeep
+	}
+	if policy.numToKeep == 0 && policy.daysToKeep == 0 {
+		return nil
+	}
+	return policy
+}
+
+// discard returns the PipelineRuns which should be discarded, and the duration after which the next kept PipelineRun
+// expires. Only completed PipelineRuns will be discarded. The zero duration means no PipelineRun is going to expire.
+func (p *retentionPolicy) discard(prs []v1alpha3.PipelineRun, now time.Time) (discarded []*v1alpha3.PipelineRun,
+	nextExpiration time.Duration) {
+	// each branch of a multi-branch Pipeline has its own history, which is the same as Jenkins
+	histories := make(map[string][]*v1alpha3.PipelineRun)
+	for i := range prs {
+		pr := &prs[i]
+		if !pr.DeletionTimestamp.IsZero() {
+			continue
+		}
+		refName := pr.Labels[v1alpha3.SCMRefNameLabelKey]
+		histories[refName] = append(histories[refName], pr)
+	}
+
+	for _, history := range histories {
+		// newest first
+		sort.SliceStable(history, func(i, j int) bool {
+			return history[j].CreationTimestamp.Before(&history[i].CreationTimestamp)
+		})
+
+		var lastSuccessful *v1alpha3.PipelineRun
+		if p.keepLastSuccessful {
+			for _, pr := range history {
+				if pr.Status.Phase == v1alpha3.Succeeded {
+					lastSuccessful = pr
+					break
+				}
+			}
+		}
+
+		for i, pr := range history {
+			if !pr.HasCompleted() || pr == lastSuccessful {
+				continue
+			}
+			if p.numToKeep > 0 && i >= p.numToKeep {
+				discarded = append(discarded, pr)
+				continue
+			}
+			if p.daysToKeep > 0 {
+				expiration := pr.CreationTimestamp.Add(time.Duration(p.daysToKeep) * 24 * time.Hour)
+				if !expiration.After(now) {
+					discarded = append(discarded, pr)
+					continue
+				}
+				if untilExpiration := expiration.Sub(now); nextExpiration == 0 || untilExpiration < nextExpiration {
+					nextExpiration = untilExpiration
+				}
+			}
+		}
+	}
+	return
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RetentionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("pipelinerun-retention")
+	r.log = ctrl.Log.WithName("pipelinerun-retention")
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("pipelinerun-retention").
+		For(&v1alpha3.Pipeline{}).
+		// PipelineRuns are controlled by Pipelines, so the Pipeline will be reconciled once a PipelineRun changes
+		Owns(&v1alpha3.PipelineRun{}).
+		Complete(r)
+}
@@ -0,0 +1,155 @@
+package pipelinerun
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func Test_newRetentionPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		discarder *v1alpha3.DiscarderProperty
+		want      *retentionPolicy
+	}{{
+		name:      "Nil discarder",
+		discarder: nil,
+		want:      nil,
+	}, {
+		name:      "Empty discarder",
+		discarder: &v1alpha3.DiscarderProperty{},
+		want:      nil,
+	}, {
+		name: "Unlimited discarder",
+		discarder: &v1alpha3.DiscarderProperty{
+			DaysToKeep:         "-1",
+			NumToKeep:          "-1",
+			KeepLastSuccessful: true,
+		},
+		want: nil,
+	}, {
+		name: "Invalid values",
+		discarder: &v1alpha3.DiscarderProperty{
+			DaysToKeep: "seven",
+			NumToKeep:  "10",
+		},
+		want: &retentionPolicy{numToKeep: 10},
+	}, {
+		name: "Valid discarder",
+		discarder: &v1alpha3.DiscarderProperty{
+			DaysToKeep:         "7",
+			NumToKeep:          "10",
+			KeepLastSuccessful: true,
+		},
+		want: &retentionPolicy{numToKeep: 10, daysToKeep: 7, keepLastSuccessful: true},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, newRetentionPolicy(tt.discarder))
+		})
+	}
+}
+
+func Test_retentionPolicy_discard(t *testing.T) {
+	now := time.Now()
+	createPipelineRun := func(name, refName string, age time.Duration, phase v1alpha3.RunPhase) v1alpha3.PipelineRun {
+		pr := v1alpha3.PipelineRun{
+			ObjectMeta: v1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: v1.NewTime(now.Add(-age)),
+				Labels:            map[string]string{},
+			},
+			Status: v1alpha3.PipelineRunStatus{
+				Phase: phase,
+			},
+		}
+		if refName != "" {
+			pr.Labels[v1alpha3.SCMRefNameLabelKey] = refName
+		}
+		if phase == v1alpha3.Succeeded || phase == v1alpha3.Failed {
+			pr.Status.CompletionTime = &pr.CreationTimestamp
+		}
+		return pr
+	}
+	day := 24 * time.Hour
+	names := func(prs []*v1alpha3.PipelineRun) (result []string) {
+		for _, pr := range prs {
+			result = append(result, pr.Name)
+		}
+		return
+	}
+
+	tests := []struct {
+		name               string
+		policy             retentionPolicy
+		prs                []v1alpha3.PipelineRun
+		wantDiscarded      []string
+		wantNextExpiration time.Duration
+	}{{
+		name:   "Keep the last two PipelineRuns",
+		policy: retentionPolicy{numToKeep: 2},
+		prs: []v1alpha3.PipelineRun{
+			createPipelineRun("pr-1", "", 4*time.Hour, v1alpha3.Succeeded),
+			createPipelineRun("pr-2", "", 3*time.Hour, v1alpha3.Failed),
+			createPipelineRun("pr-3", "", 2*time.Hour, v1alpha3.Failed),
+			createPipelineRun("pr-4", "", time.Hour, v1alpha3.Running),
+		},
+		wantDiscarded: []string{"pr-2", "pr-1"},
+	}, {
+		name:   "Keep the last two PipelineRuns and the last successful one",
+		policy: retentionPolicy{numToKeep: 2, keepLastSuccessful: true},
+		prs: []v1alpha3.PipelineRun{
+			createPipelineRun("pr-1", "", 4*time.Hour, v1alpha3.Succeeded),
+			createPipelineRun("pr-2", "", 3*time.Hour, v1alpha3.Failed),
+			createPipelineRun("pr-3", "", 2*time.Hour, v1alpha3.Failed),
+			createPipelineRun("pr-4", "", time.Hour, v1alpha3.Failed),
+		},
+		wantDiscarded: []string{"pr-2"},
+	}, {
+		name:   "Keep PipelineRuns in three days",
+		policy: retentionPolicy{daysToKeep: 3},
+		prs: []v1alpha3.PipelineRun{
+			createPipelineRun("pr-1", "", 4*day, v1alpha3.Succeeded),
+			createPipelineRun("pr-2", "", 2*day, v1alpha3.Failed),
+			createPipelineRun("pr-3", "", 5*day, v1alpha3.Running),
+		},
+		wantDiscarded:      []string{"pr-1"},
+		wantNextExpiration: day,
+	}, {
+		name:   "Keep the last PipelineRun of each branch",
+		policy: retentionPolicy{numToKeep: 1},
+		prs: []v1alpha3.PipelineRun{
+			createPipelineRun("main-1", "main", 2*time.Hour, v1alpha3.Succeeded),
+			createPipelineRun("main-2", "main", time.Hour, v1alpha3.Succeeded),
+			createPipelineRun("dev-1", "dev", 3*time.Hour, v1alpha3.Succeeded),
+		},
+		wantDiscarded: []string{"main-1"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discarded, nextExpiration := tt.policy.discard(tt.prs, now)
+			assert.ElementsMatch(t, tt.wantDiscarded, names(discarded))
+			assert.Equal(t, tt.wantNextExpiration, nextExpiration)
+		})
+	}
+}
+
+func Test_getDiscarder(t *testing.T) {
+	discarder := &v1alpha3.DiscarderProperty{NumToKeep: "1"}
+	assert.Nil(t, getDiscarder(&v1alpha3.Pipeline{}))
+	assert.Equal(t, discarder, getDiscarder(&v1alpha3.Pipeline{
+		Spec: v1alpha3.PipelineSpec{
+			Type:     v1alpha3.NoScmPipelineType,
+			Pipeline: &v1alpha3.NoScmPipeline{Discarder: discarder},
+		},
+	}))
+	assert.Equal(t, discarder, getDiscarder(&v1alpha3.Pipeline{
+		Spec: v1alpha3.PipelineSpec{
+			Type:                v1alpha3.MultiBranchPipelineType,
+			MultiBranchPipeline: &v1alpha3.MultiBranchPipeline{Discarder: discarder},
+		},
+	}))
+}
@@ -0,0 +1,173 @@
+package pipelinerun
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/sonarqube"
+)
+
+// qualityGateWaitTimeout is how long we wait for the pending analyses after the PipelineRun completed. The analyses
+// which are still pending after that are recorded without a quality gate.
+const qualityGateWaitTimeout = 10 * time.Minute
+
+// qualityGateSeverities sort the statuses of quality gates, the unknown statuses are regarded as NONE.
+var qualityGateSeverities = map[v1alpha3.QualityGateStatus]int{
+	v1alpha3.QualityGateStatusNone:  0,
+	v1alpha3.QualityGateStatusOK:    1,
+	v1alpha3.QualityGateStatusWarn:  2,
+	v1alpha3.QualityGateStatusError: 3,
+}
+
+// getSonarActions returns the actions of the SonarQube analyses in the Jenkins run, one for each analysis task.
+func (r *Reconciler) getSonarActions(run *jenkinsRun) ([]devops.GeneralAction, error) {
+	var build *devops.Build
+	var err error
+	if run.branch != "" {
+		build, err = r.DevOpsClient.GetMultiBranchPipelineBuild(run.projectName, run.pipelineName, run.branch, run.runID)
+	} else {
+		build, err = r.DevOpsClient.GetProjectPipelineBuild(run.projectName, run.pipelineName, run.runID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []devops.GeneralAction
+	taskIDs := make(map[string]bool)
+	for _, action := range build.Actions {
+		if action.ClassName != sonarqube.SonarAnalysisActionClass || action.SonarTaskId == "" || taskIDs[action.SonarTaskId] {
+			continue
+		}
+		taskIDs[action.SonarTaskId] = true
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// waitingForQualityGate indicates if the quality gate of the completed PipelineRun might still be collected. A
+// PipelineRun without any analysis has a quality gate with the status NONE, so it doesn't wait.
+func (r *Reconciler) waitingForQualityGate(status *v1alpha3.PipelineRunStatus) bool {
+	return r.SonarClient != nil && status.CompletionTime != nil && status.QualityGate == nil &&
+		time.Since(status.CompletionTime.Time) < qualityGateWaitTimeout
+}
+
+// collectQualityGate collects the results of the SonarQube analyses of the completed PipelineRun. The quality gate is
+// nil if some analyses are still pending, which is indicated by pending. The quality gate of the PipelineRun without
+// any analysis has no analyses and the status NONE.
+func (r *Reconciler) collectQualityGate(run *jenkinsRun, completionTime time.Time) (qualityGate *v1alpha3.QualityGate, pending bool, err error) {
+	var actions []devops.GeneralAction
+	if actions, err = r.getSonarActions(run); err != nil {
+		return
+	}
+
+	qualityGate = &v1alpha3.QualityGate{}
+	for _, action := range actions {
+		analysis := v1alpha3.CodeAnalysis{
+			TaskID:       action.SonarTaskId,
+			Status:       v1alpha3.QualityGateStatusNone,
+			DashboardURL: action.SonarDashboardUrl,
+		}
+		var results []*sonarqube.SonarStatus
+		if results, err = r.SonarClient.GetSonarResultsByTaskIds(action.SonarTaskId); err != nil {
+			return nil, false, err
+		}
+		if len(results) == 0 {
+			// the result of a task which cannot be retrieved is skipped, it might be a temporary error
+			pending = true
+		} else {
+			var summary *sonarqube.QualityGateSummary
+			if summary, err = results[0].Summarize(); err != nil {
+				return nil, false, err
+			}
+			analysis.Component = summary.Component
+			if summary.Pending() {
+				// the measures belong to the previous analysis before the task finishes
+				pending = true
+			} else {
+				if summary.Status != "" {
+					analysis.Status = v1alpha3.QualityGateStatus(summary.Status)
+				}
+				analysis.Bugs = summary.Bugs
+				analysis.Vulnerabilities = summary.Vulnerabilities
+				analysis.Coverage = summary.Coverage
+			}
+		}
+		qualityGate.Analyses = append(qualityGate.Analyses, analysis)
+	}
+
+	if pending && time.Since(completionTime) < qualityGateWaitTimeout {
+		return nil, true, nil
+	}
+	qualityGate.Status = getWorstQualityGateStatus(qualityGate.Analyses)
+	qualityGate.CollectTime = &v1.Time{Time: time.Now()}
+	return qualityGate, false, nil
+}
+
+// getWorstQualityGateStatus returns the worst status of the quality gates of the analyses.
+func getWorstQualityGateStatus(analyses []v1alpha3.CodeAnalysis) v1alpha3.QualityGateStatus {
+	worst := v1alpha3.QualityGateStatusNone
+	for _, analysis := range analyses {
+		if qualityGateSeverities[analysis.Status] > qualityGateSeverities[worst] {
+			worst = analysis.Status
+		}
+	}
+	return worst
+}
+
+// enforceQualityGate applies the quality gate policy of Pipeline to the status of the completed PipelineRun. The
+// status is refreshed from Jenkins in every reconciliation, so the policy has to be applied every time.
+func enforceQualityGate(pr *v1alpha3.PipelineRun, status *v1alpha3.PipelineRunStatus) {
+	qualityGate := status.QualityGate
+	if qualityGate == nil || len(qualityGate.Analyses) == 0 || pr.Spec.PipelineSpec == nil || pr.Spec.PipelineSpec.QualityGate == nil {
+		return
+	}
+	failed := qualityGate.Status == v1alpha3.QualityGateStatusError
+
+	switch pr.Spec.PipelineSpec.QualityGate.Action {
+	case v1alpha3.QualityGateActionFail:
+		// the PipelineRun which failed in Jenkins keeps its own reason
+		if !failed || status.Phase != v1alpha3.Succeeded {
+			return
+		}
+		status.Phase = v1alpha3.Failed
+		status.AddCondition(&v1alpha3.Condition{
+			Type:               v1alpha3.ConditionSucceeded,
+			Status:             v1alpha3.ConditionFalse,
+			Reason:             v1alpha3.QualityGateFailed,
+			Message:            getQualityGateFailedMessage(qualityGate),
+			LastProbeTime:      v1.Now(),
+			LastTransitionTime: v1.Now(),
+		})
+	case v1alpha3.QualityGateActionUnhealthy:
+		condition := v1alpha3.Condition{
+			Type:               v1alpha3.ConditionHealthy,
+			Status:             v1alpha3.ConditionTrue,
+			Reason:             v1alpha3.QualityGatePassed,
+			Message:            fmt.Sprintf("The status of the quality gate is %s", qualityGate.Status),
+			LastProbeTime:      v1.Now(),
+			LastTransitionTime: v1.Now(),
+		}
+		if failed {
+			condition.Status = v1alpha3.ConditionFalse
+			condition.Reason = v1alpha3.QualityGateFailed
+			condition.Message = getQualityGateFailedMessage(qualityGate)
+		}
+		status.Conditions = v1alpha3.SetCondition(status.Conditions, condition)
+	}
+}
+
+// getQualityGateFailedMessage returns the message which describes the analyses whose quality gate failed.
+func getQualityGateFailedMessage(qualityGate *v1alpha3.QualityGate) string {
+	var failures []string
+	for _, analysis := range qualityGate.Analyses {
+		if analysis.Status == v1alpha3.QualityGateStatusError {
+			failures = append(failures, fmt.Sprintf("%s (%d bugs, %d vulnerabilities)",
+				analysis.Component, analysis.Bugs, analysis.Vulnerabilities))
+		}
+	}
+	return "The quality gate failed: " + strings.Join(failures, ", ")
+}
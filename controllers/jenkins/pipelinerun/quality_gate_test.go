@@ -0,0 +1,270 @@
+package pipelinerun
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/devops/fake"
+	"kubesphere.io/devops/pkg/client/sonarqube"
+)
+
+// fakeSonar returns the results of analysis tasks from JSON, the tasks without results are skipped
+type fakeSonar struct {
+	results map[string]string
+}
+
+func (s *fakeSonar) GetSonarResultsByTaskIds(taskIds ...string) ([]*sonarqube.SonarStatus, error) {
+	statuses := make([]*sonarqube.SonarStatus, 0)
+	for _, taskID := range taskIds {
+		result, ok := s.results[taskID]
+		if !ok {
+			continue
+		}
+		status := &sonarqube.SonarStatus{}
+		if err := json.Unmarshal([]byte(result), status); err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func sonarAction(taskID string) devops.GeneralAction {
+	return devops.GeneralAction{
+		ClassName:         sonarqube.SonarAnalysisActionClass,
+		SonarTaskId:       taskID,
+		SonarDashboardUrl: "http://sonar/dashboard?id=" + taskID,
+	}
+}
+
+func TestReconciler_collectQualityGate(t *testing.T) {
+	const (
+		passed = `{
+			"task": {"task": {"componentKey": "api", "status": "SUCCESS"}},
+			"measures": {"component": {"key": "api", "measures": [
+				{"metric": "alert_status", "value": "OK"},
+				{"metric": "bugs", "value": "0"},
+				{"metric": "coverage", "value": "80.0"}
+			]}}
+		}`
+		failed = `{
+			"task": {"task": {"componentKey": "web", "status": "SUCCESS"}},
+			"measures": {"component": {"key": "web", "measures": [
+				{"metric": "alert_status", "value": "ERROR"},
+				{"metric": "bugs", "value": "2"},
+				{"metric": "vulnerabilities", "value": "1"}
+			]}}
+		}`
+		pending = `{"task": {"task": {"componentKey": "web", "status": "IN_PROGRESS"}}}`
+	)
+	run := &jenkinsRun{
+		projectName:  "project1",
+		pipelineName: "pipeline1",
+		branch:       "main",
+		runID:        "1",
+	}
+
+	tests := []struct {
+		name           string
+		actions        []devops.GeneralAction
+		results        map[string]string
+		completionTime time.Time
+		want           *v1alpha3.QualityGate
+		pending        bool
+	}{{
+		name:           "No analysis",
+		actions:        []devops.GeneralAction{{ClassName: "hudson.model.CauseAction"}},
+		completionTime: time.Now(),
+		want:           &v1alpha3.QualityGate{Status: v1alpha3.QualityGateStatusNone},
+	}, {
+		name:           "Finished analyses",
+		actions:        []devops.GeneralAction{sonarAction("a"), sonarAction("b"), sonarAction("a")},
+		results:        map[string]string{"a": passed, "b": failed},
+		completionTime: time.Now(),
+		want: &v1alpha3.QualityGate{
+			Status: v1alpha3.QualityGateStatusError,
+			Analyses: []v1alpha3.CodeAnalysis{{
+				TaskID:       "a",
+				Component:    "api",
+				Status:       v1alpha3.QualityGateStatusOK,
+				Coverage:     "80.0",
+				DashboardURL: "http://sonar/dashboard?id=a",
+			}, {
+				TaskID:          "b",
+				Component:       "web",
+				Status:          v1alpha3.QualityGateStatusError,
+				Bugs:            2,
+				Vulnerabilities: 1,
+				DashboardURL:    "http://sonar/dashboard?id=b",
+			}},
+		},
+	}, {
+		name:           "Pending analysis",
+		actions:        []devops.GeneralAction{sonarAction("a"), sonarAction("b")},
+		results:        map[string]string{"a": passed, "b": pending},
+		completionTime: time.Now(),
+		pending:        true,
+	}, {
+		name:           "Pending analysis after the wait timeout",
+		actions:        []devops.GeneralAction{sonarAction("b")},
+		results:        map[string]string{"b": pending},
+		completionTime: time.Now().Add(-qualityGateWaitTimeout),
+		want: &v1alpha3.QualityGate{
+			Status: v1alpha3.QualityGateStatusNone,
+			Analyses: []v1alpha3.CodeAnalysis{{
+				TaskID:       "b",
+				Component:    "web",
+				Status:       v1alpha3.QualityGateStatusNone,
+				DashboardURL: "http://sonar/dashboard?id=b",
+			}},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devopsClient := fake.New("project1")
+			devopsClient.Builds = map[string]*devops.Build{
+				"project1/pipeline1/main/1": {Actions: tt.actions},
+			}
+			r := &Reconciler{
+				DevOpsClient: devopsClient,
+				SonarClient:  &fakeSonar{results: tt.results},
+			}
+
+			qualityGate, pending, err := r.collectQualityGate(run, tt.completionTime)
+			if !assert.Nil(t, err) {
+				return
+			}
+			assert.Equal(t, tt.pending, pending)
+			if tt.want == nil {
+				assert.Nil(t, qualityGate)
+				return
+			}
+			if !assert.NotNil(t, qualityGate) {
+				return
+			}
+			assert.NotNil(t, qualityGate.CollectTime)
+			qualityGate.CollectTime = nil
+			assert.Equal(t, tt.want, qualityGate)
+		})
+	}
+}
+
+func Test_enforceQualityGate(t *testing.T) {
+	newPipelineRun := func(action v1alpha3.QualityGateAction) *v1alpha3.PipelineRun {
+		return &v1alpha3.PipelineRun{
+			Spec: v1alpha3.PipelineRunSpec{
+				PipelineSpec: &v1alpha3.PipelineSpec{
+					QualityGate: &v1alpha3.QualityGatePolicy{Action: action},
+				},
+			},
+		}
+	}
+	newStatus := func(phase v1alpha3.RunPhase, gateStatus v1alpha3.QualityGateStatus) *v1alpha3.PipelineRunStatus {
+		return &v1alpha3.PipelineRunStatus{
+			Phase: phase,
+			QualityGate: &v1alpha3.QualityGate{
+				Status: gateStatus,
+				Analyses: []v1alpha3.CodeAnalysis{{
+					Component: "web",
+					Status:    gateStatus,
+					Bugs:      2,
+				}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		pipelineRun   *v1alpha3.PipelineRun
+		status        *v1alpha3.PipelineRunStatus
+		wantPhase     v1alpha3.RunPhase
+		wantCondition *v1alpha3.Condition
+	}{{
+		name:        "Without analysis",
+		pipelineRun: newPipelineRun(v1alpha3.QualityGateActionUnhealthy),
+		status: &v1alpha3.PipelineRunStatus{
+			Phase:       v1alpha3.Succeeded,
+			QualityGate: &v1alpha3.QualityGate{Status: v1alpha3.QualityGateStatusNone},
+		},
+		wantPhase: v1alpha3.Succeeded,
+	}, {
+		name:        "Without policy",
+		pipelineRun: &v1alpha3.PipelineRun{Spec: v1alpha3.PipelineRunSpec{PipelineSpec: &v1alpha3.PipelineSpec{}}},
+		status:      newStatus(v1alpha3.Succeeded, v1alpha3.QualityGateStatusError),
+		wantPhase:   v1alpha3.Succeeded,
+	}, {
+		name:        "Ignore the failed quality gate",
+		pipelineRun: newPipelineRun(v1alpha3.QualityGateActionNone),
+		status:      newStatus(v1alpha3.Succeeded, v1alpha3.QualityGateStatusError),
+		wantPhase:   v1alpha3.Succeeded,
+	}, {
+		name:        "Fail with the failed quality gate",
+		pipelineRun: newPipelineRun(v1alpha3.QualityGateActionFail),
+		status:      newStatus(v1alpha3.Succeeded, v1alpha3.QualityGateStatusError),
+		wantPhase:   v1alpha3.Failed,
+		wantCondition: &v1alpha3.Condition{
+			Type:    v1alpha3.ConditionSucceeded,
+			Status:  v1alpha3.ConditionFalse,
+			Reason:  v1alpha3.QualityGateFailed,
+			Message: "The quality gate failed: web (2 bugs, 0 vulnerabilities)",
+		},
+	}, {
+		name:        "Do not fail with the passed quality gate",
+		pipelineRun: newPipelineRun(v1alpha3.QualityGateActionFail),
+		status:      newStatus(v1alpha3.Succeeded, v1alpha3.QualityGateStatusWarn),
+		wantPhase:   v1alpha3.Succeeded,
+	}, {
+		name:        "Unhealthy with the failed quality gate",
+		pipelineRun: newPipelineRun(v1alpha3.QualityGateActionUnhealthy),
+		status:      newStatus(v1alpha3.Succeeded, v1alpha3.QualityGateStatusError),
+		wantPhase:   v1alpha3.Succeeded,
+		wantCondition: &v1alpha3.Condition{
+			Type:    v1alpha3.ConditionHealthy,
+			Status:  v1alpha3.ConditionFalse,
+			Reason:  v1alpha3.QualityGateFailed,
+			Message: "The quality gate failed: web (2 bugs, 0 vulnerabilities)",
+		},
+	}, {
+		name:        "Healthy with the passed quality gate",
+		pipelineRun: newPipelineRun(v1alpha3.QualityGateActionUnhealthy),
+		status:      newStatus(v1alpha3.Failed, v1alpha3.QualityGateStatusOK),
+		wantPhase:   v1alpha3.Failed,
+		wantCondition: &v1alpha3.Condition{
+			Type:    v1alpha3.ConditionHealthy,
+			Status:  v1alpha3.ConditionTrue,
+			Reason:  v1alpha3.QualityGatePassed,
+			Message: "The status of the quality gate is OK",
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enforceQualityGate(tt.pipelineRun, tt.status)
+			assert.Equal(t, tt.wantPhase, tt.status.Phase)
+			if tt.wantCondition == nil {
+				assert.Empty(t, tt.status.Conditions)
+				return
+			}
+			condition := v1alpha3.FindCondition(tt.status.Conditions, tt.wantCondition.Type)
+			if !assert.NotNil(t, condition) {
+				return
+			}
+			assert.Equal(t, tt.wantCondition.Status, condition.Status)
+			assert.Equal(t, tt.wantCondition.Reason, condition.Reason)
+			assert.Equal(t, tt.wantCondition.Message, condition.Message)
+		})
+	}
+}
+
+func Test_getWorstQualityGateStatus(t *testing.T) {
+	assert.Equal(t, v1alpha3.QualityGateStatusNone, getWorstQualityGateStatus(nil))
+	assert.Equal(t, v1alpha3.QualityGateStatusWarn, getWorstQualityGateStatus([]v1alpha3.CodeAnalysis{
+		{Status: v1alpha3.QualityGateStatusOK},
+		{Status: v1alpha3.QualityGateStatusWarn},
+		{Status: v1alpha3.QualityGateStatusNone},
+		{Status: "UNKNOWN"},
+	}))
+}
@@ -0,0 +1,151 @@
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/utils/sliceutil"
+)
+
+// maxBackoffShift limits the growth of retry backoff, so that the backoff never overflows.
+const maxBackoffShift = 16
+
+// defaultRetryResults are the Jenkins results which need a retry when the retry policy doesn't specify any result.
+var defaultRetryResults = []string{Failure.String()}
+
+// getAttempt returns the attempt number of the PipelineRun in its retry lineage.
+func getAttempt(pr *v1alpha3.PipelineRun) int {
+	if attempt, err := strconv.Atoi(pr.Labels[v1alpha3.PipelineRunAttemptLabelKey]); err == nil && attempt > 0 {
+		return attempt
+	}
+	return 1
+}
+
+// getRetryOf returns the name of the first PipelineRun in the retry lineage of the PipelineRun.
+func getRetryOf(pr *v1alpha3.PipelineRun) string {
+	if retryOf := pr.Labels[v1alpha3.PipelineRunRetryOfLabelKey]; retryOf != "" {
+		return retryOf
+	}
+	return pr.Name
+}
+
+// getRetryAfter returns the time before which the PipelineRun won't be triggered.
+func getRetryAfter(pr *v1alpha3.PipelineRun) (retryAfter time.Time, ok bool) {
+	value, exist := pr.Annotations[v1alpha3.PipelineRunRetryAfterAnnoKey]
+	if !exist {
+		return
+	}
+	var err error
+	if retryAfter, err = time.Parse(time.RFC3339, value); err != nil {
+		return
+	}
+	return retryAfter, true
+}
+
+// needRetry indicates if the finished PipelineRun needs a retry according to the retry policy and the Jenkins result.
+func needRetry(pr *v1alpha3.PipelineRun, status *v1alpha3.PipelineRunStatus, result string) bool {
+	if pr.Spec.PipelineSpec == nil || pr.Spec.PipelineSpec.Retry == nil || status.Phase != v1alpha3.Failed {
+		return false
+	}
+	policy := pr.Spec.PipelineSpec.Retry
+	if getAttempt(pr) >= policy.MaxAttempts {
+		return false
+	}
+	// never retry a PipelineRun which was stopped on purpose
+	if condition := v1alpha3.FindCondition(status.Conditions, v1alpha3.ConditionStopped); condition != nil &&
+		condition.Status == v1alpha3.ConditionTrue {
+		return false
+	}
+	// the failed quality gate won't pass in a retry, since the retry builds the same code. The PipelineRun which
+	// timed out was aborted by us rather than failed, no matter what result Jenkins reports for it.
+	if condition := v1alpha3.FindCondition(status.Conditions, v1alpha3.ConditionSucceeded); condition != nil &&
+		(condition.Reason == v1alpha3.QualityGateFailed || condition.Reason == v1alpha3.Timeout) {
+		return false
+	}
+	results := policy.Results
+	if len(results) == 0 {
+		results = defaultRetryResults
+	}
+	return sliceutil.HasString(results, result)
+}
+
+// retryBackoff returns the duration to wait before triggering the given attempt. The backoff doubles with each retry.
+func retryBackoff(policy *v1alpha3.RetryPolicy, attempt int) time.Duration {
+	if policy.Backoff == nil || policy.Backoff.Duration <= 0 || attempt < 2 {
+		return 0
+	}
+	// the second attempt is the first retry
+	shift := attempt - 2
+	if shift > maxBackoffShift {
+		shift = maxBackoffShift
+	}
+	return policy.Backoff.Duration << uint(shift)
+}
+
+// newRetryPipelineRun creates a PipelineRun which retries the given PipelineRun. The name of the created PipelineRun
+// is determined by the retry lineage, so that we never create duplicated retries.
+func newRetryPipelineRun(pr *v1alpha3.PipelineRun, now time.Time) *v1alpha3.PipelineRun {
+	attempt := getAttempt(pr) + 1
+	retryOf := getRetryOf(pr)
+
+	labels := map[string]string{
+		v1alpha3.PipelineRunRetryOfLabelKey: retryOf,
+		v1alpha3.PipelineRunAttemptLabelKey: strconv.Itoa(attempt),
+	}
+	for _, key := range []string{v1alpha3.PipelineNameLabelKey, v1alpha3.SCMRefNameLabelKey} {
+		if value, ok := pr.Labels[key]; ok {
+			labels[key] = value
+		}
+	}
+	annotations := map[string]string{}
+	if backoff := retryBackoff(pr.Spec.PipelineSpec.Retry, attempt); backoff > 0 {
+		annotations[v1alpha3.PipelineRunRetryAfterAnnoKey] = now.Add(backoff).UTC().Format(time.RFC3339)
+	}
+
+	spec := pr.Spec.DeepCopy()
+	spec.Action = nil
+	return &v1alpha3.PipelineRun{
+		ObjectMeta: v1.ObjectMeta{
+			Name:            fmt.Sprintf("%s-attempt-%d", retryOf, attempt),
+			Namespace:       pr.Namespace,
+			OwnerReferences: pr.OwnerReferences,
+			Labels:          labels,
+			Annotations:     annotations,
+		},
+		Spec: *spec,
+	}
+}
+
+// retry creates a PipelineRun to retry the finished PipelineRun if needed, then records the retry lineage into status.
+func (r *Reconciler) retry(ctx context.Context, pr *v1alpha3.PipelineRun, status *v1alpha3.PipelineRunStatus, result string) error {
+	if !needRetry(pr, status, result) {
+		return nil
+	}
+	retryPR := newRetryPipelineRun(pr, time.Now())
+	if err := r.Create(ctx, retryPR); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	// label the first PipelineRun as well, so that the whole retry lineage can be selected by label
+	if _, ok := pr.Labels[v1alpha3.PipelineRunRetryOfLabelKey]; !ok {
+		pr.Labels[v1alpha3.PipelineRunRetryOfLabelKey] = pr.Name
+		pr.Labels[v1alpha3.PipelineRunAttemptLabelKey] = "1"
+		if err := r.updateLabelsAndAnnotations(ctx, pr); err != nil {
+			return err
+		}
+	}
+	status.Retry = &v1alpha3.RetryStatus{
+		Attempt:   getAttempt(pr),
+		RetryOf:   getRetryOf(pr),
+		RetriedBy: retryPR.Name,
+	}
+	r.recorder.Eventf(pr, corev1.EventTypeNormal, v1alpha3.Retried, "Created PipelineRun %s to retry, attempt %d",
+		retryPR.Name, getAttempt(retryPR))
+	return nil
+}
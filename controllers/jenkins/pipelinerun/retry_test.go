@@ -0,0 +1,238 @@
+package pipelinerun
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newRetryablePipelineRun(name string, attempt string, policy *v1alpha3.RetryPolicy) *v1alpha3.PipelineRun {
+	pr := &v1alpha3.PipelineRun{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace: "ns",
+			Name:      name,
+			Labels: map[string]string{
+				v1alpha3.PipelineNameLabelKey: "pipeline",
+			},
+		},
+		Spec: v1alpha3.PipelineRunSpec{
+			PipelineSpec: &v1alpha3.PipelineSpec{
+				Type:  v1alpha3.NoScmPipelineType,
+				Retry: policy,
+			},
+			Action: actionPtr(v1alpha3.Stop),
+		},
+	}
+	if attempt != "" {
+		pr.Labels[v1alpha3.PipelineRunRetryOfLabelKey] = "pipeline-abc"
+		pr.Labels[v1alpha3.PipelineRunAttemptLabelKey] = attempt
+	}
+	return pr
+}
+
+func actionPtr(action v1alpha3.Action) *v1alpha3.Action {
+	return &action
+}
+
+func Test_needRetry(t *testing.T) {
+	policy := &v1alpha3.RetryPolicy{MaxAttempts: 3}
+	failedStatus := &v1alpha3.PipelineRunStatus{Phase: v1alpha3.Failed}
+	tests := []struct {
+		name   string
+		pr     *v1alpha3.PipelineRun
+		status *v1alpha3.PipelineRunStatus
+		result string
+		want   bool
+	}{{
+		name:   "Without retry policy",
+		pr:     newRetryablePipelineRun("pipeline-abc", "", nil),
+		status: failedStatus,
+		result: Failure.String(),
+		want:   false,
+	}, {
+		name:   "Succeeded PipelineRun",
+		pr:     newRetryablePipelineRun("pipeline-abc", "", policy),
+		status: &v1alpha3.PipelineRunStatus{Phase: v1alpha3.Succeeded},
+		result: Success.String(),
+		want:   false,
+	}, {
+		name:   "Failed PipelineRun",
+		pr:     newRetryablePipelineRun("pipeline-abc", "", policy),
+		status: failedStatus,
+		result: Failure.String(),
+		want:   true,
+	}, {
+		name:   "Aborted PipelineRun is not retried by default",
+		pr:     newRetryablePipelineRun("pipeline-abc", "", policy),
+		status: failedStatus,
+		result: Aborted.String(),
+		want:   false,
+	}, {
+		name: "Unstable PipelineRun with specified results",
+		pr: newRetryablePipelineRun("pipeline-abc", "", &v1alpha3.RetryPolicy{
+			MaxAttempts: 3,
+			Results:     []string{Failure.String(), Unstable.String()},
+		}),
+		status: failedStatus,
+		result: Unstable.String(),
+		want:   true,
+	}, {
+		name:   "Reached max attempts",
+		pr:     newRetryablePipelineRun("pipeline-abc-attempt-3", "3", policy),
+		status: failedStatus,
+		result: Failure.String(),
+		want:   false,
+	}, {
+		name: "Stopped PipelineRun",
+		pr:   newRetryablePipelineRun("pipeline-abc", "", policy),
+		status: &v1alpha3.PipelineRunStatus{
+			Phase: v1alpha3.Failed,
+			// the conditions refreshed from Jenkins never overwrite the Stopped condition
+			Conditions: []v1alpha3.Condition{{
+				Type:   v1alpha3.ConditionSucceeded,
+				Status: v1alpha3.ConditionFalse,
+				Reason: Finished.String(),
+			}, {
+				Type:   v1alpha3.ConditionStopped,
+				Status: v1alpha3.ConditionTrue,
+				Reason: v1alpha3.Stopped,
+			}},
+		},
+		result: Failure.String(),
+		want:   false,
+	}, {
+		name: "Stopped PipelineRun with aborted result in retry policy",
+		pr: newRetryablePipelineRun("pipeline-abc", "", &v1alpha3.RetryPolicy{
+			MaxAttempts: 3,
+			Results:     []string{Failure.String(), Aborted.String()},
+		}),
+		status: &v1alpha3.PipelineRunStatus{
+			Phase: v1alpha3.Failed,
+			Conditions: []v1alpha3.Condition{{
+				Type:   v1alpha3.ConditionStopped,
+				Status: v1alpha3.ConditionTrue,
+				Reason: v1alpha3.Stopped,
+			}},
+		},
+		result: Aborted.String(),
+		want:   false,
+	}, {
+		name: "Failed quality gate",
+		pr: newRetryablePipelineRun("pipeline-abc", "", &v1alpha3.RetryPolicy{
+			MaxAttempts: 3,
+			Results:     []string{Failure.String(), Unstable.String()},
+		}),
+		status: &v1alpha3.PipelineRunStatus{
+			Phase: v1alpha3.Failed,
+			Conditions: []v1alpha3.Condition{{
+				Type:   v1alpha3.ConditionSucceeded,
+				Status: v1alpha3.ConditionFalse,
+				Reason: v1alpha3.QualityGateFailed,
+			}},
+		},
+		result: Unstable.String(),
+		want:   false,
+	}, {
+		name: "Timed out PipelineRun with aborted result in retry policy",
+		pr: newRetryablePipelineRun("pipeline-abc", "", &v1alpha3.RetryPolicy{
+			MaxAttempts: 3,
+			Results:     []string{Failure.String(), Aborted.String()},
+		}),
+		status: &v1alpha3.PipelineRunStatus{
+			Phase: v1alpha3.Failed,
+			Conditions: []v1alpha3.Condition{{
+				Type:   v1alpha3.ConditionSucceeded,
+				Status: v1alpha3.ConditionFalse,
+				Reason: v1alpha3.Timeout,
+			}},
+		},
+		result: Aborted.String(),
+		want:   false,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, needRetry(tt.pr, tt.status, tt.result))
+		})
+	}
+}
+
+func Test_retryBackoff(t *testing.T) {
+	assert.Equal(t, time.Duration(0), retryBackoff(&v1alpha3.RetryPolicy{}, 2))
+
+	policy := &v1alpha3.RetryPolicy{Backoff: &v1.Duration{Duration: time.Minute}}
+	assert.Equal(t, time.Duration(0), retryBackoff(policy, 1))
+	assert.Equal(t, time.Minute, retryBackoff(policy, 2))
+	assert.Equal(t, 2*time.Minute, retryBackoff(policy, 3))
+	assert.Equal(t, 4*time.Minute, retryBackoff(policy, 4))
+	assert.Equal(t, time.Minute<<maxBackoffShift, retryBackoff(policy, 100))
+}
+
+func Test_newRetryPipelineRun(t *testing.T) {
+	now := time.Date(2021, 8, 12, 8, 0, 0, 0, time.UTC)
+	policy := &v1alpha3.RetryPolicy{MaxAttempts: 3, Backoff: &v1.Duration{Duration: time.Minute}}
+
+	retryPR := newRetryPipelineRun(newRetryablePipelineRun("pipeline-abc", "", policy), now)
+	assert.Equal(t, "pipeline-abc-attempt-2", retryPR.Name)
+	assert.Equal(t, "ns", retryPR.Namespace)
+	assert.Equal(t, map[string]string{
+		v1alpha3.PipelineNameLabelKey:       "pipeline",
+		v1alpha3.PipelineRunRetryOfLabelKey: "pipeline-abc",
+		v1alpha3.PipelineRunAttemptLabelKey: "2",
+	}, retryPR.Labels)
+	assert.Equal(t, "2021-08-12T08:01:00Z", retryPR.Annotations[v1alpha3.PipelineRunRetryAfterAnnoKey])
+	assert.Nil(t, retryPR.Spec.Action)
+	retryAfter, ok := getRetryAfter(retryPR)
+	assert.True(t, ok)
+	assert.True(t, now.Add(time.Minute).Equal(retryAfter))
+
+	retryPR = newRetryPipelineRun(retryPR, now)
+	assert.Equal(t, "pipeline-abc-attempt-3", retryPR.Name)
+	assert.Equal(t, "3", retryPR.Labels[v1alpha3.PipelineRunAttemptLabelKey])
+	assert.Equal(t, "2021-08-12T08:02:00Z", retryPR.Annotations[v1alpha3.PipelineRunRetryAfterAnnoKey])
+}
+
+func TestReconciler_retry(t *testing.T) {
+	sch := runtime.NewScheme()
+	if err := v1alpha3.AddToScheme(sch); err != nil {
+		t.Fatalf("unable to add v1alpha3 into scheme, err = %v", err)
+	}
+	pr := newRetryablePipelineRun("pipeline-abc", "", &v1alpha3.RetryPolicy{MaxAttempts: 2})
+	r := &Reconciler{
+		Client:   fake.NewFakeClientWithScheme(sch, pr.DeepCopy()),
+		recorder: record.NewFakeRecorder(10),
+	}
+	status := &v1alpha3.PipelineRunStatus{Phase: v1alpha3.Failed}
+
+	assert.Nil(t, r.retry(context.Background(), pr, status, Failure.String()))
+	assert.Equal(t, &v1alpha3.RetryStatus{
+		Attempt:   1,
+		RetryOf:   "pipeline-abc",
+		RetriedBy: "pipeline-abc-attempt-2",
+	}, status.Retry)
+
+	// the first PipelineRun should be labeled
+	firstPR := &v1alpha3.PipelineRun{}
+	assert.Nil(t, r.Get(context.Background(), client.ObjectKey{Namespace: "ns", Name: "pipeline-abc"}, firstPR))
+	assert.Equal(t, "pipeline-abc", firstPR.Labels[v1alpha3.PipelineRunRetryOfLabelKey])
+	assert.Equal(t, "1", firstPR.Labels[v1alpha3.PipelineRunAttemptLabelKey])
+
+	retryPR := &v1alpha3.PipelineRun{}
+	assert.Nil(t, r.Get(context.Background(), client.ObjectKey{Namespace: "ns", Name: "pipeline-abc-attempt-2"}, retryPR))
+	assert.Equal(t, "2", retryPR.Labels[v1alpha3.PipelineRunAttemptLabelKey])
+
+	// retrying twice should not fail
+	assert.Nil(t, r.retry(context.Background(), pr, status, Failure.String()))
+
+	// the retry PipelineRun reached the max attempts
+	status = &v1alpha3.PipelineRunStatus{Phase: v1alpha3.Failed}
+	assert.Nil(t, r.retry(context.Background(), retryPR, status, Failure.String()))
+	assert.Nil(t, status.Retry)
+}
@@ -0,0 +1,153 @@
+package pipelinerun
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+)
+
+// blueOceanTimeLayout is the layout of time in the Blue Ocean REST API.
+const blueOceanTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+// parseBlueOceanTime parses the time from the Blue Ocean REST API. It returns nil if the time is empty or invalid.
+func parseBlueOceanTime(value string) *v1.Time {
+	if value == "" {
+		return nil
+	}
+	t, err := time.Parse(blueOceanTimeLayout, value)
+	if err != nil {
+		return nil
+	}
+	return &v1.Time{Time: t}
+}
+
+// isStageFinished indicates if steps of the stage will not change any more.
+func isStageFinished(stage *v1alpha3.StageStatus) bool {
+	switch stage.State {
+	case Finished.String(), Skipped.String(), NotBuiltState.String():
+		return true
+	}
+	return false
+}
+
+// getStages retrieves the status of all stages and their steps from Jenkins. Steps of stages which have finished
+// before are taken from the previous stages, so that we don't request Jenkins for them again. The nodes of Blue Ocean
+// which the stages come from are returned as well.
+func (r *Reconciler) getStages(run *jenkinsRun, previous []v1alpha3.StageStatus) ([]v1alpha3.StageStatus, []interface{}, error) {
+	stages, nodes, err := r.getStagesWithoutSteps(run)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	finishedStages := make(map[string]*v1alpha3.StageStatus)
+	for i := range previous {
+		if isStageFinished(&previous[i]) {
+			finishedStages[previous[i].ID] = &previous[i]
+		}
+	}
+
+	for i := range stages {
+		stage := &stages[i]
+		if previousStage, ok := finishedStages[stage.ID]; ok && isStageFinished(stage) {
+			stage.Steps = previousStage.Steps
+			continue
+		}
+		if stage.State == "" {
+			// the stage has not started yet
+			continue
+		}
+		if stage.Steps, err = r.getSteps(run, stage.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+	return stages, nodes, nil
+}
+
+func (r *Reconciler) getStagesWithoutSteps(run *jenkinsRun) ([]v1alpha3.StageStatus, []interface{}, error) {
+	var stages []v1alpha3.StageStatus
+	var nodes []interface{}
+	httpParameters, _ := newHTTPParameters(http.MethodGet, url.Values{}, nil)
+	if run.branch != "" {
+		branchNodes, err := r.DevOpsClient.GetBranchPipelineRunNodes(run.projectName, run.pipelineName, run.branch, run.runID, httpParameters)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range branchNodes {
+			node := &branchNodes[i]
+			stages = append(stages, v1alpha3.StageStatus{
+				ID:               node.ID,
+				Name:             node.DisplayName,
+				State:            node.State,
+				Result:           node.Result,
+				StartTime:        parseBlueOceanTime(node.StartTime),
+				DurationInMillis: int64(node.DurationInMillis),
+			})
+			nodes = append(nodes, node)
+		}
+	} else {
+		runNodes, err := r.DevOpsClient.GetPipelineRunNodes(run.projectName, run.pipelineName, run.runID, httpParameters)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range runNodes {
+			node := &runNodes[i]
+			stages = append(stages, v1alpha3.StageStatus{
+				ID:               node.ID,
+				Name:             node.DisplayName,
+				State:            node.State,
+				Result:           node.Result,
+				StartTime:        parseBlueOceanTime(node.StartTime),
+				DurationInMillis: int64(node.DurationInMillis),
+			})
+			nodes = append(nodes, node)
+		}
+	}
+	return stages, nodes, nil
+}
+
+func (r *Reconciler) getSteps(run *jenkinsRun, nodeID string) ([]v1alpha3.StepStatus, error) {
+	var (
+		steps []devops.NodeSteps
+		err   error
+	)
+	httpParameters, _ := newHTTPParameters(http.MethodGet, url.Values{}, nil)
+	if run.branch != "" {
+		steps, err = r.DevOpsClient.GetBranchNodeSteps(run.projectName, run.pipelineName, run.branch, run.runID, nodeID, httpParameters)
+	} else {
+		steps, err = r.DevOpsClient.GetNodeSteps(run.projectName, run.pipelineName, run.runID, nodeID, httpParameters)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stepStatuses := make([]v1alpha3.StepStatus, 0, len(steps))
+	for i := range steps {
+		stepStatuses = append(stepStatuses, newStepStatus(&steps[i]))
+	}
+	return stepStatuses, nil
+}
+
+func newStepStatus(step *devops.NodeSteps) v1alpha3.StepStatus {
+	stepStatus := v1alpha3.StepStatus{
+		ID:               step.ID,
+		Name:             step.DisplayName,
+		Description:      step.DisplayDescription,
+		State:            step.State,
+		Result:           step.Result,
+		StartTime:        parseBlueOceanTime(step.StartTime),
+		DurationInMillis: int64(step.DurationInMillis),
+	}
+	// mark the step as input-pending
+	if step.State == devops.StatePaused && step.Input != nil {
+		stepStatus.Input = &v1alpha3.InputStatus{
+			ID:         step.Input.ID,
+			Message:    step.Input.Message,
+			Submitters: step.Input.GetSubmitters(),
+		}
+	}
+	return stepStatus
+}
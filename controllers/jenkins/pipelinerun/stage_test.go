@@ -0,0 +1,116 @@
+package pipelinerun
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/job"
+	"github.com/stretchr/testify/assert"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/devops/fake"
+)
+
+func Test_parseBlueOceanTime(t *testing.T) {
+	assert.Nil(t, parseBlueOceanTime(""))
+	assert.Nil(t, parseBlueOceanTime("invalid"))
+
+	parsedTime := parseBlueOceanTime("2021-08-12T08:19:10.535+0000")
+	if assert.NotNil(t, parsedTime) {
+		assert.True(t, parsedTime.Equal(time.Date(2021, 8, 12, 8, 19, 10, 535000000, time.UTC)))
+	}
+}
+
+func TestReconciler_getStages(t *testing.T) {
+	devopsClient := fake.New("project1")
+	devopsClient.Data = map[string]interface{}{
+		"project1-pipeline1-1": []devops.PipelineRunNodes{{
+			ID:               "1",
+			DisplayName:      "Build",
+			State:            "FINISHED",
+			Result:           "SUCCESS",
+			StartTime:        "2021-08-12T08:19:10.535+0000",
+			DurationInMillis: 1000,
+		}, {
+			ID:          "2",
+			DisplayName: "Deploy",
+			State:       devops.StatePaused,
+		}, {
+			ID:          "3",
+			DisplayName: "Clean",
+		}},
+		"project1-pipeline1-1-1": []devops.NodeSteps{{
+			ID:                 "4",
+			DisplayName:        "Shell Script",
+			DisplayDescription: "make build",
+			State:              "FINISHED",
+			Result:             "SUCCESS",
+		}},
+		"project1-pipeline1-1-2": []devops.NodeSteps{{
+			ID:    "5",
+			State: devops.StatePaused,
+			Input: &devops.Input{
+				ID:        "input-id",
+				Message:   "Deploy to production?",
+				Submitter: "admin, tester",
+			},
+		}},
+	}
+	r := &Reconciler{DevOpsClient: devopsClient}
+	run := &jenkinsRun{
+		projectName:  "project1",
+		pipelineName: "pipeline1",
+		runID:        "1",
+	}
+
+	stages, nodes, err := r.getStages(run, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(stages))
+	// the nodes are kept for the deprecated annotation of stages status
+	assert.Equal(t, 3, len(nodes))
+
+	assert.Equal(t, "Build", stages[0].Name)
+	assert.Equal(t, "SUCCESS", stages[0].Result)
+	assert.Equal(t, int64(1000), stages[0].DurationInMillis)
+	assert.NotNil(t, stages[0].StartTime)
+	assert.Equal(t, []v1alpha3.StepStatus{{
+		ID:          "4",
+		Name:        "Shell Script",
+		Description: "make build",
+		State:       "FINISHED",
+		Result:      "SUCCESS",
+	}}, stages[0].Steps)
+
+	assert.Equal(t, 1, len(stages[1].Steps))
+	assert.Equal(t, &v1alpha3.InputStatus{
+		ID:         "input-id",
+		Message:    "Deploy to production?",
+		Submitters: []string{"admin", "tester"},
+	}, stages[1].Steps[0].Input)
+
+	// steps of the stage which has not started yet should not be retrieved
+	assert.Nil(t, stages[2].Steps)
+
+	// steps of finished stages should be taken from the previous stages
+	delete(devopsClient.Data, "project1-pipeline1-1-1")
+	stages, _, err = r.getStages(run, stages)
+	assert.Nil(t, err)
+	assert.Equal(t, "4", stages[0].Steps[0].ID)
+}
+
+func Test_pipelineBuildApplier_applyStages(t *testing.T) {
+	stages := []v1alpha3.StageStatus{{
+		ID:    "1",
+		State: Running.String(),
+	}}
+	prStatus := &v1alpha3.PipelineRunStatus{}
+	pbApplier := &pipelineBuildApplier{
+		PipelineRun: &job.PipelineRun{
+			ID:    "1",
+			State: Running.String(),
+		},
+		stages: stages,
+	}
+	pbApplier.apply(prStatus)
+	assert.Equal(t, stages, prStatus.Stages)
+}
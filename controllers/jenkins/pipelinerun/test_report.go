@@ -0,0 +1,74 @@
+package pipelinerun
+
+import (
+	"net/http"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/server/errors"
+)
+
+const (
+	// maxFailedTestCases is the max number of failed test cases recorded in the status of PipelineRun.
+	maxFailedTestCases = 100
+	// testReportWaitTimeout is how long we keep trying to collect the test results after the PipelineRun completed.
+	testReportWaitTimeout = 10 * time.Minute
+)
+
+// waitingForTestReport indicates if the test results of the completed PipelineRun might still be collected. A
+// PipelineRun without any test result has an empty test report, so it doesn't wait.
+func waitingForTestReport(status *v1alpha3.PipelineRunStatus) bool {
+	return status.CompletionTime != nil && status.TestReport == nil &&
+		time.Since(status.CompletionTime.Time) < testReportWaitTimeout
+}
+
+// collectTestReport collects the test results of the completed PipelineRun from the test report of Jenkins. The test
+// report is empty if the Jenkins run has no test result.
+func (r *Reconciler) collectTestReport(run *jenkinsRun) (*v1alpha3.TestReport, error) {
+	var report *devops.TestReport
+	var err error
+	if run.branch != "" {
+		report, err = r.DevOpsClient.GetMultiBranchPipelineBuildTestReport(run.projectName, run.pipelineName, run.branch, run.runID)
+	} else {
+		report, err = r.DevOpsClient.GetProjectPipelineBuildTestReport(run.projectName, run.pipelineName, run.runID)
+	}
+	if err != nil {
+		if errors.GetServiceErrorCode(err) == http.StatusNotFound {
+			return &v1alpha3.TestReport{CollectTime: &v1.Time{Time: time.Now()}}, nil
+		}
+		return nil, err
+	}
+	return summarizeTestReport(report), nil
+}
+
+// summarizeTestReport counts the test cases of the test report, and records the failed ones.
+func summarizeTestReport(report *devops.TestReport) *v1alpha3.TestReport {
+	testReport := &v1alpha3.TestReport{
+		Total:       report.PassCount + report.FailCount + report.SkipCount,
+		Passed:      report.PassCount,
+		Failed:      report.FailCount,
+		Skipped:     report.SkipCount,
+		CollectTime: &v1.Time{Time: time.Now()},
+	}
+	for _, suite := range report.Suites {
+		for i := range suite.Cases {
+			if len(testReport.FailedCases) >= maxFailedTestCases {
+				return testReport
+			}
+			if suite.Cases[i].Failed() {
+				testReport.FailedCases = append(testReport.FailedCases, getTestCaseName(&suite.Cases[i]))
+			}
+		}
+	}
+	return testReport
+}
+
+// getTestCaseName returns the full name of the test case, which is the same as the one in the JUnit plugin of Jenkins.
+func getTestCaseName(testCase *devops.TestCase) string {
+	if testCase.ClassName == "" {
+		return testCase.Name
+	}
+	return testCase.ClassName + "." + testCase.Name
+}
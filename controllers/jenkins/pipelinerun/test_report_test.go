@@ -0,0 +1,91 @@
+package pipelinerun
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/devops/fake"
+)
+
+func TestReconciler_collectTestReport(t *testing.T) {
+	devopsClient := fake.New("project1")
+	devopsClient.TestReports = map[string]*devops.TestReport{
+		"project1/pipeline1/1": {
+			FailCount: 2,
+			PassCount: 3,
+			SkipCount: 1,
+			Suites: []devops.TestSuite{{
+				Name: "io.kubesphere.FooTest",
+				Cases: []devops.TestCase{
+					{ClassName: "io.kubesphere.FooTest", Name: "testA", Status: devops.TestCasePassed},
+					{ClassName: "io.kubesphere.FooTest", Name: "testB", Status: devops.TestCaseRegression},
+					{ClassName: "io.kubesphere.FooTest", Name: "testC", Status: devops.TestCaseSkipped},
+				},
+			}, {
+				Name: "go test",
+				Cases: []devops.TestCase{
+					{Name: "TestFoo", Status: devops.TestCaseFixed},
+					{Name: "TestBar", Status: devops.TestCaseFailed},
+					{Name: "TestBaz", Status: devops.TestCasePassed},
+				},
+			}},
+		},
+	}
+	r := &Reconciler{DevOpsClient: devopsClient}
+
+	testReport, err := r.collectTestReport(&jenkinsRun{projectName: "project1", pipelineName: "pipeline1", runID: "1"})
+	if !assert.Nil(t, err) || !assert.NotNil(t, testReport) {
+		return
+	}
+	assert.Equal(t, 6, testReport.Total)
+	assert.Equal(t, 3, testReport.Passed)
+	assert.Equal(t, 2, testReport.Failed)
+	assert.Equal(t, 1, testReport.Skipped)
+	assert.Equal(t, []string{"io.kubesphere.FooTest.testB", "TestBar"}, testReport.FailedCases)
+	assert.True(t, testReport.Complete())
+	assert.NotNil(t, testReport.CollectTime)
+
+	// there is no test result in the run, the empty test report stops collecting it again
+	testReport, err = r.collectTestReport(&jenkinsRun{projectName: "project1", pipelineName: "pipeline1", branch: "main", runID: "1"})
+	if !assert.Nil(t, err) || !assert.NotNil(t, testReport) {
+		return
+	}
+	assert.Equal(t, 0, testReport.Total)
+	assert.Empty(t, testReport.FailedCases)
+	assert.NotNil(t, testReport.CollectTime)
+	assert.False(t, waitingForTestReport(&v1alpha3.PipelineRunStatus{
+		CompletionTime: &v1.Time{Time: time.Now()},
+		TestReport:     testReport,
+	}))
+}
+
+func Test_summarizeTestReport(t *testing.T) {
+	report := &devops.TestReport{FailCount: maxFailedTestCases + 10}
+	suite := devops.TestSuite{}
+	for i := 0; i < report.FailCount; i++ {
+		suite.Cases = append(suite.Cases, devops.TestCase{Name: fmt.Sprintf("Test%d", i), Status: devops.TestCaseFailed})
+	}
+	report.Suites = append(report.Suites, suite)
+
+	testReport := summarizeTestReport(report)
+	assert.Equal(t, maxFailedTestCases, len(testReport.FailedCases))
+	assert.False(t, testReport.Complete())
+}
+
+func Test_waitingForTestReport(t *testing.T) {
+	completedAgo := func(d time.Duration) *v1alpha3.PipelineRunStatus {
+		return &v1alpha3.PipelineRunStatus{CompletionTime: &v1.Time{Time: time.Now().Add(-d)}}
+	}
+	assert.False(t, waitingForTestReport(&v1alpha3.PipelineRunStatus{}))
+	assert.True(t, waitingForTestReport(completedAgo(time.Minute)))
+	assert.False(t, waitingForTestReport(completedAgo(testReportWaitTimeout+time.Minute)))
+
+	collected := completedAgo(time.Minute)
+	collected.TestReport = &v1alpha3.TestReport{}
+	assert.False(t, waitingForTestReport(collected))
+}
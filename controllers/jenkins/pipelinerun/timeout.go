@@ -0,0 +1,73 @@
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// getRemainingTime returns the remaining time before the PipelineRun times out. The second return value is false if
+// the PipelineRun never times out, or it has already completed.
+func getRemainingTime(pr *v1alpha3.PipelineRun, now time.Time) (time.Duration, bool) {
+	timeout := pr.Spec.GetTimeout()
+	if timeout <= 0 || pr.Status.StartTime == nil || pr.HasCompleted() {
+		return 0, false
+	}
+	return pr.Status.StartTime.Add(timeout).Sub(now), true
+}
+
+// timeoutPipelineRun aborts the Jenkins run of the PipelineRun, then marks the PipelineRun as failed.
+func (r *Reconciler) timeoutPipelineRun(ctx context.Context, pr *v1alpha3.PipelineRun, projectName, pipelineName string) error {
+	key := client.ObjectKey{Namespace: pr.Namespace, Name: pr.Name}
+	run, err := newJenkinsRun(projectName, pipelineName, pr)
+	if err != nil {
+		return err
+	}
+	if err := r.stop(run); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("The PipelineRun was aborted because it did not complete within %s", pr.Spec.GetTimeout())
+	status := pr.Status.DeepCopy()
+	status.AddCondition(&v1alpha3.Condition{
+		Type:               v1alpha3.ConditionSucceeded,
+		Status:             v1alpha3.ConditionFalse,
+		Reason:             v1alpha3.Timeout,
+		Message:            message,
+		LastProbeTime:      v1.Now(),
+		LastTransitionTime: v1.Now(),
+	})
+	status.Phase = v1alpha3.Failed
+	status.MarkCompleted(time.Now())
+	status.UpdateTime = &v1.Time{Time: time.Now()}
+	if err := r.updateStatus(ctx, status, key); err != nil {
+		return err
+	}
+	r.recorder.Event(pr, corev1.EventTypeWarning, v1alpha3.Timeout, message)
+	if err := r.wakeUpQueuedRuns(ctx, pr); err != nil {
+		r.log.Error(err, "unable to wake up the queued PipelineRuns.")
+	}
+	return nil
+}
+
+// hasTimedOut indicates if the PipelineRun was aborted by us because it ran out of its timeout.
+func hasTimedOut(status *v1alpha3.PipelineRunStatus) bool {
+	condition := v1alpha3.FindCondition(status.Conditions, v1alpha3.ConditionSucceeded)
+	return condition != nil && condition.Reason == v1alpha3.Timeout
+}
+
+// keepTimeout keeps the result of the PipelineRun which timed out after its status is refreshed from Jenkins. Jenkins
+// might still be running or report the run as aborted, but the result was decided once it timed out.
+func keepTimeout(previous, status *v1alpha3.PipelineRunStatus) {
+	if !hasTimedOut(previous) {
+		return
+	}
+	status.AddCondition(v1alpha3.FindCondition(previous.Conditions, v1alpha3.ConditionSucceeded).DeepCopy())
+	status.Phase = v1alpha3.Failed
+	status.CompletionTime = previous.CompletionTime.DeepCopy()
+}
@@ -0,0 +1,229 @@
+package pipelinerun
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-zh/jenkins-client/pkg/core"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/devops/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_getRemainingTime(t *testing.T) {
+	now := time.Now()
+	startTime := v1.NewTime(now.Add(-time.Hour))
+	tests := []struct {
+		name          string
+		pr            *v1alpha3.PipelineRun
+		wantRemaining time.Duration
+		wantOk        bool
+	}{{
+		name: "Without timeout",
+		pr: &v1alpha3.PipelineRun{
+			Status: v1alpha3.PipelineRunStatus{StartTime: &startTime},
+		},
+		wantOk: false,
+	}, {
+		name: "Not started",
+		pr: &v1alpha3.PipelineRun{
+			Spec: v1alpha3.PipelineRunSpec{Timeout: &v1.Duration{Duration: time.Hour}},
+		},
+		wantOk: false,
+	}, {
+		name: "Timeout of PipelineRun",
+		pr: &v1alpha3.PipelineRun{
+			Spec: v1alpha3.PipelineRunSpec{
+				Timeout:      &v1.Duration{Duration: 2 * time.Hour},
+				PipelineSpec: &v1alpha3.PipelineSpec{Timeout: &v1.Duration{Duration: 3 * time.Hour}},
+			},
+			Status: v1alpha3.PipelineRunStatus{StartTime: &startTime},
+		},
+		wantRemaining: time.Hour,
+		wantOk:        true,
+	}, {
+		name: "Timeout inherited from Pipeline",
+		pr: &v1alpha3.PipelineRun{
+			Spec: v1alpha3.PipelineRunSpec{
+				PipelineSpec: &v1alpha3.PipelineSpec{Timeout: &v1.Duration{Duration: 30 * time.Minute}},
+			},
+			Status: v1alpha3.PipelineRunStatus{StartTime: &startTime},
+		},
+		wantRemaining: -30 * time.Minute,
+		wantOk:        true,
+	}, {
+		name: "Completed",
+		pr: &v1alpha3.PipelineRun{
+			Spec: v1alpha3.PipelineRunSpec{Timeout: &v1.Duration{Duration: 30 * time.Minute}},
+			Status: v1alpha3.PipelineRunStatus{
+				StartTime:      &startTime,
+				CompletionTime: &v1.Time{Time: now},
+			},
+		},
+		wantOk: false,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remaining, ok := getRemainingTime(tt.pr, now)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.wantRemaining, remaining)
+		})
+	}
+}
+
+func TestReconciler_timeoutPipelineRun(t *testing.T) {
+	sch := runtime.NewScheme()
+	if err := v1alpha3.AddToScheme(sch); err != nil {
+		t.Fatalf("unable to add v1alpha3 into scheme, err = %v", err)
+	}
+	startTime := v1.NewTime(time.Now().Add(-time.Hour))
+	pr := &v1alpha3.PipelineRun{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace: "project1",
+			Name:      "pipeline1-abc",
+			Annotations: map[string]string{
+				v1alpha3.JenkinsPipelineRunIDKey: "1",
+			},
+		},
+		Spec: v1alpha3.PipelineRunSpec{
+			Timeout: &v1.Duration{Duration: time.Minute},
+		},
+		Status: v1alpha3.PipelineRunStatus{
+			StartTime: &startTime,
+			Phase:     v1alpha3.Running,
+		},
+	}
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		Client:       fakeclient.NewFakeClientWithScheme(sch, pr.DeepCopy()),
+		DevOpsClient: fake.New("project1"),
+		recorder:     recorder,
+	}
+	assert.Nil(t, r.timeoutPipelineRun(context.Background(), pr, "project1", "pipeline1"))
+
+	updatedPR := &v1alpha3.PipelineRun{}
+	assert.Nil(t, r.Get(context.Background(), client.ObjectKey{Namespace: "project1", Name: "pipeline1-abc"}, updatedPR))
+	assert.Equal(t, v1alpha3.Failed, updatedPR.Status.Phase)
+	assert.True(t, updatedPR.HasCompleted())
+	condition := updatedPR.Status.GetLatestCondition()
+	if assert.NotNil(t, condition) {
+		assert.Equal(t, v1alpha3.ConditionSucceeded, condition.Type)
+		assert.Equal(t, v1alpha3.ConditionFalse, condition.Status)
+		assert.Equal(t, v1alpha3.Timeout, condition.Reason)
+	}
+	assert.Equal(t, 1, len(recorder.Events))
+}
+
+// jenkinsStub answers the requests to Jenkins with the given build.
+type jenkinsStub struct {
+	build string
+}
+
+func (s jenkinsStub) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := s.build
+	if strings.Contains(req.URL.Path, "crumbIssuer") {
+		body = `{"crumbRequestField":"CrumbRequestField","crumb":"Crumb"}`
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Request:    req,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+	}, nil
+}
+
+func TestReconciler_ReconcileAfterTimeout(t *testing.T) {
+	sch := runtime.NewScheme()
+	if err := v1alpha3.AddToScheme(sch); err != nil {
+		t.Fatalf("unable to add v1alpha3 into scheme, err = %v", err)
+	}
+	tests := []struct {
+		name  string
+		build string
+	}{{
+		name:  "Jenkins has not aborted the run yet",
+		build: `{"id":"1","state":"RUNNING","result":"UNKNOWN"}`,
+	}, {
+		name:  "Jenkins aborted the run",
+		build: `{"id":"1","state":"FINISHED","result":"ABORTED"}`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			startTime := v1.NewTime(time.Now().Add(-time.Hour))
+			pipelineSpec := v1alpha3.PipelineSpec{
+				Type:    v1alpha3.NoScmPipelineType,
+				Timeout: &v1.Duration{Duration: time.Minute},
+				Retry:   &v1alpha3.RetryPolicy{MaxAttempts: 3, Results: []string{Aborted.String()}},
+			}
+			pipeline := &v1alpha3.Pipeline{
+				ObjectMeta: v1.ObjectMeta{Namespace: "project1", Name: "pipeline1"},
+				Spec:       pipelineSpec,
+			}
+			pr := &v1alpha3.PipelineRun{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace:   "project1",
+					Name:        "pipeline1-abc",
+					Labels:      map[string]string{v1alpha3.PipelineNameLabelKey: "pipeline1"},
+					Annotations: map[string]string{v1alpha3.JenkinsPipelineRunIDKey: "1"},
+					Finalizers:  []string{v1alpha3.PipelineRunFinalizerName},
+				},
+				Spec: v1alpha3.PipelineRunSpec{
+					PipelineRef:  &corev1.ObjectReference{Namespace: "project1", Name: "pipeline1"},
+					PipelineSpec: &pipelineSpec,
+				},
+				Status: v1alpha3.PipelineRunStatus{
+					StartTime: &startTime,
+					Phase:     v1alpha3.Running,
+				},
+			}
+			devopsClient := fake.New("project1")
+			devopsClient.Data = map[string]interface{}{"project1-pipeline1-1": []devops.PipelineRunNodes{}}
+			r := &Reconciler{
+				Client:       fakeclient.NewFakeClientWithScheme(sch, pipeline, pr.DeepCopy()),
+				DevOpsClient: devopsClient,
+				JenkinsCore:  core.JenkinsCore{URL: "http://localhost", RoundTripper: jenkinsStub{build: tt.build}},
+				log:          ctrl.Log.WithName("pipelinerun-controller"),
+				recorder:     record.NewFakeRecorder(100),
+			}
+			key := types.NamespacedName{Namespace: "project1", Name: "pipeline1-abc"}
+
+			// the first reconcile aborts the PipelineRun
+			_, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+			assert.Nil(t, err)
+			timedOutPR := &v1alpha3.PipelineRun{}
+			assert.Nil(t, r.Get(context.Background(), key, timedOutPR))
+			assert.True(t, hasTimedOut(&timedOutPR.Status))
+			assert.Equal(t, []string{"project1/pipeline1/1"}, devopsClient.StoppedRuns)
+
+			// reconcile once more, e.g. woken up by a Jenkins event, then the result should not change
+			_, err = r.Reconcile(ctrl.Request{NamespacedName: key})
+			assert.Nil(t, err)
+			updatedPR := &v1alpha3.PipelineRun{}
+			assert.Nil(t, r.Get(context.Background(), key, updatedPR))
+			assert.Equal(t, v1alpha3.Failed, updatedPR.Status.Phase)
+			assert.True(t, hasTimedOut(&updatedPR.Status))
+			assert.True(t, timedOutPR.Status.CompletionTime.Equal(updatedPR.Status.CompletionTime))
+			assert.Nil(t, updatedPR.Status.Retry)
+
+			// the PipelineRun which timed out is never retried
+			prs := &v1alpha3.PipelineRunList{}
+			assert.Nil(t, r.List(context.Background(), prs, client.InNamespace("project1")))
+			assert.Equal(t, 1, len(prs.Items))
+		})
+	}
+}
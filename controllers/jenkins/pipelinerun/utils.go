@@ -38,9 +38,10 @@ func (result JenkinsRunResult) String() string {
 	return string(result)
 }
 
-// pipelineBuildApplier applies PipelineBuilder to PipelineRunStatus.
+// pipelineBuildApplier applies PipelineBuilder and its stages to PipelineRunStatus.
 type pipelineBuildApplier struct {
 	*job.PipelineRun
+	stages []v1alpha3.StageStatus
 }
 
 func (pbApplier pipelineBuildApplier) apply(prStatus *v1alpha3.PipelineRunStatus) {
@@ -74,6 +75,7 @@ func (pbApplier pipelineBuildApplier) apply(prStatus *v1alpha3.PipelineRunStatus
 		pbApplier.whenPipelineRunFinished(&condition, prStatus)
 	}
 	prStatus.AddCondition(&condition)
+	prStatus.Stages = pbApplier.stages
 	prStatus.UpdateTime = &v1.Time{Time: time.Now()}
 }
 
@@ -43,6 +43,7 @@ import (
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 
+	"kubesphere.io/devops/controllers/drift"
 	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
 
 	kubesphereclient "kubesphere.io/devops/pkg/client/clientset/versioned"
@@ -73,6 +74,8 @@ type Controller struct {
 
 	workerLoopPeriod time.Duration
 	devopsClient     devopsClient.Interface
+
+	driftOptions drift.Options
 }
 
 func NewController(client clientset.Interface,
@@ -107,7 +110,9 @@ func NewController(client clientset.Interface,
 		UpdateFunc: func(oldObj, newObj interface{}) {
 			oldPipeline := oldObj.(*devopsv1alpha3.Pipeline)
 			newPipeline := newObj.(*devopsv1alpha3.Pipeline)
-			if oldPipeline.ResourceVersion == newPipeline.ResourceVersion {
+			// the status updated by the controller itself doesn't need to be reconciled again
+			if oldPipeline.ResourceVersion == newPipeline.ResourceVersion ||
+				k8sutil.IsStatusOnlyUpdate(oldPipeline, newPipeline) {
 				return
 			}
 
@@ -194,6 +199,9 @@ func (c *Controller) Run(workers int, stopCh <-chan struct{}) error {
 	for i := 0; i < workers; i++ {
 		go wait.Until(c.worker, c.workerLoopPeriod, stopCh)
 	}
+	if c.driftOptions.Enabled() {
+		go wait.Until(c.detectDrift, c.driftOptions.Interval, stopCh)
+	}
 
 	<-stopCh
 	return nil
@@ -246,7 +254,7 @@ func (c *Controller) syncHandler(key string) error {
 		if state, ok := copyPipeline.Annotations[devopsv1alpha3.PipelineSyncStatusAnnoKey]; ok && state == constants.StatusSuccessful {
 			specHash := utils.ComputeHash(copyPipeline.Spec)
 			oldHash, _ := copyPipeline.Annotations[devopsv1alpha3.PipelineSpecHash] // don't need to check if it's nil, only compare if they're different
-			if specHash == oldHash {
+			if specHash == oldHash && copyPipeline.Status.ObservedGeneration == copyPipeline.Generation {
 				klog.V(9).Info(fmt.Sprintf("%s/%s has no changes in spec", copyPipeline.Namespace, copyPipeline.Name))
 				// it was synced successfully, and there's any change with the Pipeline spec, skip this round
 				return nil
@@ -268,6 +276,7 @@ func (c *Controller) syncHandler(key string) error {
 				_, err := c.devopsClient.UpdateProjectPipeline(nsName, copyPipeline)
 				if err != nil {
 					klog.V(8).Info(err, fmt.Sprintf("failed to update pipeline config %s ", key))
+					c.markJenkinsSyncFailed(copyPipeline, err)
 					return err
 				}
 			} else {
@@ -277,6 +286,7 @@ func (c *Controller) syncHandler(key string) error {
 			_, err := c.devopsClient.CreateProjectPipeline(nsName, copyPipeline)
 			if err != nil {
 				klog.V(8).Info(err, fmt.Sprintf("failed to create copyPipeline %s ", key))
+				c.markJenkinsSyncFailed(copyPipeline, err)
 				return err
 			}
 		}
@@ -287,7 +297,8 @@ func (c *Controller) syncHandler(key string) error {
 		// Finalizers processing logic
 		if sliceutil.HasString(copyPipeline.ObjectMeta.Finalizers, devopsv1alpha3.PipelineFinalizerName) {
 			delSuccess := false
-			if _, err := c.devopsClient.DeleteProjectPipeline(nsName, pipeline.Name); err != nil {
+			_, err := c.devopsClient.DeleteProjectPipeline(nsName, pipeline.Name)
+			if err != nil {
 				// the status code should be 404 if the job does not exists
 				if srvErr, ok := err.(restful.ServiceError); ok {
 					delSuccess = srvErr.Code == http.StatusNotFound
@@ -309,21 +320,60 @@ func (c *Controller) syncHandler(key string) error {
 			} else {
 				// make sure the corresponding Jenkins job can be clean
 				// You can remove the finalizer via kubectl manually in a very special case that Jenkins might be not able to available anymore
+				c.markJenkinsSyncFailed(copyPipeline, err)
 				return fmt.Errorf("failed to remove pipeline job finalizer due to bad communication with Jenkins")
 			}
 		}
 	}
 
-	if !reflect.DeepEqual(pipeline, copyPipeline) {
-		_, err = c.kubesphereClient.DevopsV1alpha3().Pipelines(nsName).Update(context.Background(), copyPipeline, metav1.UpdateOptions{})
+	if !reflect.DeepEqual(pipeline.ObjectMeta, copyPipeline.ObjectMeta) {
+		updated, err := c.kubesphereClient.DevopsV1alpha3().Pipelines(nsName).Update(context.Background(), copyPipeline, metav1.UpdateOptions{})
 		if err != nil {
 			klog.V(8).Info(err, fmt.Sprintf("failed to update pipeline %s ", key))
 			return err
 		}
+		copyPipeline.ResourceVersion = updated.ResourceVersion
+	}
+	if copyPipeline.DeletionTimestamp.IsZero() {
+		if err := c.markJenkinsSynced(copyPipeline); err != nil {
+			klog.V(8).Info(err, fmt.Sprintf("failed to update the status of pipeline %s ", key))
+			return err
+		}
 	}
 	return nil
 }
 
+// markJenkinsSynced records the current generation of the Pipeline as synchronized into Jenkins. The status is a
+// subresource, so it needs to be updated separately.
+func (c *Controller) markJenkinsSynced(pipeline *devopsv1alpha3.Pipeline) error {
+	if condition := devopsv1alpha3.FindCondition(pipeline.Status.Conditions, devopsv1alpha3.ConditionJenkinsSynced); condition != nil &&
+		condition.Status == devopsv1alpha3.ConditionTrue && pipeline.Status.ObservedGeneration == pipeline.Generation {
+		return nil
+	}
+	copyPipeline := pipeline.DeepCopy()
+	copyPipeline.Status.ObservedGeneration = pipeline.Generation
+	copyPipeline.Status.Conditions = devopsv1alpha3.SetCondition(copyPipeline.Status.Conditions,
+		devopsv1alpha3.NewJenkinsSyncedCondition(devopsv1alpha3.ConditionTrue, devopsv1alpha3.Synced, ""))
+	_, err := c.kubesphereClient.DevopsV1alpha3().Pipelines(pipeline.Namespace).UpdateStatus(context.Background(), copyPipeline, metav1.UpdateOptions{})
+	return err
+}
+
+// markJenkinsSyncFailed records the failure of synchronizing into Jenkins in the conditions of the Pipeline. The status
+// is not updated if the same failure was recorded, otherwise every retry would refresh the probe time.
+func (c *Controller) markJenkinsSyncFailed(pipeline *devopsv1alpha3.Pipeline, syncErr error) {
+	if condition := devopsv1alpha3.FindCondition(pipeline.Status.Conditions, devopsv1alpha3.ConditionJenkinsSynced); condition != nil &&
+		condition.Status == devopsv1alpha3.ConditionFalse && condition.Reason == devopsv1alpha3.SyncFailed &&
+		condition.Message == syncErr.Error() {
+		return
+	}
+	copyPipeline := pipeline.DeepCopy()
+	copyPipeline.Status.Conditions = devopsv1alpha3.SetCondition(copyPipeline.Status.Conditions,
+		devopsv1alpha3.NewJenkinsSyncedCondition(devopsv1alpha3.ConditionFalse, devopsv1alpha3.SyncFailed, syncErr.Error()))
+	if _, err := c.kubesphereClient.DevopsV1alpha3().Pipelines(pipeline.Namespace).UpdateStatus(context.Background(), copyPipeline, metav1.UpdateOptions{}); err != nil {
+		klog.Errorf("failed to update the status of pipeline %s/%s, error %v", pipeline.Namespace, pipeline.Name, err)
+	}
+}
+
 func isDevOpsProjectAdminNamespace(namespace *v1.Namespace) bool {
 	_, ok := namespace.Labels[constants.DevOpsProjectLabelKey]
 
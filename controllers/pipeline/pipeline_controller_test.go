@@ -17,6 +17,8 @@ limitations under the License.
 package pipeline
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -417,3 +419,61 @@ func TestUpdatePipelineConfig(t *testing.T) {
 	f.expectPipeline = []*devops.Pipeline{expectPipeline}
 	f.run(getKey(modifiedPipeline, t))
 }
+
+func TestMarkPipelineJenkinsSynced(t *testing.T) {
+	f := newFixture(t)
+	nsName := "test-123"
+	pipelineName := "test"
+	projectName := "test_project"
+
+	ns := newNamespace(nsName, projectName)
+	pipeline := newPipeline(nsName, pipelineName, devops.PipelineSpec{}, true, false)
+	pipeline.Generation = 2
+	f.pipelineLister = append(f.pipelineLister, pipeline)
+	f.namespaceLister = append(f.namespaceLister, ns)
+	f.objects = append(f.objects, pipeline)
+	f.initDevOpsProject = nsName
+	f.expectPipeline = []*devops.Pipeline{newPipeline(nsName, pipelineName, devops.PipelineSpec{}, true, true)}
+	f.expectPipeline[0].Generation = 2
+	f.run(getKey(pipeline, t))
+
+	actual, err := f.client.DevopsV1alpha3().Pipelines(nsName).Get(context.Background(), pipelineName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get pipeline: %v", err)
+	}
+	if actual.Status.ObservedGeneration != 2 {
+		t.Errorf("expected observed generation 2, got %d", actual.Status.ObservedGeneration)
+	}
+	condition := devops.FindCondition(actual.Status.Conditions, devops.ConditionJenkinsSynced)
+	if condition == nil || condition.Status != devops.ConditionTrue || condition.Reason != devops.Synced {
+		t.Errorf("unexpected condition JenkinsSynced: %v", condition)
+	}
+}
+
+func TestMarkPipelineJenkinsSyncFailed(t *testing.T) {
+	pipeline := newPipeline("test-123", "test", devops.PipelineSpec{}, true, false)
+	client := fake.NewSimpleClientset(pipeline)
+	c := &Controller{kubesphereClient: client}
+
+	c.markJenkinsSyncFailed(pipeline, errors.New("connection refused"))
+	actual, err := client.DevopsV1alpha3().Pipelines("test-123").Get(context.Background(), "test", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get pipeline: %v", err)
+	}
+	condition := devops.FindCondition(actual.Status.Conditions, devops.ConditionJenkinsSynced)
+	if condition == nil || condition.Status != devops.ConditionFalse || condition.Message != "connection refused" {
+		t.Errorf("unexpected condition JenkinsSynced: %v", condition)
+	}
+
+	// the same failure should not update the status again
+	client.ClearActions()
+	c.markJenkinsSyncFailed(actual, errors.New("connection refused"))
+	if actions := client.Actions(); len(actions) != 0 {
+		t.Errorf("unexpected actions: %+v", actions)
+	}
+
+	c.markJenkinsSyncFailed(actual, errors.New("timeout"))
+	if actions := client.Actions(); len(actions) != 1 || actions[0].GetSubresource() != "status" {
+		t.Errorf("expected one status update, got %+v", actions)
+	}
+}
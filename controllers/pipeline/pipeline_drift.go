@@ -0,0 +1,214 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+
+	"kubesphere.io/devops/controllers/drift"
+	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/constants"
+)
+
+// EnableDriftDetection makes the controller compare the Pipelines with the jobs in Jenkins periodically.
+func (c *Controller) EnableDriftDetection(options drift.Options) {
+	c.driftOptions = options
+}
+
+// detectDrift compares the synchronized Pipelines with the jobs in Jenkins. The drifted jobs are repaired unless in
+// dry-run mode, and the drift is reported through the condition JenkinsSynced and metrics.
+func (c *Controller) detectDrift() {
+	pipelines, err := c.devOpsProjectLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list pipelines for drift detection, error %v", err)
+		return
+	}
+
+	drifts := map[drift.Type]int{}
+	for _, pipeline := range pipelines {
+		// the Pipelines which are not synchronized yet are taken care of by syncHandler
+		if !pipeline.DeletionTimestamp.IsZero() ||
+			pipeline.Annotations[devopsv1alpha3.PipelineSyncStatusAnnoKey] != constants.StatusSuccessful {
+			continue
+		}
+		driftType, err := c.checkDrift(pipeline)
+		if err != nil {
+			klog.V(4).Infof("failed to check the drift of pipeline %s/%s, error %v", pipeline.Namespace, pipeline.Name, err)
+			continue
+		}
+		if driftType == drift.None {
+			if isDriftReported(pipeline) {
+				if err := c.markJenkinsSynced(pipeline); err != nil {
+					klog.Errorf("failed to update the status of pipeline %s/%s, error %v", pipeline.Namespace, pipeline.Name, err)
+				}
+			}
+			continue
+		}
+
+		drifts[driftType]++
+		klog.Infof("pipeline %s/%s drifted from Jenkins: %s", pipeline.Namespace, pipeline.Name, driftType)
+		if c.driftOptions.DryRun {
+			c.markDrifted(pipeline, driftType)
+			continue
+		}
+		if err := c.repairDrift(pipeline, driftType); err != nil {
+			klog.Errorf("failed to repair the drift of pipeline %s/%s, error %v", pipeline.Namespace, pipeline.Name, err)
+			c.markJenkinsSyncFailed(pipeline, err)
+		} else if err := c.markJenkinsSynced(pipeline); err != nil {
+			klog.Errorf("failed to update the status of pipeline %s/%s, error %v", pipeline.Namespace, pipeline.Name, err)
+		}
+	}
+	drift.Report(devopsv1alpha3.ResourceKindPipeline, drifts)
+}
+
+// checkDrift finds out whether the job in Jenkins is different from the Pipeline.
+func (c *Controller) checkDrift(pipeline *devopsv1alpha3.Pipeline) (drift.Type, error) {
+	jenkinsPipeline, err := c.devopsClient.GetProjectPipelineConfig(pipeline.Namespace, pipeline.Name)
+	if err != nil {
+		if drift.IsNotFound(err) {
+			return drift.Missing, nil
+		}
+		return drift.None, err
+	}
+	jenkinsSpec, err := normalizeJenkinsSpec(&jenkinsPipeline.Spec)
+	if err != nil {
+		return drift.None, err
+	}
+	spec, err := normalizeJenkinsSpec(&pipeline.Spec)
+	if err != nil {
+		return drift.None, err
+	}
+	if !reflect.DeepEqual(jenkinsSpec, spec) {
+		return drift.Modified, nil
+	}
+	return drift.None, nil
+}
+
+// jenkinsManagedKeys are the keys of the job configuration which are generated by Jenkins rather than the Pipeline.
+var jenkinsManagedKeys = map[string]bool{"scm_id": true}
+
+// normalizeJenkinsSpec returns the part of PipelineSpec owned by the controller in a comparable form. The empty values
+// and the keys managed by Jenkins are dropped, so the properties added or defaulted by Jenkins are not a drift.
+func normalizeJenkinsSpec(spec *devopsv1alpha3.PipelineSpec) (interface{}, error) {
+	ownedSpec := getJenkinsSpec(spec)
+	ownedSpec = *ownedSpec.DeepCopy()
+	// the name of the job is its identity rather than a property
+	if ownedSpec.Pipeline != nil {
+		ownedSpec.Pipeline.Name = ""
+	}
+	if ownedSpec.MultiBranchPipeline != nil {
+		ownedSpec.MultiBranchPipeline.Name = ""
+	}
+
+	data, err := json.Marshal(ownedSpec)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return pruneEmptyValue(value), nil
+}
+
+// pruneEmptyValue drops the empty values and the keys managed by Jenkins from the decoded JSON value recursively. It
+// returns nil if the value is empty.
+func pruneEmptyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if item = pruneEmptyValue(item); item == nil || jenkinsManagedKeys[key] {
+				delete(v, key)
+			} else {
+				v[key] = item
+			}
+		}
+		if len(v) == 0 {
+			return nil
+		}
+	case []interface{}:
+		// the items are kept even if they are empty, because their positions matter
+		for i := range v {
+			v[i] = pruneEmptyValue(v[i])
+		}
+		if len(v) == 0 {
+			return nil
+		}
+	case string:
+		if v = strings.TrimSpace(v); v == "" {
+			return nil
+		}
+		return v
+	case bool:
+		if !v {
+			return nil
+		}
+	case float64:
+		if v == 0 {
+			return nil
+		}
+	}
+	return value
+}
+
+// getJenkinsSpec returns the part of PipelineSpec which is stored in the job configuration of Jenkins.
+func getJenkinsSpec(spec *devopsv1alpha3.PipelineSpec) devopsv1alpha3.PipelineSpec {
+	return devopsv1alpha3.PipelineSpec{
+		Type:                spec.Type,
+		Pipeline:            spec.Pipeline,
+		MultiBranchPipeline: spec.MultiBranchPipeline,
+	}
+}
+
+// repairDrift overwrites the job in Jenkins with the Pipeline.
+func (c *Controller) repairDrift(pipeline *devopsv1alpha3.Pipeline, driftType drift.Type) (err error) {
+	if driftType == drift.Missing {
+		_, err = c.devopsClient.CreateProjectPipeline(pipeline.Namespace, pipeline.DeepCopy())
+	} else {
+		_, err = c.devopsClient.UpdateProjectPipeline(pipeline.Namespace, pipeline.DeepCopy())
+	}
+	return
+}
+
+// markDrifted reports the drift through the condition JenkinsSynced.
+func (c *Controller) markDrifted(pipeline *devopsv1alpha3.Pipeline, driftType drift.Type) {
+	message := fmt.Sprintf("The job in Jenkins drifted from the Pipeline: %s", driftType)
+	if condition := devopsv1alpha3.FindCondition(pipeline.Status.Conditions, devopsv1alpha3.ConditionJenkinsSynced); condition != nil &&
+		condition.Reason == devopsv1alpha3.Drifted && condition.Message == message {
+		return
+	}
+	copyPipeline := pipeline.DeepCopy()
+	copyPipeline.Status.Conditions = devopsv1alpha3.SetCondition(copyPipeline.Status.Conditions,
+		devopsv1alpha3.NewJenkinsSyncedCondition(devopsv1alpha3.ConditionFalse, devopsv1alpha3.Drifted, message))
+	if _, err := c.kubesphereClient.DevopsV1alpha3().Pipelines(pipeline.Namespace).UpdateStatus(context.Background(), copyPipeline, metav1.UpdateOptions{}); err != nil {
+		klog.Errorf("failed to update the status of pipeline %s/%s, error %v", pipeline.Namespace, pipeline.Name, err)
+	}
+}
+
+// isDriftReported indicates if the drift of the Pipeline was reported before.
+func isDriftReported(pipeline *devopsv1alpha3.Pipeline) bool {
+	condition := devopsv1alpha3.FindCondition(pipeline.Status.Conditions, devopsv1alpha3.ConditionJenkinsSynced)
+	return condition != nil && condition.Status == devopsv1alpha3.ConditionFalse && condition.Reason == devopsv1alpha3.Drifted
+}
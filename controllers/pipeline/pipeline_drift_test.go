@@ -0,0 +1,146 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"kubesphere.io/devops/controllers/drift"
+	devops "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestController_detectDrift(t *testing.T) {
+	nsName := "test-123"
+	newDriftFixture := func(t *testing.T) *fixture {
+		spec := devops.PipelineSpec{Type: devops.NoScmPipelineType}
+		missing := newPipeline(nsName, "missing", spec, true, true)
+		modified := newPipeline(nsName, "modified", spec, true, true)
+		synced := newPipeline(nsName, "synced", spec, true, true)
+		// the pending Pipeline is left to syncHandler
+		pending := newPipeline(nsName, "pending", spec, true, false)
+		modifiedInJenkins := modified.DeepCopy()
+		modifiedInJenkins.Spec.Type = devops.MultiBranchPipelineType
+
+		f := newFixture(t)
+		f.pipelineLister = []*devops.Pipeline{missing, modified, synced, pending}
+		f.objects = append(f.objects, missing, modified, synced, pending)
+		f.initDevOpsProject = nsName
+		f.initPipeline = []*devops.Pipeline{modifiedInJenkins, synced.DeepCopy()}
+		return f
+	}
+	getCondition := func(t *testing.T, f *fixture, name string) *devops.Condition {
+		pipeline, err := f.client.DevopsV1alpha3().Pipelines(nsName).Get(context.Background(), name, metav1.GetOptions{})
+		if !assert.Nil(t, err) {
+			return nil
+		}
+		return devops.FindCondition(pipeline.Status.Conditions, devops.ConditionJenkinsSynced)
+	}
+
+	t.Run("Dry run", func(t *testing.T) {
+		f := newDriftFixture(t)
+		c, _, _, dI := f.newController()
+		c.EnableDriftDetection(drift.Options{DryRun: true})
+		c.detectDrift()
+
+		assert.Nil(t, dI.Pipelines[nsName]["missing"])
+		assert.Equal(t, devops.MultiBranchPipelineType, dI.Pipelines[nsName]["modified"].Spec.Type)
+		for _, name := range []string{"missing", "modified"} {
+			condition := getCondition(t, f, name)
+			if assert.NotNil(t, condition, name) {
+				assert.Equal(t, devops.ConditionFalse, condition.Status)
+				assert.Equal(t, devops.Drifted, condition.Reason)
+			}
+		}
+		assert.Nil(t, getCondition(t, f, "synced"))
+		assert.Nil(t, getCondition(t, f, "pending"))
+	})
+
+	t.Run("Repair", func(t *testing.T) {
+		f := newDriftFixture(t)
+		c, _, _, dI := f.newController()
+		c.EnableDriftDetection(drift.Options{})
+		c.detectDrift()
+
+		assert.NotNil(t, dI.Pipelines[nsName]["missing"])
+		assert.Equal(t, devops.NoScmPipelineType, dI.Pipelines[nsName]["modified"].Spec.Type)
+		assert.Nil(t, dI.Pipelines[nsName]["pending"])
+		for _, name := range []string{"missing", "modified"} {
+			condition := getCondition(t, f, name)
+			if assert.NotNil(t, condition, name) {
+				assert.Equal(t, devops.ConditionTrue, condition.Status)
+			}
+		}
+	})
+}
+
+func Test_getJenkinsSpec(t *testing.T) {
+	spec := devops.PipelineSpec{
+		Type:              devops.NoScmPipelineType,
+		Pipeline:          &devops.NoScmPipeline{Name: "test"},
+		ConcurrencyPolicy: devops.QueueConcurrent,
+	}
+	assert.Equal(t, devops.PipelineSpec{
+		Type:     devops.NoScmPipelineType,
+		Pipeline: &devops.NoScmPipeline{Name: "test"},
+	}, getJenkinsSpec(&spec))
+}
+
+func Test_normalizeJenkinsSpec(t *testing.T) {
+	spec := devops.PipelineSpec{
+		Type: devops.MultiBranchPipelineType,
+		MultiBranchPipeline: &devops.MultiBranchPipeline{
+			Name:       "test",
+			SourceType: devops.SourceTypeGit,
+			GitSource:  &devops.GitSource{Url: "https://github.com/kubesphere/devops", DiscoverBranches: true},
+			ScriptPath: "Jenkinsfile",
+		},
+		ConcurrencyPolicy: devops.QueueConcurrent,
+	}
+	// Jenkins adds the generated SCM ID, and the properties with default values
+	specInJenkins := devops.PipelineSpec{
+		Type: devops.MultiBranchPipelineType,
+		MultiBranchPipeline: &devops.MultiBranchPipeline{
+			Name:       "test",
+			SourceType: devops.SourceTypeGit,
+			GitSource: &devops.GitSource{
+				ScmId:            "b6b4ba35-8b0d-4b4a-a5e3-2e6b0c6e4a1d",
+				Url:              "https://github.com/kubesphere/devops",
+				DiscoverBranches: true,
+				CloneOption:      &devops.GitCloneOption{},
+			},
+			ScriptPath:            "Jenkinsfile\n",
+			MultiBranchJobTrigger: &devops.MultiBranchJobTrigger{},
+		},
+	}
+
+	normalizedSpec, err := normalizeJenkinsSpec(&spec)
+	assert.Nil(t, err)
+	normalizedSpecInJenkins, err := normalizeJenkinsSpec(&specInJenkins)
+	assert.Nil(t, err)
+	assert.Equal(t, normalizedSpec, normalizedSpecInJenkins)
+	// the Pipeline is not changed by the normalization
+	assert.Equal(t, "test", spec.MultiBranchPipeline.Name)
+
+	specInJenkins.MultiBranchPipeline.GitSource.DiscoverTags = true
+	normalizedSpecInJenkins, err = normalizeJenkinsSpec(&specInJenkins)
+	assert.Nil(t, err)
+	assert.NotEqual(t, normalizedSpec, normalizedSpecInJenkins)
+}
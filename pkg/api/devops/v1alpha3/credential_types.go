@@ -73,4 +73,12 @@ const (
 	CredentialSyncStatusAnnoKey = DevOpsCredentialPrefix + "syncstatus"
 	CredentialSyncTimeAnnoKey   = DevOpsCredentialPrefix + "synctime"
 	CredentialSyncMsgAnnoKey    = DevOpsCredentialPrefix + "syncmsg"
+	// CredentialExpiresAtAnnoKey is the optional expiry time of the credential in RFC 3339 format, e.g. the expiry of a token
+	CredentialExpiresAtAnnoKey = DevOpsCredentialPrefix + "expires-at"
+	// CredentialRotatedAtAnnoKey is the last time of rotating the credential in RFC 3339 format
+	CredentialRotatedAtAnnoKey = DevOpsCredentialPrefix + "rotated-at"
+	// CredentialExternalRefAnnoKey refers to the data of the credential in an external provider, e.g.
+	// "vault:project-abc/github". The path must be under the namespace of the DevOps project. The data is resolved and
+	// synchronized into Jenkins, but never stored in the secret.
+	CredentialExternalRefAnnoKey = DevOpsCredentialPrefix + "external-ref"
 )
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha3
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -33,15 +34,76 @@ const (
 	DevOpeProjectSyncStatusAnnoKey = DevOpsProjectPrefix + "syncstatus"
 	DevOpeProjectSyncTimeAnnoKey   = DevOpsProjectPrefix + "synctime"
 	DevOpeProjectSyncMsgAnnoKey    = DevOpsProjectPrefix + "syncmsg"
+	// DevOpsProjectSpecHashAnnoKey is the hash of the spec which has been synchronized successfully
+	DevOpsProjectSpecHashAnnoKey = DevOpsProjectPrefix + "spechash"
+	// DevOpsProjectRequestToImportJobsAnnoKey is the key of requesting to import the Jenkins jobs of the project as Pipelines.
+	// The result is recorded in the condition JobsImported.
+	DevOpsProjectRequestToImportJobsAnnoKey = DevOpsProjectPrefix + "request-to-import-jobs"
+	// DevOpsProjectImportSourceFolderAnnoKey is the Jenkins folder which the jobs are imported from. The folder of the
+	// project is used if it's absent.
+	DevOpsProjectImportSourceFolderAnnoKey = DevOpsProjectPrefix + "import-source-folder"
 )
 
 // DevOpsProjectSpec defines the desired state of DevOpsProject
 type DevOpsProjectSpec struct {
+	// Members are the users and their roles in the DevOps project.
+	// +optional
+	Members []ProjectMember `json:"members,omitempty"`
+
+	// MaxConcurrentRuns is the max number of PipelineRuns which are allowed to run concurrently in the DevOps project.
+	// Zero means no limit.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxConcurrentRuns int `json:"maxConcurrentRuns,omitempty"`
+
+	// ResourceQuota is the template of the ResourceQuota in the admin namespace.
+	// +optional
+	ResourceQuota *corev1.ResourceQuotaSpec `json:"resourceQuota,omitempty"`
+
+	// LimitRange is the template of the LimitRange in the admin namespace.
+	// +optional
+	LimitRange *corev1.LimitRangeSpec `json:"limitRange,omitempty"`
+}
+
+// ProjectRole is the role of a member in the DevOps project.
+// +kubebuilder:validation:Enum=owner;maintainer;developer;reporter
+type ProjectRole string
+
+const (
+	// ProjectOwner is able to do all the operations of a DevOps project
+	ProjectOwner ProjectRole = "owner"
+	// ProjectMaintainer is able to manage pipeline and credential configuration in a DevOps project
+	ProjectMaintainer ProjectRole = "maintainer"
+	// ProjectDeveloper is able to view and trigger the pipeline
+	ProjectDeveloper ProjectRole = "developer"
+	// ProjectReporter is only allowed to view the status of the pipeline
+	ProjectReporter ProjectRole = "reporter"
+)
+
+// ProjectMember is a user and its role in the DevOps project.
+type ProjectMember struct {
+	// Username is the name of the user.
+	Username string `json:"username"`
+
+	// Role is the role of the user.
+	Role ProjectRole `json:"role"`
 }
 
 // DevOpsProjectStatus defines the observed state of DevOpsProject
 type DevOpsProjectStatus struct {
 	AdminNamespace string `json:"adminNamespace,omitempty"`
+
+	// Members are the members which have been assigned roles in Jenkins.
+	// +optional
+	Members []ProjectMember `json:"members,omitempty"`
+
+	// ObservedGeneration is the generation of the DevOpsProject which was synchronized last time.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions are the latest observations of the DevOpsProject, e.g. JenkinsSynced and JobsImported.
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
 }
 
 // +genclient
@@ -50,6 +112,7 @@ type DevOpsProjectStatus struct {
 
 // DevOpsProject is the Schema for the devopsprojects API
 // +kubebuilder:resource:categories="devops",scope="Cluster"
+// +kubebuilder:subresource:status
 // +k8s:openapi-gen=true
 type DevOpsProject struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -27,11 +27,19 @@ const (
 	GroupName                         = "devops.kubesphere.io"
 	JenkinsPipelineRunIDKey           = GroupName + "/jenkins-pipelinerun-id"
 	JenkinsPipelineRunStatusKey       = GroupName + "/jenkins-pipelinerun-status"
-	JenkinsPipelineRunStagesStatusKey = GroupName + "/jenkins-pipelinerun-stages-status"
+	JenkinsPipelineRunStagesStatusKey = GroupName + "/jenkins-pipelinerun-stages-status" // Deprecated: use PipelineRunStatus.Stages instead
 	PipelineRunOrphanKey              = GroupName + "/jenkins-pipelinerun-orphan"
+	// JenkinsPipelineRunEventKey is the annotation key of the latest event received from Jenkins.
+	JenkinsPipelineRunEventKey = GroupName + "/jenkins-pipelinerun-event"
 
 	PipelineNameLabelKey = GroupName + "/pipeline"
 	SCMRefNameLabelKey   = GroupName + "/scm-ref-name"
+	// PipelineRunRetryOfLabelKey is the label key of the name of the first PipelineRun in a retry lineage.
+	PipelineRunRetryOfLabelKey = GroupName + "/pipelinerun-retry-of"
+	// PipelineRunAttemptLabelKey is the label key of the attempt number of a PipelineRun in a retry lineage.
+	PipelineRunAttemptLabelKey = GroupName + "/pipelinerun-attempt"
+	// PipelineRunRetryAfterAnnoKey is the annotation key of the time before which a retry PipelineRun won't be triggered.
+	PipelineRunRetryAfterAnnoKey = GroupName + "/pipelinerun-retry-after"
 )
 
 var (
@@ -36,6 +36,8 @@ const (
 	PipelineSyncMsgAnnoKey    = PipelinePrefix + "syncmsg"
 	// PipelineRequestToSyncRunsAnnoKey is the key of requesting to synchronize PipelineRun after a dedicated time.
 	PipelineRequestToSyncRunsAnnoKey = PipelinePrefix + "request-to-sync-pipelineruns"
+	// PipelineSCMWebhookSecretAnnoKey is the name of the secret-text credential used to verify the SCM webhook events.
+	PipelineSCMWebhookSecretAnnoKey = PipelinePrefix + "scm-webhook-secret"
 )
 
 // PipelineSpec defines the desired state of Pipeline
@@ -45,12 +47,93 @@ type PipelineSpec struct {
 	Type                string               `json:"type" description:"type of devops pipeline, in scm or no scm"`
 	Pipeline            *NoScmPipeline       `json:"pipeline,omitempty" description:"no scm pipeline structs"`
 	MultiBranchPipeline *MultiBranchPipeline `json:"multi_branch_pipeline,omitempty" description:"in scm pipeline structs"`
+	// +optional
+	Retry *RetryPolicy `json:"retry,omitempty" description:"retry policy of failed pipeline runs"`
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty" description:"default timeout of pipeline runs, e.g. 1h30m"`
+	// +optional
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrency_policy,omitempty" description:"how to treat concurrent pipeline runs, one of Allow, Queue, CancelOlderQueued"`
+	// +optional
+	QualityGate *QualityGatePolicy `json:"quality_gate,omitempty" description:"how the quality gate of code analyses affects pipeline runs"`
 }
 
+// ConcurrencyPolicy describes how to treat concurrent PipelineRuns of the same Pipeline, or the same branch of a
+// multi-branch Pipeline.
+// +kubebuilder:validation:Enum=Allow;Queue;CancelOlderQueued
+type ConcurrencyPolicy string
+
+const (
+	// AllowConcurrent allows PipelineRuns to run concurrently.
+	AllowConcurrent ConcurrencyPolicy = "Allow"
+
+	// QueueConcurrent keeps new PipelineRuns in queue while another PipelineRun is active.
+	QueueConcurrent ConcurrencyPolicy = "Queue"
+
+	// CancelOlderQueued keeps new PipelineRuns in queue while another PipelineRun is active, and cancels the older
+	// queued PipelineRuns, which is useful for pushes to the same branch.
+	CancelOlderQueued ConcurrencyPolicy = "CancelOlderQueued"
+)
+
+// GetConcurrencyPolicy returns the concurrency policy of Pipeline. PipelineRuns are queued if the concurrency policy is
+// not specified but DisableConcurrent is set.
+func (ps *PipelineSpec) GetConcurrencyPolicy() ConcurrencyPolicy {
+	if ps.ConcurrencyPolicy != "" {
+		return ps.ConcurrencyPolicy
+	}
+	if ps.Type == NoScmPipelineType && ps.Pipeline != nil && ps.Pipeline.DisableConcurrent {
+		return QueueConcurrent
+	}
+	return AllowConcurrent
+}
+
+// RetryPolicy defines how to retry a failed PipelineRun.
+type RetryPolicy struct {
+	// MaxAttempts is the max number of attempts, including the first run.
+	MaxAttempts int `json:"maxAttempts" description:"max number of attempts, including the first run"`
+
+	// Backoff is the duration to wait before the first retry. It doubles with each retry.
+	// +optional
+	Backoff *metav1.Duration `json:"backoff,omitempty" description:"duration to wait before the first retry, doubled with each retry"`
+
+	// Results are the Jenkins results which need a retry, e.g. FAILURE, UNSTABLE, ABORTED. Defaults to FAILURE.
+	// PipelineRuns which were stopped, timed out or failed in the quality gate are never retried.
+	// +optional
+	Results []string `json:"results,omitempty" description:"Jenkins results which need a retry, defaults to FAILURE"`
+}
+
+// QualityGatePolicy defines how the quality gate of the code analyses affects a PipelineRun.
+type QualityGatePolicy struct {
+	// Action is what to do with a completed PipelineRun whose quality gate failed. Defaults to None.
+	// +optional
+	Action QualityGateAction `json:"action,omitempty" description:"what to do with a pipeline run whose quality gate failed, one of None, Fail, Unhealthy"`
+}
+
+// QualityGateAction is what to do with a PipelineRun whose quality gate failed. A quality gate with warnings is not
+// regarded as failed.
+// +kubebuilder:validation:Enum=None;Fail;Unhealthy
+type QualityGateAction string
+
+const (
+	// QualityGateActionNone only records the result of the quality gate.
+	QualityGateActionNone QualityGateAction = "None"
+
+	// QualityGateActionFail marks the PipelineRun as failed, even if Jenkins reports a success.
+	QualityGateActionFail QualityGateAction = "Fail"
+
+	// QualityGateActionUnhealthy keeps the phase of the PipelineRun, but marks it as unhealthy through the condition
+	// Healthy.
+	QualityGateActionUnhealthy QualityGateAction = "Unhealthy"
+)
+
 // PipelineStatus defines the observed state of Pipeline
 type PipelineStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// ObservedGeneration is the generation of the Pipeline which was synchronized into Jenkins last time.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" description:"generation of the pipeline which was synchronized into Jenkins last time"`
+
+	// Conditions are the latest observations of the Pipeline, e.g. JenkinsSynced.
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty" description:"latest observations of the pipeline"`
 }
 
 // +genclient
@@ -58,6 +141,7 @@ type PipelineStatus struct {
 
 // Pipeline is the Schema for the pipelines API
 // +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`,description="The type of a Pipeline"
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="The age of a Pipeline"
 type Pipeline struct {
@@ -93,6 +177,7 @@ const (
 	SourceTypeGitlab    = "gitlab"
 	SourceTypeGithub    = "github"
 	SourceTypeBitbucket = "bitbucket_server"
+	SourceTypeGitea     = "gitea"
 )
 
 type NoScmPipeline struct {
@@ -119,6 +204,7 @@ type MultiBranchPipeline struct {
 	SvnSource             *SvnSource             `json:"svn_source,omitempty" description:"multi branch svn scm define"`
 	SingleSvnSource       *SingleSvnSource       `json:"single_svn_source,omitempty" description:"single branch svn scm define"`
 	BitbucketServerSource *BitbucketServerSource `json:"bitbucket_server_source,omitempty" description:"bitbucket server scm defile"`
+	GiteaSource           *GiteaSource           `json:"gitea_source,omitempty" description:"gitea scm define"`
 	ScriptPath            string                 `json:"script_path" mapstructure:"script_path" description:"script path in scm"`
 	MultiBranchJobTrigger *MultiBranchJobTrigger `json:"multibranch_job_trigger,omitempty" mapstructure:"multibranch_job_trigger" description:"Pipeline tasks that need to be triggered when branch creation/deletion"`
 }
@@ -177,6 +263,21 @@ type BitbucketServerSource struct {
 	RegexFilter          string               `json:"regex_filter,omitempty" mapstructure:"regex_filter" description:"Regex used to match the name of the branch that needs to be run"`
 }
 
+// GiteaSource has the same discovery options as GithubSource, but Gitea is always self-hosted, so the server URL is required
+type GiteaSource struct {
+	ScmId                string               `json:"scm_id,omitempty" description:"uid of scm"`
+	ServerUrl            string               `json:"server_url,omitempty" mapstructure:"server_url" description:"url of the gitea server"`
+	Owner                string               `json:"owner,omitempty" mapstructure:"owner" description:"owner of gitea repo"`
+	Repo                 string               `json:"repo,omitempty" mapstructure:"repo" description:"repo name of gitea repo"`
+	CredentialId         string               `json:"credential_id,omitempty" mapstructure:"credential_id" description:"credential id to access gitea source"`
+	DiscoverBranches     int                  `json:"discover_branches,omitempty" mapstructure:"discover_branches" description:"Discover branch configuration"`
+	DiscoverPRFromOrigin int                  `json:"discover_pr_from_origin,omitempty" mapstructure:"discover_pr_from_origin" description:"Discover origin PR configuration"`
+	DiscoverPRFromForks  *DiscoverPRFromForks `json:"discover_pr_from_forks,omitempty" mapstructure:"discover_pr_from_forks" description:"Discover fork PR configuration"`
+	DiscoverTags         bool                 `json:"discover_tags,omitempty" mapstructure:"discover_tags" description:"Discover tag configuration"`
+	CloneOption          *GitCloneOption      `json:"git_clone_option,omitempty" mapstructure:"git_clone_option" description:"advavced git clone options"`
+	RegexFilter          string               `json:"regex_filter,omitempty" mapstructure:"regex_filter" description:"Regex used to match the name of the branch that needs to be run"`
+}
+
 type MultiBranchJobTrigger struct {
 	CreateActionJobsToTrigger string `json:"create_action_job_to_trigger,omitempty" description:"pipeline name to trigger"`
 	DeleteActionJobsToTrigger string `json:"delete_action_job_to_trigger,omitempty" description:"pipeline name to trigger"`
@@ -207,8 +308,9 @@ type DiscoverPRFromForks struct {
 }
 
 type DiscarderProperty struct {
-	DaysToKeep string `json:"days_to_keep,omitempty" mapstructure:"days_to_keep" description:"days to keep pipeline"`
-	NumToKeep  string `json:"num_to_keep,omitempty" mapstructure:"num_to_keep" description:"nums to keep pipeline"`
+	DaysToKeep         string `json:"days_to_keep,omitempty" mapstructure:"days_to_keep" description:"days to keep pipeline"`
+	NumToKeep          string `json:"num_to_keep,omitempty" mapstructure:"num_to_keep" description:"nums to keep pipeline"`
+	KeepLastSuccessful bool   `json:"keep_last_successful,omitempty" mapstructure:"keep_last_successful" description:"always keep the last successful PipelineRun"`
 }
 
 type ParameterDefinition struct {
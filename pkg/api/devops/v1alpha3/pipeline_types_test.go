@@ -0,0 +1,34 @@
+package v1alpha3
+
+import "testing"
+
+func TestPipelineSpec_GetConcurrencyPolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		spec PipelineSpec
+		want ConcurrencyPolicy
+	}{{
+		name: "Default policy",
+		spec: PipelineSpec{Type: NoScmPipelineType, Pipeline: &NoScmPipeline{}},
+		want: AllowConcurrent,
+	}, {
+		name: "Concurrent builds are disabled",
+		spec: PipelineSpec{Type: NoScmPipelineType, Pipeline: &NoScmPipeline{DisableConcurrent: true}},
+		want: QueueConcurrent,
+	}, {
+		name: "Specified policy",
+		spec: PipelineSpec{
+			Type:                MultiBranchPipelineType,
+			MultiBranchPipeline: &MultiBranchPipeline{},
+			ConcurrencyPolicy:   CancelOlderQueued,
+		},
+		want: CancelOlderQueued,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.spec.GetConcurrencyPolicy(); got != tt.want {
+				t.Errorf("GetConcurrencyPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// DefaultScriptPath is the default path of the Jenkinsfile in the SCM of a multi-branch Pipeline
+const DefaultScriptPath = "Jenkinsfile"
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of Pipeline into the manager
+func (p *Pipeline) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(p).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-devops-kubesphere-io-v1alpha3-pipeline,mutating=true,failurePolicy=fail,sideEffects=None,groups=devops.kubesphere.io,resources=pipelines,verbs=create;update,versions=v1alpha3,name=mpipeline.devops.kubesphere.io,admissionReviewVersions=v1beta1
+
+var _ webhook.Defaulter = &Pipeline{}
+
+// Default fills the fields which can be inferred, e.g. the path of the Jenkinsfile and the type of the SCM
+func (p *Pipeline) Default() {
+	if pipeline := p.Spec.Pipeline; pipeline != nil && pipeline.Name == "" {
+		pipeline.Name = p.Name
+	}
+
+	pipeline := p.Spec.MultiBranchPipeline
+	if pipeline == nil {
+		return
+	}
+	if pipeline.Name == "" {
+		pipeline.Name = p.Name
+	}
+	if pipeline.ScriptPath == "" {
+		pipeline.ScriptPath = DefaultScriptPath
+	}
+	if pipeline.SourceType == "" {
+		// the source type is obvious if there's only one source
+		if sourceTypes := pipeline.populatedSourceTypes(); len(sourceTypes) == 1 {
+			pipeline.SourceType = sourceTypes[0]
+		}
+	}
+}
+
+// +kubebuilder:webhook:path=/validate-devops-kubesphere-io-v1alpha3-pipeline,mutating=false,failurePolicy=fail,sideEffects=None,groups=devops.kubesphere.io,resources=pipelines,verbs=create;update,versions=v1alpha3,name=vpipeline.devops.kubesphere.io,admissionReviewVersions=v1beta1
+
+var _ webhook.Validator = &Pipeline{}
+
+// ValidateCreate rejects the Pipeline which cannot be converted into a Jenkins job
+func (p *Pipeline) ValidateCreate() error {
+	return p.toInvalidError(p.Spec.validate(field.NewPath("spec")))
+}
+
+// ValidateUpdate rejects the Pipeline which cannot be converted into a Jenkins job. The Pipelines created before the
+// validation was introduced can still be updated as long as the spec is untouched, and can always be deleted.
+func (p *Pipeline) ValidateUpdate(old runtime.Object) error {
+	if p.DeletionTimestamp != nil {
+		return nil
+	}
+	if oldPipeline, ok := old.(*Pipeline); ok && equality.Semantic.DeepEqual(oldPipeline.Spec, p.Spec) {
+		return nil
+	}
+	return p.toInvalidError(p.Spec.validate(field.NewPath("spec")))
+}
+
+// ValidateDelete allows deleting any Pipeline
+func (p *Pipeline) ValidateDelete() error {
+	return nil
+}
+
+func (p *Pipeline) toInvalidError(errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind(ResourceKindPipeline).GroupKind(), p.Name, errs)
+}
+
+func (ps *PipelineSpec) validate(path *field.Path) (errs field.ErrorList) {
+	switch ps.Type {
+	case NoScmPipelineType:
+		if ps.Pipeline == nil {
+			errs = append(errs, field.Required(path.Child("pipeline"), "required by the type "+NoScmPipelineType))
+		} else {
+			errs = append(errs, ps.Pipeline.validate(path.Child("pipeline"))...)
+		}
+	case MultiBranchPipelineType:
+		if ps.MultiBranchPipeline == nil {
+			errs = append(errs, field.Required(path.Child("multi_branch_pipeline"), "required by the type "+MultiBranchPipelineType))
+		} else {
+			errs = append(errs, ps.MultiBranchPipeline.validate(path.Child("multi_branch_pipeline"))...)
+		}
+	default:
+		errs = append(errs, field.NotSupported(path.Child("type"), ps.Type, []string{NoScmPipelineType, MultiBranchPipelineType}))
+	}
+
+	if ps.Retry != nil && ps.Retry.MaxAttempts < 1 {
+		errs = append(errs, field.Invalid(path.Child("retry", "maxAttempts"), ps.Retry.MaxAttempts, "must be at least 1"))
+	}
+	if ps.Timeout != nil && ps.Timeout.Duration < 0 {
+		errs = append(errs, field.Invalid(path.Child("timeout"), ps.Timeout.Duration.String(), "must not be negative"))
+	}
+	return
+}
+
+func (p *NoScmPipeline) validate(path *field.Path) (errs field.ErrorList) {
+	if p.Name == "" {
+		errs = append(errs, field.Required(path.Child("name"), ""))
+	}
+	if p.TimerTrigger != nil && p.TimerTrigger.Cron != "" {
+		if err := ValidateCron(p.TimerTrigger.Cron); err != nil {
+			errs = append(errs, field.Invalid(path.Child("timer_trigger", "cron"), p.TimerTrigger.Cron, err.Error()))
+		}
+	}
+	return
+}
+
+func (p *MultiBranchPipeline) validate(path *field.Path) (errs field.ErrorList) {
+	if p.Name == "" {
+		errs = append(errs, field.Required(path.Child("name"), ""))
+	}
+	if p.ScriptPath == "" {
+		errs = append(errs, field.Required(path.Child("script_path"), ""))
+	}
+
+	if sourceField, ok := sourceFields[p.SourceType]; !ok {
+		supported := make([]string, 0, len(sourceFields))
+		for sourceType := range sourceFields {
+			supported = append(supported, sourceType)
+		}
+		sort.Strings(supported)
+		errs = append(errs, field.NotSupported(path.Child("source_type"), p.SourceType, supported))
+	} else if !p.hasSource(p.SourceType) {
+		errs = append(errs, field.Required(path.Child(sourceField), "required by the source type "+p.SourceType))
+	}
+
+	if p.TimerTrigger != nil && p.TimerTrigger.Interval != "" {
+		// the interval is in milliseconds
+		if interval, err := strconv.ParseInt(p.TimerTrigger.Interval, 10, 64); err != nil || interval <= 0 {
+			errs = append(errs, field.Invalid(path.Child("timer_trigger", "interval"), p.TimerTrigger.Interval,
+				"must be a positive number of milliseconds"))
+		}
+	}
+	return
+}
+
+// sourceFields are the fields of the sources of a multi-branch Pipeline, the key is the source type
+var sourceFields = map[string]string{
+	SourceTypeGit:       "git_source",
+	SourceTypeGithub:    "github_source",
+	SourceTypeGitlab:    "gitlab_source",
+	SourceTypeSVN:       "svn_source",
+	SourceTypeSingleSVN: "single_svn_source",
+	SourceTypeBitbucket: "bitbucket_server_source",
+	SourceTypeGitea:     "gitea_source",
+}
+
+// hasSource indicates if the source of the source type is populated
+func (p *MultiBranchPipeline) hasSource(sourceType string) bool {
+	switch sourceType {
+	case SourceTypeGit:
+		return p.GitSource != nil
+	case SourceTypeGithub:
+		return p.GitHubSource != nil
+	case SourceTypeGitlab:
+		return p.GitlabSource != nil
+	case SourceTypeSVN:
+		return p.SvnSource != nil
+	case SourceTypeSingleSVN:
+		return p.SingleSvnSource != nil
+	case SourceTypeBitbucket:
+		return p.BitbucketServerSource != nil
+	case SourceTypeGitea:
+		return p.GiteaSource != nil
+	}
+	return false
+}
+
+func (p *MultiBranchPipeline) populatedSourceTypes() (sourceTypes []string) {
+	for sourceType := range sourceFields {
+		if p.hasSource(sourceType) {
+			sourceTypes = append(sourceTypes, sourceType)
+		}
+	}
+	return
+}
+
+// cronFieldRanges are the ranges of minute, hour, day of month, month and day of week in a Jenkins cron line
+var cronFieldRanges = [][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+var cronAliases = map[string]bool{
+	"@yearly": true, "@annually": true, "@monthly": true, "@weekly": true,
+	"@daily": true, "@midnight": true, "@hourly": true,
+}
+
+// ValidateCron checks the syntax of the Jenkins cron, e.g. "H/15 * * * *". It consists of lines of five fields or
+// an alias like @daily. Empty lines, comments and timezone lines like "TZ=Asia/Shanghai" are allowed.
+func ValidateCron(cron string) error {
+	for _, line := range strings.Split(cron, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "TZ=") {
+			continue
+		}
+		if cronAliases[line] {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != len(cronFieldRanges) {
+			return fmt.Errorf("line %q must have 5 fields, but got %d", line, len(fields))
+		}
+		for i, item := range fields {
+			if err := validateCronField(item, cronFieldRanges[i][0], cronFieldRanges[i][1]); err != nil {
+				return fmt.Errorf("invalid field %q in line %q: %v", item, line, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateCronField checks a field like "*", "H/5", "H(0-29)/10", "1-5" or "0,30"
+func validateCronField(cronField string, min, max int) error {
+	for _, item := range strings.Split(cronField, ",") {
+		rangeExpr := item
+		if i := strings.Index(item, "/"); i >= 0 {
+			rangeExpr = item[:i]
+			if step, err := strconv.Atoi(item[i+1:]); err != nil || step <= 0 {
+				return fmt.Errorf("step of %q must be a positive number", item)
+			}
+		}
+
+		switch {
+		case rangeExpr == "*" || rangeExpr == "H":
+		case strings.HasPrefix(rangeExpr, "H(") && strings.HasSuffix(rangeExpr, ")"):
+			if err := validateCronRange(strings.TrimSuffix(strings.TrimPrefix(rangeExpr, "H("), ")"), min, max); err != nil {
+				return err
+			}
+		default:
+			if err := validateCronRange(rangeExpr, min, max); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateCronRange checks a number or a range like "1-5"
+func validateCronRange(rangeExpr string, min, max int) error {
+	bounds := strings.SplitN(rangeExpr, "-", 2)
+	values := make([]int, len(bounds))
+	for i, bound := range bounds {
+		value, err := strconv.Atoi(bound)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", bound)
+		}
+		if value < min || value > max {
+			return fmt.Errorf("%d is out of range %d-%d", value, min, max)
+		}
+		values[i] = value
+	}
+	if len(values) == 2 && values[0] > values[1] {
+		return fmt.Errorf("range %q is reversed", rangeExpr)
+	}
+	return nil
+}
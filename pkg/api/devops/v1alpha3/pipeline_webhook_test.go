@@ -0,0 +1,205 @@
+package v1alpha3
+
+import (
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPipeline_Default(t *testing.T) {
+	tests := []struct {
+		name           string
+		spec           PipelineSpec
+		wantScriptPath string
+		wantSourceType string
+	}{{
+		name: "Fill the script path and source type",
+		spec: PipelineSpec{
+			Type: MultiBranchPipelineType,
+			MultiBranchPipeline: &MultiBranchPipeline{
+				GitSource: &GitSource{Url: "https://github.com/kubesphere/devops"},
+			},
+		},
+		wantScriptPath: DefaultScriptPath,
+		wantSourceType: SourceTypeGit,
+	}, {
+		name: "Keep the specified fields",
+		spec: PipelineSpec{
+			Type: MultiBranchPipelineType,
+			MultiBranchPipeline: &MultiBranchPipeline{
+				ScriptPath:   "ci/Jenkinsfile",
+				SourceType:   SourceTypeGithub,
+				GitHubSource: &GithubSource{},
+			},
+		},
+		wantScriptPath: "ci/Jenkinsfile",
+		wantSourceType: SourceTypeGithub,
+	}, {
+		name: "Source type is ambiguous",
+		spec: PipelineSpec{
+			Type: MultiBranchPipelineType,
+			MultiBranchPipeline: &MultiBranchPipeline{
+				GitSource:    &GitSource{},
+				GitHubSource: &GithubSource{},
+			},
+		},
+		wantScriptPath: DefaultScriptPath,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline := &Pipeline{ObjectMeta: metav1.ObjectMeta{Name: "demo"}, Spec: tt.spec}
+			pipeline.Default()
+			got := pipeline.Spec.MultiBranchPipeline
+			if got.Name != "demo" {
+				t.Errorf("Default() name = %v, want %v", got.Name, "demo")
+			}
+			if got.ScriptPath != tt.wantScriptPath {
+				t.Errorf("Default() script path = %v, want %v", got.ScriptPath, tt.wantScriptPath)
+			}
+			if got.SourceType != tt.wantSourceType {
+				t.Errorf("Default() source type = %v, want %v", got.SourceType, tt.wantSourceType)
+			}
+		})
+	}
+}
+
+func TestPipeline_ValidateCreate(t *testing.T) {
+	newMultiBranchPipeline := func(sourceType string) *MultiBranchPipeline {
+		return &MultiBranchPipeline{
+			Name:       "demo",
+			ScriptPath: DefaultScriptPath,
+			SourceType: sourceType,
+			GitSource:  &GitSource{Url: "https://github.com/kubesphere/devops"},
+		}
+	}
+	tests := []struct {
+		name    string
+		spec    PipelineSpec
+		wantErr bool
+	}{{
+		name: "Valid pipeline",
+		spec: PipelineSpec{
+			Type: NoScmPipelineType,
+			Pipeline: &NoScmPipeline{
+				Name:         "demo",
+				TimerTrigger: &TimerTrigger{Cron: "TZ=Asia/Shanghai\n# nightly\nH H(0-3) * * 1-5"},
+			},
+		},
+	}, {
+		name: "Valid multi-branch pipeline",
+		spec: PipelineSpec{
+			Type:                MultiBranchPipelineType,
+			MultiBranchPipeline: newMultiBranchPipeline(SourceTypeGit),
+		},
+	}, {
+		name:    "Unknown type",
+		spec:    PipelineSpec{Type: "freestyle"},
+		wantErr: true,
+	}, {
+		name:    "Multi-branch pipeline without source",
+		spec:    PipelineSpec{Type: MultiBranchPipelineType},
+		wantErr: true,
+	}, {
+		name: "Source type doesn't match the source",
+		spec: PipelineSpec{
+			Type:                MultiBranchPipelineType,
+			MultiBranchPipeline: newMultiBranchPipeline(SourceTypeGithub),
+		},
+		wantErr: true,
+	}, {
+		name: "Empty script path",
+		spec: PipelineSpec{
+			Type: MultiBranchPipelineType,
+			MultiBranchPipeline: func() *MultiBranchPipeline {
+				pipeline := newMultiBranchPipeline(SourceTypeGit)
+				pipeline.ScriptPath = ""
+				return pipeline
+			}(),
+		},
+		wantErr: true,
+	}, {
+		name: "Invalid interval",
+		spec: PipelineSpec{
+			Type: MultiBranchPipelineType,
+			MultiBranchPipeline: func() *MultiBranchPipeline {
+				pipeline := newMultiBranchPipeline(SourceTypeGit)
+				pipeline.TimerTrigger = &TimerTrigger{Interval: "1h"}
+				return pipeline
+			}(),
+		},
+		wantErr: true,
+	}, {
+		name: "Invalid cron",
+		spec: PipelineSpec{
+			Type:     NoScmPipelineType,
+			Pipeline: &NoScmPipeline{Name: "demo", TimerTrigger: &TimerTrigger{Cron: "H/15 * * *"}},
+		},
+		wantErr: true,
+	}, {
+		name: "Invalid retry",
+		spec: PipelineSpec{
+			Type:     NoScmPipelineType,
+			Pipeline: &NoScmPipeline{Name: "demo"},
+			Retry:    &RetryPolicy{},
+		},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline := &Pipeline{ObjectMeta: metav1.ObjectMeta{Name: "demo"}, Spec: tt.spec}
+			err := pipeline.ValidateCreate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !apierrors.IsInvalid(err) {
+				t.Errorf("ValidateCreate() error = %v, want an invalid error", err)
+			}
+		})
+	}
+}
+
+func TestPipeline_ValidateUpdate(t *testing.T) {
+	invalid := &Pipeline{ObjectMeta: metav1.ObjectMeta{Name: "demo"}, Spec: PipelineSpec{Type: MultiBranchPipelineType}}
+
+	// the invalid pipeline created before can still be updated if the spec isn't changed
+	updated := invalid.DeepCopy()
+	updated.Finalizers = nil
+	if err := updated.ValidateUpdate(invalid); err != nil {
+		t.Errorf("ValidateUpdate() error = %v, want nil", err)
+	}
+
+	updated.Spec.Timeout = &metav1.Duration{Duration: 1}
+	if err := updated.ValidateUpdate(invalid); err == nil {
+		t.Errorf("ValidateUpdate() error = nil, want an error")
+	}
+}
+
+func TestValidateCron(t *testing.T) {
+	tests := []struct {
+		cron    string
+		wantErr bool
+	}{
+		{cron: "* * * * *"},
+		{cron: "H/15 * * * *"},
+		{cron: "H(0-29)/10 H(9-16) * * 1-5"},
+		{cron: "0,30 8 1 1,6 0"},
+		{cron: "@daily"},
+		{cron: "TZ=Europe/London\n\n# every hour\n@hourly\nH 12 * * 7"},
+		{cron: "", wantErr: false},
+		{cron: "* * * *", wantErr: true},
+		{cron: "60 * * * *", wantErr: true},
+		{cron: "H/0 * * * *", wantErr: true},
+		{cron: "5-1 * * * *", wantErr: true},
+		{cron: "H(0-99) * * * *", wantErr: true},
+		{cron: "@every 5m", wantErr: true},
+		{cron: "* * 0 * *", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cron, func(t *testing.T) {
+			if err := ValidateCron(tt.cron); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCron() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -47,6 +47,11 @@ type PipelineRunSpec struct {
 	// Action indicates what we need to do with current PipelineRun.
 	// +optional
 	Action *Action `json:"action,omitempty"`
+
+	// Timeout is the max duration of PipelineRun since it started. The PipelineRun will be aborted once it times out.
+	// Defaults to the timeout of Pipeline.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
 }
 
 // PipelineRunStatus defines the observed state of PipelineRun
@@ -72,6 +77,243 @@ type PipelineRunStatus struct {
 	// Current phase of PipelineRun.
 	// +optional
 	Phase RunPhase `json:"phase,omitempty"`
+
+	// Stages are the status of all stages of PipelineRun.
+	// +optional
+	Stages []StageStatus `json:"stages,omitempty"`
+
+	// Retry is the retry lineage of PipelineRun.
+	// +optional
+	Retry *RetryStatus `json:"retry,omitempty"`
+
+	// LogArchive records where the logs of the completed PipelineRun are persisted.
+	// +optional
+	LogArchive *LogArchive `json:"logArchive,omitempty"`
+
+	// QualityGate is the summary of the code analyses reported by the completed PipelineRun. It has no analyses
+	// and the status NONE if the PipelineRun reported no analysis.
+	// +optional
+	QualityGate *QualityGate `json:"qualityGate,omitempty"`
+
+	// TestReport is the summary of the test results reported by the completed PipelineRun. It's empty if the
+	// PipelineRun reported no test result.
+	// +optional
+	TestReport *TestReport `json:"testReport,omitempty"`
+}
+
+// LogArchive records where the logs of a completed PipelineRun are persisted in the object storage.
+type LogArchive struct {
+	// Key is the object key of the full log of PipelineRun.
+	Key string `json:"key"`
+
+	// Steps are the object keys of the logs of steps.
+	// +optional
+	Steps []StepLogArchive `json:"steps,omitempty"`
+
+	// ArchiveTime is the time when the logs were persisted.
+	// +optional
+	ArchiveTime *metav1.Time `json:"archiveTime,omitempty"`
+}
+
+// QualityGate is the summary of the code analyses reported by a PipelineRun.
+type QualityGate struct {
+	// Status is the worst status of the quality gates of all analyses.
+	Status QualityGateStatus `json:"status"`
+
+	// Analyses are the results of the code analyses, one for each analysis task.
+	// +optional
+	Analyses []CodeAnalysis `json:"analyses,omitempty"`
+
+	// CollectTime is the time when the results were collected.
+	// +optional
+	CollectTime *metav1.Time `json:"collectTime,omitempty"`
+}
+
+// CodeAnalysis is the summarized result of a code analysis.
+type CodeAnalysis struct {
+	// TaskID is the ID of the analysis task, e.g. the background task of SonarQube.
+	TaskID string `json:"taskId"`
+
+	// Component is the key of the analyzed project.
+	// +optional
+	Component string `json:"component,omitempty"`
+
+	// Status is the status of the quality gate of the analysis.
+	Status QualityGateStatus `json:"status"`
+
+	// Bugs is the number of bugs.
+	Bugs int `json:"bugs"`
+
+	// Vulnerabilities is the number of vulnerabilities.
+	Vulnerabilities int `json:"vulnerabilities"`
+
+	// Coverage is the percentage of the code covered by tests, e.g. "85.3". It's empty if the coverage is unknown.
+	// +optional
+	Coverage string `json:"coverage,omitempty"`
+
+	// DashboardURL is the URL of the analysis report.
+	// +optional
+	DashboardURL string `json:"dashboardUrl,omitempty"`
+}
+
+// QualityGateStatus is the status of a quality gate.
+type QualityGateStatus string
+
+const (
+	// QualityGateStatusOK indicates that the quality gate passed.
+	QualityGateStatusOK QualityGateStatus = "OK"
+	// QualityGateStatusWarn indicates that the quality gate passed with warnings.
+	QualityGateStatusWarn QualityGateStatus = "WARN"
+	// QualityGateStatusError indicates that the quality gate failed.
+	QualityGateStatusError QualityGateStatus = "ERROR"
+	// QualityGateStatusNone indicates that there is no quality gate, or its result is not available.
+	QualityGateStatusNone QualityGateStatus = "NONE"
+)
+
+// TestReport is the summary of the test results reported by a PipelineRun.
+type TestReport struct {
+	// Total is the number of all test cases.
+	Total int `json:"total"`
+
+	// Passed is the number of the passed test cases.
+	Passed int `json:"passed"`
+
+	// Failed is the number of the failed test cases.
+	Failed int `json:"failed"`
+
+	// Skipped is the number of the skipped test cases.
+	Skipped int `json:"skipped"`
+
+	// FailedCases are the names of the failed test cases, e.g. "io.kubesphere.FooTest.testBar". Not all of them are
+	// recorded if there are too many failed test cases, which means it's shorter than Failed.
+	// +optional
+	FailedCases []string `json:"failedCases,omitempty"`
+
+	// CollectTime is the time when the test results were collected.
+	// +optional
+	CollectTime *metav1.Time `json:"collectTime,omitempty"`
+}
+
+// Complete indicates if all failed test cases are recorded.
+func (r *TestReport) Complete() bool {
+	return len(r.FailedCases) >= r.Failed
+}
+
+// StepLogArchive is the object key of the log of a step.
+type StepLogArchive struct {
+	// NodeID is the ID of the node which the step belongs to.
+	NodeID string `json:"nodeId"`
+
+	// StepID is the ID of the step.
+	StepID string `json:"stepId"`
+
+	// Key is the object key of the log of the step.
+	Key string `json:"key"`
+}
+
+// GetKey returns the object key of the persisted log of the PipelineRun, or a step of it.
+func (a *LogArchive) GetKey(nodeID, stepID string) (string, bool) {
+	if nodeID == "" && stepID == "" {
+		return a.Key, true
+	}
+	for _, step := range a.Steps {
+		if step.NodeID == nodeID && step.StepID == stepID {
+			return step.Key, true
+		}
+	}
+	return "", false
+}
+
+// RetryStatus is the retry lineage of a PipelineRun.
+type RetryStatus struct {
+	// Attempt is the attempt number of the PipelineRun, starting from 1.
+	Attempt int `json:"attempt"`
+
+	// RetryOf is the name of the first PipelineRun in the retry lineage.
+	// +optional
+	RetryOf string `json:"retryOf,omitempty"`
+
+	// RetriedBy is the name of the PipelineRun which retries the current one.
+	// +optional
+	RetriedBy string `json:"retriedBy,omitempty"`
+}
+
+// StageStatus is the status of a stage of PipelineRun.
+type StageStatus struct {
+	// ID is the node ID of the stage in Jenkins.
+	ID string `json:"id"`
+
+	// Name is the display name of the stage.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// State of the stage, e.g. QUEUED, RUNNING, PAUSED, SKIPPED, NOT_BUILT, FINISHED.
+	// +optional
+	State string `json:"state,omitempty"`
+
+	// Result of the stage, e.g. SUCCESS, UNSTABLE, FAILURE, NOT_BUILT, UNKNOWN, ABORTED.
+	// +optional
+	Result string `json:"result,omitempty"`
+
+	// Start timestamp of the stage.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// DurationInMillis is the duration of the stage in milliseconds.
+	// +optional
+	DurationInMillis int64 `json:"durationInMillis,omitempty"`
+
+	// Steps are the status of all steps of the stage.
+	// +optional
+	Steps []StepStatus `json:"steps,omitempty"`
+}
+
+// StepStatus is the status of a step of PipelineRun.
+type StepStatus struct {
+	// ID is the ID of the step in Jenkins.
+	ID string `json:"id"`
+
+	// Name is the display name of the step.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Description is the display description of the step, e.g. the script of a shell step.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// State of the step, e.g. QUEUED, RUNNING, PAUSED, SKIPPED, NOT_BUILT, FINISHED.
+	// +optional
+	State string `json:"state,omitempty"`
+
+	// Result of the step, e.g. SUCCESS, UNSTABLE, FAILURE, NOT_BUILT, UNKNOWN, ABORTED.
+	// +optional
+	Result string `json:"result,omitempty"`
+
+	// Start timestamp of the step.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// DurationInMillis is the duration of the step in milliseconds.
+	// +optional
+	DurationInMillis int64 `json:"durationInMillis,omitempty"`
+
+	// Input is the pending input of the step. It is only present when the step is waiting for submission.
+	// +optional
+	Input *InputStatus `json:"input,omitempty"`
+}
+
+// InputStatus is the status of an input step which is waiting for submission.
+type InputStatus struct {
+	// ID is the ID of the input.
+	ID string `json:"id"`
+
+	// Message is the message of the input.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Submitters are the users who are allowed to submit the input. Empty means anyone.
+	// +optional
+	Submitters []string `json:"submitters,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -159,6 +401,18 @@ func (pr *PipelineRun) Buildable() bool {
 	return !pr.HasCompleted() && pr.Labels[PipelineRunOrphanKey] != "true"
 }
 
+// GetTimeout returns the timeout of the PipelineRun, which is inherited from the Pipeline if not specified. Zero means
+// the PipelineRun never times out.
+func (prSpec *PipelineRunSpec) GetTimeout() time.Duration {
+	if prSpec.Timeout != nil {
+		return prSpec.Timeout.Duration
+	}
+	if prSpec.PipelineSpec != nil && prSpec.PipelineSpec.Timeout != nil {
+		return prSpec.PipelineSpec.Timeout.Duration
+	}
+	return 0
+}
+
 // IsMultiBranchPipeline indicates if the PipelineRun belongs a multi-branch pipeline.
 func (prSpec *PipelineRunSpec) IsMultiBranchPipeline() bool {
 	return prSpec.PipelineSpec != nil && prSpec.PipelineSpec.Type == MultiBranchPipelineType
@@ -235,6 +489,25 @@ const (
 	// ConditionSucceeded indicates that the pipeline has finished.
 	// For pipeline which runs to completion
 	ConditionSucceeded ConditionType = "Succeeded"
+
+	// ConditionPaused indicates that the pipeline is held by the Pause action, no new step is started until it's resumed.
+	ConditionPaused ConditionType = "Paused"
+
+	// ConditionStopped indicates that the pipeline has been stopped by the Stop action.
+	ConditionStopped ConditionType = "Stopped"
+
+	// ConditionQueued indicates that the pipeline is waiting for other pipelines to complete.
+	ConditionQueued ConditionType = "Queued"
+
+	// ConditionJenkinsSynced indicates that the DevOpsProject or Pipeline has been synchronized into Jenkins.
+	ConditionJenkinsSynced ConditionType = "JenkinsSynced"
+
+	// ConditionHealthy indicates that the PipelineRun passed the quality gate of its code analyses.
+	ConditionHealthy ConditionType = "Healthy"
+
+	// ConditionJobsImported indicates that the Jenkins jobs requested to be imported have been imported into the
+	// DevOpsProject as Pipelines.
+	ConditionJobsImported ConditionType = "JobsImported"
 )
 
 // ConditionStatus is the status of the current condition.
@@ -278,15 +551,63 @@ type Condition struct {
 	Message string `json:"message,omitempty" protobuf:"bytes,6,opt,name=message"`
 }
 
-// Action indicates what we need to do with current PipelineRun.
+// NewCondition returns a condition which is probed and transitioned now. SetCondition keeps the last transition time if
+// the status doesn't change.
+func NewCondition(conditionType ConditionType, status ConditionStatus, reason, message string) Condition {
+	now := metav1.Now()
+	return Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastProbeTime:      now,
+		LastTransitionTime: now,
+	}
+}
+
+// NewJenkinsSyncedCondition returns the JenkinsSynced condition of a DevOpsProject or Pipeline.
+func NewJenkinsSyncedCondition(status ConditionStatus, reason, message string) Condition {
+	return NewCondition(ConditionJenkinsSynced, status, reason, message)
+}
+
+// SetCondition adds the new condition into conditions, or replaces the condition which has the same type. The last
+// transition time of the existing condition is kept if its status doesn't change.
+func SetCondition(conditions []Condition, newCondition Condition) []Condition {
+	for i := range conditions {
+		if conditions[i].Type != newCondition.Type {
+			continue
+		}
+		if conditions[i].Status == newCondition.Status {
+			newCondition.LastTransitionTime = conditions[i].LastTransitionTime
+		}
+		conditions[i] = newCondition
+		return conditions
+	}
+	return append(conditions, newCondition)
+}
+
+// FindCondition returns the condition which has the given type, or nil if it doesn't exist.
+func FindCondition(conditions []Condition, conditionType ConditionType) *Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// Action indicates what we need to do with current PipelineRun. The controller clears the action once it has been
+// handled.
 type Action string
 
 const (
 	// Stop indicates we need to stop the current PipelineRun.
 	Stop Action = "Stop"
-	// Pause indicates we need to pause the current PipelineRun.
+	// Pause indicates we need to pause the current PipelineRun, which holds the PipelineRun before its next step.
+	// The pending input steps stay held until it's resumed.
 	Pause Action = "Pause"
-	// Resume indicates we need to resume the current PipelineRun.
+	// Resume indicates we need to resume the current PipelineRun, which releases the hold of Pause, and proceeds the
+	// pending input steps with their default parameters.
 	Resume Action = "Resume"
 )
 
@@ -300,6 +621,58 @@ const (
 	TriggerFailed string = "TriggerFailed"
 	// RetrieveFailed indicates that it failed to retrieve the latest running data
 	RetrieveFailed string = "RetrieveFailed"
+	// Stopped indicates PipelineRun has been stopped by action
+	Stopped string = "Stopped"
+	// Paused indicates PipelineRun has been paused by action
+	Paused string = "Paused"
+	// Resumed indicates PipelineRun has been resumed by action
+	Resumed string = "Resumed"
+	// Cancelled indicates PipelineRun has been stopped before it was triggered
+	Cancelled string = "Cancelled"
+	// ActionFailed indicates that it failed to act on the action of PipelineRun
+	ActionFailed string = "ActionFailed"
+	// Retried indicates that a PipelineRun has been created to retry the failed PipelineRun
+	Retried string = "Retried"
+	// Timeout indicates that PipelineRun has been aborted because it ran out of time
+	Timeout string = "Timeout"
+	// Queued indicates that PipelineRun is waiting for other PipelineRuns to complete
+	Queued string = "Queued"
+	// Dequeued indicates that PipelineRun has left the queue and been triggered
+	Dequeued string = "Dequeued"
+	// LogArchived indicates that the logs of PipelineRun have been persisted into the object storage
+	LogArchived string = "LogArchived"
+	// LogArchiveFailed indicates that it failed to persist the logs of PipelineRun
+	LogArchiveFailed string = "LogArchiveFailed"
+	// QualityGateCollected indicates that the results of the code analyses of PipelineRun have been collected
+	QualityGateCollected string = "QualityGateCollected"
+	// QualityGateCollectFailed indicates that it failed to collect the results of the code analyses of PipelineRun
+	QualityGateCollectFailed string = "QualityGateCollectFailed"
+	// QualityGateFailed indicates that the quality gate of the code analyses of PipelineRun failed
+	QualityGateFailed string = "QualityGateFailed"
+	// QualityGatePassed indicates that the quality gate of the code analyses of PipelineRun passed
+	QualityGatePassed string = "QualityGatePassed"
+	// TestReportCollected indicates that the test results of PipelineRun have been collected
+	TestReportCollected string = "TestReportCollected"
+	// TestReportCollectFailed indicates that it failed to collect the test results of PipelineRun
+	TestReportCollectFailed string = "TestReportCollectFailed"
+)
+
+// Valid values for the reasons of condition JenkinsSynced
+const (
+	// Synced indicates that the resource has been synchronized into Jenkins
+	Synced string = "Synced"
+	// SyncFailed indicates that it failed to synchronize the resource into Jenkins
+	SyncFailed string = "SyncFailed"
+	// Drifted indicates that the resource in Jenkins has drifted from the one in Kubernetes
+	Drifted string = "Drifted"
+)
+
+// Valid values for the reasons of condition JobsImported
+const (
+	// Imported indicates that the Jenkins jobs have been imported
+	Imported string = "Imported"
+	// ImportFailed indicates that it failed to import the Jenkins jobs
+	ImportFailed string = "ImportFailed"
 )
 
 func init() {
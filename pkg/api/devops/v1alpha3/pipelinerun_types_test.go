@@ -2,8 +2,10 @@ package v1alpha3
 
 import (
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestPipelineRunSpec_IsMultiBranchPipeline(t *testing.T) {
@@ -53,3 +55,72 @@ func TestPipelineRunSpec_IsMultiBranchPipeline(t *testing.T) {
 		})
 	}
 }
+
+func TestNewJenkinsSyncedCondition(t *testing.T) {
+	condition := NewJenkinsSyncedCondition(ConditionFalse, SyncFailed, "connection refused")
+	if condition.Type != ConditionJenkinsSynced || condition.Status != ConditionFalse || condition.Reason != SyncFailed ||
+		condition.Message != "connection refused" {
+		t.Errorf("unexpected condition: %v", condition)
+	}
+	if condition.LastProbeTime.IsZero() || !condition.LastTransitionTime.Equal(&condition.LastProbeTime) {
+		t.Errorf("unexpected times of condition: %v", condition)
+	}
+}
+
+func TestSetCondition(t *testing.T) {
+	oldTime := metav1.NewTime(time.Now().Add(-time.Hour))
+	newTime := metav1.Now()
+	conditions := []Condition{{
+		Type:               ConditionJenkinsSynced,
+		Status:             ConditionTrue,
+		LastTransitionTime: oldTime,
+	}}
+
+	// the last transition time is kept if the status doesn't change
+	conditions = SetCondition(conditions, Condition{
+		Type:               ConditionJenkinsSynced,
+		Status:             ConditionTrue,
+		Reason:             Synced,
+		LastTransitionTime: newTime,
+	})
+	if len(conditions) != 1 || conditions[0].Reason != Synced || !conditions[0].LastTransitionTime.Equal(&oldTime) {
+		t.Errorf("unexpected conditions: %v", conditions)
+	}
+
+	conditions = SetCondition(conditions, Condition{
+		Type:               ConditionJenkinsSynced,
+		Status:             ConditionFalse,
+		Reason:             SyncFailed,
+		LastTransitionTime: newTime,
+	})
+	if len(conditions) != 1 || conditions[0].Reason != SyncFailed || !conditions[0].LastTransitionTime.Equal(&newTime) {
+		t.Errorf("unexpected conditions: %v", conditions)
+	}
+
+	conditions = SetCondition(conditions, Condition{Type: ConditionReady, Status: ConditionTrue})
+	if len(conditions) != 2 {
+		t.Errorf("unexpected conditions: %v", conditions)
+	}
+	if condition := FindCondition(conditions, ConditionReady); condition == nil || condition.Status != ConditionTrue {
+		t.Errorf("unexpected condition: %v", condition)
+	}
+	if condition := FindCondition(conditions, ConditionQueued); condition != nil {
+		t.Errorf("unexpected condition: %v", condition)
+	}
+}
+
+func TestLogArchive_GetKey(t *testing.T) {
+	logArchive := &LogArchive{
+		Key:   "run.log",
+		Steps: []StepLogArchive{{NodeID: "3", StepID: "4", Key: "step.log"}},
+	}
+	if key, ok := logArchive.GetKey("", ""); !ok || key != "run.log" {
+		t.Errorf("GetKey() = %s, %v, want run.log", key, ok)
+	}
+	if key, ok := logArchive.GetKey("3", "4"); !ok || key != "step.log" {
+		t.Errorf("GetKey() = %s, %v, want step.log", key, ok)
+	}
+	if key, ok := logArchive.GetKey("3", "5"); ok {
+		t.Errorf("GetKey() = %s, want no key", key)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// ResourceKindPipelineRun is the kind of PipelineRun
+const ResourceKindPipelineRun = "PipelineRun"
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of PipelineRun into the manager
+func (pr *PipelineRun) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(pr).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-devops-kubesphere-io-v1alpha3-pipelinerun,mutating=true,failurePolicy=fail,sideEffects=None,groups=devops.kubesphere.io,resources=pipelineruns,verbs=create,versions=v1alpha3,name=mpipelinerun.devops.kubesphere.io,admissionReviewVersions=v1beta1
+
+var _ webhook.Defaulter = &PipelineRun{}
+
+// Default fills the namespace of the Pipeline which the PipelineRun belongs to
+func (pr *PipelineRun) Default() {
+	if ref := pr.Spec.PipelineRef; ref != nil && ref.Namespace == "" {
+		ref.Namespace = pr.Namespace
+	}
+}
+
+// +kubebuilder:webhook:path=/validate-devops-kubesphere-io-v1alpha3-pipelinerun,mutating=false,failurePolicy=fail,sideEffects=None,groups=devops.kubesphere.io,resources=pipelineruns,verbs=create;update,versions=v1alpha3,name=vpipelinerun.devops.kubesphere.io,admissionReviewVersions=v1beta1
+
+var _ webhook.Validator = &PipelineRun{}
+
+// ValidateCreate rejects the PipelineRun which cannot be triggered in Jenkins
+func (pr *PipelineRun) ValidateCreate() error {
+	return pr.toInvalidError(pr.Spec.validate(field.NewPath("spec")))
+}
+
+// ValidateUpdate rejects changing the Pipeline which the PipelineRun belongs to
+func (pr *PipelineRun) ValidateUpdate(old runtime.Object) error {
+	oldPipelineRun, ok := old.(*PipelineRun)
+	if !ok {
+		return nil
+	}
+	var errs field.ErrorList
+	if !equality.Semantic.DeepEqual(oldPipelineRun.Spec.PipelineRef, pr.Spec.PipelineRef) {
+		errs = append(errs, field.Forbidden(field.NewPath("spec", "pipelineRef"), "field is immutable"))
+	}
+	return pr.toInvalidError(errs)
+}
+
+// ValidateDelete allows deleting any PipelineRun
+func (pr *PipelineRun) ValidateDelete() error {
+	return nil
+}
+
+func (pr *PipelineRun) toInvalidError(errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind(ResourceKindPipelineRun).GroupKind(), pr.Name, errs)
+}
+
+func (prSpec *PipelineRunSpec) validate(path *field.Path) (errs field.ErrorList) {
+	if prSpec.PipelineRef == nil {
+		errs = append(errs, field.Required(path.Child("pipelineRef"), ""))
+	} else if prSpec.PipelineRef.Name == "" {
+		errs = append(errs, field.Required(path.Child("pipelineRef", "name"), ""))
+	}
+
+	if prSpec.IsMultiBranchPipeline() {
+		if prSpec.SCM == nil || prSpec.SCM.RefName == "" {
+			errs = append(errs, field.Required(path.Child("scm", "refName"), "required by a multi-branch pipeline"))
+		}
+	}
+	// the reference type might be unknown when the PipelineRun is created
+	if scm := prSpec.SCM; scm != nil {
+		switch scm.RefType {
+		case "", Branch, Tag, PullRequest, MergeRequest:
+		default:
+			errs = append(errs, field.NotSupported(path.Child("scm", "refType"), scm.RefType,
+				[]string{string(Branch), string(Tag), string(PullRequest), string(MergeRequest)}))
+		}
+	}
+
+	if prSpec.Timeout != nil && prSpec.Timeout.Duration < 0 {
+		errs = append(errs, field.Invalid(path.Child("timeout"), prSpec.Timeout.Duration.String(), "must not be negative"))
+	}
+	return
+}
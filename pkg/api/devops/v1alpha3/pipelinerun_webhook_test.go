@@ -0,0 +1,78 @@
+package v1alpha3
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPipelineRun_Default(t *testing.T) {
+	pr := &PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo-abcde", Namespace: "devops-test"},
+		Spec:       PipelineRunSpec{PipelineRef: &v1.ObjectReference{Name: "demo"}},
+	}
+	pr.Default()
+	if pr.Spec.PipelineRef.Namespace != "devops-test" {
+		t.Errorf("Default() namespace of pipelineRef = %v, want %v", pr.Spec.PipelineRef.Namespace, "devops-test")
+	}
+}
+
+func TestPipelineRun_ValidateCreate(t *testing.T) {
+	multiBranchSpec := &PipelineSpec{Type: MultiBranchPipelineType}
+	tests := []struct {
+		name    string
+		spec    PipelineRunSpec
+		wantErr bool
+	}{{
+		name: "Valid PipelineRun",
+		spec: PipelineRunSpec{
+			PipelineRef:  &v1.ObjectReference{Name: "demo"},
+			PipelineSpec: multiBranchSpec,
+			SCM:          &SCM{RefName: "master"},
+		},
+	}, {
+		name:    "Without pipelineRef",
+		spec:    PipelineRunSpec{},
+		wantErr: true,
+	}, {
+		name: "Multi-branch PipelineRun without branch",
+		spec: PipelineRunSpec{
+			PipelineRef:  &v1.ObjectReference{Name: "demo"},
+			PipelineSpec: multiBranchSpec,
+		},
+		wantErr: true,
+	}, {
+		name: "Unknown reference type",
+		spec: PipelineRunSpec{
+			PipelineRef:  &v1.ObjectReference{Name: "demo"},
+			PipelineSpec: multiBranchSpec,
+			SCM:          &SCM{RefName: "master", RefType: "commit"},
+		},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := &PipelineRun{Spec: tt.spec}
+			if err := pr.ValidateCreate(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPipelineRun_ValidateUpdate(t *testing.T) {
+	old := &PipelineRun{Spec: PipelineRunSpec{PipelineRef: &v1.ObjectReference{Name: "demo"}}}
+
+	updated := old.DeepCopy()
+	action := Stop
+	updated.Spec.Action = &action
+	if err := updated.ValidateUpdate(old); err != nil {
+		t.Errorf("ValidateUpdate() error = %v, want nil", err)
+	}
+
+	updated.Spec.PipelineRef.Name = "another"
+	if err := updated.ValidateUpdate(old); err == nil {
+		t.Errorf("ValidateUpdate() error = nil, want an error")
+	}
+}
@@ -23,6 +23,7 @@ package v1alpha3
 
 import (
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -51,6 +52,21 @@ func (in *BitbucketServerSource) DeepCopy() *BitbucketServerSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CodeAnalysis) DeepCopyInto(out *CodeAnalysis) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CodeAnalysis.
+func (in *CodeAnalysis) DeepCopy() *CodeAnalysis {
+	if in == nil {
+		return nil
+	}
+	out := new(CodeAnalysis)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Condition) DeepCopyInto(out *Condition) {
 	*out = *in
@@ -73,8 +89,8 @@ func (in *DevOpsProject) DeepCopyInto(out *DevOpsProject) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevOpsProject.
@@ -130,6 +146,21 @@ func (in *DevOpsProjectList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DevOpsProjectSpec) DeepCopyInto(out *DevOpsProjectSpec) {
 	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]ProjectMember, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceQuota != nil {
+		in, out := &in.ResourceQuota, &out.ResourceQuota
+		*out = new(v1.ResourceQuotaSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LimitRange != nil {
+		in, out := &in.LimitRange, &out.LimitRange
+		*out = new(v1.LimitRangeSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevOpsProjectSpec.
@@ -145,6 +176,18 @@ func (in *DevOpsProjectSpec) DeepCopy() *DevOpsProjectSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DevOpsProjectStatus) DeepCopyInto(out *DevOpsProjectStatus) {
 	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]ProjectMember, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevOpsProjectStatus.
@@ -351,6 +394,31 @@ func (in *GitSource) DeepCopy() *GitSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GiteaSource) DeepCopyInto(out *GiteaSource) {
+	*out = *in
+	if in.DiscoverPRFromForks != nil {
+		in, out := &in.DiscoverPRFromForks, &out.DiscoverPRFromForks
+		*out = new(DiscoverPRFromForks)
+		**out = **in
+	}
+	if in.CloneOption != nil {
+		in, out := &in.CloneOption, &out.CloneOption
+		*out = new(GitCloneOption)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GiteaSource.
+func (in *GiteaSource) DeepCopy() *GiteaSource {
+	if in == nil {
+		return nil
+	}
+	out := new(GiteaSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GithubSource) DeepCopyInto(out *GithubSource) {
 	*out = *in
@@ -401,6 +469,50 @@ func (in *GitlabSource) DeepCopy() *GitlabSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InputStatus) DeepCopyInto(out *InputStatus) {
+	*out = *in
+	if in.Submitters != nil {
+		in, out := &in.Submitters, &out.Submitters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InputStatus.
+func (in *InputStatus) DeepCopy() *InputStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InputStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogArchive) DeepCopyInto(out *LogArchive) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]StepLogArchive, len(*in))
+		copy(*out, *in)
+	}
+	if in.ArchiveTime != nil {
+		in, out := &in.ArchiveTime, &out.ArchiveTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogArchive.
+func (in *LogArchive) DeepCopy() *LogArchive {
+	if in == nil {
+		return nil
+	}
+	out := new(LogArchive)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MultiBranchJobTrigger) DeepCopyInto(out *MultiBranchJobTrigger) {
 	*out = *in
@@ -459,6 +571,11 @@ func (in *MultiBranchPipeline) DeepCopyInto(out *MultiBranchPipeline) {
 		*out = new(BitbucketServerSource)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.GiteaSource != nil {
+		in, out := &in.GiteaSource, &out.GiteaSource
+		*out = new(GiteaSource)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.MultiBranchJobTrigger != nil {
 		in, out := &in.MultiBranchJobTrigger, &out.MultiBranchJobTrigger
 		*out = new(MultiBranchJobTrigger)
@@ -552,7 +669,7 @@ func (in *Pipeline) DeepCopyInto(out *Pipeline) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Pipeline.
@@ -692,6 +809,11 @@ func (in *PipelineRunSpec) DeepCopyInto(out *PipelineRunSpec) {
 		*out = new(Action)
 		**out = **in
 	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineRunSpec.
@@ -726,6 +848,33 @@ func (in *PipelineRunStatus) DeepCopyInto(out *PipelineRunStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Stages != nil {
+		in, out := &in.Stages, &out.Stages
+		*out = make([]StageStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(RetryStatus)
+		**out = **in
+	}
+	if in.LogArchive != nil {
+		in, out := &in.LogArchive, &out.LogArchive
+		*out = new(LogArchive)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.QualityGate != nil {
+		in, out := &in.QualityGate, &out.QualityGate
+		*out = new(QualityGate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TestReport != nil {
+		in, out := &in.TestReport, &out.TestReport
+		*out = new(TestReport)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineRunStatus.
@@ -751,6 +900,21 @@ func (in *PipelineSpec) DeepCopyInto(out *PipelineSpec) {
 		*out = new(MultiBranchPipeline)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(RetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.QualityGate != nil {
+		in, out := &in.QualityGate, &out.QualityGate
+		*out = new(QualityGatePolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineSpec.
@@ -766,6 +930,13 @@ func (in *PipelineSpec) DeepCopy() *PipelineSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineStatus) DeepCopyInto(out *PipelineStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineStatus.
@@ -778,6 +949,60 @@ func (in *PipelineStatus) DeepCopy() *PipelineStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectMember) DeepCopyInto(out *ProjectMember) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectMember.
+func (in *ProjectMember) DeepCopy() *ProjectMember {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QualityGate) DeepCopyInto(out *QualityGate) {
+	*out = *in
+	if in.Analyses != nil {
+		in, out := &in.Analyses, &out.Analyses
+		*out = make([]CodeAnalysis, len(*in))
+		copy(*out, *in)
+	}
+	if in.CollectTime != nil {
+		in, out := &in.CollectTime, &out.CollectTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QualityGate.
+func (in *QualityGate) DeepCopy() *QualityGate {
+	if in == nil {
+		return nil
+	}
+	out := new(QualityGate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QualityGatePolicy) DeepCopyInto(out *QualityGatePolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QualityGatePolicy.
+func (in *QualityGatePolicy) DeepCopy() *QualityGatePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(QualityGatePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RemoteTrigger) DeepCopyInto(out *RemoteTrigger) {
 	*out = *in
@@ -793,6 +1018,46 @@ func (in *RemoteTrigger) DeepCopy() *RemoteTrigger {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryPolicy) DeepCopyInto(out *RetryPolicy) {
+	*out = *in
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryPolicy.
+func (in *RetryPolicy) DeepCopy() *RetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryStatus) DeepCopyInto(out *RetryStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryStatus.
+func (in *RetryStatus) DeepCopy() *RetryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SCM) DeepCopyInto(out *SCM) {
 	*out = *in
@@ -823,6 +1088,71 @@ func (in *SingleSvnSource) DeepCopy() *SingleSvnSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StageStatus) DeepCopyInto(out *StageStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]StepStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StageStatus.
+func (in *StageStatus) DeepCopy() *StageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepLogArchive) DeepCopyInto(out *StepLogArchive) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepLogArchive.
+func (in *StepLogArchive) DeepCopy() *StepLogArchive {
+	if in == nil {
+		return nil
+	}
+	out := new(StepLogArchive)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepStatus) DeepCopyInto(out *StepStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Input != nil {
+		in, out := &in.Input, &out.Input
+		*out = new(InputStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepStatus.
+func (in *StepStatus) DeepCopy() *StepStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StepStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SvnSource) DeepCopyInto(out *SvnSource) {
 	*out = *in
@@ -838,6 +1168,30 @@ func (in *SvnSource) DeepCopy() *SvnSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TestReport) DeepCopyInto(out *TestReport) {
+	*out = *in
+	if in.FailedCases != nil {
+		in, out := &in.FailedCases, &out.FailedCases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CollectTime != nil {
+		in, out := &in.CollectTime, &out.CollectTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TestReport.
+func (in *TestReport) DeepCopy() *TestReport {
+	if in == nil {
+		return nil
+	}
+	out := new(TestReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TimerTrigger) DeepCopyInto(out *TimerTrigger) {
 	*out = *in
@@ -24,10 +24,15 @@ import (
 	"k8s.io/apiserver/pkg/authentication/authenticator"
 	"k8s.io/apiserver/pkg/authentication/request/bearertoken"
 	unionauth "k8s.io/apiserver/pkg/authentication/request/union"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
 	"kubesphere.io/devops/pkg/api/devops/v1alpha1"
 	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/apiserver/auditing"
 	devopsbearertoken "kubesphere.io/devops/pkg/apiserver/authentication/authenticators/bearertoken"
 	"kubesphere.io/devops/pkg/apiserver/authentication/request/anonymous"
+	authorizationoptions "kubesphere.io/devops/pkg/apiserver/authorization/options"
+	"kubesphere.io/devops/pkg/apiserver/authorization/rbac"
+	"kubesphere.io/devops/pkg/apiserver/authorization/subjectaccessreview"
 	"kubesphere.io/devops/pkg/apiserver/filters"
 	"kubesphere.io/devops/pkg/apiserver/request"
 	"kubesphere.io/devops/pkg/kapis/oauth"
@@ -100,6 +105,9 @@ type APIServer struct {
 	RuntimeCache runtimecache.Cache
 
 	Client client.Client
+
+	// Auditing records the mutating requests, it's nil if auditing is disabled
+	Auditing auditing.Auditing
 }
 
 func (s *APIServer) PrepareRun(stopCh <-chan struct{}) error {
@@ -141,8 +149,10 @@ func (s *APIServer) installKubeSphereAPIs() {
 		s.S3Client,
 		s.Config.JenkinsOptions.Host,
 		s.KubernetesClient,
-		jenkinsCore))
-	devopsv1alpha3.AddToContainer(s.container, s.DevopsClient, s.KubernetesClient, s.Client)
+		jenkinsCore,
+		s.Client))
+	devopsv1alpha3.AddToContainer(s.container, s.DevopsClient, s.KubernetesClient, s.Client, s.RuntimeCache, s.S3Client,
+		s.Config.JenkinsOptions.EventToken, s.Config.JenkinsOptions.AllowUnsignedSCMEvents)
 	utilruntime.Must(oauth.AddToContainer(s.container,
 		auth.NewTokenOperator(
 			s.CacheClient,
@@ -184,6 +194,12 @@ func (s *APIServer) buildHandlerChain(stopCh <-chan struct{}) {
 	handler := s.Server.Handler
 	handler = filters.WithKubeAPIServer(handler, s.KubernetesClient.Config(), &errorResponder{})
 
+	if s.Config.AuthorizationOptions != nil {
+		handler = filters.WithAuthorization(handler, s.buildAuthorizer(), s.Config.AuthorizationOptions.AlwaysAllowPaths)
+	}
+
+	handler = filters.WithAuditing(handler, s.Auditing)
+
 	authenticators := make([]authenticator.Request, 0)
 	authenticators = append(authenticators, anonymous.NewAuthenticator())
 
@@ -200,6 +216,21 @@ func (s *APIServer) buildHandlerChain(stopCh <-chan struct{}) {
 	s.Server.Handler = handler
 }
 
+// buildAuthorizer returns nil if all requests are allowed
+func (s *APIServer) buildAuthorizer() authorizer.Authorizer {
+	switch s.Config.AuthorizationOptions.Mode {
+	case authorizationoptions.SubjectAccessReview:
+		return subjectaccessreview.NewAuthorizer(s.KubernetesClient.Kubernetes())
+	case authorizationoptions.RBAC:
+		// the informers are registered here, and they are started in waitForResourceSync
+		rbacInformers := s.InformerFactory.KubernetesSharedInformerFactory().Rbac().V1()
+		return rbac.NewAuthorizer(rbacInformers.Roles().Lister(), rbacInformers.RoleBindings().Lister(),
+			rbacInformers.ClusterRoles().Lister(), rbacInformers.ClusterRoleBindings().Lister())
+	default:
+		return nil
+	}
+}
+
 func (s *APIServer) waitForResourceSync(stopCh <-chan struct{}) error {
 	klog.V(0).Info("Start cache objects")
 
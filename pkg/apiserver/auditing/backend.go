@@ -0,0 +1,188 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+
+	"kubesphere.io/devops/pkg/apiserver/auditing/options"
+)
+
+const (
+	// eventCacheSize is the max number of events waiting for sending, the new events are dropped if it's full
+	eventCacheSize = 10000
+	// webhookTimeout is the timeout of sending a batch of events to the webhook
+	webhookTimeout = 10 * time.Second
+)
+
+// Sink is the destination of the audit events
+type Sink interface {
+	Write(events []*Event) error
+}
+
+// Backend sends the events to the sinks in batches
+type Backend struct {
+	sinks         []Sink
+	eventCache    chan *Event
+	batchSize     int
+	batchInterval time.Duration
+}
+
+// NewBackend creates the sinks from the options, the backend starts to work after Run is called
+func NewBackend(opts *options.Options) (*Backend, error) {
+	b := &Backend{
+		eventCache:    make(chan *Event, eventCacheSize),
+		batchSize:     opts.EventBatchSize,
+		batchInterval: opts.EventBatchInterval,
+	}
+	if opts.LogPath != "" {
+		sink, err := NewFileSink(opts.LogPath)
+		if err != nil {
+			return nil, err
+		}
+		b.sinks = append(b.sinks, sink)
+	}
+	if opts.WebhookURL != "" {
+		b.sinks = append(b.sinks, NewWebhookSink(opts.WebhookURL))
+	}
+	return b, nil
+}
+
+// Process puts the event into the cache without blocking the request
+func (b *Backend) Process(e *Event) {
+	select {
+	case b.eventCache <- e:
+	default:
+		klog.Warningf("the audit event cache is full, drop the event %s of %s", e.AuditID, e.RequestURI)
+	}
+}
+
+// Run sends the events until the stopCh is closed, the events in the cache are sent before returning
+func (b *Backend) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(b.batchInterval)
+	defer ticker.Stop()
+
+	events := make([]*Event, 0, b.batchSize)
+	flush := func() {
+		if len(events) == 0 {
+			return
+		}
+		b.send(events)
+		events = make([]*Event, 0, b.batchSize)
+	}
+
+	for {
+		select {
+		case e := <-b.eventCache:
+			events = append(events, e)
+			if len(events) >= b.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-stopCh:
+			for len(b.eventCache) > 0 {
+				events = append(events, <-b.eventCache)
+			}
+			flush()
+			return
+		}
+	}
+}
+
+func (b *Backend) send(events []*Event) {
+	for _, sink := range b.sinks {
+		if err := sink.Write(events); err != nil {
+			klog.Errorf("failed to send %d audit events, error %v", len(events), err)
+		}
+	}
+}
+
+// fileSink appends the events to a file as JSON lines
+type fileSink struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+// NewFileSink creates a sink which appends the events to the file, "-" means the standard output
+func NewFileSink(path string) (Sink, error) {
+	if path == "-" {
+		return &fileSink{writer: os.Stdout}, nil
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the audit log file %s, error %v", path, err)
+	}
+	return &fileSink{writer: file}, nil
+}
+
+func (s *fileSink) Write(events []*Event) error {
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	for _, e := range events {
+		if err := encoder.Encode(e); err != nil {
+			return err
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err := s.writer.Write(buffer.Bytes())
+	return err
+}
+
+// webhookSink posts the events to a webhook as an EventList
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink creates a sink which posts the events to the url
+func NewWebhookSink(url string) Sink {
+	return &webhookSink{
+		url:    url,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+func (s *webhookSink) Write(events []*Event) error {
+	data, err := json.Marshal(&EventList{Items: events})
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("the audit webhook %s responded with status code %d", s.url, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+)
+
+type Options struct {
+	Enable bool `json:"enable" yaml:"enable"`
+	// Level is the amount of information recorded for each request, one of Metadata, Request and RequestResponse.
+	// The bodies of credentials are never recorded.
+	Level string `json:"level" yaml:"level"`
+	// Verbs are the verbs of the requests to audit, e.g. create, update, patch and delete
+	Verbs []string `json:"verbs" yaml:"verbs"`
+	// Resources limits the resources to audit, all resources are audited if it's empty
+	Resources []string `json:"resources,omitempty" yaml:"resources,omitempty"`
+	// LogPath is the file which the events are appended to, "-" means the standard output
+	LogPath string `json:"logPath,omitempty" yaml:"logPath,omitempty"`
+	// WebhookURL is the address which the events are sent to in batches
+	WebhookURL         string        `json:"webhookURL,omitempty" yaml:"webhookURL,omitempty"`
+	EventBatchSize     int           `json:"eventBatchSize" yaml:"eventBatchSize"`
+	EventBatchInterval time.Duration `json:"eventBatchInterval" yaml:"eventBatchInterval"`
+}
+
+func NewAuditingOptions() *Options {
+	return &Options{
+		Enable:             false,
+		Level:              string(auditv1.LevelMetadata),
+		Verbs:              []string{"create", "update", "patch", "delete", "deletecollection"},
+		EventBatchSize:     100,
+		EventBatchInterval: 3 * time.Second,
+	}
+}
+
+func (s *Options) Validate() []error {
+	var errs []error
+	if !s.Enable {
+		return errs
+	}
+
+	switch auditv1.Level(s.Level) {
+	case auditv1.LevelMetadata, auditv1.LevelRequest, auditv1.LevelRequestResponse:
+	default:
+		errs = append(errs, fmt.Errorf("auditing level %s is not supported, it should be one of %s, %s and %s",
+			s.Level, auditv1.LevelMetadata, auditv1.LevelRequest, auditv1.LevelRequestResponse))
+	}
+	if s.LogPath == "" && s.WebhookURL == "" {
+		errs = append(errs, fmt.Errorf("auditing is enabled, but neither the log path nor the webhook url is set"))
+	}
+	if s.EventBatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("auditing event batch size should be greater than 0"))
+	}
+	if s.EventBatchInterval <= 0 {
+		errs = append(errs, fmt.Errorf("auditing event batch interval should be greater than 0"))
+	}
+	return errs
+}
+
+func (s *Options) AddFlags(fs *pflag.FlagSet, c *Options) {
+	fs.BoolVar(&s.Enable, "auditing-enabled", c.Enable, "Enable auditing of the mutating requests.")
+	fs.StringVar(&s.Level, "auditing-level", c.Level, "The amount of information recorded for each request, "+
+		"one of Metadata, Request and RequestResponse.")
+	fs.StringSliceVar(&s.Verbs, "auditing-verbs", c.Verbs, "The verbs of the requests to audit.")
+	fs.StringSliceVar(&s.Resources, "auditing-resources", c.Resources, "The resources to audit, "+
+		"all resources are audited if it's empty.")
+	fs.StringVar(&s.LogPath, "auditing-log-path", c.LogPath, "The file which the audit events are appended to, "+
+		"'-' means the standard output.")
+	fs.StringVar(&s.WebhookURL, "auditing-webhook-url", c.WebhookURL, "The address which the audit events are sent to.")
+	fs.IntVar(&s.EventBatchSize, "auditing-event-batch-size", c.EventBatchSize, "The max number of events in a batch.")
+	fs.DurationVar(&s.EventBatchInterval, "auditing-event-batch-interval", c.EventBatchInterval,
+		"The interval of sending the events which are not enough for a batch.")
+}
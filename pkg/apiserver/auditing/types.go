@@ -0,0 +1,218 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditing
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apiserver/pkg/apis/audit"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+	"k8s.io/klog"
+
+	"kubesphere.io/devops/pkg/apiserver/auditing/options"
+	"kubesphere.io/devops/pkg/apiserver/request"
+)
+
+// maxBodySize is the max size of the request or response body recorded in an event
+const maxBodySize = 64 * 1024
+
+// sensitiveResources are the resources whose bodies are never recorded
+var sensitiveResources = sets.NewString("credentials", "secrets")
+
+// Event is an audit event of the DevOps apiserver. It's the same as the audit event of Kubernetes except
+// the DevOps project and the latency of the request.
+type Event struct {
+	auditv1.Event `json:",inline"`
+	// DevOps is the DevOps project of the requested resource
+	DevOps string `json:"devops,omitempty"`
+	// Latency is the time spent on handling the request
+	Latency metav1.Duration `json:"latency"`
+}
+
+// EventList is a batch of events sent to the webhook
+type EventList struct {
+	Items []*Event `json:"items"`
+}
+
+// Auditing records the requests matching the policy
+type Auditing interface {
+	// Enabled indicates if the request should be audited
+	Enabled(info *request.RequestInfo) bool
+	// NeedResponseBody indicates if the response body should be recorded
+	NeedResponseBody(info *request.RequestInfo) bool
+	// LogRequestObject creates an event for the request
+	LogRequestObject(req *http.Request, info *request.RequestInfo) *Event
+	// LogResponseObject completes the event with the response and sends it to the sinks
+	LogResponseObject(e *Event, statusCode int, body []byte)
+}
+
+type auditing struct {
+	level     audit.Level
+	verbs     sets.String
+	resources sets.String
+	backend   *Backend
+}
+
+// NewAuditing creates an Auditing which sends the events to the backend
+func NewAuditing(opts *options.Options, backend *Backend) Auditing {
+	return &auditing{
+		level:     audit.Level(opts.Level),
+		verbs:     sets.NewString(opts.Verbs...),
+		resources: sets.NewString(opts.Resources...),
+		backend:   backend,
+	}
+}
+
+func (a *auditing) Enabled(info *request.RequestInfo) bool {
+	if info == nil || a.level == audit.LevelNone {
+		return false
+	}
+	if !a.verbs.Has(getVerb(info)) {
+		return false
+	}
+	return a.resources.Len() == 0 || a.resources.Has(info.Resource)
+}
+
+func (a *auditing) NeedResponseBody(info *request.RequestInfo) bool {
+	return a.level.GreaterOrEqual(audit.LevelRequestResponse) && !sensitiveResources.Has(info.Resource)
+}
+
+func (a *auditing) LogRequestObject(req *http.Request, info *request.RequestInfo) *Event {
+	now := time.Now()
+	e := &Event{
+		Event: auditv1.Event{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Event",
+				APIVersion: auditv1.SchemeGroupVersion.String(),
+			},
+			Level:                    auditv1.Level(a.level),
+			AuditID:                  uuid.NewUUID(),
+			Stage:                    auditv1.StageResponseComplete,
+			RequestURI:               req.URL.String(),
+			Verb:                     getVerb(info),
+			SourceIPs:                []string{info.SourceIP},
+			UserAgent:                info.UserAgent,
+			RequestReceivedTimestamp: metav1.NewMicroTime(now),
+		},
+		DevOps: info.DevOps,
+	}
+
+	if requester, ok := request.UserFrom(req.Context()); ok {
+		e.User = authenticationv1.UserInfo{
+			Username: requester.GetName(),
+			UID:      requester.GetUID(),
+			Groups:   requester.GetGroups(),
+		}
+		if extra := requester.GetExtra(); len(extra) > 0 {
+			e.User.Extra = make(map[string]authenticationv1.ExtraValue, len(extra))
+			for key, values := range extra {
+				e.User.Extra[key] = values
+			}
+		}
+	}
+
+	if info.IsResourceRequest {
+		namespace := info.Namespace
+		if info.DevOps != "" {
+			namespace = info.DevOps
+		}
+		e.ObjectRef = &auditv1.ObjectReference{
+			Resource:    info.Resource,
+			Namespace:   namespace,
+			Name:        info.Name,
+			APIGroup:    info.APIGroup,
+			APIVersion:  info.APIVersion,
+			Subresource: info.Subresource,
+		}
+	}
+
+	if a.level.GreaterOrEqual(audit.LevelRequest) && !sensitiveResources.Has(info.Resource) && req.Body != nil {
+		body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxBodySize))
+		if err != nil {
+			klog.Errorf("failed to read the request body of %s, error %v", req.URL, err)
+		}
+		// the handler still needs to read the whole body
+		req.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), req.Body))
+		e.RequestObject = toRawObject(body)
+	}
+	return e
+}
+
+func (a *auditing) LogResponseObject(e *Event, statusCode int, body []byte) {
+	now := time.Now()
+	e.StageTimestamp = metav1.NewMicroTime(now)
+	e.Latency = metav1.Duration{Duration: now.Sub(e.RequestReceivedTimestamp.Time)}
+
+	e.ResponseStatus = &metav1.Status{Code: int32(statusCode)}
+	if statusCode < http.StatusBadRequest {
+		e.ResponseStatus.Status = metav1.StatusSuccess
+	} else {
+		e.ResponseStatus.Status = metav1.StatusFailure
+		// the errors of Kubernetes are Status objects, others are plain text
+		status := &metav1.Status{}
+		if err := json.Unmarshal(body, status); err == nil && status.Message != "" {
+			e.ResponseStatus.Message = status.Message
+			e.ResponseStatus.Reason = status.Reason
+		} else {
+			e.ResponseStatus.Message = string(body)
+		}
+	}
+
+	if a.level.GreaterOrEqual(audit.LevelRequestResponse) && statusCode < http.StatusBadRequest {
+		e.ResponseObject = toRawObject(body)
+	}
+	a.backend.Process(e)
+}
+
+// getVerb returns the verb of the request, the HTTP method is converted for non-resource requests
+func getVerb(info *request.RequestInfo) string {
+	if info.IsResourceRequest {
+		return info.Verb
+	}
+	switch info.Verb {
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut:
+		return "update"
+	case http.MethodPatch:
+		return "patch"
+	case http.MethodDelete:
+		return "delete"
+	case http.MethodGet, http.MethodHead:
+		return "get"
+	default:
+		return info.Verb
+	}
+}
+
+// toRawObject keeps the body only if it's a JSON object, the truncated body cannot be encoded as a part of the event
+func toRawObject(body []byte) *runtime.Unknown {
+	if len(body) == 0 || !json.Valid(body) {
+		return nil
+	}
+	return &runtime.Unknown{Raw: body, ContentType: runtime.ContentTypeJSON}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditing
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"kubesphere.io/devops/pkg/apiserver/auditing/options"
+	"kubesphere.io/devops/pkg/apiserver/request"
+)
+
+type fakeSink struct {
+	mutex  sync.Mutex
+	events []*Event
+}
+
+func (s *fakeSink) Write(events []*Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func (s *fakeSink) len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.events)
+}
+
+func newTestRequest(t *testing.T, method, url, body string) (*http.Request, *request.RequestInfo) {
+	requestInfoResolver := &request.RequestInfoFactory{
+		APIPrefixes:          sets.NewString("api", "apis", "kapis", "kapi"),
+		GrouplessAPIPrefixes: sets.NewString("api", "kapi"),
+	}
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	info, err := requestInfoResolver.NewRequestInfo(req)
+	assert.Nil(t, err)
+	ctx := request.WithUser(req.Context(), &user.DefaultInfo{Name: "alice", Groups: []string{"developers"}})
+	return req.WithContext(ctx), info
+}
+
+func TestAuditing_Enabled(t *testing.T) {
+	opts := options.NewAuditingOptions()
+	a := NewAuditing(opts, nil)
+
+	_, info := newTestRequest(t, http.MethodPost, "/kapis/devops.kubesphere.io/v1alpha3/devops/devops-a/pipelines/demo/runs", "")
+	assert.True(t, a.Enabled(info))
+	_, info = newTestRequest(t, http.MethodGet, "/kapis/devops.kubesphere.io/v1alpha3/devops/devops-a/pipelines/demo", "")
+	assert.False(t, a.Enabled(info))
+	// non-resource requests are audited by the verbs converted from the methods
+	_, info = newTestRequest(t, http.MethodPost, "/v1alpha3/webhooks/scm", "")
+	assert.True(t, a.Enabled(info))
+
+	opts.Resources = []string{"credentials"}
+	a = NewAuditing(opts, nil)
+	_, info = newTestRequest(t, http.MethodPost, "/kapis/devops.kubesphere.io/v1alpha3/devops/devops-a/pipelines/demo/runs", "")
+	assert.False(t, a.Enabled(info))
+	_, info = newTestRequest(t, http.MethodDelete, "/kapis/devops.kubesphere.io/v1alpha3/devops/devops-a/credentials/github", "")
+	assert.True(t, a.Enabled(info))
+}
+
+func TestAuditing_LogRequestObject(t *testing.T) {
+	opts := options.NewAuditingOptions()
+	opts.Level = string(auditv1.LevelRequestResponse)
+	sink := &fakeSink{}
+	backend := &Backend{sinks: []Sink{sink}, eventCache: make(chan *Event, 10), batchSize: 1, batchInterval: time.Second}
+	a := NewAuditing(opts, backend)
+
+	body := `{"metadata":{"name":"demo"}}`
+	req, info := newTestRequest(t, http.MethodPut, "/kapis/devops.kubesphere.io/v1alpha3/devops/devops-a/pipelines/demo", body)
+	e := a.LogRequestObject(req, info)
+
+	// the body is still readable by the handler
+	data, err := ioutil.ReadAll(req.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, body, string(data))
+
+	assert.Equal(t, "update", e.Verb)
+	assert.Equal(t, "alice", e.User.Username)
+	assert.Equal(t, "devops-a", e.DevOps)
+	assert.Equal(t, &auditv1.ObjectReference{
+		Resource:   "pipelines",
+		Namespace:  "devops-a",
+		Name:       "demo",
+		APIGroup:   "devops.kubesphere.io",
+		APIVersion: "v1alpha3",
+	}, e.ObjectRef)
+	assert.Equal(t, body, string(e.RequestObject.Raw))
+
+	a.LogResponseObject(e, http.StatusOK, []byte(body))
+	assert.Equal(t, int32(http.StatusOK), e.ResponseStatus.Code)
+	assert.Equal(t, metav1.StatusSuccess, e.ResponseStatus.Status)
+	assert.Equal(t, body, string(e.ResponseObject.Raw))
+	assert.Equal(t, 1, len(backend.eventCache))
+}
+
+func TestAuditing_LogCredentialRequest(t *testing.T) {
+	opts := options.NewAuditingOptions()
+	opts.Level = string(auditv1.LevelRequestResponse)
+	backend := &Backend{eventCache: make(chan *Event, 10)}
+	a := NewAuditing(opts, backend)
+
+	req, info := newTestRequest(t, http.MethodPost, "/kapis/devops.kubesphere.io/v1alpha3/devops/devops-a/credentials",
+		`{"data":{"password":"c2VjcmV0"}}`)
+	e := a.LogRequestObject(req, info)
+	assert.Nil(t, e.RequestObject)
+	assert.False(t, a.NeedResponseBody(info))
+
+	a.LogResponseObject(e, http.StatusForbidden, []byte("forbidden"))
+	assert.Equal(t, metav1.StatusFailure, e.ResponseStatus.Status)
+	assert.Equal(t, "forbidden", e.ResponseStatus.Message)
+	assert.Nil(t, e.ResponseObject)
+}
+
+func TestBackend_Run(t *testing.T) {
+	sink := &fakeSink{}
+	backend := &Backend{sinks: []Sink{sink}, eventCache: make(chan *Event, 10), batchSize: 2, batchInterval: time.Hour}
+	stopCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		backend.Run(stopCh)
+		close(done)
+	}()
+
+	for i := 0; i < 3; i++ {
+		backend.Process(&Event{})
+	}
+	assert.Eventually(t, func() bool {
+		return sink.len() == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// the rest events are sent when the backend stops
+	close(stopCh)
+	<-done
+	assert.Equal(t, 3, sink.len())
+}
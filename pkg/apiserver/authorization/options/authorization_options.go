@@ -0,0 +1,73 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	// AlwaysAllow allows all requests of the authenticated users
+	AlwaysAllow = "AlwaysAllow"
+	// SubjectAccessReview asks Kubernetes whether the user is allowed through SubjectAccessReview
+	SubjectAccessReview = "SubjectAccessReview"
+	// RBAC evaluates the Roles, ClusterRoles and their bindings in the cache
+	RBAC = "RBAC"
+)
+
+type AuthorizationOptions struct {
+	// Mode is the way to authorize requests, one of AlwaysAllow, SubjectAccessReview and RBAC.
+	// The DevOps project is the namespace of the requested resource, and credentials are authorized as secrets.
+	Mode string `json:"mode" yaml:"mode"`
+	// AlwaysAllowPaths are the paths which are allowed without authorization, e.g. the webhooks of SCM and Jenkins,
+	// which verify the signature or token of requests by themselves.
+	// A path ending with "*" matches all paths with the prefix.
+	AlwaysAllowPaths []string `json:"alwaysAllowPaths" yaml:"alwaysAllowPaths"`
+}
+
+func NewAuthorizationOptions() *AuthorizationOptions {
+	return &AuthorizationOptions{
+		Mode: AlwaysAllow,
+		AlwaysAllowPaths: []string{
+			"/oauth/*",
+			"/kapis/devops.kubesphere.io/v1alpha2/webhook/*",
+			"/kapis/devops.kubesphere.io/v1alpha3/webhooks/*",
+			"/v1alpha2/webhook/*",
+			"/v1alpha3/webhooks/*",
+		},
+	}
+}
+
+func (options *AuthorizationOptions) Validate() []error {
+	var errs []error
+	switch options.Mode {
+	case AlwaysAllow, SubjectAccessReview, RBAC:
+	default:
+		errs = append(errs, fmt.Errorf("authorization mode %s is not supported, it should be one of %s, %s and %s",
+			options.Mode, AlwaysAllow, SubjectAccessReview, RBAC))
+	}
+	return errs
+}
+
+func (options *AuthorizationOptions) AddFlags(fs *pflag.FlagSet, s *AuthorizationOptions) {
+	fs.StringVar(&options.Mode, "authorization-mode", s.Mode, "The way to authorize requests, one of "+
+		"AlwaysAllow, SubjectAccessReview and RBAC.")
+	fs.StringSliceVar(&options.AlwaysAllowPaths, "authorization-always-allow-paths", s.AlwaysAllowPaths, "The paths "+
+		"which are allowed without authorization, a path ending with '*' matches all paths with the prefix.")
+}
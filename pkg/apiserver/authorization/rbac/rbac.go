@@ -0,0 +1,198 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	rbaclisters "k8s.io/client-go/listers/rbac/v1"
+	"k8s.io/klog"
+)
+
+// Authorizer evaluates the Roles, ClusterRoles and their bindings in the cache. The rules are the same as the RBAC
+// of Kubernetes, but only the granted permissions are evaluated, so a request is either allowed or not decided.
+type Authorizer struct {
+	roleLister               rbaclisters.RoleLister
+	roleBindingLister        rbaclisters.RoleBindingLister
+	clusterRoleLister        rbaclisters.ClusterRoleLister
+	clusterRoleBindingLister rbaclisters.ClusterRoleBindingLister
+}
+
+// NewAuthorizer creates an authorizer based on the RBAC resources in the cache
+func NewAuthorizer(roleLister rbaclisters.RoleLister, roleBindingLister rbaclisters.RoleBindingLister,
+	clusterRoleLister rbaclisters.ClusterRoleLister, clusterRoleBindingLister rbaclisters.ClusterRoleBindingLister) *Authorizer {
+	return &Authorizer{
+		roleLister:               roleLister,
+		roleBindingLister:        roleBindingLister,
+		clusterRoleLister:        clusterRoleLister,
+		clusterRoleBindingLister: clusterRoleBindingLister,
+	}
+}
+
+// Authorize allows the request if any rule bound to the user grants it
+func (a *Authorizer) Authorize(ctx context.Context, attrs authorizer.Attributes) (authorizer.Decision, string, error) {
+	requester := attrs.GetUser()
+	if requester == nil {
+		return authorizer.DecisionNoOpinion, "no user found in the request", nil
+	}
+
+	clusterRoleBindings, err := a.clusterRoleBindingLister.List(labels.Everything())
+	if err != nil {
+		return authorizer.DecisionNoOpinion, "", err
+	}
+	for _, binding := range clusterRoleBindings {
+		if !appliesTo(requester, binding.Subjects, "") {
+			continue
+		}
+		if a.allows(attrs, binding.RoleRef, "") {
+			return authorizer.DecisionAllow, fmt.Sprintf("allowed by ClusterRoleBinding %q", binding.Name), nil
+		}
+	}
+
+	// the rules in a namespace only grant the resources in the namespace
+	namespace := attrs.GetNamespace()
+	if !attrs.IsResourceRequest() || namespace == "" {
+		return authorizer.DecisionNoOpinion, "", nil
+	}
+	roleBindings, err := a.roleBindingLister.RoleBindings(namespace).List(labels.Everything())
+	if err != nil {
+		return authorizer.DecisionNoOpinion, "", err
+	}
+	for _, binding := range roleBindings {
+		if !appliesTo(requester, binding.Subjects, namespace) {
+			continue
+		}
+		if a.allows(attrs, binding.RoleRef, namespace) {
+			return authorizer.DecisionAllow, fmt.Sprintf("allowed by RoleBinding %q in namespace %q", binding.Name, namespace), nil
+		}
+	}
+	return authorizer.DecisionNoOpinion, "", nil
+}
+
+// allows indicates if the rules of the referred role grant the request
+func (a *Authorizer) allows(attrs authorizer.Attributes, roleRef rbacv1.RoleRef, namespace string) bool {
+	var rules []rbacv1.PolicyRule
+	switch roleRef.Kind {
+	case "ClusterRole":
+		role, err := a.clusterRoleLister.Get(roleRef.Name)
+		if err != nil {
+			klog.V(4).Infof("failed to get ClusterRole %s, error %v", roleRef.Name, err)
+			return false
+		}
+		rules = role.Rules
+	case "Role":
+		if namespace == "" {
+			return false
+		}
+		role, err := a.roleLister.Roles(namespace).Get(roleRef.Name)
+		if err != nil {
+			klog.V(4).Infof("failed to get Role %s/%s, error %v", namespace, roleRef.Name, err)
+			return false
+		}
+		rules = role.Rules
+	default:
+		return false
+	}
+
+	for i := range rules {
+		if ruleAllows(attrs, &rules[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// appliesTo indicates if the user is one of the subjects
+func appliesTo(requester user.Info, subjects []rbacv1.Subject, namespace string) bool {
+	for _, subject := range subjects {
+		switch subject.Kind {
+		case rbacv1.UserKind:
+			if requester.GetName() == subject.Name {
+				return true
+			}
+		case rbacv1.GroupKind:
+			for _, group := range requester.GetGroups() {
+				if group == subject.Name {
+					return true
+				}
+			}
+		case rbacv1.ServiceAccountKind:
+			saNamespace := subject.Namespace
+			if saNamespace == "" {
+				saNamespace = namespace
+			}
+			if saNamespace != "" && requester.GetName() == serviceaccount.MakeUsername(saNamespace, subject.Name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ruleAllows indicates if the rule grants the request
+func ruleAllows(attrs authorizer.Attributes, rule *rbacv1.PolicyRule) bool {
+	if !matches(rule.Verbs, attrs.GetVerb()) {
+		return false
+	}
+
+	if !attrs.IsResourceRequest() {
+		for _, url := range rule.NonResourceURLs {
+			if url == rbacv1.NonResourceAll || url == attrs.GetPath() ||
+				(strings.HasSuffix(url, "*") && strings.HasPrefix(attrs.GetPath(), strings.TrimSuffix(url, "*"))) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !matches(rule.APIGroups, attrs.GetAPIGroup()) {
+		return false
+	}
+	resource := attrs.GetResource()
+	if attrs.GetSubresource() != "" {
+		resource = resource + "/" + attrs.GetSubresource()
+	}
+	resourceMatched := false
+	for _, item := range rule.Resources {
+		if item == rbacv1.ResourceAll || item == resource ||
+			(attrs.GetSubresource() != "" && item == "*/"+attrs.GetSubresource()) {
+			resourceMatched = true
+			break
+		}
+	}
+	if !resourceMatched {
+		return false
+	}
+	return len(rule.ResourceNames) == 0 || (attrs.GetName() != "" && matches(rule.ResourceNames, attrs.GetName()))
+}
+
+// matches indicates if the items contain the value or the wildcard
+func matches(items []string, value string) bool {
+	for _, item := range items {
+		if item == "*" || item == value {
+			return true
+		}
+	}
+	return false
+}
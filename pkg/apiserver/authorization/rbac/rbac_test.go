@@ -0,0 +1,214 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbac
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/client-go/informers"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestAuthorizer(t *testing.T) *Authorizer {
+	informerFactory := informers.NewSharedInformerFactory(k8sfake.NewSimpleClientset(), 0)
+	rbacInformers := informerFactory.Rbac().V1()
+
+	objects := []interface{}{
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "devops-viewer"},
+			Rules: []rbacv1.PolicyRule{{
+				Verbs:     []string{"get", "list"},
+				APIGroups: []string{"devops.kubesphere.io"},
+				Resources: []string{"devopsprojects"},
+			}},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "devops-viewers"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "viewers"}},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "devops-viewer"},
+		},
+		&rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "operator", Namespace: "devops-a"},
+			Rules: []rbacv1.PolicyRule{{
+				Verbs:     []string{"*"},
+				APIGroups: []string{"devops.kubesphere.io"},
+				Resources: []string{"pipelines", "pipelines/runs"},
+			}, {
+				Verbs:         []string{"get"},
+				APIGroups:     []string{""},
+				Resources:     []string{"secrets"},
+				ResourceNames: []string{"github"},
+			}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "operators", Namespace: "devops-a"},
+			Subjects: []rbacv1.Subject{
+				{Kind: rbacv1.UserKind, Name: "alice"},
+				{Kind: rbacv1.ServiceAccountKind, Name: "robot"},
+			},
+			RoleRef: rbacv1.RoleRef{Kind: "Role", Name: "operator"},
+		},
+	}
+	for _, obj := range objects {
+		var err error
+		switch item := obj.(type) {
+		case *rbacv1.ClusterRole:
+			err = rbacInformers.ClusterRoles().Informer().GetIndexer().Add(item)
+		case *rbacv1.ClusterRoleBinding:
+			err = rbacInformers.ClusterRoleBindings().Informer().GetIndexer().Add(item)
+		case *rbacv1.Role:
+			err = rbacInformers.Roles().Informer().GetIndexer().Add(item)
+		case *rbacv1.RoleBinding:
+			err = rbacInformers.RoleBindings().Informer().GetIndexer().Add(item)
+		}
+		assert.Nil(t, err)
+	}
+	return NewAuthorizer(rbacInformers.Roles().Lister(), rbacInformers.RoleBindings().Lister(),
+		rbacInformers.ClusterRoles().Lister(), rbacInformers.ClusterRoleBindings().Lister())
+}
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	alice := &user.DefaultInfo{Name: "alice"}
+	tests := []struct {
+		name  string
+		attrs authorizer.AttributesRecord
+		want  authorizer.Decision
+	}{{
+		name: "list DevOps projects by a group",
+		attrs: authorizer.AttributesRecord{
+			User:            &user.DefaultInfo{Name: "bob", Groups: []string{"viewers"}},
+			Verb:            "list",
+			APIGroup:        "devops.kubesphere.io",
+			Resource:        "devopsprojects",
+			ResourceRequest: true,
+		},
+		want: authorizer.DecisionAllow,
+	}, {
+		name: "delete a DevOps project without permission",
+		attrs: authorizer.AttributesRecord{
+			User:            &user.DefaultInfo{Name: "bob", Groups: []string{"viewers"}},
+			Verb:            "delete",
+			APIGroup:        "devops.kubesphere.io",
+			Resource:        "devopsprojects",
+			Name:            "devops-a",
+			ResourceRequest: true,
+		},
+		want: authorizer.DecisionNoOpinion,
+	}, {
+		name: "run a pipeline in the bound namespace",
+		attrs: authorizer.AttributesRecord{
+			User:            alice,
+			Verb:            "create",
+			Namespace:       "devops-a",
+			APIGroup:        "devops.kubesphere.io",
+			Resource:        "pipelines",
+			Subresource:     "runs",
+			Name:            "demo",
+			ResourceRequest: true,
+		},
+		want: authorizer.DecisionAllow,
+	}, {
+		name: "run a pipeline by a service account",
+		attrs: authorizer.AttributesRecord{
+			User:            &user.DefaultInfo{Name: "system:serviceaccount:devops-a:robot"},
+			Verb:            "create",
+			Namespace:       "devops-a",
+			APIGroup:        "devops.kubesphere.io",
+			Resource:        "pipelines",
+			Subresource:     "runs",
+			Name:            "demo",
+			ResourceRequest: true,
+		},
+		want: authorizer.DecisionAllow,
+	}, {
+		name: "run a pipeline in another namespace",
+		attrs: authorizer.AttributesRecord{
+			User:            alice,
+			Verb:            "create",
+			Namespace:       "devops-b",
+			APIGroup:        "devops.kubesphere.io",
+			Resource:        "pipelines",
+			Subresource:     "runs",
+			Name:            "demo",
+			ResourceRequest: true,
+		},
+		want: authorizer.DecisionNoOpinion,
+	}, {
+		name: "get the named credential",
+		attrs: authorizer.AttributesRecord{
+			User:            alice,
+			Verb:            "get",
+			Namespace:       "devops-a",
+			Resource:        "secrets",
+			Name:            "github",
+			ResourceRequest: true,
+		},
+		want: authorizer.DecisionAllow,
+	}, {
+		name: "get another credential",
+		attrs: authorizer.AttributesRecord{
+			User:            alice,
+			Verb:            "get",
+			Namespace:       "devops-a",
+			Resource:        "secrets",
+			Name:            "gitlab",
+			ResourceRequest: true,
+		},
+		want: authorizer.DecisionNoOpinion,
+	}, {
+		name: "non-resource request",
+		attrs: authorizer.AttributesRecord{
+			User: alice,
+			Verb: "get",
+			Path: "/healthz",
+		},
+		want: authorizer.DecisionNoOpinion,
+	}}
+	a := newTestAuthorizer(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, _, err := a.Authorize(context.TODO(), tt.attrs)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, decision)
+		})
+	}
+}
+
+func TestRuleAllows(t *testing.T) {
+	rule := &rbacv1.PolicyRule{
+		Verbs:           []string{"get"},
+		NonResourceURLs: []string{"/kapis/devops.kubesphere.io/v1alpha3/*"},
+	}
+	assert.True(t, ruleAllows(authorizer.AttributesRecord{
+		Verb: "get",
+		Path: "/kapis/devops.kubesphere.io/v1alpha3/scms",
+	}, rule))
+	assert.False(t, ruleAllows(authorizer.AttributesRecord{
+		Verb: "get",
+		Path: "/kapis/devops.kubesphere.io/v1alpha2/scms",
+	}, rule))
+	assert.False(t, ruleAllows(authorizer.AttributesRecord{
+		Verb: "post",
+		Path: "/kapis/devops.kubesphere.io/v1alpha3/scms",
+	}, rule))
+}
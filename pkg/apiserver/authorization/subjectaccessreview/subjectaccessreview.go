@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subjectaccessreview
+
+import (
+	"context"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Authorizer delegates the authorization to Kubernetes through SubjectAccessReview
+type Authorizer struct {
+	client kubernetes.Interface
+}
+
+// NewAuthorizer creates an authorizer which asks Kubernetes whether the user is allowed
+func NewAuthorizer(client kubernetes.Interface) *Authorizer {
+	return &Authorizer{client: client}
+}
+
+// Authorize creates a SubjectAccessReview with the attributes of the request
+func (a *Authorizer) Authorize(ctx context.Context, attrs authorizer.Attributes) (authorizer.Decision, string, error) {
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: newSubjectAccessReviewSpec(attrs),
+	}
+	result, err := a.client.AuthorizationV1().SubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return authorizer.DecisionNoOpinion, "", err
+	}
+
+	switch {
+	case result.Status.Allowed:
+		return authorizer.DecisionAllow, result.Status.Reason, nil
+	case result.Status.Denied:
+		return authorizer.DecisionDeny, result.Status.Reason, nil
+	default:
+		return authorizer.DecisionNoOpinion, result.Status.Reason, nil
+	}
+}
+
+func newSubjectAccessReviewSpec(attrs authorizer.Attributes) authorizationv1.SubjectAccessReviewSpec {
+	spec := authorizationv1.SubjectAccessReviewSpec{}
+	if requester := attrs.GetUser(); requester != nil {
+		spec.User = requester.GetName()
+		spec.UID = requester.GetUID()
+		spec.Groups = requester.GetGroups()
+		if extra := requester.GetExtra(); len(extra) > 0 {
+			spec.Extra = make(map[string]authorizationv1.ExtraValue, len(extra))
+			for key, values := range extra {
+				spec.Extra[key] = values
+			}
+		}
+	}
+
+	if attrs.IsResourceRequest() {
+		spec.ResourceAttributes = &authorizationv1.ResourceAttributes{
+			Namespace:   attrs.GetNamespace(),
+			Verb:        attrs.GetVerb(),
+			Group:       attrs.GetAPIGroup(),
+			Version:     attrs.GetAPIVersion(),
+			Resource:    attrs.GetResource(),
+			Subresource: attrs.GetSubresource(),
+			Name:        attrs.GetName(),
+		}
+	} else {
+		spec.NonResourceAttributes = &authorizationv1.NonResourceAttributes{
+			Path: attrs.GetPath(),
+			Verb: attrs.GetVerb(),
+		}
+	}
+	return spec
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subjectaccessreview
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	attrs := authorizer.AttributesRecord{
+		User:            &user.DefaultInfo{Name: "alice", Groups: []string{"developers"}},
+		Verb:            "delete",
+		Namespace:       "devops-a",
+		APIGroup:        "devops.kubesphere.io",
+		Resource:        "pipelines",
+		Name:            "demo",
+		ResourceRequest: true,
+	}
+	tests := []struct {
+		name    string
+		status  authorizationv1.SubjectAccessReviewStatus
+		err     error
+		want    authorizer.Decision
+		wantErr bool
+	}{{
+		name:   "allowed",
+		status: authorizationv1.SubjectAccessReviewStatus{Allowed: true},
+		want:   authorizer.DecisionAllow,
+	}, {
+		name:   "denied",
+		status: authorizationv1.SubjectAccessReviewStatus{Denied: true, Reason: "denied by policy"},
+		want:   authorizer.DecisionDeny,
+	}, {
+		name: "no opinion",
+		want: authorizer.DecisionNoOpinion,
+	}, {
+		name:    "failed to create the review",
+		err:     errors.New("connection refused"),
+		want:    authorizer.DecisionNoOpinion,
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := k8sfake.NewSimpleClientset()
+			client.PrependReactor("create", "subjectaccessreviews",
+				func(action k8stesting.Action) (bool, runtime.Object, error) {
+					review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+					assert.Equal(t, "alice", review.Spec.User)
+					assert.Equal(t, []string{"developers"}, review.Spec.Groups)
+					assert.Equal(t, &authorizationv1.ResourceAttributes{
+						Namespace: "devops-a",
+						Verb:      "delete",
+						Group:     "devops.kubesphere.io",
+						Resource:  "pipelines",
+						Name:      "demo",
+					}, review.Spec.ResourceAttributes)
+					review.Status = tt.status
+					return true, review, tt.err
+				})
+
+			decision, _, err := NewAuthorizer(client).Authorize(context.TODO(), attrs)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, decision)
+		})
+	}
+}
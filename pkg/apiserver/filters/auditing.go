@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filters
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+
+	"k8s.io/klog"
+
+	"kubesphere.io/devops/pkg/apiserver/auditing"
+	"kubesphere.io/devops/pkg/apiserver/request"
+)
+
+// maxCapturedBodySize is the max size of the response body kept for the audit event
+const maxCapturedBodySize = 64 * 1024
+
+// WithAuditing installs auditing handler to handler chain. It should be installed after the authentication,
+// and before the authorization, so that the forbidden requests are recorded as well.
+func WithAuditing(handler http.Handler, a auditing.Auditing) http.Handler {
+	if a == nil {
+		klog.Warningf("Auditing is disabled")
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requestInfo, found := request.RequestInfoFrom(req.Context())
+		if !found || !a.Enabled(requestInfo) {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		e := a.LogRequestObject(req, requestInfo)
+		resp := NewResponseCapture(w, a.NeedResponseBody(requestInfo))
+		handler.ServeHTTP(resp, req)
+		a.LogResponseObject(e, resp.StatusCode(), resp.Bytes())
+	})
+}
+
+// ResponseCapture records the status code and the body of the response
+type ResponseCapture struct {
+	http.ResponseWriter
+	wroteHeader bool
+	status      int
+	captureBody bool
+	body        *bytes.Buffer
+}
+
+// NewResponseCapture creates a ResponseCapture, the body is always captured for the failed responses
+func NewResponseCapture(w http.ResponseWriter, captureBody bool) *ResponseCapture {
+	return &ResponseCapture{
+		ResponseWriter: w,
+		status:         http.StatusOK,
+		captureBody:    captureBody,
+		body:           &bytes.Buffer{},
+	}
+}
+
+func (c *ResponseCapture) WriteHeader(statusCode int) {
+	if !c.wroteHeader {
+		c.status = statusCode
+		c.wroteHeader = true
+	}
+	c.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (c *ResponseCapture) Write(data []byte) (int, error) {
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+	if (c.captureBody || c.status >= http.StatusBadRequest) && c.body.Len() < maxCapturedBodySize {
+		remaining := maxCapturedBodySize - c.body.Len()
+		if len(data) < remaining {
+			remaining = len(data)
+		}
+		c.body.Write(data[:remaining])
+	}
+	return c.ResponseWriter.Write(data)
+}
+
+func (c *ResponseCapture) Bytes() []byte {
+	return c.body.Bytes()
+}
+
+func (c *ResponseCapture) StatusCode() int {
+	return c.status
+}
+
+// Flush implements http.Flusher, the proxied responses might be streamed
+func (c *ResponseCapture) Flush() {
+	if flusher, ok := c.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker, the proxied requests might be upgraded
+func (c *ResponseCapture) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := c.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, errors.New("the response writer doesn't implement http.Hijacker")
+}
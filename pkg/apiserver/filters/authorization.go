@@ -0,0 +1,127 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filters
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"k8s.io/klog"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/apiserver/request"
+)
+
+// WithAuthorization installs authorization handler to handler chain.
+// The DevOps project of the request is taken as the namespace of the requested resource, because the name of
+// a DevOps project is always the same as its admin namespace. The requests of the paths in alwaysAllowPaths,
+// e.g. the webhooks from SCM, are not authorized.
+func WithAuthorization(handler http.Handler, authorizers authorizer.Authorizer, alwaysAllowPaths []string) http.Handler {
+	if authorizers == nil {
+		klog.Warningf("Authorization is disabled")
+		return handler
+	}
+	s := serializer.NewCodecFactory(runtime.NewScheme()).WithoutConversion()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if isAlwaysAllowed(req.URL.Path, alwaysAllowPaths) {
+			handler.ServeHTTP(w, req)
+			return
+		}
+
+		ctx := req.Context()
+		attributes, err := getAuthorizerAttributes(req)
+		if err != nil {
+			responsewriters.InternalError(w, req, err)
+			return
+		}
+
+		decision, reason, err := authorizers.Authorize(ctx, attributes)
+		if err != nil {
+			klog.Errorf("failed to authorize request %s %s, error %v", req.Method, req.URL.Path, err)
+			responsewriters.InternalError(w, req, err)
+			return
+		}
+		if decision != authorizer.DecisionAllow {
+			klog.V(4).Infof("Forbidden: %q, Reason: %q", req.RequestURI, reason)
+			responsewriters.Forbidden(ctx, attributes, w, req, reason, s)
+			return
+		}
+
+		handler.ServeHTTP(w, req)
+	})
+}
+
+// getAuthorizerAttributes converts the RequestInfo and the user into the attributes to authorize
+func getAuthorizerAttributes(req *http.Request) (authorizer.Attributes, error) {
+	ctx := req.Context()
+	requestInfo, found := request.RequestInfoFrom(ctx)
+	if !found {
+		return nil, errors.New("no RequestInfo found in the context")
+	}
+	requester, found := request.UserFrom(ctx)
+	if !found {
+		return nil, errors.New("no user found in the context")
+	}
+
+	attributes := authorizer.AttributesRecord{
+		User:            requester,
+		Verb:            requestInfo.Verb,
+		Namespace:       requestInfo.Namespace,
+		APIGroup:        requestInfo.APIGroup,
+		APIVersion:      requestInfo.APIVersion,
+		Resource:        requestInfo.Resource,
+		Subresource:     requestInfo.Subresource,
+		Name:            requestInfo.Name,
+		ResourceRequest: requestInfo.IsResourceRequest,
+		Path:            requestInfo.Path,
+	}
+
+	if requestInfo.DevOps != "" {
+		attributes.Namespace = requestInfo.DevOps
+	}
+	switch requestInfo.Resource {
+	case "devops":
+		// the request on a DevOps project itself, e.g. /devops/{devops}
+		attributes.APIGroup = v1alpha3.GroupVersion.Group
+		attributes.APIVersion = v1alpha3.GroupVersion.Version
+		attributes.Resource = "devopsprojects"
+		attributes.Namespace = ""
+	case "credentials":
+		// credentials are stored as secrets
+		attributes.APIGroup = ""
+		attributes.APIVersion = "v1"
+		attributes.Resource = "secrets"
+	}
+	return attributes, nil
+}
+
+// isAlwaysAllowed checks if the path matches one of the paths, the path ending with "*" matches the prefix
+func isAlwaysAllowed(path string, alwaysAllowPaths []string) bool {
+	for _, allowed := range alwaysAllowPaths {
+		if allowed == path ||
+			(strings.HasSuffix(allowed, "*") && strings.HasPrefix(path, strings.TrimSuffix(allowed, "*"))) {
+			return true
+		}
+	}
+	return false
+}
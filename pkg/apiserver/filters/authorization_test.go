@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filters
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+
+	"kubesphere.io/devops/pkg/apiserver/request"
+)
+
+// fakeAuthorizer records the attributes and allows the requests of the user alice only
+type fakeAuthorizer struct {
+	attrs authorizer.Attributes
+}
+
+func (a *fakeAuthorizer) Authorize(ctx context.Context, attrs authorizer.Attributes) (authorizer.Decision, string, error) {
+	a.attrs = attrs
+	if attrs.GetUser().GetName() == "alice" {
+		return authorizer.DecisionAllow, "", nil
+	}
+	return authorizer.DecisionNoOpinion, "not allowed", nil
+}
+
+func TestWithAuthorization(t *testing.T) {
+	requestInfoResolver := &request.RequestInfoFactory{
+		APIPrefixes:          sets.NewString("api", "apis", "kapis", "kapi"),
+		GrouplessAPIPrefixes: sets.NewString("api", "kapi"),
+	}
+	tests := []struct {
+		name            string
+		user            string
+		method          string
+		url             string
+		wantStatus      int
+		wantAuthorized  bool
+		wantNamespace   string
+		wantAPIGroup    string
+		wantResource    string
+		wantSubresource string
+		wantName        string
+	}{{
+		name:            "run a pipeline",
+		user:            "alice",
+		method:          http.MethodPost,
+		url:             "/kapis/devops.kubesphere.io/v1alpha3/devops/devops-a/pipelines/demo/runs",
+		wantStatus:      http.StatusOK,
+		wantAuthorized:  true,
+		wantNamespace:   "devops-a",
+		wantAPIGroup:    "devops.kubesphere.io",
+		wantResource:    "pipelines",
+		wantSubresource: "runs",
+		wantName:        "demo",
+	}, {
+		name:           "get a DevOps project",
+		user:           "alice",
+		method:         http.MethodGet,
+		url:            "/kapis/devops.kubesphere.io/v1alpha3/devops/devops-a",
+		wantStatus:     http.StatusOK,
+		wantAuthorized: true,
+		wantAPIGroup:   "devops.kubesphere.io",
+		wantResource:   "devopsprojects",
+		wantName:       "devops-a",
+	}, {
+		name:           "delete a credential without permission",
+		user:           "bob",
+		method:         http.MethodDelete,
+		url:            "/kapis/devops.kubesphere.io/v1alpha3/devops/devops-a/credentials/github",
+		wantStatus:     http.StatusForbidden,
+		wantAuthorized: true,
+		wantNamespace:  "devops-a",
+		wantResource:   "secrets",
+		wantName:       "github",
+	}, {
+		name:       "webhook is always allowed",
+		user:       "anonymous",
+		method:     http.MethodPost,
+		url:        "/kapis/devops.kubesphere.io/v1alpha2/webhook/github",
+		wantStatus: http.StatusOK,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeAuthorizer{}
+			handler := WithAuthorization(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}), fake, []string{"/kapis/devops.kubesphere.io/v1alpha2/webhook/*"})
+
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			requestInfo, err := requestInfoResolver.NewRequestInfo(req)
+			assert.Nil(t, err)
+			ctx := request.WithRequestInfo(req.Context(), requestInfo)
+			ctx = request.WithUser(ctx, &user.DefaultInfo{Name: tt.user})
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req.WithContext(ctx))
+
+			assert.Equal(t, tt.wantStatus, recorder.Code)
+			assert.Equal(t, tt.wantAuthorized, fake.attrs != nil)
+			if fake.attrs != nil {
+				assert.Equal(t, tt.wantNamespace, fake.attrs.GetNamespace())
+				assert.Equal(t, tt.wantAPIGroup, fake.attrs.GetAPIGroup())
+				assert.Equal(t, tt.wantResource, fake.attrs.GetResource())
+				assert.Equal(t, tt.wantSubresource, fake.attrs.GetSubresource())
+				assert.Equal(t, tt.wantName, fake.attrs.GetName())
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package codequality
+
+import (
+	"strconv"
+
+	"kubesphere.io/devops/pkg/client/devops"
+)
+
+// The statuses of code quality reports, they are the same as the statuses of the quality gates of SonarQube
+const (
+	StatusOK    = "OK"
+	StatusWarn  = "WARN"
+	StatusError = "ERROR"
+	// StatusNone indicates that there is no quality gate, or its result is not available
+	StatusNone = "NONE"
+)
+
+// CodeQualityProvider reports the results of a kind of code analyses of Jenkins builds
+type CodeQualityProvider interface {
+	// Name returns the unique name of the provider, e.g. sonarqube
+	Name() string
+	// GetReports returns the code quality reports of the build, it's empty if the build has no analysis of the provider
+	GetReports(build *BuildReference) ([]*Report, error)
+}
+
+// BuildReference locates a Jenkins build of Pipeline
+type BuildReference struct {
+	ProjectID  string
+	PipelineID string
+	// Branch is the branch of multi-branch Pipeline, it's empty for the other Pipelines
+	Branch string
+	Build  *devops.Build
+}
+
+// RunID returns the ID of the build in Jenkins
+func (b *BuildReference) RunID() string {
+	return strconv.FormatInt(b.Build.Number, 10)
+}
+
+// GetArtifact returns the content of an artifact archived by the build
+func (b *BuildReference) GetArtifact(client devops.BuildGetter, relativePath string) ([]byte, error) {
+	if b.Branch != "" {
+		return client.GetMultiBranchPipelineBuildArtifact(b.ProjectID, b.PipelineID, b.Branch, b.RunID(), relativePath)
+	}
+	return client.GetProjectPipelineBuildArtifact(b.ProjectID, b.PipelineID, b.RunID(), relativePath)
+}
+
+// Result is the code quality of a build reported by all providers. The reports of the providers which succeeded are
+// returned even if the others failed.
+type Result struct {
+	Reports []*Report       `json:"reports" description:"the code quality reports of all providers"`
+	Errors  []ProviderError `json:"errors,omitempty" description:"the errors of the providers which failed to report"`
+}
+
+// ProviderError is the error of a provider which failed to report
+type ProviderError struct {
+	Provider string `json:"provider" description:"the name of the provider, e.g. sonarqube, sarif"`
+	Message  string `json:"message" description:"the error message"`
+}
+
+// Report is the result of a code analysis
+type Report struct {
+	Provider     string            `json:"provider" description:"the name of the provider, e.g. sonarqube, sarif"`
+	Tool         string            `json:"tool" description:"the name of the analysis tool, e.g. SonarQube, Semgrep"`
+	Component    string            `json:"component,omitempty" description:"the analyzed component, e.g. the project key of SonarQube, or the path of the SARIF report"`
+	Status       string            `json:"status" description:"the status of the quality gate, one of OK, WARN, ERROR and NONE"`
+	Measures     map[string]string `json:"measures,omitempty" description:"the measures of the analysis, e.g. bugs and coverage of SonarQube, or the number of results by level of SARIF"`
+	Issues       []Issue           `json:"issues,omitempty" description:"the issues found by the analysis"`
+	DashboardURL string            `json:"dashboardUrl,omitempty" description:"the URL of the dashboard of the analysis"`
+	Details      interface{}       `json:"details,omitempty" description:"the original result of the provider, e.g. the sonar status of SonarQube"`
+}
+
+// Issue is a problem found by a code analysis
+type Issue struct {
+	RuleID  string `json:"ruleId,omitempty" description:"the ID of the rule which found the issue"`
+	Level   string `json:"level" description:"the severity of the issue, e.g. error, warning, note"`
+	Message string `json:"message" description:"the description of the issue"`
+	File    string `json:"file,omitempty" description:"the file which contains the issue"`
+	Line    int    `json:"line,omitempty" description:"the line where the issue starts"`
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sarif
+
+import (
+	"strings"
+
+	"k8s.io/klog"
+
+	"kubesphere.io/devops/pkg/client/codequality"
+	"kubesphere.io/devops/pkg/client/devops"
+)
+
+// ProviderName is the name of SARIF reports as a code quality provider
+const ProviderName = "sarif"
+
+// ReportSuffixes are the suffixes of the artifacts which are regarded as SARIF logs
+var ReportSuffixes = []string{".sarif", ".sarif.json"}
+
+type codeQualityProvider struct {
+	client devops.BuildGetter
+}
+
+// NewCodeQualityProvider creates a code quality provider which reports the SARIF logs archived as the artifacts of
+// Jenkins builds, e.g. the results of Semgrep, gosec or CodeQL
+func NewCodeQualityProvider(client devops.BuildGetter) codequality.CodeQualityProvider {
+	return &codeQualityProvider{client: client}
+}
+
+func (p *codeQualityProvider) Name() string {
+	return ProviderName
+}
+
+func (p *codeQualityProvider) GetReports(build *codequality.BuildReference) ([]*codequality.Report, error) {
+	var reports []*codequality.Report
+	for _, artifact := range build.Build.Artifacts {
+		if !isReport(artifact.FileName) {
+			continue
+		}
+		data, err := build.GetArtifact(p.client, artifact.RelativePath)
+		if err != nil {
+			return nil, err
+		}
+		log, err := Parse(data)
+		if err != nil {
+			// an invalid artifact should not hide the other reports
+			klog.Errorf("failed to parse the SARIF log %s of build %s, error: %v",
+				artifact.RelativePath, build.Build.FullDisplayName, err)
+			continue
+		}
+		reports = append(reports, log.Reports(artifact.RelativePath)...)
+	}
+	return reports, nil
+}
+
+func isReport(fileName string) bool {
+	for _, suffix := range ReportSuffixes {
+		if strings.HasSuffix(fileName, suffix) {
+			return true
+		}
+	}
+	return false
+}
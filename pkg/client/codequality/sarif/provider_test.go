@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sarif
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"kubesphere.io/devops/pkg/client/codequality"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/devops/fake"
+)
+
+func TestCodeQualityProvider_GetReports(t *testing.T) {
+	semgrep, err := ioutil.ReadFile(filepath.Join("testdata", "semgrep.sarif"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	devopsClient := fake.New("project1")
+	devopsClient.BuildArtifacts = map[string][]byte{
+		"project1/pipeline1/main/2/reports/semgrep.sarif": semgrep,
+		"project1/pipeline1/main/2/reports/broken.sarif":  []byte("{"),
+	}
+
+	build := &devops.Build{Number: 2}
+	for _, path := range []string{"reports/semgrep.sarif", "reports/broken.sarif", "target/app.jar"} {
+		build.Artifacts = append(build.Artifacts, devops.BuildArtifact{
+			DisplayPath:  filepath.Base(path),
+			FileName:     filepath.Base(path),
+			RelativePath: path,
+		})
+	}
+
+	provider := NewCodeQualityProvider(devopsClient)
+	assert.Equal(t, ProviderName, provider.Name())
+	reports, err := provider.GetReports(&codequality.BuildReference{
+		ProjectID:  "project1",
+		PipelineID: "pipeline1",
+		Branch:     "main",
+		Build:      build,
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	if assert.Equal(t, 1, len(reports)) {
+		assert.Equal(t, "Semgrep", reports[0].Tool)
+		assert.Equal(t, "reports/semgrep.sarif", reports[0].Component)
+		assert.Equal(t, codequality.StatusError, reports[0].Status)
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sarif
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"kubesphere.io/devops/pkg/client/codequality"
+)
+
+// The levels of results, see also https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+	LevelNone    = "none"
+)
+
+// kindFail is the kind of the results which are problems, the others like pass or notApplicable are ignored
+const kindFail = "fail"
+
+// maxIssues is the max number of issues in a report, the same as the issues of SonarQube
+const maxIssues = 10
+
+var levelSeverities = map[string]int{
+	LevelError:   3,
+	LevelWarning: 2,
+	LevelNote:    1,
+}
+
+// Log is the part of a SARIF log which is reported
+type Log struct {
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+// Run is the result of a single invocation of an analysis tool
+type Run struct {
+	Tool struct {
+		Driver struct {
+			Name  string `json:"name"`
+			Rules []Rule `json:"rules"`
+		} `json:"driver"`
+	} `json:"tool"`
+	Results []Result `json:"results"`
+}
+
+// Rule describes a rule of the analysis tool
+type Rule struct {
+	ID                   string `json:"id"`
+	DefaultConfiguration *struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+// Result is a result found by the analysis tool
+type Result struct {
+	RuleID    string `json:"ruleId"`
+	RuleIndex *int   `json:"ruleIndex"`
+	Kind      string `json:"kind"`
+	Level     string `json:"level"`
+	Message   struct {
+		Text string `json:"text"`
+	} `json:"message"`
+	Locations []struct {
+		PhysicalLocation struct {
+			ArtifactLocation struct {
+				URI string `json:"uri"`
+			} `json:"artifactLocation"`
+			Region struct {
+				StartLine int `json:"startLine"`
+			} `json:"region"`
+		} `json:"physicalLocation"`
+	} `json:"locations"`
+}
+
+// Parse parses a SARIF log in JSON
+func Parse(data []byte) (*Log, error) {
+	log := &Log{}
+	if err := json.Unmarshal(data, log); err != nil {
+		return nil, fmt.Errorf("invalid SARIF log, error: %v", err)
+	}
+	return log, nil
+}
+
+// Reports returns a code quality report for each run in the log, component is the name of the log, e.g. its path
+func (l *Log) Reports(component string) []*codequality.Report {
+	reports := make([]*codequality.Report, 0, len(l.Runs))
+	for i := range l.Runs {
+		reports = append(reports, l.Runs[i].report(component))
+	}
+	return reports
+}
+
+func (r *Run) report(component string) *codequality.Report {
+	counts := map[string]int{LevelError: 0, LevelWarning: 0, LevelNote: 0}
+	var issues []codequality.Issue
+	for i := range r.Results {
+		result := &r.Results[i]
+		if result.Kind != "" && result.Kind != kindFail {
+			continue
+		}
+		level := r.level(result)
+		if _, ok := counts[level]; !ok {
+			continue
+		}
+		counts[level]++
+
+		issue := codequality.Issue{
+			RuleID:  r.ruleID(result),
+			Level:   level,
+			Message: result.Message.Text,
+		}
+		if len(result.Locations) > 0 {
+			issue.File = result.Locations[0].PhysicalLocation.ArtifactLocation.URI
+			issue.Line = result.Locations[0].PhysicalLocation.Region.StartLine
+		}
+		issues = append(issues, issue)
+	}
+
+	// the most severe issues come first
+	sort.SliceStable(issues, func(i, j int) bool {
+		return levelSeverities[issues[i].Level] > levelSeverities[issues[j].Level]
+	})
+	if len(issues) > maxIssues {
+		issues = issues[:maxIssues]
+	}
+
+	report := &codequality.Report{
+		Provider:  ProviderName,
+		Tool:      r.Tool.Driver.Name,
+		Component: component,
+		Status:    codequality.StatusOK,
+		Measures:  make(map[string]string, len(counts)),
+		Issues:    issues,
+	}
+	for level, count := range counts {
+		report.Measures[level] = strconv.Itoa(count)
+	}
+	if counts[LevelError] > 0 {
+		report.Status = codequality.StatusError
+	} else if counts[LevelWarning] > 0 {
+		report.Status = codequality.StatusWarn
+	}
+	return report
+}
+
+// rule returns the rule of the result, it's nil if the rule is not described by the tool
+func (r *Run) rule(result *Result) *Rule {
+	if result.RuleIndex != nil && *result.RuleIndex >= 0 && *result.RuleIndex < len(r.Tool.Driver.Rules) {
+		return &r.Tool.Driver.Rules[*result.RuleIndex]
+	}
+	for i := range r.Tool.Driver.Rules {
+		if result.RuleID != "" && r.Tool.Driver.Rules[i].ID == result.RuleID {
+			return &r.Tool.Driver.Rules[i]
+		}
+	}
+	return nil
+}
+
+func (r *Run) ruleID(result *Result) string {
+	if result.RuleID == "" {
+		if rule := r.rule(result); rule != nil {
+			return rule.ID
+		}
+	}
+	return result.RuleID
+}
+
+// level returns the level of the result, which defaults to the level of its rule, then warning
+func (r *Run) level(result *Result) string {
+	if result.Level != "" {
+		return result.Level
+	}
+	if rule := r.rule(result); rule != nil && rule.DefaultConfiguration != nil && rule.DefaultConfiguration.Level != "" {
+		return rule.DefaultConfiguration.Level
+	}
+	return LevelWarning
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sarif
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"kubesphere.io/devops/pkg/client/codequality"
+)
+
+func TestLog_Reports(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		want    []*codequality.Report
+	}{{
+		name:    "Levels from the default configuration of rules",
+		fixture: "semgrep.sarif",
+		want: []*codequality.Report{{
+			Provider:  ProviderName,
+			Tool:      "Semgrep",
+			Component: "semgrep.sarif",
+			Status:    codequality.StatusError,
+			Measures:  map[string]string{LevelError: "1", LevelWarning: "1", LevelNote: "0"},
+			Issues: []codequality.Issue{{
+				RuleID:  "go.lang.security.audit.database.string-formatted-query.string-formatted-query",
+				Level:   LevelError,
+				Message: "String-formatted SQL query detected.",
+				File:    "pkg/store/user.go",
+				Line:    48,
+			}, {
+				RuleID:  "go.lang.security.audit.crypto.use_of_weak_crypto.use-of-md5",
+				Level:   LevelWarning,
+				Message: "Detected MD5 hash algorithm which is considered insecure.",
+				File:    "pkg/utils/hash.go",
+				Line:    12,
+			}},
+		}},
+	}, {
+		name:    "Rules referred by index and levels overridden by results",
+		fixture: "gosec.sarif",
+		want: []*codequality.Report{{
+			Provider:  ProviderName,
+			Tool:      "gosec",
+			Component: "gosec.sarif",
+			Status:    codequality.StatusWarn,
+			Measures:  map[string]string{LevelError: "0", LevelWarning: "1", LevelNote: "1"},
+			Issues: []codequality.Issue{{
+				RuleID:  "G402",
+				Level:   LevelWarning,
+				Message: "TLS InsecureSkipVerify set true.",
+				File:    "pkg/client/http.go",
+				Line:    33,
+			}, {
+				RuleID:  "G104",
+				Level:   LevelNote,
+				Message: "Errors unhandled.",
+				File:    "cmd/main.go",
+				Line:    20,
+			}},
+		}},
+	}, {
+		name:    "Passed results and multiple runs",
+		fixture: "codeql.sarif",
+		want: []*codequality.Report{{
+			Provider:  ProviderName,
+			Tool:      "CodeQL",
+			Component: "codeql.sarif",
+			Status:    codequality.StatusOK,
+			Measures:  map[string]string{LevelError: "0", LevelWarning: "0", LevelNote: "0"},
+		}, {
+			Provider:  ProviderName,
+			Tool:      "CodeQL",
+			Component: "codeql.sarif",
+			Status:    codequality.StatusOK,
+			Measures:  map[string]string{LevelError: "0", LevelWarning: "0", LevelNote: "0"},
+		}},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ioutil.ReadFile(filepath.Join("testdata", tt.fixture))
+			if !assert.Nil(t, err) {
+				return
+			}
+			log, err := Parse(data)
+			if !assert.Nil(t, err) {
+				return
+			}
+			assert.Equal(t, tt.want, log.Reports(tt.fixture))
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse([]byte("<html>Not Found</html>"))
+	assert.NotNil(t, err)
+}
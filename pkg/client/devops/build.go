@@ -70,15 +70,18 @@ type GeneralAction struct {
 	UrlName            string                   `json:",omitempty"`
 }
 
+// BuildArtifact is a file archived by the build
+type BuildArtifact struct {
+	DisplayPath  string `json:"displayPath"`
+	FileName     string `json:"fileName"`
+	RelativePath string `json:"relativePath"`
+}
+
 type Build struct {
 	Actions   []GeneralAction
-	Artifacts []struct {
-		DisplayPath  string `json:"displayPath"`
-		FileName     string `json:"fileName"`
-		RelativePath string `json:"relativePath"`
-	} `json:"artifacts"`
-	Building  bool   `json:"building"`
-	BuiltOn   string `json:"builtOn"`
+	Artifacts []BuildArtifact `json:"artifacts"`
+	Building  bool            `json:"building"`
+	BuiltOn   string          `json:"builtOn"`
 	ChangeSet struct {
 		Items []struct {
 			AffectedPaths []string `json:"affectedPaths"`
@@ -122,10 +125,65 @@ type Build struct {
 	} `json:"runs"`
 }
 
+// The statuses of test cases in the test report of Jenkins
+const (
+	TestCasePassed     = "PASSED"
+	TestCaseSkipped    = "SKIPPED"
+	TestCaseFailed     = "FAILED"
+	TestCaseFixed      = "FIXED"
+	TestCaseRegression = "REGRESSION"
+)
+
+// TestReport is the test results collected by the JUnit plugin of Jenkins
+type TestReport struct {
+	Duration  float64     `json:"duration"`
+	FailCount int         `json:"failCount"`
+	PassCount int         `json:"passCount"`
+	SkipCount int         `json:"skipCount"`
+	Suites    []TestSuite `json:"suites"`
+}
+
+// TestSuite is a suite of test cases in the test report
+type TestSuite struct {
+	Name  string     `json:"name"`
+	Cases []TestCase `json:"cases"`
+}
+
+// TestCase is a test case in the test report
+type TestCase struct {
+	ClassName string `json:"className"`
+	Name      string `json:"name"`
+	// Status is one of PASSED, SKIPPED, FAILED, FIXED and REGRESSION
+	Status string `json:"status"`
+}
+
+// Failed indicates if the test case failed
+func (c *TestCase) Failed() bool {
+	return c.Status == TestCaseFailed || c.Status == TestCaseRegression
+}
+
 type BuildGetter interface {
 	// GetProjectPipelineBuildByType get the last build of the pipeline, status can specify the status of the last build.
 	GetProjectPipelineBuildByType(projectId, pipelineId string, status string) (*Build, error)
 
 	// GetMultiBranchPipelineBuildByType get the last build of the pipeline, status can specify the status of the last build.
 	GetMultiBranchPipelineBuildByType(projectId, pipelineId, branch string, status string) (*Build, error)
+
+	// GetProjectPipelineBuild get the build of the pipeline by the run ID.
+	GetProjectPipelineBuild(projectId, pipelineId, runId string) (*Build, error)
+
+	// GetMultiBranchPipelineBuild get the build of the pipeline branch by the run ID.
+	GetMultiBranchPipelineBuild(projectId, pipelineId, branch, runId string) (*Build, error)
+
+	// GetProjectPipelineBuildArtifact get the content of an artifact archived by the build of the pipeline.
+	GetProjectPipelineBuildArtifact(projectId, pipelineId, runId, relativePath string) ([]byte, error)
+
+	// GetMultiBranchPipelineBuildArtifact get the content of an artifact archived by the build of the pipeline branch.
+	GetMultiBranchPipelineBuildArtifact(projectId, pipelineId, branch, runId, relativePath string) ([]byte, error)
+
+	// GetProjectPipelineBuildTestReport get the test report of the build of the pipeline.
+	GetProjectPipelineBuildTestReport(projectId, pipelineId, runId string) (*TestReport, error)
+
+	// GetMultiBranchPipelineBuildTestReport get the test report of the build of the pipeline branch.
+	GetMultiBranchPipelineBuildTestReport(projectId, pipelineId, branch, runId string) (*TestReport, error)
 }
@@ -21,6 +21,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 
 	"github.com/emicklei/go-restful"
@@ -39,6 +40,21 @@ type Devops struct {
 	Pipelines map[string]map[string]*devopsv1alpha3.Pipeline
 
 	Credentials map[string]map[string]*v1.Secret
+
+	// Builds are keyed by the path of the build, e.g. project/pipeline/1, project/pipeline/branch/1,
+	// or project/pipeline/lastBuild for the builds got by type
+	Builds map[string]*devops.Build
+	// BuildArtifacts are keyed by the path of the build and the relative path of the artifact,
+	// e.g. project/pipeline/1/report.sarif
+	BuildArtifacts map[string][]byte
+	// TestReports are keyed by the path of the build, e.g. project/pipeline/1, or project/pipeline/branch/1
+	TestReports map[string]*devops.TestReport
+
+	// StoppedRuns, PauseToggledRuns and SubmittedInputs record the runs which have been acted on, e.g.
+	// project/pipeline/1, project/pipeline/branch/1, or project/pipeline/1/node/step for input steps
+	StoppedRuns      []string
+	PauseToggledRuns []string
+	SubmittedInputs  []string
 }
 
 func New(projects ...string) *Devops {
@@ -178,8 +194,13 @@ func (d *Devops) ListPipelineRuns(projectName, pipelineName string, httpParamete
 	return nil, nil
 }
 func (d *Devops) StopPipeline(projectName, pipelineName, runId string, httpParameters *devops.HttpParameters) (*devops.StopPipeline, error) {
+	d.StoppedRuns = append(d.StoppedRuns, strings.Join([]string{projectName, pipelineName, runId}, "/"))
 	return nil, nil
 }
+func (d *Devops) TogglePausePipeline(projectName, pipelineName, runId string) error {
+	d.PauseToggledRuns = append(d.PauseToggledRuns, strings.Join([]string{projectName, pipelineName, runId}, "/"))
+	return nil
+}
 func (d *Devops) ReplayPipeline(projectName, pipelineName, runId string, httpParameters *devops.HttpParameters) (*devops.ReplayPipeline, error) {
 	return nil, nil
 }
@@ -208,6 +229,7 @@ func (d *Devops) GetPipelineRunNodes(projectName, pipelineName, runId string, ht
 	return res, nil
 }
 func (d *Devops) SubmitInputStep(projectName, pipelineName, runId, nodeId, stepId string, httpParameters *devops.HttpParameters) ([]byte, error) {
+	d.SubmittedInputs = append(d.SubmittedInputs, strings.Join([]string{projectName, pipelineName, runId, nodeId, stepId}, "/"))
 	return nil, nil
 }
 
@@ -219,8 +241,13 @@ func (d *Devops) GetBranchPipelineRun(projectName, pipelineName, branchName, run
 	return nil, nil
 }
 func (d *Devops) StopBranchPipeline(projectName, pipelineName, branchName, runId string, httpParameters *devops.HttpParameters) (*devops.StopPipeline, error) {
+	d.StoppedRuns = append(d.StoppedRuns, strings.Join([]string{projectName, pipelineName, branchName, runId}, "/"))
 	return nil, nil
 }
+func (d *Devops) TogglePauseBranchPipeline(projectName, pipelineName, branchName, runId string) error {
+	d.PauseToggledRuns = append(d.PauseToggledRuns, strings.Join([]string{projectName, pipelineName, branchName, runId}, "/"))
+	return nil
+}
 func (d *Devops) ReplayBranchPipeline(projectName, pipelineName, branchName, runId string, httpParameters *devops.HttpParameters) (*devops.ReplayPipeline, error) {
 	return nil, nil
 }
@@ -249,6 +276,7 @@ func (d *Devops) GetBranchPipelineRunNodes(projectName, pipelineName, branchName
 	return res, nil
 }
 func (d *Devops) SubmitBranchInputStep(projectName, pipelineName, branchName, runId, nodeId, stepId string, httpParameters *devops.HttpParameters) ([]byte, error) {
+	d.SubmittedInputs = append(d.SubmittedInputs, strings.Join([]string{projectName, pipelineName, branchName, runId, nodeId, stepId}, "/"))
 	return nil, nil
 }
 func (d *Devops) GetPipelineBranch(projectName, pipelineName string, httpParameters *devops.HttpParameters) (*devops.PipelineBranch, error) {
@@ -262,6 +290,9 @@ func (d *Devops) ScanBranch(projectName, pipelineName string, httpParameters *de
 func (d *Devops) GetConsoleLog(projectName, pipelineName string, httpParameters *devops.HttpParameters) ([]byte, error) {
 	return nil, nil
 }
+func (d *Devops) GetProgressiveLog(options devops.LogOptions) (*devops.ProgressiveLog, error) {
+	return &devops.ProgressiveLog{NextStart: options.Start}, nil
+}
 func (d *Devops) GetCrumb(httpParameters *devops.HttpParameters) (*devops.Crumb, error) {
 	return nil, nil
 }
@@ -433,10 +464,46 @@ func (d *Devops) DeleteCredentialInProject(projectId, id string) (string, error)
 
 // BuildGetter
 func (d *Devops) GetProjectPipelineBuildByType(projectId, pipelineId string, status string) (*devops.Build, error) {
-	return nil, nil
+	return d.getBuild(projectId, pipelineId, status)
 }
 func (d *Devops) GetMultiBranchPipelineBuildByType(projectId, pipelineId, branch string, status string) (*devops.Build, error) {
-	return nil, nil
+	return d.getBuild(projectId, pipelineId, branch, status)
+}
+func (d *Devops) GetProjectPipelineBuild(projectId, pipelineId, runId string) (*devops.Build, error) {
+	return d.getBuild(projectId, pipelineId, runId)
+}
+func (d *Devops) GetMultiBranchPipelineBuild(projectId, pipelineId, branch, runId string) (*devops.Build, error) {
+	return d.getBuild(projectId, pipelineId, branch, runId)
+}
+func (d *Devops) getBuild(path ...string) (*devops.Build, error) {
+	if build, ok := d.Builds[strings.Join(path, "/")]; ok {
+		return build, nil
+	}
+	return nil, restful.NewError(http.StatusNotFound, fmt.Sprintf("build %s not found", strings.Join(path, "/")))
+}
+func (d *Devops) GetProjectPipelineBuildArtifact(projectId, pipelineId, runId, relativePath string) ([]byte, error) {
+	return d.getBuildArtifact(projectId, pipelineId, runId, relativePath)
+}
+func (d *Devops) GetMultiBranchPipelineBuildArtifact(projectId, pipelineId, branch, runId, relativePath string) ([]byte, error) {
+	return d.getBuildArtifact(projectId, pipelineId, branch, runId, relativePath)
+}
+func (d *Devops) GetProjectPipelineBuildTestReport(projectId, pipelineId, runId string) (*devops.TestReport, error) {
+	return d.getBuildTestReport(projectId, pipelineId, runId)
+}
+func (d *Devops) GetMultiBranchPipelineBuildTestReport(projectId, pipelineId, branch, runId string) (*devops.TestReport, error) {
+	return d.getBuildTestReport(projectId, pipelineId, branch, runId)
+}
+func (d *Devops) getBuildTestReport(path ...string) (*devops.TestReport, error) {
+	if report, ok := d.TestReports[strings.Join(path, "/")]; ok {
+		return report, nil
+	}
+	return nil, restful.NewError(http.StatusNotFound, fmt.Sprintf("test report of build %s not found", strings.Join(path, "/")))
+}
+func (d *Devops) getBuildArtifact(path ...string) ([]byte, error) {
+	if content, ok := d.BuildArtifacts[strings.Join(path, "/")]; ok {
+		return content, nil
+	}
+	return nil, restful.NewError(http.StatusNotFound, fmt.Sprintf("artifact %s not found", strings.Join(path, "/")))
 }
 
 // ProjectPipelineOperator
@@ -563,6 +630,18 @@ func (d *Devops) GetProjectPipelineConfig(projectId, pipelineId string) (*devops
 	return d.Pipelines[projectId][pipelineId], nil
 }
 
+func (d *Devops) ListProjectPipelines(projectId string) ([]string, error) {
+	if _, ok := d.Projects[projectId]; !ok {
+		return nil, restful.NewError(http.StatusNotFound, fmt.Sprintf("project %s not found", projectId))
+	}
+	names := make([]string, 0, len(d.Pipelines[projectId]))
+	for name := range d.Pipelines[projectId] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
 func (d *Devops) AddGlobalRole(roleName string, ids devops.GlobalPermissionIds, overwrite bool) error {
 	return nil
 }
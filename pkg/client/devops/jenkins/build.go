@@ -17,9 +17,11 @@ package jenkins
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/emicklei/go-restful"
@@ -418,6 +420,99 @@ func (j *Jenkins) GetMultiBranchPipelineBuildByType(projectId, pipelineId, branc
 	return
 }
 
+func (j *Jenkins) GetProjectPipelineBuild(projectId, pipelineId, runId string) (*devops.Build, error) {
+	return j.getBuild(runId, projectId, pipelineId)
+}
+
+func (j *Jenkins) GetMultiBranchPipelineBuild(projectId, pipelineId, branch, runId string) (*devops.Build, error) {
+	return j.getBuild(runId, projectId, pipelineId, branch)
+}
+
+// getBuild gets the build of the job by the run ID, the job is located by the names of itself and its parents
+func (j *Jenkins) getBuild(runId string, jobNames ...string) (*devops.Build, error) {
+	build := Build{
+		Jenkins: j,
+		Depth:   1,
+		Raw:     new(devops.Build),
+		Base:    "/job/" + strings.Join(jobNames, "/job/") + "/" + runId,
+	}
+	status, err := build.Poll()
+	if err != nil {
+		return nil, restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+	}
+	if status != http.StatusOK {
+		return nil, restful.NewError(status, fmt.Sprintf("failed to get build %s of job %s", runId, strings.Join(jobNames, "/")))
+	}
+	return build.Raw, nil
+}
+
+func (j *Jenkins) GetProjectPipelineBuildArtifact(projectId, pipelineId, runId, relativePath string) ([]byte, error) {
+	return j.getBuildArtifact(runId, relativePath, projectId, pipelineId)
+}
+
+func (j *Jenkins) GetMultiBranchPipelineBuildArtifact(projectId, pipelineId, branch, runId, relativePath string) ([]byte, error) {
+	return j.getBuildArtifact(runId, relativePath, projectId, pipelineId, branch)
+}
+
+// getBuildArtifact gets the content of an artifact archived by the build, the job is located the same as getBuild
+func (j *Jenkins) getBuildArtifact(runId, relativePath string, jobNames ...string) ([]byte, error) {
+	segments := strings.Split(relativePath, "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	endpoint := "/job/" + strings.Join(jobNames, "/job/") + "/" + runId + "/artifact/" + strings.Join(segments, "/")
+
+	var content string
+	if _, err := j.Requester.GetHtml(endpoint, &content, nil); err != nil {
+		return nil, restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+	}
+	return []byte(content), nil
+}
+
+func (j *Jenkins) GetProjectPipelineBuildTestReport(projectId, pipelineId, runId string) (*devops.TestReport, error) {
+	return j.getBuildTestReport(runId, projectId, pipelineId)
+}
+
+func (j *Jenkins) GetMultiBranchPipelineBuildTestReport(projectId, pipelineId, branch, runId string) (*devops.TestReport, error) {
+	return j.getBuildTestReport(runId, projectId, pipelineId, branch)
+}
+
+// getBuildTestReport gets the test report of the build, the job is located the same as getBuild
+func (j *Jenkins) getBuildTestReport(runId string, jobNames ...string) (*devops.TestReport, error) {
+	endpoint := "/job/" + strings.Join(jobNames, "/job/") + "/" + runId + "/testReport"
+	// the output of the test cases are not needed
+	query := map[string]string{
+		"tree": "duration,failCount,passCount,skipCount,suites[name,cases[className,name,status]]",
+	}
+
+	report := &devops.TestReport{}
+	if _, err := j.Requester.GetJSON(endpoint, report, query); err != nil {
+		return nil, restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+	}
+	return report, nil
+}
+
+func (j *Jenkins) TogglePausePipeline(projectName, pipelineName, runId string) error {
+	return j.togglePauseBuild(runId, projectName, pipelineName)
+}
+
+func (j *Jenkins) TogglePauseBranchPipeline(projectName, pipelineName, branchName, runId string) error {
+	return j.togglePauseBuild(runId, projectName, pipelineName, branchName)
+}
+
+// togglePauseBuild pauses or resumes the build, the job is located the same as getBuild. A paused build doesn't start
+// any new step, including input steps, until it's resumed
+func (j *Jenkins) togglePauseBuild(runId string, jobNames ...string) error {
+	build := &Build{
+		Jenkins: j,
+		Base:    "/job/" + strings.Join(jobNames, "/job/") + "/" + runId,
+	}
+	if err := build.PauseToggle(); err != nil {
+		return restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+	}
+	return nil
+}
+
 func getBuildByType(job *Job, typeStr string) (build *devops.Build, err error) {
 	var jobBuild *Build
 	if jobBuild, err = job.getBuildByType(typeStr); err == nil {
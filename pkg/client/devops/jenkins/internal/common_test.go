@@ -11,4 +11,5 @@ func TestCommonSituation(t *testing.T) {
 	AppendGitSourceToEtree(nil, nil)
 	AppendSingleSvnSourceToEtree(nil, nil)
 	AppendSvnSourceToEtree(nil, nil)
+	AppendGiteaSourceToEtree(nil, nil)
 }
@@ -0,0 +1,148 @@
+package internal
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/beevik/etree"
+	"k8s.io/klog"
+
+	devopsv1alpha3 "kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func AppendGiteaSourceToEtree(source *etree.Element, giteaSource *devopsv1alpha3.GiteaSource) {
+	if giteaSource == nil {
+		klog.Warning("please provide Gitea source when the sourceType is Gitea")
+		return
+	}
+	source.CreateAttr("class", "org.jenkinsci.plugin.gitea.GiteaSCMSource")
+	source.CreateAttr("plugin", "gitea")
+	source.CreateElement("id").SetText(giteaSource.ScmId)
+	source.CreateElement("serverUrl").SetText(giteaSource.ServerUrl)
+	source.CreateElement("repoOwner").SetText(giteaSource.Owner)
+	source.CreateElement("repository").SetText(giteaSource.Repo)
+	source.CreateElement("credentialsId").SetText(giteaSource.CredentialId)
+	traits := source.CreateElement("traits")
+	if giteaSource.DiscoverBranches != 0 {
+		traits.CreateElement("org.jenkinsci.plugin.gitea.BranchDiscoveryTrait").
+			CreateElement("strategyId").SetText(strconv.Itoa(giteaSource.DiscoverBranches))
+	}
+	if giteaSource.DiscoverPRFromOrigin != 0 {
+		traits.CreateElement("org.jenkinsci.plugin.gitea.OriginPullRequestDiscoveryTrait").
+			CreateElement("strategyId").SetText(strconv.Itoa(giteaSource.DiscoverPRFromOrigin))
+	}
+	if giteaSource.DiscoverPRFromForks != nil {
+		forkTrait := traits.CreateElement("org.jenkinsci.plugin.gitea.ForkPullRequestDiscoveryTrait")
+		forkTrait.CreateElement("strategyId").SetText(strconv.Itoa(giteaSource.DiscoverPRFromForks.Strategy))
+		trustClass := "org.jenkinsci.plugin.gitea.ForkPullRequestDiscoveryTrait$"
+		if prTrust := GiteaPRDiscoverTrust(giteaSource.DiscoverPRFromForks.Trust); prTrust.IsValid() {
+			trustClass += prTrust.String()
+		} else {
+			klog.Warningf("invalid Gitea discover PR trust value: %d", prTrust.Value())
+		}
+		forkTrait.CreateElement("trust").CreateAttr("class", trustClass)
+	}
+	if giteaSource.DiscoverTags {
+		traits.CreateElement("org.jenkinsci.plugin.gitea.TagDiscoveryTrait")
+	}
+	if giteaSource.CloneOption != nil {
+		cloneExtension := traits.CreateElement("jenkins.plugins.git.traits.CloneOptionTrait").CreateElement("extension")
+		cloneExtension.CreateAttr("class", "hudson.plugins.git.extensions.impl.CloneOption")
+		cloneExtension.CreateElement("shallow").SetText(strconv.FormatBool(giteaSource.CloneOption.Shallow))
+		cloneExtension.CreateElement("noTags").SetText(strconv.FormatBool(false))
+		cloneExtension.CreateElement("honorRefspec").SetText(strconv.FormatBool(true))
+		cloneExtension.CreateElement("reference")
+		if giteaSource.CloneOption.Timeout >= 0 {
+			cloneExtension.CreateElement("timeout").SetText(strconv.Itoa(giteaSource.CloneOption.Timeout))
+		} else {
+			cloneExtension.CreateElement("timeout").SetText(strconv.Itoa(10))
+		}
+
+		if giteaSource.CloneOption.Depth >= 0 {
+			cloneExtension.CreateElement("depth").SetText(strconv.Itoa(giteaSource.CloneOption.Depth))
+		} else {
+			cloneExtension.CreateElement("depth").SetText(strconv.Itoa(1))
+		}
+	}
+	if giteaSource.RegexFilter != "" {
+		regexTraits := traits.CreateElement("jenkins.scm.impl.trait.RegexSCMHeadFilterTrait")
+		regexTraits.CreateAttr("plugin", "scm-api")
+		regexTraits.CreateElement("regex").SetText(giteaSource.RegexFilter)
+	}
+	return
+}
+
+func GetGiteaSourceFromEtree(source *etree.Element) *devopsv1alpha3.GiteaSource {
+	var giteaSource devopsv1alpha3.GiteaSource
+	if id := source.SelectElement("id"); id != nil {
+		giteaSource.ScmId = id.Text()
+	}
+	if serverUrl := source.SelectElement("serverUrl"); serverUrl != nil {
+		giteaSource.ServerUrl = serverUrl.Text()
+	}
+	if repoOwner := source.SelectElement("repoOwner"); repoOwner != nil {
+		giteaSource.Owner = repoOwner.Text()
+	}
+	if repository := source.SelectElement("repository"); repository != nil {
+		giteaSource.Repo = repository.Text()
+	}
+	if credential := source.SelectElement("credentialsId"); credential != nil {
+		giteaSource.CredentialId = credential.Text()
+	}
+	traits := source.SelectElement("traits")
+	if traits == nil {
+		return &giteaSource
+	}
+	if branchDiscoverTrait := traits.SelectElement(
+		"org.jenkinsci.plugin.gitea.BranchDiscoveryTrait"); branchDiscoverTrait != nil {
+		strategyId, _ := strconv.Atoi(branchDiscoverTrait.SelectElement("strategyId").Text())
+		giteaSource.DiscoverBranches = strategyId
+	}
+	if tagDiscoverTrait := traits.SelectElement(
+		"org.jenkinsci.plugin.gitea.TagDiscoveryTrait"); tagDiscoverTrait != nil {
+		giteaSource.DiscoverTags = true
+	}
+	if originPRDiscoverTrait := traits.SelectElement(
+		"org.jenkinsci.plugin.gitea.OriginPullRequestDiscoveryTrait"); originPRDiscoverTrait != nil {
+		strategyId, _ := strconv.Atoi(originPRDiscoverTrait.SelectElement("strategyId").Text())
+		giteaSource.DiscoverPRFromOrigin = strategyId
+	}
+	if forkPRDiscoverTrait := traits.SelectElement(
+		"org.jenkinsci.plugin.gitea.ForkPullRequestDiscoveryTrait"); forkPRDiscoverTrait != nil {
+		strategyId, _ := strconv.Atoi(forkPRDiscoverTrait.SelectElement("strategyId").Text())
+		trustClass := forkPRDiscoverTrait.SelectElement("trust").SelectAttr("class").Value
+		trust := trustClass[strings.LastIndex(trustClass, "$")+1:]
+		if prTrust := GiteaPRDiscoverTrust(1).ParseFromString(trust); prTrust.IsValid() {
+			giteaSource.DiscoverPRFromForks = &devopsv1alpha3.DiscoverPRFromForks{
+				Strategy: strategyId,
+				Trust:    prTrust.Value(),
+			}
+		} else {
+			klog.Warningf("invalid Gitea discover PR trust value: %s", trust)
+		}
+	}
+	// the clone option and the regex filter don't depend on the discovery of PRs
+	if cloneTrait := traits.SelectElement(
+		"jenkins.plugins.git.traits.CloneOptionTrait"); cloneTrait != nil {
+		if cloneExtension := cloneTrait.SelectElement(
+			"extension"); cloneExtension != nil {
+			giteaSource.CloneOption = &devopsv1alpha3.GitCloneOption{}
+			if value, err := strconv.ParseBool(cloneExtension.SelectElement("shallow").Text()); err == nil {
+				giteaSource.CloneOption.Shallow = value
+			}
+			if value, err := strconv.ParseInt(cloneExtension.SelectElement("timeout").Text(), 10, 32); err == nil {
+				giteaSource.CloneOption.Timeout = int(value)
+			}
+			if value, err := strconv.ParseInt(cloneExtension.SelectElement("depth").Text(), 10, 32); err == nil {
+				giteaSource.CloneOption.Depth = int(value)
+			}
+		}
+	}
+	if regexTrait := traits.SelectElement(
+		"jenkins.scm.impl.trait.RegexSCMHeadFilterTrait"); regexTrait != nil {
+		if regex := regexTrait.SelectElement("regex"); regex != nil {
+			giteaSource.RegexFilter = regex.Text()
+		}
+	}
+	return &giteaSource
+}
@@ -741,6 +741,31 @@ func (j *Jenkins) GetConsoleLog(projectName, pipelineName string, httpParameters
 	return res, err
 }
 
+func (j *Jenkins) GetProgressiveLog(options devops.LogOptions) (*devops.ProgressiveLog, error) {
+	var path string
+	switch {
+	case options.BranchName != "" && options.NodeID != "":
+		path = fmt.Sprintf(GetBranchStepLogUrl, options.ProjectName, options.PipelineName, options.BranchName,
+			options.RunID, options.NodeID, options.StepID)
+	case options.BranchName != "":
+		path = fmt.Sprintf(GetBranchRunLogUrl, options.ProjectName, options.PipelineName, options.BranchName, options.RunID)
+	case options.NodeID != "":
+		path = fmt.Sprintf(GetStepLogUrl, options.ProjectName, options.PipelineName, options.RunID, options.NodeID, options.StepID)
+	default:
+		path = fmt.Sprintf(GetRunLogUrl, options.ProjectName, options.PipelineName, options.RunID)
+	}
+	PipelineOjb := &Pipeline{
+		HttpParameters: &devops.HttpParameters{
+			Method: http.MethodGet,
+			Header: http.Header{},
+			Url:    &url.URL{RawQuery: url.Values{"start": []string{strconv.FormatInt(options.Start, 10)}}.Encode()},
+		},
+		Jenkins: j,
+		Path:    path,
+	}
+	return PipelineOjb.GetProgressiveLog(options.Start)
+}
+
 func (j *Jenkins) GetCrumb(httpParameters *devops.HttpParameters) (*devops.Crumb, error) {
 	PipelineOjb := &Pipeline{
 		HttpParameters: httpParameters,
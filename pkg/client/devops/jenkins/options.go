@@ -32,6 +32,29 @@ type Options struct {
 	Namespace       string        `json:"namespace,omitempty" yaml:"namespace"`
 	WorkerNamespace string        `json:"workerNamespace,omitempty" yaml:"workerNamespace"`
 	ReloadCasCDelay time.Duration `json:"reloadCasCDelay,omitempty" yaml:"reloadCasCDelay"`
+
+	// PipelineRunPollInterval is the initial interval of polling a running PipelineRun from Jenkins. The polling is
+	// only a fallback when Jenkins doesn't send events to apiserver.
+	PipelineRunPollInterval time.Duration `json:"pipelineRunPollInterval,omitempty" yaml:"pipelineRunPollInterval"`
+	// PipelineRunMaxPollInterval is the upper limit of the interval of polling a running PipelineRun from Jenkins.
+	PipelineRunMaxPollInterval time.Duration `json:"pipelineRunMaxPollInterval,omitempty" yaml:"pipelineRunMaxPollInterval"`
+
+	// DriftDetectionInterval is the interval of detecting the drift between the resources in Kubernetes and Jenkins,
+	// e.g. jobs or credentials edited or deleted inside Jenkins. Zero means the detection is disabled.
+	DriftDetectionInterval time.Duration `json:"driftDetectionInterval,omitempty" yaml:"driftDetectionInterval"`
+	// DriftDetectionDryRun reports the drift without repairing it.
+	DriftDetectionDryRun bool `json:"driftDetectionDryRun,omitempty" yaml:"driftDetectionDryRun"`
+
+	// CredentialExpiryWarningPeriod is the period before the expiry of credentials in which warnings are reported.
+	// Zero means the expiry is not checked.
+	CredentialExpiryWarningPeriod time.Duration `json:"credentialExpiryWarningPeriod,omitempty" yaml:"credentialExpiryWarningPeriod"`
+
+	// EventToken is the token which Jenkins sends along with run and stage events. The events are rejected if it's
+	// empty, because the receiver of events is exposed without authentication.
+	EventToken string `json:"eventToken,omitempty" yaml:"eventToken"`
+	// AllowUnsignedSCMEvents allows the SCM webhook events for the Pipelines without a webhook secret. It's insecure,
+	// because anyone could trigger those Pipelines.
+	AllowUnsignedSCMEvents bool `json:"allowUnsignedSCMEvents,omitempty" yaml:"allowUnsignedSCMEvents"`
 }
 
 // NewJenkinsOptions returns a `zero` instance
@@ -46,7 +69,12 @@ func NewJenkinsOptions() *Options {
 		// Default syncFrequency of Kubernetes is "1m", and increasing it will result in longer refresh times for
 		// ConfigMap, so we use 70s as the default value of ReloadCasCDelay. Please see also:
 		// https://kubernetes.io/docs/reference/config-api/kubelet-config.v1beta1/#kubelet-config-k8s-io-v1beta1-KubeletConfiguration
-		ReloadCasCDelay: 70 * time.Second,
+		ReloadCasCDelay:            70 * time.Second,
+		PipelineRunPollInterval:    30 * time.Second,
+		PipelineRunMaxPollInterval: 5 * time.Minute,
+		DriftDetectionInterval:     30 * time.Minute,
+		// a week is long enough to rotate the expiring credentials
+		CredentialExpiryWarningPeriod: 7 * 24 * time.Hour,
 	}
 }
 
@@ -74,6 +102,10 @@ func (s *Options) Validate() []error {
 		errors = append(errors, fmt.Errorf("jenkins's maximum connections should be greater than 0"))
 	}
 
+	if s.PipelineRunMaxPollInterval > 0 && s.PipelineRunMaxPollInterval < s.PipelineRunPollInterval {
+		errors = append(errors, fmt.Errorf("the maximum poll interval of PipelineRun should not be less than the poll interval"))
+	}
+
 	return errors
 }
 
@@ -96,4 +128,25 @@ func (s *Options) AddFlags(fs *pflag.FlagSet, c *Options) {
 	fs.DurationVar(&s.ReloadCasCDelay, "reload-casc-delay", c.ReloadCasCDelay,
 		"ReloadCasCDelay specifies the total duration that controller should delay the reload action for "+
 			"jenkins-casc-config ConfigMap change, and it is only valid for controller manager.")
+	fs.DurationVar(&s.PipelineRunPollInterval, "pipelinerun-poll-interval", c.PipelineRunPollInterval,
+		"PipelineRunPollInterval specifies the initial interval of polling running PipelineRuns from Jenkins. "+
+			"The interval grows with the elapsed time of PipelineRuns, and it is only valid for controller manager.")
+	fs.DurationVar(&s.PipelineRunMaxPollInterval, "pipelinerun-max-poll-interval", c.PipelineRunMaxPollInterval,
+		"PipelineRunMaxPollInterval specifies the maximum interval of polling running PipelineRuns from Jenkins, "+
+			"and it is only valid for controller manager.")
+	fs.DurationVar(&s.DriftDetectionInterval, "drift-detection-interval", c.DriftDetectionInterval,
+		"DriftDetectionInterval specifies the interval of detecting the drift between the resources in Kubernetes and "+
+			"Jenkins. Zero means the detection is disabled, and it is only valid for controller manager.")
+	fs.BoolVar(&s.DriftDetectionDryRun, "drift-detection-dry-run", c.DriftDetectionDryRun,
+		"DriftDetectionDryRun reports the drift between the resources in Kubernetes and Jenkins without repairing it, "+
+			"and it is only valid for controller manager.")
+	fs.DurationVar(&s.CredentialExpiryWarningPeriod, "credential-expiry-warning-period", c.CredentialExpiryWarningPeriod,
+		"CredentialExpiryWarningPeriod specifies the period before the expiry of credentials in which warnings are "+
+			"reported. Zero means the expiry is not checked, and it is only valid for controller manager.")
+	fs.StringVar(&s.EventToken, "jenkins-event-token", c.EventToken,
+		"EventToken specifies the token which Jenkins sends along with run and stage events. The events are rejected "+
+			"if it's empty, and it is only valid for apiserver.")
+	fs.BoolVar(&s.AllowUnsignedSCMEvents, "allow-unsigned-scm-events", c.AllowUnsignedSCMEvents,
+		"AllowUnsignedSCMEvents allows the SCM webhook events for the Pipelines without a webhook secret. It's "+
+			"insecure, and it is only valid for apiserver.")
 }
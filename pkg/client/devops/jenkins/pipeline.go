@@ -597,6 +597,30 @@ func (p *Pipeline) GetConsoleLog() ([]byte, error) {
 	return res, err
 }
 
+// GetProgressiveLog returns a part of the log from the start offset. Jenkins tells the offset of the next part and
+// whether more log is coming through the headers X-Text-Size and X-More-Data.
+func (p *Pipeline) GetProgressiveLog(start int64) (*devops.ProgressiveLog, error) {
+	res, header, err := p.Jenkins.SendPureRequestWithHeaderResp(p.Path, p.HttpParameters)
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+
+	return parseProgressiveLog(res, header, start), nil
+}
+
+func parseProgressiveLog(text []byte, header http.Header, start int64) *devops.ProgressiveLog {
+	progressiveLog := &devops.ProgressiveLog{
+		Text:      text,
+		NextStart: start + int64(len(text)),
+		HasMore:   header.Get("X-More-Data") == "true",
+	}
+	if textSize, err := strconv.ParseInt(header.Get("X-Text-Size"), 10, 64); err == nil {
+		progressiveLog.NextStart = textSize
+	}
+	return progressiveLog
+}
+
 func (p *Pipeline) GetCrumb() (*devops.Crumb, error) {
 	res, err := p.Jenkins.SendPureRequest(p.Path, p.HttpParameters)
 	if err != nil {
@@ -386,6 +386,8 @@ func createMultiBranchPipelineConfigXml(projectName string, pipeline *devopsv1al
 		internal.AppendSingleSvnSourceToEtree(source, pipeline.SingleSvnSource)
 	case devopsv1alpha3.SourceTypeBitbucket:
 		internal.AppendBitbucketServerSourceToEtree(source, pipeline.BitbucketServerSource)
+	case devopsv1alpha3.SourceTypeGitea:
+		internal.AppendGiteaSourceToEtree(source, pipeline.GiteaSource)
 
 	default:
 		return "", fmt.Errorf("unsupport source type: %s", pipeline.SourceType)
@@ -452,6 +454,9 @@ func parseMultiBranchPipelineConfigXml(config string) (*devopsv1alpha3.MultiBran
 				case "io.jenkins.plugins.gitlabbranchsource.GitLabSCMSource":
 					pipeline.GitlabSource = internal.GetGitlabSourceFromEtree(source)
 					pipeline.SourceType = devopsv1alpha3.SourceTypeGitlab
+				case "org.jenkinsci.plugin.gitea.GiteaSCMSource":
+					pipeline.GiteaSource = internal.GetGiteaSourceFromEtree(source)
+					pipeline.SourceType = devopsv1alpha3.SourceTypeGitea
 
 				case "jenkins.plugins.git.GitSCMSource":
 					pipeline.SourceType = devopsv1alpha3.SourceTypeGit
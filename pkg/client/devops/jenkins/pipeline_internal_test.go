@@ -258,6 +258,13 @@ func Test_MultiBranchPipelineConfig(t *testing.T) {
 			SourceType:   "gitlab",
 			GitlabSource: &devopsv1alpha3.GitlabSource{},
 		},
+		{
+			Name:        "",
+			Description: "for test",
+			ScriptPath:  "Jenkinsfile",
+			SourceType:  "gitea",
+			GiteaSource: &devopsv1alpha3.GiteaSource{},
+		},
 	}
 	for _, input := range inputs {
 		outputString, err := createMultiBranchPipelineConfigXml("", input)
@@ -461,6 +468,56 @@ func Test_MultiBranchPipelineConfig_Source(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name:        "",
+			Description: "for test",
+			ScriptPath:  "Jenkinsfile",
+			SourceType:  "gitea",
+			TimerTrigger: &devopsv1alpha3.TimerTrigger{
+				Interval: "12345566",
+			},
+			GiteaSource: &devopsv1alpha3.GiteaSource{
+				ScmId:                "gitea-scm",
+				ServerUrl:            "https://gitea.example.com",
+				Owner:                "kubesphere",
+				Repo:                 "devops",
+				CredentialId:         "gitea",
+				DiscoverBranches:     1,
+				DiscoverPRFromOrigin: 2,
+				DiscoverTags:         true,
+				DiscoverPRFromForks: &devopsv1alpha3.DiscoverPRFromForks{
+					Strategy: 1,
+					Trust:    1,
+				},
+				RegexFilter: "*-dev",
+			},
+		},
+		{
+			Name:        "",
+			Description: "for test",
+			ScriptPath:  "Jenkinsfile",
+			SourceType:  "gitea",
+			GiteaSource: &devopsv1alpha3.GiteaSource{
+				ServerUrl: "https://gitea.example.com",
+				DiscoverPRFromForks: &devopsv1alpha3.DiscoverPRFromForks{
+					Strategy: 2,
+					Trust:    2,
+				},
+			},
+		},
+		{
+			Name:        "",
+			Description: "for test",
+			ScriptPath:  "Jenkinsfile",
+			SourceType:  "gitea",
+			GiteaSource: &devopsv1alpha3.GiteaSource{
+				ServerUrl: "https://gitea.example.com",
+				DiscoverPRFromForks: &devopsv1alpha3.DiscoverPRFromForks{
+					Strategy: 3,
+					Trust:    4,
+				},
+			},
+		},
 
 		{
 			Name:        "",
@@ -566,6 +623,23 @@ func Test_MultiBranchPipelineCloneConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name:        "",
+			Description: "for test",
+			ScriptPath:  "Jenkinsfile",
+			SourceType:  "gitea",
+			GiteaSource: &devopsv1alpha3.GiteaSource{
+				ServerUrl:        "https://gitea.example.com",
+				Owner:            "kubesphere",
+				Repo:             "devops",
+				DiscoverBranches: 1,
+				CloneOption: &devopsv1alpha3.GitCloneOption{
+					Shallow: true,
+					Depth:   3,
+					Timeout: 20,
+				},
+			},
+		},
 	}
 
 	for _, input := range inputs {
@@ -630,6 +704,19 @@ func Test_MultiBranchPipelineRegexFilter(t *testing.T) {
 				RegexFilter: ".*",
 			},
 		},
+		{
+			Name:        "",
+			Description: "for test",
+			ScriptPath:  "Jenkinsfile",
+			SourceType:  "gitea",
+			GiteaSource: &devopsv1alpha3.GiteaSource{
+				ServerUrl:        "https://gitea.example.com",
+				Owner:            "kubesphere",
+				Repo:             "devops",
+				DiscoverBranches: 1,
+				RegexFilter:      ".*",
+			},
+		},
 	}
 
 	for _, input := range inputs {
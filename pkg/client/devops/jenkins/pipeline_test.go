@@ -2,6 +2,7 @@ package jenkins
 
 import (
 	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
 	"testing"
@@ -106,3 +107,33 @@ func TestParsePaging(t *testing.T) {
 func printTestMessage(index int, message string) string {
 	return fmt.Sprintf("index: %d, message: %s", index, message)
 }
+
+func TestParseProgressiveLog(t *testing.T) {
+	table := []struct {
+		text    string
+		header  http.Header
+		start   int64
+		want    *devops.ProgressiveLog
+		message string
+	}{{
+		text: "hello",
+		header: http.Header{
+			"X-Text-Size": []string{"15"},
+			"X-More-Data": []string{"true"},
+		},
+		start:   10,
+		want:    &devops.ProgressiveLog{Text: []byte("hello"), NextStart: 15, HasMore: true},
+		message: "with headers",
+	}, {
+		text:    "hello",
+		header:  http.Header{},
+		start:   10,
+		want:    &devops.ProgressiveLog{Text: []byte("hello"), NextStart: 15},
+		message: "without headers",
+	}}
+
+	for index, item := range table {
+		result := parseProgressiveLog([]byte(item.text), item.header, item.start)
+		assert.Equal(t, item.want, result, "index: [%d], message: %s", index, item.message)
+	}
+}
@@ -181,7 +181,21 @@ func (j *Jenkins) GetProjectPipelineConfig(projectId, pipelineId string) (*devop
 			},
 		}, nil
 	default:
+		err = fmt.Errorf("unsupported job type %s", job.Raw.Class)
 		klog.Errorf("%+v", err)
 		return nil, restful.NewError(http.StatusBadRequest, err.Error())
 	}
 }
+
+func (j *Jenkins) ListProjectPipelines(projectId string) ([]string, error) {
+	folder, err := j.GetJob(projectId)
+	if err != nil {
+		klog.Errorf("%+v", err)
+		return nil, restful.NewError(devops.GetDevOpsStatusCode(err), err.Error())
+	}
+	names := make([]string, 0, len(folder.Raw.Jobs))
+	for _, job := range folder.Raw.Jobs {
+		names = append(names, job.Name)
+	}
+	return names, nil
+}
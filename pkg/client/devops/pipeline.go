@@ -1122,6 +1122,29 @@ type HttpParameters struct {
 	Url      *url.URL      `json:"url,omitempty"`
 }
 
+// LogOptions locates a part of the log of a PipelineRun in Jenkins.
+type LogOptions struct {
+	ProjectName  string
+	PipelineName string
+	// BranchName is only required for multi-branch Pipeline
+	BranchName string
+	RunID      string
+	// NodeID and StepID filter the log of a specific step. The log of the whole run is returned if they are empty.
+	NodeID string
+	StepID string
+	// Start is the offset in bytes where the log starts
+	Start int64
+}
+
+// ProgressiveLog is a part of the log of a PipelineRun.
+type ProgressiveLog struct {
+	Text []byte
+	// NextStart is the offset in bytes where the next part of the log starts
+	NextStart int64
+	// HasMore indicates if more log is going to be written, e.g. the PipelineRun is still running
+	HasMore bool
+}
+
 type PipelineOperator interface {
 	// Pipelinne operator interface
 	GetPipeline(projectName, pipelineName string, httpParameters *HttpParameters) (*Pipeline, error)
@@ -1129,6 +1152,8 @@ type PipelineOperator interface {
 	GetPipelineRun(projectName, pipelineName, runId string, httpParameters *HttpParameters) (*PipelineRun, error)
 	ListPipelineRuns(projectName, pipelineName string, httpParameters *HttpParameters) (*PipelineRunList, error)
 	StopPipeline(projectName, pipelineName, runId string, httpParameters *HttpParameters) (*StopPipeline, error)
+	// TogglePausePipeline pauses the run of the pipeline before its next step, or resumes it if it has been paused
+	TogglePausePipeline(projectName, pipelineName, runId string) error
 	ReplayPipeline(projectName, pipelineName, runId string, httpParameters *HttpParameters) (*ReplayPipeline, error)
 	RunPipeline(projectName, pipelineName string, httpParameters *HttpParameters) (*RunPipeline, error)
 	GetArtifacts(projectName, pipelineName, runId string, httpParameters *HttpParameters) ([]Artifacts, error)
@@ -1142,6 +1167,8 @@ type PipelineOperator interface {
 	GetBranchPipeline(projectName, pipelineName, branchName string, httpParameters *HttpParameters) (*BranchPipeline, error)
 	GetBranchPipelineRun(projectName, pipelineName, branchName, runId string, httpParameters *HttpParameters) (*PipelineRun, error)
 	StopBranchPipeline(projectName, pipelineName, branchName, runId string, httpParameters *HttpParameters) (*StopPipeline, error)
+	// TogglePauseBranchPipeline pauses the run of the pipeline branch before its next step, or resumes it if it has been paused
+	TogglePauseBranchPipeline(projectName, pipelineName, branchName, runId string) error
 	ReplayBranchPipeline(projectName, pipelineName, branchName, runId string, httpParameters *HttpParameters) (*ReplayPipeline, error)
 	RunBranchPipeline(projectName, pipelineName, branchName string, httpParameters *HttpParameters) (*RunPipeline, error)
 	GetBranchArtifacts(projectName, pipelineName, branchName, runId string, httpParameters *HttpParameters) ([]Artifacts, error)
@@ -1155,6 +1182,8 @@ type PipelineOperator interface {
 
 	// Common pipeline operator interface
 	GetConsoleLog(projectName, pipelineName string, httpParameters *HttpParameters) ([]byte, error)
+	// GetProgressiveLog returns a part of the log of a PipelineRun or a step, starting from the given offset
+	GetProgressiveLog(options LogOptions) (*ProgressiveLog, error)
 	GetCrumb(httpParameters *HttpParameters) (*Crumb, error)
 
 	// SCM operator interface
@@ -23,4 +23,6 @@ type ProjectPipelineOperator interface {
 	DeleteProjectPipeline(projectId string, pipelineId string) (string, error)
 	UpdateProjectPipeline(projectId string, pipeline *v1alpha3.Pipeline) (string, error)
 	GetProjectPipelineConfig(projectId, pipelineId string) (*v1alpha3.Pipeline, error)
+	// ListProjectPipelines returns the names of the jobs in the folder of the project
+	ListProjectPipelines(projectId string) ([]string, error)
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package fake
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -44,6 +45,14 @@ type Object struct {
 }
 
 func (s *FakeS3) Upload(key, fileName string, body io.Reader) error {
+	// consume the body as the real uploader does, the body might be a stream
+	if body != nil {
+		data, err := ioutil.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
 	s.Storage[key] = &Object{
 		Key:      key,
 		FileName: fileName,
@@ -74,3 +83,21 @@ func (s *FakeS3) Read(key string) ([]byte, error) {
 	}
 	return nil, awserr.New(s3.ErrCodeNoSuchKey, "no such object", nil)
 }
+
+func (s *FakeS3) ReadFrom(key string, offset int64) (io.ReadCloser, int64, error) {
+	o, ok := s.Storage[key]
+	if !ok || o.Body == nil {
+		return nil, 0, awserr.New(s3.ErrCodeNoSuchKey, "no such object", nil)
+	}
+	data, err := ioutil.ReadAll(o.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	// the object is able to be read again
+	o.Body = bytes.NewReader(data)
+	size := int64(len(data))
+	if offset > size {
+		offset = size
+	}
+	return ioutil.NopCloser(bytes.NewReader(data[offset:])), size, nil
+}
@@ -24,6 +24,10 @@ type Interface interface {
 	//read the content, caller should close the io.ReadCloser.
 	Read(key string) ([]byte, error)
 
+	// ReadFrom reads the content from the offset to the end, and returns the size of the whole object. The caller
+	// should close the io.ReadCloser.
+	ReadFrom(key string, offset int64) (io.ReadCloser, int64, error)
+
 	// Upload uploads a object to storage and returns object location if succeeded
 	Upload(key, fileName string, body io.Reader) error
 
@@ -19,10 +19,15 @@ package s3
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"code.cloudfoundry.org/bytefmt"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -68,6 +73,43 @@ func (s *Client) Read(key string) ([]byte, error) {
 	return writer.Bytes(), nil
 }
 
+func (s *Client) ReadFrom(key string, offset int64) (io.ReadCloser, int64, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}
+	if offset > 0 {
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-", offset))
+	}
+	output, err := s.s3Client.GetObject(input)
+	if err != nil {
+		if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() == http.StatusRequestedRangeNotSatisfiable {
+			// the offset is not less than the size, there is nothing to read
+			head, err := s.s3Client.HeadObject(&s3.HeadObjectInput{
+				Bucket: aws.String(s.bucket),
+				Key:    aws.String(key),
+			})
+			if err != nil {
+				return nil, 0, err
+			}
+			return ioutil.NopCloser(strings.NewReader("")), aws.Int64Value(head.ContentLength), nil
+		}
+		return nil, 0, err
+	}
+
+	size := aws.Int64Value(output.ContentLength)
+	// the content range looks like "bytes 100-199/200"
+	if contentRange := aws.StringValue(output.ContentRange); contentRange != "" {
+		if index := strings.LastIndex(contentRange, "/"); index >= 0 {
+			if size, err = strconv.ParseInt(contentRange[index+1:], 10, 64); err != nil {
+				_ = output.Body.Close()
+				return nil, 0, fmt.Errorf("invalid content range %s of object %s", contentRange, key)
+			}
+		}
+	}
+	return output.Body, size, nil
+}
+
 func (s *Client) GetDownloadURL(key string, fileName string) (string, error) {
 	req, _ := s.s3Client.GetObjectRequest(&s3.GetObjectInput{
 		Bucket:                     aws.String(s.bucket),
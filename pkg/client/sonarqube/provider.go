@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sonarqube
+
+import (
+	"strconv"
+
+	"kubesphere.io/devops/pkg/client/codequality"
+)
+
+// ProviderName is the name of SonarQube as a code quality provider
+const ProviderName = "sonarqube"
+
+type codeQualityProvider struct {
+	client SonarInterface
+}
+
+// NewCodeQualityProvider creates a code quality provider which reports the analyses of SonarQube
+func NewCodeQualityProvider(client SonarInterface) codequality.CodeQualityProvider {
+	return &codeQualityProvider{client: client}
+}
+
+func (p *codeQualityProvider) Name() string {
+	return ProviderName
+}
+
+// GetReports reports the analyses whose task IDs are recorded by the SonarQube plugin of Jenkins
+func (p *codeQualityProvider) GetReports(build *codequality.BuildReference) ([]*codequality.Report, error) {
+	var reports []*codequality.Report
+	taskIDs := make(map[string]bool)
+	for _, action := range build.Build.Actions {
+		if action.ClassName != SonarAnalysisActionClass || action.SonarTaskId == "" || taskIDs[action.SonarTaskId] {
+			continue
+		}
+		taskIDs[action.SonarTaskId] = true
+
+		// the results of the tasks which failed are skipped
+		statuses, err := p.client.GetSonarResultsByTaskIds(action.SonarTaskId)
+		if err != nil {
+			return nil, err
+		}
+		for _, status := range statuses {
+			summary, err := status.Summarize()
+			if err != nil {
+				return nil, err
+			}
+			reports = append(reports, newReport(summary, status, action.SonarDashboardUrl))
+		}
+	}
+	return reports, nil
+}
+
+func newReport(summary *QualityGateSummary, status *SonarStatus, dashboardURL string) *codequality.Report {
+	report := &codequality.Report{
+		Provider:     ProviderName,
+		Tool:         "SonarQube",
+		Component:    summary.Component,
+		Status:       summary.Status,
+		DashboardURL: dashboardURL,
+		Details:      status,
+	}
+	if summary.Pending() || report.Status == "" {
+		report.Status = codequality.StatusNone
+	}
+	if !summary.Pending() {
+		report.Measures = map[string]string{
+			MetricBugs:            strconv.Itoa(summary.Bugs),
+			MetricVulnerabilities: strconv.Itoa(summary.Vulnerabilities),
+		}
+		if summary.Coverage != "" {
+			report.Measures[MetricCoverage] = summary.Coverage
+		}
+	}
+	return report
+}
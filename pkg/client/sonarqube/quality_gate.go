@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sonarqube
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// The statuses of the background tasks of SonarQube
+const (
+	TaskPending    = "PENDING"
+	TaskInProgress = "IN_PROGRESS"
+	TaskSuccess    = "SUCCESS"
+	TaskFailed     = "FAILED"
+	TaskCanceled   = "CANCELED"
+)
+
+// The metrics of the quality gate summary
+const (
+	MetricAlertStatus     = "alert_status"
+	MetricBugs            = "bugs"
+	MetricVulnerabilities = "vulnerabilities"
+	MetricCoverage        = "coverage"
+)
+
+// QualityGateSummary is the summary of the result of an analysis task
+type QualityGateSummary struct {
+	// Component is the key of the analyzed project
+	Component string
+	// TaskStatus is the status of the analysis task, the measures are not ready until the task succeeds
+	TaskStatus string
+	// Status is the status of the quality gate, e.g. OK, WARN, ERROR. It's empty if there is no quality gate
+	Status          string
+	Bugs            int
+	Vulnerabilities int
+	// Coverage is the percentage of the covered code, it's empty if the coverage is unknown
+	Coverage string
+}
+
+// Pending indicates if the analysis task has not finished yet
+func (s *QualityGateSummary) Pending() bool {
+	return s.TaskStatus == TaskPending || s.TaskStatus == TaskInProgress
+}
+
+// sonarResult is the part of the result which is summarized, the fields follow the web API of SonarQube
+type sonarResult struct {
+	Measures *struct {
+		Component *struct {
+			Key      string `json:"key"`
+			Measures []struct {
+				Metric string `json:"metric"`
+				Value  string `json:"value"`
+			} `json:"measures"`
+		} `json:"component"`
+	} `json:"measures"`
+	Task *struct {
+		Task *struct {
+			ComponentKey string `json:"componentKey"`
+			Status       string `json:"status"`
+		} `json:"task"`
+	} `json:"task"`
+}
+
+// Summarize returns the quality gate summary of the analysis result
+func (s *SonarStatus) Summarize() (*QualityGateSummary, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var result sonarResult
+	if err = json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	summary := &QualityGateSummary{}
+	if result.Task != nil && result.Task.Task != nil {
+		summary.Component = result.Task.Task.ComponentKey
+		summary.TaskStatus = result.Task.Task.Status
+	}
+	if result.Measures == nil || result.Measures.Component == nil {
+		return summary, nil
+	}
+	if summary.Component == "" {
+		summary.Component = result.Measures.Component.Key
+	}
+	for _, measure := range result.Measures.Component.Measures {
+		switch measure.Metric {
+		case MetricAlertStatus:
+			summary.Status = measure.Value
+		case MetricBugs:
+			summary.Bugs, _ = strconv.Atoi(measure.Value)
+		case MetricVulnerabilities:
+			summary.Vulnerabilities, _ = strconv.Atoi(measure.Value)
+		case MetricCoverage:
+			summary.Coverage = measure.Value
+		}
+	}
+	return summary, nil
+}
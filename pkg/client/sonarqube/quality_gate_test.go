@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sonarqube
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSonarStatus_Summarize(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  string
+		want    *QualityGateSummary
+		pending bool
+	}{{
+		name: "Finished analysis",
+		result: `{
+			"task": {"task": {"id": "AXo1", "componentKey": "devops", "status": "SUCCESS"}},
+			"measures": {"component": {"key": "devops", "measures": [
+				{"metric": "alert_status", "value": "ERROR"},
+				{"metric": "bugs", "value": "3"},
+				{"metric": "vulnerabilities", "value": "1"},
+				{"metric": "coverage", "value": "85.3"},
+				{"metric": "code_smells", "value": "20"}
+			]}}
+		}`,
+		want: &QualityGateSummary{
+			Component:       "devops",
+			TaskStatus:      TaskSuccess,
+			Status:          "ERROR",
+			Bugs:            3,
+			Vulnerabilities: 1,
+			Coverage:        "85.3",
+		},
+	}, {
+		name:    "Pending analysis",
+		result:  `{"task": {"task": {"id": "AXo2", "componentKey": "devops", "status": "IN_PROGRESS"}}}`,
+		want:    &QualityGateSummary{Component: "devops", TaskStatus: TaskInProgress},
+		pending: true,
+	}, {
+		name:   "Project without tests",
+		result: `{"measures": {"component": {"key": "devops", "measures": [{"metric": "alert_status", "value": "OK"}]}}}`,
+		want:   &QualityGateSummary{Component: "devops", Status: "OK"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := &SonarStatus{}
+			if !assert.Nil(t, json.Unmarshal([]byte(tt.result), status)) {
+				return
+			}
+			summary, err := status.Summarize()
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, summary)
+			assert.Equal(t, tt.pending, summary.Pending())
+		})
+	}
+}
@@ -0,0 +1,26 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+// ProviderName is the name of Vault in the external references of credentials
+const ProviderName = "vault"
+
+// Interface reads secrets from the KV secrets engine (version 2) of Vault
+type Interface interface {
+	// Read returns the data and the version of the latest version of the secret in the path
+	Read(path string) (map[string][]byte, string, error)
+}
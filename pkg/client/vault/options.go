@@ -0,0 +1,80 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"kubesphere.io/devops/pkg/utils/reflectutils"
+)
+
+// Options contains configuration to access the KV secrets engine (version 2) of HashiCorp Vault
+type Options struct {
+	Address string `json:"address,omitempty" yaml:"address"`
+	Token   string `json:"token,omitempty" yaml:"token"`
+	// Namespace is the namespace of Vault Enterprise, leave it blank for the open source Vault
+	Namespace string `json:"namespace,omitempty" yaml:"namespace"`
+	// Mount is the path where the KV secrets engine is mounted
+	Mount string `json:"mount,omitempty" yaml:"mount"`
+	// RefreshInterval is the interval of synchronizing the credential data in Vault into Jenkins again, so that the
+	// data rotated in Vault reaches Jenkins eventually
+	RefreshInterval time.Duration `json:"refreshInterval,omitempty" yaml:"refreshInterval"`
+}
+
+// NewVaultOptions creates a default disabled Options(empty address)
+func NewVaultOptions() *Options {
+	return &Options{
+		Address:         "",
+		Token:           "",
+		Namespace:       "",
+		Mount:           "secret",
+		RefreshInterval: 10 * time.Minute,
+	}
+}
+
+// Validate check options values
+func (s *Options) Validate() []error {
+	var errors []error
+
+	return errors
+}
+
+// ApplyTo overrides options if it's valid, which address is not empty
+func (s *Options) ApplyTo(options *Options) {
+	if s.Address != "" {
+		reflectutils.Override(options, s)
+	}
+}
+
+// AddFlags add options flags to command line flags,
+// if vault-address if left empty, following options will be ignored
+func (s *Options) AddFlags(fs *pflag.FlagSet, c *Options) {
+	fs.StringVar(&s.Address, "vault-address", c.Address, ""+
+		"Address of HashiCorp Vault which stores the data of credentials, if left blank, the following options "+
+		"will be ignored.")
+
+	fs.StringVar(&s.Token, "vault-token", c.Token, "token for access to Vault")
+
+	fs.StringVar(&s.Namespace, "vault-namespace", c.Namespace, "namespace of Vault Enterprise")
+
+	fs.StringVar(&s.Mount, "vault-mount", c.Mount, "path where the KV secrets engine version 2 is mounted")
+
+	fs.DurationVar(&s.RefreshInterval, "vault-refresh-interval", c.RefreshInterval, ""+
+		"interval of synchronizing the credential data in Vault into Jenkins again")
+}
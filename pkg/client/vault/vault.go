@@ -0,0 +1,111 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	tokenHeader     = "X-Vault-Token"
+	namespaceHeader = "X-Vault-Namespace"
+)
+
+// Client reads secrets through the HTTP API of Vault
+type Client struct {
+	options    Options
+	httpClient *http.Client
+}
+
+// kvResponse is the response of reading a secret from the KV secrets engine (version 2)
+type kvResponse struct {
+	Data *struct {
+		Data     map[string]interface{} `json:"data"`
+		Metadata struct {
+			Version   int  `json:"version"`
+			Destroyed bool `json:"destroyed"`
+		} `json:"metadata"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+// NewVaultClient creates a client of Vault
+func NewVaultClient(options *Options) (*Client, error) {
+	if options.Address == "" {
+		return nil, fmt.Errorf("the address of vault is required")
+	}
+	return &Client{
+		options:    *options,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Read returns the data of the latest version of the secret in the path, and the number of the version. The values
+// which are not strings are encoded in JSON.
+func (c *Client) Read(path string) (map[string][]byte, string, error) {
+	url := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimSuffix(c.options.Address, "/"),
+		strings.Trim(c.options.Mount, "/"), strings.TrimPrefix(path, "/"))
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set(tokenHeader, c.options.Token)
+	if c.options.Namespace != "" {
+		req.Header.Set(namespaceHeader, c.options.Namespace)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	result := &kvResponse{}
+	if err := json.Unmarshal(body, result); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, "", fmt.Errorf("failed to parse the secret %s from vault, error %v", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to read the secret %s from vault, status code %d, errors %v",
+			path, resp.StatusCode, result.Errors)
+	}
+	// the data is null if the latest version was deleted or destroyed
+	if result.Data == nil || result.Data.Data == nil {
+		return nil, "", fmt.Errorf("the latest version of secret %s in vault was deleted", path)
+	}
+
+	data := make(map[string][]byte, len(result.Data.Data))
+	for key, value := range result.Data.Data {
+		if str, ok := value.(string); ok {
+			data[key] = []byte(str)
+		} else if data[key], err = json.Marshal(value); err != nil {
+			return nil, "", err
+		}
+	}
+	return data, strconv.Itoa(result.Data.Metadata.Version), nil
+}
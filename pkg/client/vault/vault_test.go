@@ -0,0 +1,110 @@
+/*
+Copyright 2021 KubeSphere Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newKVServer returns a stand-in of Vault which serves the secrets of the KV secrets engine mounted at "secret"
+func newKVServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get(tokenHeader) != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/devops/github":
+			assert.Equal(t, "team", r.Header.Get(namespaceHeader))
+			_, _ = w.Write([]byte(`{"data":{"data":{"username":"admin","password":"secret","port":22},` +
+				`"metadata":{"version":2,"destroyed":false}}}`))
+		case "/v1/secret/data/devops/deleted":
+			_, _ = w.Write([]byte(`{"data":{"data":null,"metadata":{"version":3,"destroyed":true}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+}
+
+func TestClient_Read(t *testing.T) {
+	server := newKVServer(t)
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		token       string
+		path        string
+		want        map[string][]byte
+		wantVersion string
+		wantErr     bool
+	}{{
+		name:  "normal case",
+		token: "token",
+		path:  "devops/github",
+		want: map[string][]byte{
+			"username": []byte("admin"),
+			"password": []byte("secret"),
+			"port":     []byte("22"),
+		},
+		wantVersion: "2",
+	}, {
+		name:    "deleted secret",
+		token:   "token",
+		path:    "devops/deleted",
+		wantErr: true,
+	}, {
+		name:    "not found",
+		token:   "token",
+		path:    "devops/missing",
+		wantErr: true,
+	}, {
+		name:    "permission denied",
+		token:   "invalid",
+		path:    "devops/github",
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := NewVaultOptions()
+			options.Address = server.URL + "/"
+			options.Token = tt.token
+			options.Namespace = "team"
+			client, err := NewVaultClient(options)
+			if !assert.Nil(t, err) {
+				return
+			}
+
+			got, version, err := client.Read(tt.path)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantVersion, version)
+		})
+	}
+}
+
+func TestNewVaultClient(t *testing.T) {
+	_, err := NewVaultClient(NewVaultOptions())
+	assert.NotNil(t, err)
+}
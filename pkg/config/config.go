@@ -18,7 +18,9 @@ package config
 
 import (
 	"fmt"
+	auditingoptions "kubesphere.io/devops/pkg/apiserver/auditing/options"
 	authoptions "kubesphere.io/devops/pkg/apiserver/authentication/options"
+	authzoptions "kubesphere.io/devops/pkg/apiserver/authorization/options"
 	"kubesphere.io/devops/pkg/client/cache"
 	"kubesphere.io/devops/pkg/client/k8s"
 	"kubesphere.io/devops/pkg/client/sonarqube"
@@ -29,6 +31,7 @@ import (
 
 	"kubesphere.io/devops/pkg/client/devops/jenkins"
 	"kubesphere.io/devops/pkg/client/s3"
+	"kubesphere.io/devops/pkg/client/vault"
 )
 
 // Package config saves configuration for running KubeSphere components
@@ -83,8 +86,11 @@ type Config struct {
 	KubernetesOptions     *k8s.KubernetesOptions             `json:"kubernetes,omitempty" yaml:"kubernetes,omitempty" mapstructure:"kubernetes"`
 	RedisOptions          *cache.Options                     `json:"redis,omitempty" yaml:"redis,omitempty" mapstructure:"redis"`
 	S3Options             *s3.Options                        `json:"s3,omitempty" yaml:"s3,omitempty" mapstructure:"s3"`
+	VaultOptions          *vault.Options                     `json:"vault,omitempty" yaml:"vault,omitempty" mapstructure:"vault"`
 	SonarQubeOptions      *sonarqube.Options                 `json:"sonarqube,omitempty" yaml:"sonarQube,omitempty" mapstructure:"sonarqube"`
 	AuthenticationOptions *authoptions.AuthenticationOptions `json:"authentication,omitempty" yaml:"authentication,omitempty" mapstructure:"authentication"`
+	AuthorizationOptions  *authzoptions.AuthorizationOptions `json:"authorization,omitempty" yaml:"authorization,omitempty" mapstructure:"authorization"`
+	AuditingOptions       *auditingoptions.Options           `json:"auditing,omitempty" yaml:"auditing,omitempty" mapstructure:"auditing"`
 	AuthMode              AuthMode                           `json:"authMode,omitempty" yaml:"authMode,omitempty" mapstructure:"authMode"`
 	JWTSecret             string                             `json:"jwtSecret,omitempty" yaml:"jwtSecret,omitempty" mapstructure:"jwtSecret"`
 }
@@ -92,11 +98,14 @@ type Config struct {
 // newConfig creates a default non-empty Config
 func New() *Config {
 	return &Config{
-		SonarQubeOptions:  sonarqube.NewSonarQubeOptions(),
-		JenkinsOptions:    jenkins.NewJenkinsOptions(),
-		KubernetesOptions: k8s.NewKubernetesOptions(),
-		S3Options:         s3.NewS3Options(),
-		AuthMode:          AuthModeToken,
+		SonarQubeOptions:     sonarqube.NewSonarQubeOptions(),
+		JenkinsOptions:       jenkins.NewJenkinsOptions(),
+		KubernetesOptions:    k8s.NewKubernetesOptions(),
+		S3Options:            s3.NewS3Options(),
+		VaultOptions:         vault.NewVaultOptions(),
+		AuthorizationOptions: authzoptions.NewAuthorizationOptions(),
+		AuditingOptions:      auditingoptions.NewAuditingOptions(),
+		AuthMode:             AuthModeToken,
 	}
 }
 
@@ -163,4 +172,8 @@ func (conf *Config) stripEmptyOptions() {
 	if conf.S3Options != nil && conf.S3Options.Endpoint == "" {
 		conf.S3Options = nil
 	}
+
+	if conf.VaultOptions != nil && conf.VaultOptions.Address == "" {
+		conf.VaultOptions = nil
+	}
 }
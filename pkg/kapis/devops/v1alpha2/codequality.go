@@ -0,0 +1,81 @@
+package v1alpha2
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	restfulspec "github.com/emicklei/go-restful-openapi"
+	"k8s.io/klog"
+
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/client/codequality"
+	"kubesphere.io/devops/pkg/client/codequality/sarif"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/sonarqube"
+	"kubesphere.io/devops/pkg/constants"
+)
+
+func addCodeQualityToWebService(webservice *restful.WebService, devopsClient devops.Interface, sonarClient sonarqube.SonarInterface) error {
+	if devopsClient == nil {
+		klog.Infof("Code quality integration is disabled")
+		return nil
+	}
+
+	codeQualityHandler := NewPipelineCodeQualityHandler(devopsClient, newCodeQualityProviders(devopsClient, sonarClient)...)
+
+	webservice.Route(webservice.GET("/devops/{devops}/pipelines/{pipeline}/codequality").
+		To(codeQualityHandler.GetPipelineCodeQualityHandler).
+		Doc("Get the code quality reports of the last build of the specified pipeline of the DevOps project, from SonarQube and the archived SARIF logs. The providers which failed are reported in errors.").
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DevOpsPipelineTag}).
+		Param(webservice.PathParameter("devops", "DevOps project's ID, e.g. project-RRRRAzLBlLEm")).
+		Param(webservice.PathParameter("pipeline", "the name of pipeline, e.g. sample-pipeline")).
+		Returns(http.StatusOK, api.StatusOK, codequality.Result{}).
+		Writes(codequality.Result{}))
+
+	webservice.Route(webservice.GET("/devops/{devops}/pipelines/{pipeline}/branches/{branch}/codequality").
+		To(codeQualityHandler.GetMultiBranchesPipelineCodeQualityHandler).
+		Doc("Get the code quality reports of the last build of the specified pipeline branch of the DevOps project, from SonarQube and the archived SARIF logs. The providers which failed are reported in errors.").
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DevOpsPipelineTag}).
+		Param(webservice.PathParameter("devops", "DevOps project's ID, e.g. project-RRRRAzLBlLEm")).
+		Param(webservice.PathParameter("pipeline", "the name of pipeline, e.g. sample-pipeline")).
+		Param(webservice.PathParameter("branch", "branch name, e.g. master")).
+		Returns(http.StatusOK, api.StatusOK, codequality.Result{}).
+		Writes(codequality.Result{}))
+	return nil
+}
+
+// newCodeQualityProviders returns the enabled code quality providers, which serve both the code quality API and the
+// SonarQube API.
+func newCodeQualityProviders(devopsClient devops.Interface, sonarClient sonarqube.SonarInterface) []codequality.CodeQualityProvider {
+	// SARIF logs are archived in Jenkins, so they are always available
+	providers := []codequality.CodeQualityProvider{sarif.NewCodeQualityProvider(devopsClient)}
+	if sonarClient != nil {
+		providers = append(providers, sonarqube.NewCodeQualityProvider(sonarClient))
+	}
+	return providers
+}
+
+func (h PipelineCodeQualityHandler) GetPipelineCodeQualityHandler(request *restful.Request, resp *restful.Response) {
+	projectId := request.PathParameter("devops")
+	pipelineId := request.PathParameter("pipeline")
+	result, err := h.pipelineCodeQualityGetter.GetPipelineCodeQuality(projectId, pipelineId)
+	if err != nil {
+		klog.Errorf("%+v", err)
+		api.HandleInternalError(resp, nil, err)
+		return
+	}
+	resp.WriteAsJson(result)
+}
+
+func (h PipelineCodeQualityHandler) GetMultiBranchesPipelineCodeQualityHandler(request *restful.Request, resp *restful.Response) {
+	projectId := request.PathParameter("devops")
+	pipelineId := request.PathParameter("pipeline")
+	branchId := request.PathParameter("branch")
+	result, err := h.pipelineCodeQualityGetter.GetMultiBranchPipelineCodeQuality(projectId, pipelineId, branchId)
+	if err != nil {
+		klog.Errorf("%+v", err)
+		api.HandleInternalError(resp, nil, err)
+		return
+	}
+	resp.WriteAsJson(result)
+}
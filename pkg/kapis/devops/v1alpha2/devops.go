@@ -258,6 +258,10 @@ func (h *ProjectPipelineHandler) GetRunLog(req *restful.Request, resp *restful.R
 
 	res, err := h.devopsOperator.GetRunLog(projectName, pipelineName, runId, req.Request)
 	if err != nil {
+		// Jenkins might have discarded the run, serve the persisted log instead
+		if h.writeArchivedLog(req, resp, "") {
+			return
+		}
 		parseErr(err, resp)
 		return
 	}
@@ -274,6 +278,10 @@ func (h *ProjectPipelineHandler) GetStepLog(req *restful.Request, resp *restful.
 
 	res, header, err := h.devopsOperator.GetStepLog(projectName, pipelineName, runId, nodeId, stepId, req.Request)
 	if err != nil {
+		// Jenkins might have discarded the run, serve the persisted log instead
+		if h.writeArchivedLog(req, resp, "") {
+			return
+		}
 		parseErr(err, resp)
 		return
 	}
@@ -581,6 +589,10 @@ func (h *ProjectPipelineHandler) GetBranchRunLog(req *restful.Request, resp *res
 
 	res, err := h.devopsOperator.GetBranchRunLog(projectName, pipelineName, branchName, runId, req.Request)
 	if err != nil {
+		// Jenkins might have discarded the run, serve the persisted log instead
+		if h.writeArchivedLog(req, resp, branchName) {
+			return
+		}
 		parseErr(err, resp)
 		return
 	}
@@ -599,6 +611,10 @@ func (h *ProjectPipelineHandler) GetBranchStepLog(req *restful.Request, resp *re
 	res, header, err := h.devopsOperator.GetBranchStepLog(projectName, pipelineName, branchName, runId, nodeId, stepId, req.Request)
 
 	if err != nil {
+		// Jenkins might have discarded the run, serve the persisted log instead
+		if h.writeArchivedLog(req, resp, branchName) {
+			return
+		}
 		parseErr(err, resp)
 		return
 	}
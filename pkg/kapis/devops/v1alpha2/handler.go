@@ -18,18 +18,23 @@ package v1alpha2
 
 import (
 	"kubesphere.io/devops/pkg/client/clientset/versioned"
+	"kubesphere.io/devops/pkg/client/codequality"
 	devopsClient "kubesphere.io/devops/pkg/client/devops"
 	"kubesphere.io/devops/pkg/client/informers/externalversions"
 	"kubesphere.io/devops/pkg/client/k8s"
 	"kubesphere.io/devops/pkg/client/s3"
 	"kubesphere.io/devops/pkg/client/sonarqube"
 	"kubesphere.io/devops/pkg/models/devops"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type ProjectPipelineHandler struct {
 	k8sClient               k8s.Client
 	devopsOperator          devops.DevopsOperator
 	projectCredentialGetter devops.ProjectCredentialGetter
+	// client and s3Client read the persisted logs once Jenkins discarded the runs
+	client   client.Client
+	s3Client s3.Interface
 }
 
 type PipelineSonarHandler struct {
@@ -37,11 +42,18 @@ type PipelineSonarHandler struct {
 	pipelineSonarGetter devops.PipelineSonarGetter
 }
 
-func NewProjectPipelineHandler(devopsClient devopsClient.Interface, k8sClient k8s.Client) ProjectPipelineHandler {
+type PipelineCodeQualityHandler struct {
+	pipelineCodeQualityGetter devops.PipelineCodeQualityGetter
+}
+
+func NewProjectPipelineHandler(devopsClient devopsClient.Interface, k8sClient k8s.Client, runtimeClient client.Client,
+	s3Client s3.Interface) ProjectPipelineHandler {
 	return ProjectPipelineHandler{
 		devopsOperator:          devops.NewDevopsOperator(devopsClient, k8sClient.Kubernetes(), k8sClient.KubeSphere()),
 		projectCredentialGetter: devops.NewProjectCredentialOperator(devopsClient),
 		k8sClient:               k8sClient,
+		client:                  runtimeClient,
+		s3Client:                s3Client,
 	}
 }
 
@@ -49,7 +61,13 @@ func NewPipelineSonarHandler(devopsClient devopsClient.Interface, sonarClient so
 	k8sClient k8s.Client) PipelineSonarHandler {
 	return PipelineSonarHandler{
 		k8sClient:           k8sClient,
-		pipelineSonarGetter: devops.NewPipelineSonarGetter(devopsClient, sonarClient),
+		pipelineSonarGetter: devops.NewPipelineSonarGetter(devopsClient, newCodeQualityProviders(devopsClient, sonarClient)...),
+	}
+}
+
+func NewPipelineCodeQualityHandler(devopsClient devopsClient.Interface, providers ...codequality.CodeQualityProvider) PipelineCodeQualityHandler {
+	return PipelineCodeQualityHandler{
+		pipelineCodeQualityGetter: devops.NewPipelineCodeQualityGetter(devopsClient, providers...),
 	}
 }
 
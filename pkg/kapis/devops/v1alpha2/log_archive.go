@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/klog"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// openArchivedLog opens the persisted log of a Jenkins run, or a step of it, in the object storage from the start
+// offset, and returns the size of the whole log. The run is located by the PipelineRun which refers to it.
+func (h *ProjectPipelineHandler) openArchivedLog(projectName, pipelineName, branchName, runID, nodeID, stepID string,
+	start int64) (io.ReadCloser, int64, error) {
+	if h.client == nil || h.s3Client == nil {
+		return nil, 0, fmt.Errorf("logs are not persisted")
+	}
+	matchingLabels := client.MatchingLabels{v1alpha3.PipelineNameLabelKey: pipelineName}
+	if branchName != "" {
+		matchingLabels[v1alpha3.SCMRefNameLabelKey] = branchName
+	}
+	var prs v1alpha3.PipelineRunList
+	if err := h.client.List(context.Background(), &prs, client.InNamespace(projectName), matchingLabels); err != nil {
+		return nil, 0, err
+	}
+	for i := range prs.Items {
+		pr := &prs.Items[i]
+		if id, ok := pr.GetPipelineRunID(); !ok || id != runID || pr.Status.LogArchive == nil {
+			continue
+		}
+		if key, ok := pr.Status.LogArchive.GetKey(nodeID, stepID); ok {
+			return h.s3Client.ReadFrom(key, start)
+		}
+	}
+	return nil, 0, fmt.Errorf("the log of run %s in pipeline %s/%s was not persisted", runID, projectName, pipelineName)
+}
+
+// writeArchivedLog writes the persisted log from the start offset, in the same way as Jenkins does. It returns false
+// if the log was not persisted.
+func (h *ProjectPipelineHandler) writeArchivedLog(req *restful.Request, resp *restful.Response, branchName string) bool {
+	projectName := req.PathParameter("devops")
+	pipelineName := req.PathParameter("pipeline")
+	runID := req.PathParameter("run")
+	start, _ := strconv.ParseInt(req.QueryParameter("start"), 10, 64)
+	if start < 0 {
+		start = 0
+	}
+	reader, size, err := h.openArchivedLog(projectName, pipelineName, branchName, runID,
+		req.PathParameter("node"), req.PathParameter("step"), start)
+	if err != nil {
+		klog.V(4).Infof("failed to get the persisted log of run %s in pipeline %s/%s, err = %v", runID, projectName, pipelineName, err)
+		return false
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	resp.AddHeader("X-Text-Size", strconv.FormatInt(size, 10))
+	resp.AddHeader("X-More-Data", "false")
+	// the log might be large, it's streamed instead of being loaded into memory
+	if _, err := io.Copy(resp, reader); err != nil {
+		klog.V(4).Infof("failed to write the persisted log of run %s in pipeline %s/%s, err = %v", runID, projectName, pipelineName, err)
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package v1alpha2
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	fakes3 "kubesphere.io/devops/pkg/client/s3/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestProjectPipelineHandler_openArchivedLog(t *testing.T) {
+	sch := runtime.NewScheme()
+	if err := v1alpha3.AddToScheme(sch); err != nil {
+		t.Fatalf("unable to add v1alpha3 into scheme, err = %v", err)
+	}
+	createPipelineRun := func(name, branch, runID string, logArchive *v1alpha3.LogArchive) *v1alpha3.PipelineRun {
+		pr := &v1alpha3.PipelineRun{
+			ObjectMeta: v1.ObjectMeta{
+				Namespace: "project1",
+				Name:      name,
+				Labels: map[string]string{
+					v1alpha3.PipelineNameLabelKey: "pipeline1",
+				},
+				Annotations: map[string]string{
+					v1alpha3.JenkinsPipelineRunIDKey: runID,
+				},
+			},
+			Status: v1alpha3.PipelineRunStatus{LogArchive: logArchive},
+		}
+		if branch != "" {
+			pr.Labels[v1alpha3.SCMRefNameLabelKey] = branch
+		}
+		return pr
+	}
+	newHandler := func() *ProjectPipelineHandler {
+		return &ProjectPipelineHandler{
+			client: fake.NewFakeClientWithScheme(sch,
+				createPipelineRun("pipeline1-a", "", "1", &v1alpha3.LogArchive{
+					Key:   "a.log",
+					Steps: []v1alpha3.StepLogArchive{{NodeID: "3", StepID: "4", Key: "a-3-4.log"}},
+				}),
+				createPipelineRun("pipeline1-b", "main", "3", &v1alpha3.LogArchive{Key: "b.log"}),
+				createPipelineRun("pipeline1-c", "", "2", nil)),
+			s3Client: fakes3.NewFakeS3(
+				&fakes3.Object{Key: "a.log", Body: bytes.NewBufferString("run a")},
+				&fakes3.Object{Key: "a-3-4.log", Body: bytes.NewBufferString("step a")},
+				&fakes3.Object{Key: "b.log", Body: bytes.NewBufferString("run b")}),
+		}
+	}
+
+	readAll := func(reader io.ReadCloser, size int64, err error) (string, int64, error) {
+		if err != nil {
+			return "", 0, err
+		}
+		defer func() {
+			_ = reader.Close()
+		}()
+		text, err := ioutil.ReadAll(reader)
+		return string(text), size, err
+	}
+
+	text, size, err := readAll(newHandler().openArchivedLog("project1", "pipeline1", "", "1", "", "", 0))
+	assert.Nil(t, err)
+	assert.Equal(t, "run a", text)
+	assert.Equal(t, int64(5), size)
+
+	text, size, err = readAll(newHandler().openArchivedLog("project1", "pipeline1", "", "1", "3", "4", 5))
+	assert.Nil(t, err)
+	assert.Equal(t, "a", text)
+	assert.Equal(t, int64(6), size)
+
+	text, _, err = readAll(newHandler().openArchivedLog("project1", "pipeline1", "main", "3", "", "", 100))
+	assert.Nil(t, err)
+	assert.Empty(t, text)
+
+	// the logs were not persisted
+	_, _, err = newHandler().openArchivedLog("project1", "pipeline1", "dev", "3", "", "", 0)
+	assert.NotNil(t, err)
+	_, _, err = newHandler().openArchivedLog("project1", "pipeline1", "", "2", "", "", 0)
+	assert.NotNil(t, err)
+	_, _, err = newHandler().openArchivedLog("project1", "pipeline1", "", "1", "3", "5", 0)
+	assert.NotNil(t, err)
+
+	// there is no object storage
+	_, _, err = (&ProjectPipelineHandler{}).openArchivedLog("project1", "pipeline1", "", "1", "", "", 0)
+	assert.NotNil(t, err)
+}
@@ -43,23 +43,24 @@ import (
 	"net/http"
 
 	"kubesphere.io/devops/pkg/client/devops"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var GroupVersion = schema.GroupVersion{Group: api.GroupName, Version: "v1alpha2"}
 
 func AddToContainer(container *restful.Container, ksInformers externalversions.SharedInformerFactory,
 	devopsClient devops.Interface, sonarqubeClient sonarqube.SonarInterface, ksClient versioned.Interface,
-	s3Client s3.Interface, endpoint string, k8sClient k8s.Client, jenkinsClient core.JenkinsCore) error {
+	s3Client s3.Interface, endpoint string, k8sClient k8s.Client, jenkinsClient core.JenkinsCore, runtimeClient client.Client) error {
 	wsWithGroup := runtime.NewWebService(GroupVersion)
 	// the API endpoint with group version will be removed in the future release
 	if err := addToContainerWithWebService(container, ksInformers, devopsClient, sonarqubeClient, ksClient,
-		s3Client, endpoint, k8sClient, jenkinsClient, wsWithGroup); err != nil {
+		s3Client, endpoint, k8sClient, jenkinsClient, runtimeClient, wsWithGroup); err != nil {
 		return err
 	}
 
 	ws := runtime.NewWebServiceWithoutGroup(GroupVersion)
 	if err := addToContainerWithWebService(container, ksInformers, devopsClient, sonarqubeClient, ksClient,
-		s3Client, endpoint, k8sClient, jenkinsClient, ws); err != nil {
+		s3Client, endpoint, k8sClient, jenkinsClient, runtimeClient, ws); err != nil {
 		return err
 	}
 	return nil
@@ -67,8 +68,9 @@ func AddToContainer(container *restful.Container, ksInformers externalversions.S
 
 func addToContainerWithWebService(container *restful.Container, ksInformers externalversions.SharedInformerFactory,
 	devopsClient devops.Interface, sonarqubeClient sonarqube.SonarInterface, ksClient versioned.Interface,
-	s3Client s3.Interface, endpoint string, k8sClient k8s.Client, jenkinsClient core.JenkinsCore, ws *restful.WebService) error {
-	err := AddPipelineToWebService(ws, devopsClient, k8sClient)
+	s3Client s3.Interface, endpoint string, k8sClient k8s.Client, jenkinsClient core.JenkinsCore, runtimeClient client.Client,
+	ws *restful.WebService) error {
+	err := AddPipelineToWebService(ws, devopsClient, k8sClient, runtimeClient, s3Client)
 	if err != nil {
 		return err
 	}
@@ -78,6 +80,11 @@ func addToContainerWithWebService(container *restful.Container, ksInformers exte
 		return err
 	}
 
+	err = addCodeQualityToWebService(ws, devopsClient, sonarqubeClient)
+	if err != nil {
+		return err
+	}
+
 	err = AddS2IToWebService(ws, ksClient, ksInformers, s3Client, k8sClient)
 	if err != nil {
 		return err
@@ -92,11 +99,12 @@ func addToContainerWithWebService(container *restful.Container, ksInformers exte
 	return nil
 }
 
-func AddPipelineToWebService(webservice *restful.WebService, devopsClient devops.Interface, k8sClient k8s.Client) error {
+func AddPipelineToWebService(webservice *restful.WebService, devopsClient devops.Interface, k8sClient k8s.Client,
+	runtimeClient client.Client, s3Client s3.Interface) error {
 	projectPipelineEnable := devopsClient != nil
 
 	if projectPipelineEnable {
-		projectPipelineHandler := NewProjectPipelineHandler(devopsClient, k8sClient)
+		projectPipelineHandler := NewProjectPipelineHandler(devopsClient, k8sClient, runtimeClient, s3Client)
 
 		webservice.Route(webservice.GET("/devops/{devops}/credentials/{credential}/usage").
 			To(projectPipelineHandler.GetProjectCredentialUsage).
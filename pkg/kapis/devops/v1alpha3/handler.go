@@ -293,6 +293,26 @@ func (h *devopsHandler) DeletePipeline(request *restful.Request, response *restf
 	}
 }
 
+func (h *devopsHandler) ImportPipelines(request *restful.Request, response *restful.Response) {
+	devops := request.PathParameter("devops")
+
+	if client, err := h.getDevOps(request); err == nil {
+		project, err := client.ImportPipelines(devops, request.QueryParameter("sourceFolder"))
+		if err != nil {
+			klog.Error(err)
+			if errors.IsNotFound(err) {
+				api.HandleNotFound(response, request, err)
+				return
+			}
+			api.HandleBadRequest(response, request, err)
+			return
+		}
+		_ = response.WriteEntity(project)
+	} else {
+		api.HandleBadRequest(response, request, err)
+	}
+}
+
 //credential handler about get/list/post/put/delete
 func (h *devopsHandler) GetCredential(request *restful.Request, response *restful.Response) {
 	devops := request.PathParameter("devops")
@@ -394,17 +414,69 @@ func (h *devopsHandler) UpdateCredential(request *restful.Request, response *res
 	}
 }
 
+func (h *devopsHandler) RotateCredential(request *restful.Request, response *restful.Response) {
+	var rotation devops.CredentialRotation
+	if err := request.ReadEntity(&rotation); err != nil {
+		klog.Error(err)
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+
+	devops := request.PathParameter("devops")
+	credential := request.PathParameter("credential")
+
+	if client, err := h.getDevOps(request); err == nil {
+		secret, err := client.RotateCredentialObj(devops, credential, &rotation)
+		if err != nil {
+			klog.Error(err)
+			if errors.IsNotFound(err) {
+				api.HandleNotFound(response, request, err)
+				return
+			}
+			api.HandleBadRequest(response, request, err)
+			return
+		}
+		_ = response.WriteEntity(secret)
+	} else {
+		api.HandleBadRequest(response, request, err)
+	}
+}
+
+func (h *devopsHandler) GetCredentialUsage(request *restful.Request, response *restful.Response) {
+	devops := request.PathParameter("devops")
+	credential := request.PathParameter("credential")
+
+	if client, err := h.getDevOps(request); err == nil {
+		usage, err := client.GetCredentialUsage(devops, credential)
+		if err != nil {
+			klog.Error(err)
+			if errors.IsNotFound(err) {
+				api.HandleNotFound(response, request, err)
+				return
+			}
+			api.HandleBadRequest(response, request, err)
+			return
+		}
+		_ = response.WriteEntity(usage)
+	} else {
+		api.HandleBadRequest(response, request, err)
+	}
+}
+
 func (h *devopsHandler) DeleteCredential(request *restful.Request, response *restful.Response) {
 	devops := request.PathParameter("devops")
 	credential := request.PathParameter("credential")
 
 	if client, err := h.getDevOps(request); err == nil {
-		err := client.DeleteCredentialObj(devops, credential)
+		err := client.DeleteCredentialObj(devops, credential, request.QueryParameter("force") == "true")
 		if err != nil {
 			klog.Error(err)
 			if errors.IsNotFound(err) {
 				api.HandleNotFound(response, request, err)
 				return
+			} else if errors.IsConflict(err) {
+				api.HandleConflict(response, request, err)
+				return
 			}
 			api.HandleBadRequest(response, request, err)
 			return
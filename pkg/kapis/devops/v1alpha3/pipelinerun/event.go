@@ -0,0 +1,100 @@
+package pipelinerun
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RunEventType is the type of event sent by Jenkins.
+type RunEventType string
+
+const (
+	// RunStarted indicates that a Jenkins run has started.
+	RunStarted RunEventType = "run.started"
+	// RunFinalized indicates that a Jenkins run has finished.
+	RunFinalized RunEventType = "run.finalized"
+	// StageStarted indicates that a stage of a Jenkins run has started.
+	StageStarted RunEventType = "stage.started"
+	// StageFinished indicates that a stage of a Jenkins run has finished.
+	StageFinished RunEventType = "stage.finished"
+	// InputPending indicates that a Jenkins run is waiting for an input step.
+	InputPending RunEventType = "input.pending"
+)
+
+// EventTokenHeader is the header which carries the token of Jenkins events.
+const EventTokenHeader = "X-Jenkins-Event-Token"
+
+// RunEvent is an event sent by Jenkins when the state of a run or stage has changed.
+type RunEvent struct {
+	// Type is the type of the event.
+	Type RunEventType `json:"type" description:"type of the event, e.g. run.started, run.finalized, stage.started"`
+	// Namespace is the name of DevOps project, which is the Jenkins folder as well.
+	Namespace string `json:"namespace" description:"namespace of the pipeline"`
+	// Pipeline is the name of Pipeline.
+	Pipeline string `json:"pipeline" description:"name of the pipeline"`
+	// Branch is the SCM reference name, only for multi-branch Pipeline.
+	Branch string `json:"branch,omitempty" description:"name of the SCM reference, only for multi-branch pipeline"`
+	// RunID is the ID of Jenkins run.
+	RunID string `json:"runId" description:"ID of the Jenkins run"`
+	// Stage is the name of the stage which the event comes from.
+	Stage string `json:"stage,omitempty" description:"name of the stage"`
+}
+
+func (e *RunEvent) validate() error {
+	switch e.Type {
+	case RunStarted, RunFinalized, StageStarted, StageFinished, InputPending:
+	default:
+		return fmt.Errorf("unsupported event type: %q", e.Type)
+	}
+	if e.Namespace == "" || e.Pipeline == "" || e.RunID == "" {
+		return errors.New("namespace, pipeline and runId are required")
+	}
+	return nil
+}
+
+// verifyEventToken verifies the token carried by the Jenkins event.
+func verifyEventToken(header http.Header, token string) error {
+	if subtle.ConstantTimeCompare([]byte(header.Get(EventTokenHeader)), []byte(token)) != 1 {
+		return fmt.Errorf("the header %s doesn't carry a valid token", EventTokenHeader)
+	}
+	return nil
+}
+
+// findPipelineRun finds the PipelineRun which the event belongs to. It returns nil if there is no matching PipelineRun.
+func findPipelineRun(ctx context.Context, c client.Reader, e *RunEvent) (*v1alpha3.PipelineRun, error) {
+	matchingLabels := client.MatchingLabels{
+		v1alpha3.PipelineNameLabelKey: e.Pipeline,
+	}
+	if e.Branch != "" {
+		matchingLabels[v1alpha3.SCMRefNameLabelKey] = e.Branch
+	}
+	var prs v1alpha3.PipelineRunList
+	if err := c.List(ctx, &prs, client.InNamespace(e.Namespace), matchingLabels); err != nil {
+		return nil, err
+	}
+	for i := range prs.Items {
+		if runID, ok := prs.Items[i].GetPipelineRunID(); ok && runID == e.RunID {
+			return &prs.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// notifyPipelineRun records the event into annotations of the PipelineRun, which will wake up the PipelineRun
+// controller to retrieve the latest running data from Jenkins.
+func notifyPipelineRun(ctx context.Context, c client.Client, pr *v1alpha3.PipelineRun, e *RunEvent) error {
+	prToUpdate := pr.DeepCopy()
+	if prToUpdate.Annotations == nil {
+		prToUpdate.Annotations = make(map[string]string)
+	}
+	prToUpdate.Annotations[v1alpha3.JenkinsPipelineRunEventKey] = fmt.Sprintf("%s/%s", e.Type,
+		time.Now().UTC().Format(time.RFC3339Nano))
+	return c.Patch(ctx, prToUpdate, client.MergeFrom(pr))
+}
@@ -0,0 +1,146 @@
+package pipelinerun
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRunEvent_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   RunEvent
+		wantErr bool
+	}{{
+		name:    "Unsupported event type",
+		event:   RunEvent{Type: "fake", Namespace: "ns", Pipeline: "pipeline", RunID: "1"},
+		wantErr: true,
+	}, {
+		name:    "Missing run ID",
+		event:   RunEvent{Type: RunStarted, Namespace: "ns", Pipeline: "pipeline"},
+		wantErr: true,
+	}, {
+		name:  "Valid event",
+		event: RunEvent{Type: StageFinished, Namespace: "ns", Pipeline: "pipeline", RunID: "1", Stage: "build"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.event.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_verifyEventToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{{
+		name:    "Missing token",
+		wantErr: true,
+	}, {
+		name:    "Invalid token",
+		token:   "fake",
+		wantErr: true,
+	}, {
+		name:  "Valid token",
+		token: "secret",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.token != "" {
+				header.Set(EventTokenHeader, tt.token)
+			}
+			if err := verifyEventToken(header, "secret"); (err != nil) != tt.wantErr {
+				t.Errorf("verifyEventToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_findAndNotifyPipelineRun(t *testing.T) {
+	sch := runtime.NewScheme()
+	if err := v1alpha3.AddToScheme(sch); err != nil {
+		t.Fatalf("unable to add v1alpha3 into scheme, err = %v", err)
+	}
+	createPipelineRun := func(name, branch, runID string) *v1alpha3.PipelineRun {
+		pr := &v1alpha3.PipelineRun{
+			ObjectMeta: v1.ObjectMeta{
+				Namespace: "ns",
+				Name:      name,
+				Labels: map[string]string{
+					v1alpha3.PipelineNameLabelKey: "pipeline",
+				},
+				Annotations: map[string]string{
+					v1alpha3.JenkinsPipelineRunIDKey: runID,
+				},
+			},
+		}
+		if branch != "" {
+			pr.Labels[v1alpha3.SCMRefNameLabelKey] = branch
+		}
+		return pr
+	}
+	c := fake.NewFakeClientWithScheme(sch,
+		createPipelineRun("pipeline-a", "main", "1"),
+		createPipelineRun("pipeline-b", "dev", "1"),
+		createPipelineRun("pipeline-c", "main", "2"))
+
+	tests := []struct {
+		name     string
+		event    *RunEvent
+		wantName string
+	}{{
+		name:     "Match by branch and run ID",
+		event:    &RunEvent{Type: RunStarted, Namespace: "ns", Pipeline: "pipeline", Branch: "dev", RunID: "1"},
+		wantName: "pipeline-b",
+	}, {
+		name:     "Match by run ID",
+		event:    &RunEvent{Type: RunFinalized, Namespace: "ns", Pipeline: "pipeline", RunID: "2"},
+		wantName: "pipeline-c",
+	}, {
+		name:  "Not found",
+		event: &RunEvent{Type: RunFinalized, Namespace: "ns", Pipeline: "pipeline", RunID: "3"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr, err := findPipelineRun(context.Background(), c, tt.event)
+			if err != nil {
+				t.Fatalf("findPipelineRun() error = %v", err)
+			}
+			if tt.wantName == "" {
+				if pr != nil {
+					t.Errorf("findPipelineRun() got = %s, want nil", pr.Name)
+				}
+				return
+			}
+			if pr == nil || pr.Name != tt.wantName {
+				t.Fatalf("findPipelineRun() got = %v, want %s", pr, tt.wantName)
+			}
+
+			if err := notifyPipelineRun(context.Background(), c, pr, tt.event); err != nil {
+				t.Fatalf("notifyPipelineRun() error = %v", err)
+			}
+			updatedPipelineRun := &v1alpha3.PipelineRun{}
+			if err := c.Get(context.Background(), client.ObjectKey{Namespace: "ns", Name: tt.wantName}, updatedPipelineRun); err != nil {
+				t.Fatalf("unable to get PipelineRun, err = %v", err)
+			}
+			if got := updatedPipelineRun.Annotations[v1alpha3.JenkinsPipelineRunEventKey]; !strings.HasPrefix(got, string(tt.event.Type)+"/") {
+				t.Errorf("unexpected event annotation: %s", got)
+			}
+			if runID, _ := updatedPipelineRun.GetPipelineRunID(); runID != tt.event.RunID {
+				t.Errorf("run ID annotation should be kept, got = %s", runID)
+			}
+		})
+	}
+}
@@ -2,7 +2,9 @@ package pipelinerun
 
 import (
 	"context"
+	"errors"
 	"io"
+	"net/http"
 	"strconv"
 
 	"github.com/emicklei/go-restful"
@@ -12,13 +14,19 @@ import (
 	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
 	"kubesphere.io/devops/pkg/apiserver/query"
 	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/s3"
 	resourcesV1alpha3 "kubesphere.io/devops/pkg/models/resources/v1alpha3"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // apiHandlerOption holds some useful tools for API handler.
 type apiHandlerOption struct {
-	client client.Client
+	client       client.Client
+	devopsClient devops.Interface
+	s3Client     s3.Interface
+	broadcaster  *pipelineRunBroadcaster
+	// jenkinsEventToken is the token which Jenkins events must carry
+	jenkinsEventToken string
 }
 
 // apiHandler contains functions to handle coming request and give a response.
@@ -35,11 +43,14 @@ func (h *apiHandler) listPipelineRuns(request *restful.Request, response *restfu
 	nsName := request.PathParameter("namespace")
 	pipName := request.PathParameter("pipeline")
 	branchName := request.QueryParameter("branch")
+	retryOf := request.QueryParameter("retryOf")
+	latestAttempt, _ := strconv.ParseBool(request.QueryParameter("latestAttempt"))
 	backward, err := strconv.ParseBool(request.QueryParameter("backward"))
 	if err != nil {
 		// by default, we have to guarantee backward compatibility
 		backward = true
 	}
+	watching, _ := strconv.ParseBool(request.QueryParameter("watch"))
 
 	queryParam := query.ParseQueryParameter(request)
 
@@ -53,11 +64,20 @@ func (h *apiHandler) listPipelineRuns(request *restful.Request, response *restfu
 
 	// build label selector
 	labelSelector, err := buildLabelSelector(queryParam, pipeline.Name, branchName)
+	if err == nil {
+		labelSelector, err = selectRetryLineage(labelSelector, retryOf)
+	}
 	if err != nil {
 		api.HandleError(request, response, err)
 		return
 	}
 
+	if watching {
+		go h.requestSyncPipelineRunInBackground(client.ObjectKey{Namespace: pipeline.Namespace, Name: pipeline.Name})
+		h.watchPipelineRuns(request, response, pipeline.Namespace, labelSelector)
+		return
+	}
+
 	var prs v1alpha3.PipelineRunList
 	// fetch PipelineRuns
 	if err := h.client.List(context.Background(), &prs,
@@ -67,20 +87,45 @@ func (h *apiHandler) listPipelineRuns(request *restful.Request, response *restfu
 		return
 	}
 
+	items := prs.Items
+	if latestAttempt {
+		items = latestAttempts(items)
+	}
 	var listHandler resourcesV1alpha3.ListHandler
 	if backward {
 		listHandler = backwardListHandler{}
 	}
-	apiResult := resourcesV1alpha3.ToListResult(convertPipelineRunsToObject(prs.Items), queryParam, listHandler)
+	apiResult := resourcesV1alpha3.ToListResult(convertPipelineRunsToObject(items), queryParam, listHandler)
 	_ = response.WriteAsJson(apiResult)
 
-	go func() {
-		err := h.requestSyncPipelineRun(client.ObjectKey{Namespace: pipeline.Namespace, Name: pipeline.Name})
-		if err != nil {
-			klog.Errorf("failed to request to synchronize PipelineRuns. Pipeline = %s", pipeline.Name)
-			return
-		}
-	}()
+	go h.requestSyncPipelineRunInBackground(client.ObjectKey{Namespace: pipeline.Namespace, Name: pipeline.Name})
+}
+
+func (h *apiHandler) requestSyncPipelineRunInBackground(key client.ObjectKey) {
+	if err := h.requestSyncPipelineRun(key); err != nil {
+		klog.Errorf("failed to request to synchronize PipelineRuns. Pipeline = %s", key.Name)
+	}
+}
+
+func (h *apiHandler) listNamespacedPipelineRuns(request *restful.Request, response *restful.Response) {
+	nsName := request.PathParameter("namespace")
+	watching, _ := strconv.ParseBool(request.QueryParameter("watch"))
+	queryParam := query.ParseQueryParameter(request)
+	labelSelector := queryParam.Selector()
+
+	if watching {
+		h.watchPipelineRuns(request, response, nsName, labelSelector)
+		return
+	}
+
+	var prs v1alpha3.PipelineRunList
+	if err := h.client.List(context.Background(), &prs,
+		client.InNamespace(nsName),
+		client.MatchingLabelsSelector{Selector: labelSelector}); err != nil {
+		api.HandleError(request, response, err)
+		return
+	}
+	_ = response.WriteAsJson(resourcesV1alpha3.ToListResult(convertPipelineRunsToObject(prs.Items), queryParam, nil))
 }
 
 func (h *apiHandler) requestSyncPipelineRun(key client.ObjectKey) error {
@@ -150,3 +195,62 @@ func (h *apiHandler) getPipelineRun(request *restful.Request, response *restful.
 	}
 	_ = response.WriteEntity(&pr)
 }
+
+func (h *apiHandler) getPipelineRunStages(request *restful.Request, response *restful.Response) {
+	nsName := request.PathParameter("namespace")
+	prName := request.PathParameter("pipelinerun")
+
+	// get pipelinerun
+	var pr v1alpha3.PipelineRun
+	if err := h.client.Get(context.Background(), client.ObjectKey{Namespace: nsName, Name: prName}, &pr); err != nil {
+		api.HandleError(request, response, err)
+		return
+	}
+	stages := pr.Status.Stages
+	if stages == nil {
+		stages = []v1alpha3.StageStatus{}
+	}
+	_ = response.WriteEntity(stages)
+}
+
+func (h *apiHandler) receiveJenkinsEvent(request *restful.Request, response *restful.Response) {
+	// the receiver is exposed without authentication, so it only works with a token
+	if h.jenkinsEventToken == "" {
+		api.HandleForbidden(response, request, errors.New("the receiver of Jenkins events is disabled because no token is configured"))
+		return
+	}
+	if err := verifyEventToken(request.Request.Header, h.jenkinsEventToken); err != nil {
+		api.HandleUnauthorized(response, request, err)
+		return
+	}
+
+	runEvent := &RunEvent{}
+	if err := request.ReadEntity(runEvent); err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+	if err := runEvent.validate(); err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+
+	ctx := context.Background()
+	pr, err := findPipelineRun(ctx, h.client, runEvent)
+	if err != nil {
+		api.HandleError(request, response, err)
+		return
+	}
+	if pr == nil {
+		// the run might be triggered in Jenkins directly, so we request to synchronize PipelineRuns from Jenkins
+		klog.V(4).Infof("not found PipelineRun for Jenkins run %s of Pipeline %s/%s, requesting to synchronize",
+			runEvent.RunID, runEvent.Namespace, runEvent.Pipeline)
+		err = h.requestSyncPipelineRun(client.ObjectKey{Namespace: runEvent.Namespace, Name: runEvent.Pipeline})
+	} else {
+		err = notifyPipelineRun(ctx, h.client, pr, runEvent)
+	}
+	if err != nil {
+		api.HandleError(request, response, err)
+		return
+	}
+	response.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,231 @@
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/klog"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// logPollInterval is the interval of fetching the log from Jenkins in follow mode.
+var logPollInterval = 2 * time.Second
+
+// logFetcher fetches a part of the log from the start offset.
+type logFetcher func(start int64) (*devops.ProgressiveLog, error)
+
+// streamLog writes the log from the start offset into the writer. In follow mode, it keeps fetching the log until
+// no more log is coming or the context is done. It returns the offset where the next part of the log starts.
+func streamLog(ctx context.Context, writer io.Writer, flush func(), fetch logFetcher, start int64, follow bool) (int64, error) {
+	for {
+		progressiveLog, err := fetch(start)
+		if err != nil {
+			return start, err
+		}
+		if len(progressiveLog.Text) > 0 {
+			if _, err := writer.Write(progressiveLog.Text); err != nil {
+				return start, err
+			}
+			flush()
+		}
+		start = progressiveLog.NextStart
+		if !follow || !progressiveLog.HasMore {
+			return start, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return start, nil
+		case <-time.After(logPollInterval):
+		}
+	}
+}
+
+// newLogOptions locates the log of the PipelineRun, or a step of it, in Jenkins.
+func newLogOptions(pr *v1alpha3.PipelineRun, nodeID, stepID string, start int64) (*devops.LogOptions, error) {
+	if pr.Spec.PipelineRef == nil {
+		return nil, fmt.Errorf("the PipelineRun %s/%s does not refer to any Pipeline", pr.Namespace, pr.Name)
+	}
+	runID, exists := pr.GetPipelineRunID()
+	if !exists {
+		return nil, fmt.Errorf("the PipelineRun %s/%s has not started yet", pr.Namespace, pr.Name)
+	}
+	if (nodeID == "") != (stepID == "") {
+		return nil, fmt.Errorf("node and step must be specified together")
+	}
+	options := &devops.LogOptions{
+		ProjectName:  pr.Namespace,
+		PipelineName: pr.Spec.PipelineRef.Name,
+		RunID:        runID,
+		NodeID:       nodeID,
+		StepID:       stepID,
+		Start:        start,
+	}
+	if pr.Spec.IsMultiBranchPipeline() {
+		if pr.Spec.SCM == nil || pr.Spec.SCM.RefName == "" {
+			return nil, fmt.Errorf("failed to obtain SCM reference name for multi-branch Pipeline")
+		}
+		options.BranchName = pr.Spec.SCM.RefName
+	}
+	return options, nil
+}
+
+// openArchivedLog opens the persisted log in the object storage from the start offset. It returns the size of the
+// whole log as well, which is the offset where the next part of the log starts.
+func (h *apiHandler) openArchivedLog(logArchive *v1alpha3.LogArchive, nodeID, stepID string, start int64) (io.ReadCloser, int64, error) {
+	key, ok := logArchive.GetKey(nodeID, stepID)
+	if !ok {
+		return nil, 0, fmt.Errorf("the log of step %s in node %s was not persisted", stepID, nodeID)
+	}
+	return h.s3Client.ReadFrom(key, start)
+}
+
+// copyArchivedLog copies the persisted log from the start offset into the writer without loading it into memory.
+func (h *apiHandler) copyArchivedLog(writer io.Writer, logArchive *v1alpha3.LogArchive, nodeID, stepID string, start int64) (int64, error) {
+	reader, size, err := h.openArchivedLog(logArchive, nodeID, stepID, start)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	_, err = io.Copy(writer, reader)
+	return size, err
+}
+
+// waitForPipelineRunStarted waits until the PipelineRun is triggered in Jenkins or the context is done.
+func (h *apiHandler) waitForPipelineRunStarted(ctx context.Context, key client.ObjectKey) (*v1alpha3.PipelineRun, error) {
+	for {
+		pr := &v1alpha3.PipelineRun{}
+		if err := h.client.Get(ctx, key, pr); err != nil {
+			return nil, err
+		}
+		if pr.HasStarted() || pr.HasCompleted() {
+			return pr, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(logPollInterval):
+		}
+	}
+}
+
+// getPipelineRunLog streams the log of a PipelineRun, or a step of it. The response header X-Text-Size tells the
+// offset where the next part of the log starts, clients are able to resume from the offset through the parameter
+// start. In follow mode, the log is streamed until the PipelineRun completes, and the next offset equals the start
+// plus the size of the received log.
+func (h *apiHandler) getPipelineRunLog(request *restful.Request, response *restful.Response) {
+	nsName := request.PathParameter("namespace")
+	prName := request.PathParameter("pipelinerun")
+	nodeID := request.QueryParameter("node")
+	stepID := request.QueryParameter("step")
+	follow, _ := strconv.ParseBool(request.QueryParameter("follow"))
+	var start int64
+	if startParam := request.QueryParameter("start"); startParam != "" {
+		var err error
+		if start, err = strconv.ParseInt(startParam, 10, 64); err != nil || start < 0 {
+			api.HandleBadRequest(response, request, fmt.Errorf("invalid start offset: %s", startParam))
+			return
+		}
+	}
+
+	ctx := request.Request.Context()
+	key := client.ObjectKey{Namespace: nsName, Name: prName}
+	pr := &v1alpha3.PipelineRun{}
+	if err := h.client.Get(ctx, key, pr); err != nil {
+		api.HandleError(request, response, err)
+		return
+	}
+	if follow && !pr.HasStarted() && !pr.HasCompleted() {
+		var err error
+		if pr, err = h.waitForPipelineRunStarted(ctx, key); err != nil {
+			api.HandleError(request, response, err)
+			return
+		}
+	}
+
+	options, err := newLogOptions(pr, nodeID, stepID, start)
+	if err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+	// Jenkins might have discarded the build, serve the persisted log instead
+	archived := pr.Status.LogArchive != nil && h.s3Client != nil
+	fetch := func(start int64) (*devops.ProgressiveLog, error) {
+		options.Start = start
+		progressiveLog, err := h.devopsClient.GetProgressiveLog(*options)
+		if err != nil && archived {
+			klog.V(4).Infof("failed to get the log of PipelineRun %s from Jenkins, fall back to the persisted log, err = %v", key, err)
+			// the persisted log is complete, nothing is coming after it
+			size, err := h.copyArchivedLog(response, pr.Status.LogArchive, nodeID, stepID, start)
+			if err != nil {
+				return nil, err
+			}
+			return &devops.ProgressiveLog{NextStart: size}, nil
+		}
+		return progressiveLog, err
+	}
+
+	// fetch the first part before writing the header, then the errors from Jenkins can be reported properly
+	options.Start = start
+	firstPart, err := h.devopsClient.GetProgressiveLog(*options)
+	if err != nil && archived {
+		klog.V(4).Infof("failed to get the log of PipelineRun %s from Jenkins, fall back to the persisted log, err = %v", key, err)
+		h.writeArchivedLog(request, response, pr.Status.LogArchive, nodeID, stepID, start, follow)
+		return
+	}
+	if err != nil {
+		api.HandleError(request, response, err)
+		return
+	}
+	response.Header().Set(restful.HEADER_ContentType, "text/plain; charset=utf-8")
+	if !follow {
+		response.Header().Set("X-Text-Size", strconv.FormatInt(firstPart.NextStart, 10))
+		response.Header().Set("X-More-Data", strconv.FormatBool(firstPart.HasMore))
+	}
+	response.WriteHeader(http.StatusOK)
+
+	fetched := false
+	if _, err := streamLog(ctx, response, response.Flush, func(start int64) (*devops.ProgressiveLog, error) {
+		if !fetched {
+			fetched = true
+			return firstPart, nil
+		}
+		return fetch(start)
+	}, start, follow); err != nil {
+		klog.V(4).Infof("failed to stream the log of PipelineRun %s, err = %v", key, err)
+	}
+}
+
+// writeArchivedLog streams the persisted log from the start offset into the response, in the same way as the log from
+// Jenkins.
+func (h *apiHandler) writeArchivedLog(request *restful.Request, response *restful.Response, logArchive *v1alpha3.LogArchive,
+	nodeID, stepID string, start int64, follow bool) {
+	reader, size, err := h.openArchivedLog(logArchive, nodeID, stepID, start)
+	if err != nil {
+		api.HandleError(request, response, err)
+		return
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	response.Header().Set(restful.HEADER_ContentType, "text/plain; charset=utf-8")
+	if !follow {
+		response.Header().Set("X-Text-Size", strconv.FormatInt(size, 10))
+		response.Header().Set("X-More-Data", "false")
+	}
+	response.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(response, reader); err != nil {
+		klog.V(4).Infof("failed to stream the persisted log, err = %v", err)
+	}
+}
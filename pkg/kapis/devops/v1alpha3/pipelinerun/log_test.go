@@ -0,0 +1,183 @@
+package pipelinerun
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	fakes3 "kubesphere.io/devops/pkg/client/s3/fake"
+)
+
+func Test_streamLog(t *testing.T) {
+	logPollInterval = time.Millisecond
+	parts := []*devops.ProgressiveLog{
+		{Text: []byte("hello "), NextStart: 16, HasMore: true},
+		{NextStart: 16, HasMore: true},
+		{Text: []byte("world"), NextStart: 21, HasMore: false},
+	}
+	newFetcher := func(t *testing.T) logFetcher {
+		index := 0
+		wantStarts := []int64{10, 16, 16}
+		return func(start int64) (*devops.ProgressiveLog, error) {
+			assert.Equal(t, wantStarts[index], start)
+			part := parts[index]
+			index++
+			return part, nil
+		}
+	}
+
+	t.Run("Without follow", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		next, err := streamLog(context.Background(), buffer, func() {}, newFetcher(t), 10, false)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(16), next)
+		assert.Equal(t, "hello ", buffer.String())
+	})
+
+	t.Run("With follow", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		flushed := 0
+		next, err := streamLog(context.Background(), buffer, func() { flushed++ }, newFetcher(t), 10, true)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(21), next)
+		assert.Equal(t, "hello world", buffer.String())
+		assert.Equal(t, 2, flushed)
+	})
+
+	t.Run("Context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		buffer := &bytes.Buffer{}
+		next, err := streamLog(ctx, buffer, func() {}, newFetcher(t), 10, true)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(16), next)
+		assert.Equal(t, "hello ", buffer.String())
+	})
+
+	t.Run("Failed to fetch", func(t *testing.T) {
+		next, err := streamLog(context.Background(), &bytes.Buffer{}, func() {}, func(start int64) (*devops.ProgressiveLog, error) {
+			return nil, errors.New("failed")
+		}, 10, true)
+		assert.NotNil(t, err)
+		assert.Equal(t, int64(10), next)
+	})
+}
+
+func Test_newLogOptions(t *testing.T) {
+	newPipelineRun := func(runID, branch string) *v1alpha3.PipelineRun {
+		pr := &v1alpha3.PipelineRun{
+			ObjectMeta: v1.ObjectMeta{
+				Namespace:   "project1",
+				Name:        "pipeline1-abc",
+				Annotations: map[string]string{},
+			},
+			Spec: v1alpha3.PipelineRunSpec{
+				PipelineRef:  &corev1.ObjectReference{Name: "pipeline1"},
+				PipelineSpec: &v1alpha3.PipelineSpec{Type: v1alpha3.NoScmPipelineType},
+			},
+		}
+		if runID != "" {
+			pr.Annotations[v1alpha3.JenkinsPipelineRunIDKey] = runID
+		}
+		if branch != "" {
+			pr.Spec.PipelineSpec.Type = v1alpha3.MultiBranchPipelineType
+			pr.Spec.SCM = &v1alpha3.SCM{RefName: branch}
+		}
+		return pr
+	}
+	tests := []struct {
+		name    string
+		pr      *v1alpha3.PipelineRun
+		nodeID  string
+		stepID  string
+		want    *devops.LogOptions
+		wantErr bool
+	}{{
+		name:    "Not started",
+		pr:      newPipelineRun("", ""),
+		wantErr: true,
+	}, {
+		name:    "Node without step",
+		pr:      newPipelineRun("1", ""),
+		nodeID:  "3",
+		wantErr: true,
+	}, {
+		name: "Log of run",
+		pr:   newPipelineRun("1", ""),
+		want: &devops.LogOptions{
+			ProjectName:  "project1",
+			PipelineName: "pipeline1",
+			RunID:        "1",
+			Start:        10,
+		},
+	}, {
+		name:   "Log of step in multi-branch Pipeline",
+		pr:     newPipelineRun("1", "main"),
+		nodeID: "3",
+		stepID: "4",
+		want: &devops.LogOptions{
+			ProjectName:  "project1",
+			PipelineName: "pipeline1",
+			BranchName:   "main",
+			RunID:        "1",
+			NodeID:       "3",
+			StepID:       "4",
+			Start:        10,
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options, err := newLogOptions(tt.pr, tt.nodeID, tt.stepID, 10)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, options)
+		})
+	}
+}
+
+func Test_apiHandler_copyArchivedLog(t *testing.T) {
+	logArchive := &v1alpha3.LogArchive{
+		Key: "run.log",
+		Steps: []v1alpha3.StepLogArchive{{
+			NodeID: "3",
+			StepID: "4",
+			Key:    "step.log",
+		}},
+	}
+	newHandler := func() *apiHandler {
+		return newAPIHandler(apiHandlerOption{
+			s3Client: fakes3.NewFakeS3(&fakes3.Object{
+				Key:  "run.log",
+				Body: bytes.NewBufferString("hello world"),
+			}, &fakes3.Object{
+				Key:  "step.log",
+				Body: bytes.NewBufferString("step"),
+			}),
+		})
+	}
+
+	buffer := &bytes.Buffer{}
+	size, err := newHandler().copyArchivedLog(buffer, logArchive, "", "", 6)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(11), size)
+	assert.Equal(t, "world", buffer.String())
+
+	buffer.Reset()
+	size, err = newHandler().copyArchivedLog(buffer, logArchive, "3", "4", 100)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), size)
+	assert.Empty(t, buffer.String())
+
+	_, err = newHandler().copyArchivedLog(buffer, logArchive, "3", "5", 0)
+	assert.NotNil(t, err)
+}
@@ -2,12 +2,15 @@ package pipelinerun
 
 import (
 	"net/http"
+	"strconv"
 
 	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
 
 	"github.com/emicklei/go-restful"
 	"kubesphere.io/devops/pkg/api"
 	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/s3"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -17,11 +20,33 @@ type Option struct {
 	Client    client.Client
 }
 
-// RegisterRoutes register routes into web service.
-func RegisterRoutes(ws *restful.WebService, c client.Client) {
+const watchParameterDoc = "Watch for changes of PipelineRuns instead of listing them. Existing PipelineRuns are " +
+	"sent as ADDED events at first, then ADDED, MODIFIED and DELETED events are streamed as newline-delimited JSON."
+
+// RegisterRoutes register routes into web services. The informers are used to watch PipelineRuns, the watch mode
+// won't be supported if it's nil. The s3Client is used to read the persisted logs of PipelineRuns, it's optional.
+// Jenkins events are rejected if the jenkinsEventToken is empty.
+// All web services share the same handler, so that PipelineRuns are watched only once.
+func RegisterRoutes(devopsClient devops.Interface, c client.Client, informers cache.Informers, s3Client s3.Interface,
+	jenkinsEventToken string, wss ...*restful.WebService) {
+	var broadcaster *pipelineRunBroadcaster
+	if informers != nil {
+		broadcaster = newPipelineRunBroadcaster(informers)
+	}
 	handler := newAPIHandler(apiHandlerOption{
-		client: c,
+		client:       c,
+		devopsClient: devopsClient,
+		s3Client:     s3Client,
+		broadcaster:  broadcaster,
+
+		jenkinsEventToken: jenkinsEventToken,
 	})
+	for _, ws := range wss {
+		registerRoutes(ws, handler)
+	}
+}
+
+func registerRoutes(ws *restful.WebService, handler *apiHandler) {
 	ws.Route(ws.GET("/namespaces/{namespace}/pipelines/{pipeline}/pipelineruns").
 		To(handler.listPipelineRuns).
 		Doc("Get all runs of the specified pipeline").
@@ -33,6 +58,25 @@ func RegisterRoutes(ws *restful.WebService, c client.Client) {
 			"full data of PipelineRuns, just set the parameters to false.").
 			DataType("bool").
 			DefaultValue("true")).
+		Param(ws.QueryParameter("watch", watchParameterDoc).
+			DataType("bool").
+			DefaultValue("false")).
+		Param(ws.QueryParameter("retryOf", "Only list the PipelineRuns in the retry lineage of the given PipelineRun, "+
+			"which is the first PipelineRun of the lineage")).
+		Param(ws.QueryParameter("latestAttempt", "Group the PipelineRuns by their retry lineages, and only list the "+
+			"latest attempt of each lineage. It doesn't work in the watch mode").
+			DataType("bool").
+			DefaultValue("false")).
+		Returns(http.StatusOK, api.StatusOK, v1alpha3.PipelineRunList{}),
+	)
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns").
+		To(handler.listNamespacedPipelineRuns).
+		Doc("Get all PipelineRuns in the specified namespace").
+		Param(ws.PathParameter("namespace", "Namespace of the PipelineRuns")).
+		Param(ws.QueryParameter("labelSelector", "The label selector of PipelineRuns")).
+		Param(ws.QueryParameter("watch", watchParameterDoc).
+			DataType("bool").
+			DefaultValue("false")).
 		Returns(http.StatusOK, api.StatusOK, v1alpha3.PipelineRunList{}),
 	)
 	ws.Route(ws.POST("/namespaces/{namespace}/pipelines/{pipeline}/pipelineruns").
@@ -50,4 +94,46 @@ func RegisterRoutes(ws *restful.WebService, c client.Client) {
 		Param(ws.PathParameter("namespace", "Namespace of the pipeline")).
 		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
 		Returns(http.StatusOK, api.StatusOK, v1alpha3.PipelineRun{}))
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns/{pipelinerun}/stages").
+		To(handler.getPipelineRunStages).
+		Doc("Get the status of all stages and steps of a PipelineRun").
+		Param(ws.PathParameter("namespace", "Namespace of the pipeline")).
+		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
+		Returns(http.StatusOK, api.StatusOK, []v1alpha3.StageStatus{}))
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelineruns/{pipelinerun}/log").
+		To(handler.getPipelineRunLog).
+		Doc("Get the log of a PipelineRun, or a step of it. The response header X-Text-Size tells the offset where "+
+			"the next part of the log starts. The persisted log is served once Jenkins no longer has the build").
+		Param(ws.PathParameter("namespace", "Namespace of the pipeline")).
+		Param(ws.PathParameter("pipelinerun", "Name of the PipelineRun")).
+		Param(ws.QueryParameter("start", "The offset in bytes where the log starts").
+			DataType("integer").
+			DefaultValue("0")).
+		Param(ws.QueryParameter("follow", "Keep streaming the log until the PipelineRun completes").
+			DataType("bool").
+			DefaultValue("false")).
+		Param(ws.QueryParameter("node", "ID of the node which the step belongs to, required if step is specified")).
+		Param(ws.QueryParameter("step", "ID of the step, only the log of the step will be returned if it's specified")).
+		Produces("text/plain").
+		Returns(http.StatusOK, api.StatusOK, nil))
+	ws.Route(ws.GET("/namespaces/{namespace}/pipelines/{pipeline}/testhistory").
+		To(handler.getTestHistory).
+		Doc("Get the test results of the recent PipelineRuns of the specified pipeline. The test cases which flip "+
+			"between pass and fail across the runs are flagged as flaky").
+		Param(ws.PathParameter("namespace", "Namespace of the pipeline")).
+		Param(ws.PathParameter("pipeline", "Name of the pipeline")).
+		Param(ws.QueryParameter("branch", "The name of SCM reference, only for multi-branch pipeline")).
+		Param(ws.QueryParameter("limit", "The number of the recent PipelineRuns which reported test results").
+			DataType("integer").
+			DefaultValue(strconv.Itoa(defaultTestHistoryLimit))).
+		Returns(http.StatusOK, api.StatusOK, TestHistory{}))
+	ws.Route(ws.POST("/webhooks/jenkins").
+		To(handler.receiveJenkinsEvent).
+		Doc("Receive run and stage events from Jenkins, then wake up the corresponding PipelineRun. The events "+
+			"must carry the configured token in the header "+EventTokenHeader).
+		Param(ws.HeaderParameter(EventTokenHeader, "The token of Jenkins events").Required(true)).
+		Reads(RunEvent{}).
+		Returns(http.StatusAccepted, http.StatusText(http.StatusAccepted), nil).
+		Returns(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized), nil).
+		Returns(http.StatusForbidden, http.StatusText(http.StatusForbidden), nil))
 }
@@ -0,0 +1,205 @@
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/emicklei/go-restful"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/apiserver/query"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// defaultTestHistoryLimit is the number of the recent PipelineRuns in test history by default.
+	defaultTestHistoryLimit = 10
+	// maxTestHistoryLimit is the max number of the recent PipelineRuns in test history.
+	maxTestHistoryLimit = 100
+	// flakyFlips is the number of flips between pass and fail which makes a test case flaky. A test case which breaks
+	// or gets fixed only flips once.
+	flakyFlips = 2
+)
+
+// TestResult is the result of a test case in a PipelineRun.
+type TestResult string
+
+const (
+	// TestResultPassed indicates that the test case passed or was skipped.
+	TestResultPassed TestResult = "Passed"
+	// TestResultFailed indicates that the test case failed.
+	TestResultFailed TestResult = "Failed"
+	// TestResultUnknown indicates that the result is unknown because not all failed test cases of the PipelineRun
+	// were recorded.
+	TestResultUnknown TestResult = "Unknown"
+)
+
+// TestHistory is the test results of the recent PipelineRuns of a Pipeline.
+type TestHistory struct {
+	// Runs are the recent PipelineRuns which reported test results, the latest comes first.
+	Runs []TestHistoryRun `json:"runs"`
+	// Cases are the test cases which failed in any of the runs, the flaky ones come first.
+	Cases []TestCaseHistory `json:"cases"`
+}
+
+// TestHistoryRun is the summary of the test results of a PipelineRun.
+type TestHistoryRun struct {
+	Name           string       `json:"name" description:"name of the PipelineRun"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty" description:"completion time of the PipelineRun"`
+	Total          int          `json:"total" description:"number of all test cases"`
+	Passed         int          `json:"passed" description:"number of the passed test cases"`
+	Failed         int          `json:"failed" description:"number of the failed test cases"`
+	Skipped        int          `json:"skipped" description:"number of the skipped test cases"`
+}
+
+// TestCaseHistory is the results of a test case in the recent PipelineRuns.
+type TestCaseHistory struct {
+	Name     string       `json:"name" description:"full name of the test case, e.g. io.kubesphere.FooTest.testBar"`
+	Results  []TestResult `json:"results" description:"results of the test case in the runs, in the same order as the runs. One of Passed, Failed and Unknown"`
+	Failures int          `json:"failures" description:"number of the runs in which the test case failed"`
+	Flips    int          `json:"flips" description:"number of the changes between pass and fail in the chronological order"`
+	Flaky    bool         `json:"flaky" description:"whether the test case flips between pass and fail"`
+}
+
+// buildTestHistory builds the test history of the latest PipelineRuns which reported test results.
+func buildTestHistory(prs []v1alpha3.PipelineRun, limit int) *TestHistory {
+	var runs []*v1alpha3.PipelineRun
+	for i := range prs {
+		// the PipelineRuns without any test result have an empty test report
+		if prs[i].Status.TestReport != nil && prs[i].Status.TestReport.Total > 0 {
+			runs = append(runs, &prs[i])
+		}
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		return getRunTime(runs[i]).After(getRunTime(runs[j]).Time)
+	})
+	if len(runs) > limit {
+		runs = runs[:limit]
+	}
+
+	history := &TestHistory{
+		Runs:  make([]TestHistoryRun, 0, len(runs)),
+		Cases: make([]TestCaseHistory, 0),
+	}
+	failedCases := make([]map[string]bool, len(runs))
+	var names []string
+	for i, run := range runs {
+		report := run.Status.TestReport
+		history.Runs = append(history.Runs, TestHistoryRun{
+			Name:           run.Name,
+			CompletionTime: run.Status.CompletionTime,
+			Total:          report.Total,
+			Passed:         report.Passed,
+			Failed:         report.Failed,
+			Skipped:        report.Skipped,
+		})
+		failedCases[i] = make(map[string]bool, len(report.FailedCases))
+		for _, name := range report.FailedCases {
+			if !failedCases[i][name] && !containsTestCase(failedCases[:i], name) {
+				names = append(names, name)
+			}
+			failedCases[i][name] = true
+		}
+	}
+
+	for _, name := range names {
+		caseHistory := TestCaseHistory{Name: name, Results: make([]TestResult, len(runs))}
+		for i, run := range runs {
+			switch {
+			case failedCases[i][name]:
+				caseHistory.Results[i] = TestResultFailed
+				caseHistory.Failures++
+			case run.Status.TestReport.Complete():
+				caseHistory.Results[i] = TestResultPassed
+			default:
+				caseHistory.Results[i] = TestResultUnknown
+			}
+		}
+		caseHistory.Flips = countFlips(caseHistory.Results)
+		caseHistory.Flaky = caseHistory.Flips >= flakyFlips
+		history.Cases = append(history.Cases, caseHistory)
+	}
+	sort.SliceStable(history.Cases, func(i, j int) bool {
+		left, right := history.Cases[i], history.Cases[j]
+		if left.Flips != right.Flips {
+			return left.Flips > right.Flips
+		}
+		if left.Failures != right.Failures {
+			return left.Failures > right.Failures
+		}
+		return left.Name < right.Name
+	})
+	return history
+}
+
+// getRunTime returns the time when the PipelineRun completed, or was created if it has not completed.
+func getRunTime(pr *v1alpha3.PipelineRun) metav1.Time {
+	if pr.Status.CompletionTime != nil {
+		return *pr.Status.CompletionTime
+	}
+	return pr.CreationTimestamp
+}
+
+func containsTestCase(failedCases []map[string]bool, name string) bool {
+	for _, cases := range failedCases {
+		if cases[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// countFlips counts the changes between pass and fail, the results are in reverse chronological order. The unknown
+// results are ignored.
+func countFlips(results []TestResult) int {
+	flips := 0
+	var last TestResult
+	for i := len(results) - 1; i >= 0; i-- {
+		if results[i] == TestResultUnknown {
+			continue
+		}
+		if last != "" && results[i] != last {
+			flips++
+		}
+		last = results[i]
+	}
+	return flips
+}
+
+func (h *apiHandler) getTestHistory(request *restful.Request, response *restful.Response) {
+	nsName := request.PathParameter("namespace")
+	pipName := request.PathParameter("pipeline")
+	branchName := request.QueryParameter("branch")
+	limit := defaultTestHistoryLimit
+	if limitParam := request.QueryParameter("limit"); limitParam != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitParam); err != nil || limit <= 0 || limit > maxTestHistoryLimit {
+			api.HandleBadRequest(response, request, fmt.Errorf("limit should be an integer between 1 and %d", maxTestHistoryLimit))
+			return
+		}
+	}
+
+	// validate the Pipeline
+	pipeline := &v1alpha3.Pipeline{}
+	if err := h.client.Get(context.Background(), client.ObjectKey{Namespace: nsName, Name: pipName}, pipeline); err != nil {
+		api.HandleError(request, response, err)
+		return
+	}
+
+	labelSelector, err := buildLabelSelector(query.ParseQueryParameter(request), pipeline.Name, branchName)
+	if err != nil {
+		api.HandleError(request, response, err)
+		return
+	}
+	var prs v1alpha3.PipelineRunList
+	if err := h.client.List(context.Background(), &prs,
+		client.InNamespace(pipeline.Namespace),
+		client.MatchingLabelsSelector{Selector: labelSelector}); err != nil {
+		api.HandleError(request, response, err)
+		return
+	}
+	_ = response.WriteAsJson(buildTestHistory(prs.Items, limit))
+}
@@ -0,0 +1,68 @@
+package pipelinerun
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func Test_buildTestHistory(t *testing.T) {
+	now := time.Now()
+	newRun := func(name string, minutesAgo int, report *v1alpha3.TestReport) v1alpha3.PipelineRun {
+		completionTime := v1.NewTime(now.Add(-time.Duration(minutesAgo) * time.Minute))
+		return v1alpha3.PipelineRun{
+			ObjectMeta: v1.ObjectMeta{Name: name},
+			Status: v1alpha3.PipelineRunStatus{
+				CompletionTime: &completionTime,
+				TestReport:     report,
+			},
+		}
+	}
+	prs := []v1alpha3.PipelineRun{
+		newRun("run-1", 50, &v1alpha3.TestReport{Total: 3, Passed: 2, Failed: 1, FailedCases: []string{"TestFlaky"}}),
+		newRun("run-3", 30, &v1alpha3.TestReport{Total: 3, Passed: 1, Failed: 2, FailedCases: []string{"TestFlaky", "TestBroken"}}),
+		newRun("run-2", 40, &v1alpha3.TestReport{Total: 3, Passed: 3}),
+		// the test report is truncated
+		newRun("run-4", 20, &v1alpha3.TestReport{Total: 3, Passed: 1, Failed: 2, FailedCases: []string{"TestBroken"}}),
+		// there is no test result
+		newRun("run-5", 10, nil),
+		newRun("run-6", 5, &v1alpha3.TestReport{}),
+		// it's out of the limit
+		newRun("run-0", 60, &v1alpha3.TestReport{Total: 3, Passed: 2, Failed: 1, FailedCases: []string{"TestOld"}}),
+	}
+
+	history := buildTestHistory(prs, 4)
+	var runNames []string
+	for _, run := range history.Runs {
+		runNames = append(runNames, run.Name)
+	}
+	assert.Equal(t, []string{"run-4", "run-3", "run-2", "run-1"}, runNames)
+	assert.Equal(t, 2, history.Runs[0].Failed)
+	assert.Equal(t, []TestCaseHistory{{
+		Name:     "TestFlaky",
+		Results:  []TestResult{TestResultUnknown, TestResultFailed, TestResultPassed, TestResultFailed},
+		Failures: 2,
+		Flips:    2,
+		Flaky:    true,
+	}, {
+		Name:     "TestBroken",
+		Results:  []TestResult{TestResultFailed, TestResultFailed, TestResultPassed, TestResultPassed},
+		Failures: 2,
+		Flips:    1,
+	}}, history.Cases)
+
+	// no run reports test results
+	history = buildTestHistory([]v1alpha3.PipelineRun{newRun("run-1", 10, nil)}, 10)
+	assert.Empty(t, history.Runs)
+	assert.Empty(t, history.Cases)
+}
+
+func Test_countFlips(t *testing.T) {
+	assert.Equal(t, 0, countFlips(nil))
+	assert.Equal(t, 0, countFlips([]TestResult{TestResultFailed, TestResultFailed}))
+	assert.Equal(t, 1, countFlips([]TestResult{TestResultPassed, TestResultUnknown, TestResultFailed}))
+	assert.Equal(t, 3, countFlips([]TestResult{TestResultFailed, TestResultPassed, TestResultUnknown, TestResultFailed, TestResultPassed}))
+}
@@ -3,6 +3,7 @@ package pipelinerun
 import (
 	"errors"
 	"fmt"
+	"strconv"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,6 +34,47 @@ func buildLabelSelector(queryParam *query.Query, pipelineName, branchName string
 	return labelSelector, nil
 }
 
+// selectRetryLineage narrows the label selector down to the PipelineRuns in the retry lineage of the given PipelineRun.
+func selectRetryLineage(labelSelector labels.Selector, retryOf string) (labels.Selector, error) {
+	if retryOf == "" {
+		return labelSelector, nil
+	}
+	rq, err := labels.NewRequirement(v1alpha3.PipelineRunRetryOfLabelKey, selection.Equals, []string{retryOf})
+	if err != nil {
+		return nil, err
+	}
+	return labelSelector.Add(*rq), nil
+}
+
+// latestAttempts groups the PipelineRuns by their retry lineages, and keeps the latest attempt of each lineage only.
+// The PipelineRuns which have never been retried are lineages of their own.
+func latestAttempts(prs []v1alpha3.PipelineRun) []v1alpha3.PipelineRun {
+	var result []v1alpha3.PipelineRun
+	lineageIndexes := map[string]int{}
+	for i := range prs {
+		retryOf := prs[i].Labels[v1alpha3.PipelineRunRetryOfLabelKey]
+		if retryOf == "" {
+			retryOf = prs[i].Name
+		}
+		index, ok := lineageIndexes[retryOf]
+		if !ok {
+			lineageIndexes[retryOf] = len(result)
+			result = append(result, prs[i])
+		} else if getAttempt(&prs[i]) > getAttempt(&result[index]) {
+			result[index] = prs[i]
+		}
+	}
+	return result
+}
+
+// getAttempt returns the attempt number of the PipelineRun in its retry lineage, starting from 1.
+func getAttempt(pr *v1alpha3.PipelineRun) int {
+	if attempt, err := strconv.Atoi(pr.Labels[v1alpha3.PipelineRunAttemptLabelKey]); err == nil && attempt > 0 {
+		return attempt
+	}
+	return 1
+}
+
 func convertPipelineRunsToObject(prs []v1alpha3.PipelineRun) []runtime.Object {
 	var result []runtime.Object
 	for i := range prs {
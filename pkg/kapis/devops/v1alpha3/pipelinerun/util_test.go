@@ -66,6 +66,51 @@ func Test_buildLabelSelector(t *testing.T) {
 	}
 }
 
+func Test_selectRetryLineage(t *testing.T) {
+	labelSelector, err := buildLabelSelector(&query.Query{}, "pipelineA", "")
+	if err != nil {
+		t.Fatalf("unable to build label selector, err = %v", err)
+	}
+
+	got, err := selectRetryLineage(labelSelector, "")
+	if err != nil || !reflect.DeepEqual(got, labelSelector) {
+		t.Errorf("selectRetryLineage() got = %v, err = %v, want %v", got, err, labelSelector)
+	}
+
+	got, err = selectRetryLineage(labelSelector, "pipelineA-abc")
+	want, _ := labels.Parse(fmt.Sprintf("%s=pipelineA,%s=pipelineA-abc", v1alpha3.PipelineNameLabelKey, v1alpha3.PipelineRunRetryOfLabelKey))
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("selectRetryLineage() got = %v, err = %v, want %v", got, err, want)
+	}
+}
+
+func Test_latestAttempts(t *testing.T) {
+	newPipelineRun := func(name, retryOf, attempt string) v1alpha3.PipelineRun {
+		pr := v1alpha3.PipelineRun{ObjectMeta: v1.ObjectMeta{Name: name, Labels: map[string]string{}}}
+		if retryOf != "" {
+			pr.Labels[v1alpha3.PipelineRunRetryOfLabelKey] = retryOf
+			pr.Labels[v1alpha3.PipelineRunAttemptLabelKey] = attempt
+		}
+		return pr
+	}
+	prs := []v1alpha3.PipelineRun{
+		newPipelineRun("run-a", "run-a", "1"),
+		newPipelineRun("run-b", "", ""),
+		newPipelineRun("run-a-attempt-3", "run-a", "3"),
+		newPipelineRun("run-a-attempt-2", "run-a", "2"),
+		newPipelineRun("run-c", "run-c", "1"),
+		newPipelineRun("run-c-attempt-2", "run-c", "2"),
+	}
+	var got []string
+	for _, pr := range latestAttempts(prs) {
+		got = append(got, pr.Name)
+	}
+	want := []string{"run-a-attempt-3", "run-b", "run-c-attempt-2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("latestAttempts() = %v, want %v", got, want)
+	}
+}
+
 func Test_convertPipelineRunsToObject(t *testing.T) {
 	type args struct {
 		prs []v1alpha3.PipelineRun
@@ -0,0 +1,193 @@
+package pipelinerun
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// watcherBufferSize is the number of events which a watcher can hold before it falls behind.
+const watcherBufferSize = 100
+
+// WatchEvent is an event of PipelineRun streamed to watchers, which is the same as the watch event of Kubernetes.
+type WatchEvent struct {
+	// Type is the type of the event, e.g. ADDED, MODIFIED, DELETED.
+	Type watch.EventType `json:"type" description:"type of the event, e.g. ADDED, MODIFIED, DELETED"`
+	// Object is the PipelineRun which the event relates with.
+	Object *v1alpha3.PipelineRun `json:"object" description:"the PipelineRun"`
+}
+
+// pipelineRunWatcher receives events of PipelineRuns which match its namespace and label selector.
+type pipelineRunWatcher struct {
+	namespace string
+	selector  labels.Selector
+	events    chan WatchEvent
+}
+
+func (w *pipelineRunWatcher) matches(pr *v1alpha3.PipelineRun) bool {
+	return pr.Namespace == w.namespace && w.selector.Matches(labels.Set(pr.Labels))
+}
+
+// pipelineRunBroadcaster distributes events of PipelineRuns from the shared informer to all watchers. Event handlers
+// cannot be removed from the shared informer, so all watchers share only one event handler.
+type pipelineRunBroadcaster struct {
+	informers cache.Informers
+
+	lock        sync.Mutex
+	initialized bool
+	watchers    map[*pipelineRunWatcher]struct{}
+}
+
+func newPipelineRunBroadcaster(informers cache.Informers) *pipelineRunBroadcaster {
+	return &pipelineRunBroadcaster{
+		informers: informers,
+		watchers:  make(map[*pipelineRunWatcher]struct{}),
+	}
+}
+
+// init adds the event handler into the shared informer once the first watcher comes.
+func (b *pipelineRunBroadcaster) init(ctx context.Context) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.initialized {
+		return nil
+	}
+	informer, err := b.informers.GetInformer(ctx, &v1alpha3.PipelineRun{})
+	if err != nil {
+		return err
+	}
+	informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			b.broadcast(watch.Added, obj)
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			b.broadcast(watch.Modified, newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			b.broadcast(watch.Deleted, obj)
+		},
+	})
+	b.initialized = true
+	return nil
+}
+
+// watch creates a watcher for PipelineRuns in the namespace which match the label selector.
+func (b *pipelineRunBroadcaster) watch(ctx context.Context, namespace string, selector labels.Selector) (*pipelineRunWatcher, error) {
+	if err := b.init(ctx); err != nil {
+		return nil, err
+	}
+	w := &pipelineRunWatcher{
+		namespace: namespace,
+		selector:  selector,
+		events:    make(chan WatchEvent, watcherBufferSize),
+	}
+	b.lock.Lock()
+	b.watchers[w] = struct{}{}
+	b.lock.Unlock()
+	return w, nil
+}
+
+// stop removes the watcher and closes its event channel.
+func (b *pipelineRunBroadcaster) stop(w *pipelineRunWatcher) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.removeWatcher(w)
+}
+
+// removeWatcher must be called with the lock held.
+func (b *pipelineRunBroadcaster) removeWatcher(w *pipelineRunWatcher) {
+	if _, ok := b.watchers[w]; ok {
+		delete(b.watchers, w)
+		close(w.events)
+	}
+}
+
+func (b *pipelineRunBroadcaster) broadcast(eventType watch.EventType, obj interface{}) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	pr, ok := obj.(*v1alpha3.PipelineRun)
+	if !ok {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for w := range b.watchers {
+		if !w.matches(pr) {
+			continue
+		}
+		select {
+		case w.events <- WatchEvent{Type: eventType, Object: pr}:
+		default:
+			// the watcher falls behind, so we stop it and let the client watch again
+			klog.V(4).Infof("stopped a PipelineRun watcher in namespace %s because it fell behind", w.namespace)
+			b.removeWatcher(w)
+		}
+	}
+}
+
+// watchPipelineRuns streams events of PipelineRuns in the namespace which match the label selector. Events are
+// encoded as JSON and separated by newlines, which is the same as watching Kubernetes resources. All existing
+// PipelineRuns are sent as ADDED events at first.
+func (h *apiHandler) watchPipelineRuns(request *restful.Request, response *restful.Response, namespace string,
+	selector labels.Selector) {
+	if h.broadcaster == nil {
+		_ = response.WriteErrorString(http.StatusNotImplemented, "watching PipelineRuns is not supported")
+		return
+	}
+	ctx := request.Request.Context()
+	w, err := h.broadcaster.watch(ctx, namespace, selector)
+	if err != nil {
+		api.HandleError(request, response, err)
+		return
+	}
+	defer h.broadcaster.stop(w)
+
+	var prs v1alpha3.PipelineRunList
+	if err := h.client.List(ctx, &prs, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		api.HandleError(request, response, err)
+		return
+	}
+
+	response.Header().Set(restful.HEADER_ContentType, restful.MIME_JSON)
+	response.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(response)
+	send := func(event WatchEvent) error {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+		response.Flush()
+		return nil
+	}
+
+	for i := range prs.Items {
+		if err := send(WatchEvent{Type: watch.Added, Object: &prs.Items[i]}); err != nil {
+			return
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.events:
+			if !ok {
+				return
+			}
+			if err := send(event); err != nil {
+				klog.V(4).Infof("failed to send PipelineRun event, err = %v", err)
+				return
+			}
+		}
+	}
+}
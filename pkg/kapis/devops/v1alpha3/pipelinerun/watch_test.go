@@ -0,0 +1,94 @@
+package pipelinerun
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+)
+
+func newWatchedPipelineRun(namespace, name, pipeline string) *v1alpha3.PipelineRun {
+	return &v1alpha3.PipelineRun{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels: map[string]string{
+				v1alpha3.PipelineNameLabelKey: pipeline,
+			},
+		},
+	}
+}
+
+func newFakeBroadcaster(t *testing.T) (*pipelineRunBroadcaster, *controllertest.FakeInformer) {
+	sch := runtime.NewScheme()
+	if err := v1alpha3.AddToScheme(sch); err != nil {
+		t.Fatalf("unable to add v1alpha3 into scheme, err = %v", err)
+	}
+	informers := &informertest.FakeInformers{Scheme: sch}
+	informer, err := informers.FakeInformerFor(&v1alpha3.PipelineRun{})
+	if err != nil {
+		t.Fatalf("unable to get fake informer, err = %v", err)
+	}
+	return newPipelineRunBroadcaster(informers), informer
+}
+
+func Test_pipelineRunBroadcaster_watch(t *testing.T) {
+	broadcaster, informer := newFakeBroadcaster(t)
+	selector := labels.SelectorFromSet(labels.Set{v1alpha3.PipelineNameLabelKey: "pipeline1"})
+	w, err := broadcaster.watch(context.Background(), "ns", selector)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	pr := newWatchedPipelineRun("ns", "pipeline1-abc", "pipeline1")
+	informer.Add(pr)
+	informer.Add(newWatchedPipelineRun("ns", "pipeline2-abc", "pipeline2"))
+	informer.Add(newWatchedPipelineRun("another-ns", "pipeline1-abc", "pipeline1"))
+	updatedPR := pr.DeepCopy()
+	updatedPR.Status.Phase = v1alpha3.Running
+	informer.Update(pr, updatedPR)
+	informer.Delete(updatedPR)
+
+	wantEvents := []WatchEvent{
+		{Type: watch.Added, Object: pr},
+		{Type: watch.Modified, Object: updatedPR},
+		{Type: watch.Deleted, Object: updatedPR},
+	}
+	assert.Equal(t, len(wantEvents), len(w.events))
+	for _, want := range wantEvents {
+		assert.Equal(t, want, <-w.events)
+	}
+
+	broadcaster.stop(w)
+	_, ok := <-w.events
+	assert.False(t, ok)
+	// stopping twice should be fine
+	broadcaster.stop(w)
+}
+
+func Test_pipelineRunBroadcaster_slowWatcher(t *testing.T) {
+	broadcaster, informer := newFakeBroadcaster(t)
+	w, err := broadcaster.watch(context.Background(), "ns", labels.Everything())
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	pr := newWatchedPipelineRun("ns", "pipeline1-abc", "pipeline1")
+	for i := 0; i <= watcherBufferSize; i++ {
+		informer.Add(pr)
+	}
+	assert.Empty(t, broadcaster.watchers)
+
+	count := 0
+	for range w.events {
+		count++
+	}
+	assert.Equal(t, watcherBufferSize, count)
+}
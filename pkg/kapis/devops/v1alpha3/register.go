@@ -25,8 +25,11 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
 	"kubesphere.io/devops/pkg/client/k8s"
+	"kubesphere.io/devops/pkg/client/s3"
 	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/pipelinerun"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/webhook"
 	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"kubesphere.io/devops/pkg/api"
@@ -34,6 +37,7 @@ import (
 	"kubesphere.io/devops/pkg/apiserver/runtime"
 	devopsClient "kubesphere.io/devops/pkg/client/devops"
 	"kubesphere.io/devops/pkg/constants"
+	"kubesphere.io/devops/pkg/models/devops"
 	"kubesphere.io/devops/pkg/server/params"
 )
 
@@ -41,15 +45,17 @@ import (
 var GroupVersion = schema.GroupVersion{Group: api.GroupName, Version: "v1alpha3"}
 
 // AddToContainer adds web service into container.
-func AddToContainer(container *restful.Container, devopsClient devopsClient.Interface, k8sClient k8s.Client, client client.Client) {
-	ws := runtime.NewWebService(GroupVersion)
-	registerRoutes(devopsClient, k8sClient, ws)
-	pipelinerun.RegisterRoutes(ws, client)
-	container.Add(ws)
-
-	ws = runtime.NewWebServiceWithoutGroup(GroupVersion)
-	registerRoutes(devopsClient, k8sClient, ws)
-	pipelinerun.RegisterRoutes(ws, client)
+func AddToContainer(container *restful.Container, devopsClient devopsClient.Interface, k8sClient k8s.Client, client client.Client,
+	informers cache.Informers, s3Client s3.Interface, jenkinsEventToken string, allowUnsignedSCMEvents bool) {
+	wsWithGroup := runtime.NewWebService(GroupVersion)
+	ws := runtime.NewWebServiceWithoutGroup(GroupVersion)
+	for _, ws := range []*restful.WebService{wsWithGroup, ws} {
+		registerRoutes(devopsClient, k8sClient, ws)
+		webhook.RegisterRoutes(ws, client, k8sClient.Kubernetes(), devopsClient, allowUnsignedSCMEvents)
+	}
+	// register the routes of PipelineRuns at once, so that both web services share the watch of PipelineRuns
+	pipelinerun.RegisterRoutes(devopsClient, client, informers, s3Client, jenkinsEventToken, wsWithGroup, ws)
+	container.Add(wsWithGroup)
 	container.Add(ws)
 }
 
@@ -91,10 +97,30 @@ func registerRoutes(devopsClient devopsClient.Interface, k8sClient k8s.Client, w
 		Returns(http.StatusOK, api.StatusOK, []v1.Secret{}).
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DevOpsProjectTag}))
 
+	ws.Route(ws.POST("/devops/{devops}/credentials/{credential}/rotate").
+		To(handler.RotateCredential).
+		Param(ws.PathParameter("devops", "project name")).
+		Param(ws.PathParameter("credential", "credential name")).
+		Reads(devops.CredentialRotation{}).
+		Doc("merge the new data into the credential of the specified devops, and synchronize it into Jenkins. "+
+			"The keys must belong to the type of the credential, and the required ones cannot be empty").
+		Returns(http.StatusOK, api.StatusOK, v1.Secret{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DevOpsProjectTag}))
+
+	ws.Route(ws.GET("/devops/{devops}/credentials/{credential}/usage").
+		To(handler.GetCredentialUsage).
+		Param(ws.PathParameter("devops", "project name")).
+		Param(ws.PathParameter("credential", "credential name")).
+		Doc("get the pipelines which use the credential of the specified devops, according to the pipeline specs").
+		Returns(http.StatusOK, api.StatusOK, devops.CredentialUsage{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DevOpsProjectTag}))
+
 	ws.Route(ws.DELETE("/devops/{devops}/credentials/{credential}").
 		To(handler.DeleteCredential).
 		Param(ws.PathParameter("devops", "project name")).
 		Param(ws.PathParameter("credential", "credential name")).
+		Param(ws.QueryParameter("force", "delete the credential even if it is used by pipelines, e.g. force=true").Required(false)).
+		Returns(http.StatusConflict, "the credential is used by pipelines", nil).
 		Doc("delete the credential of the specified devops for the current user").
 		Returns(http.StatusOK, api.StatusOK, []v1.Secret{}).
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DevOpsPipelineTag}))
@@ -118,6 +144,16 @@ func registerRoutes(devopsClient devopsClient.Interface, k8sClient k8s.Client, w
 		Returns(http.StatusOK, api.StatusOK, []v1alpha3.Pipeline{}).
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DevOpsProjectTag}))
 
+	ws.Route(ws.POST("/devops/{devops}/pipelines/import").
+		To(handler.ImportPipelines).
+		Param(ws.PathParameter("devops", "devops name")).
+		Param(ws.QueryParameter("sourceFolder", "the Jenkins folder which the jobs are imported from, it must not "+
+			"belong to another devops. The folder of the specified devops is used by default").Required(false)).
+		Doc("import the existing Jenkins jobs of the specified devops as pipelines, the run history is synchronized as well "+
+			"unless the jobs are imported from another folder").
+		Returns(http.StatusOK, api.StatusOK, v1alpha3.DevOpsProject{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DevOpsProjectTag}))
+
 	ws.Route(ws.GET("/devops/{devops}/pipelines/{pipeline}").
 		To(handler.GetPipeline).
 		Operation("getPipelineByName").
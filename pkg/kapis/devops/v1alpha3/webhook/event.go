@@ -0,0 +1,386 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+// Provider is the SCM provider which sends the event, it's the same as the source type of the Pipeline.
+type Provider string
+
+const (
+	// GitHub sends the events with the header X-GitHub-Event.
+	GitHub Provider = v1alpha3.SourceTypeGithub
+	// GitLab sends the events with the header X-Gitlab-Event.
+	GitLab Provider = v1alpha3.SourceTypeGitlab
+	// BitbucketServer sends the events with the header X-Event-Key.
+	BitbucketServer Provider = v1alpha3.SourceTypeBitbucket
+	// Gitea sends the events with the header X-Gitea-Event, and the headers of GitHub as well.
+	Gitea Provider = v1alpha3.SourceTypeGitea
+)
+
+// zeroCommit is the commit of a deleted reference
+const zeroCommit = "0000000000000000000000000000000000000000"
+
+// Reference is a reference changed by the event.
+type Reference struct {
+	// Type is the type of the reference.
+	Type v1alpha3.RefType
+	// Name is the name of the reference in Jenkins, e.g. main, v1.0.0, PR-1 and MR-1.
+	Name string
+	// Commit is the latest commit of the reference.
+	Commit string
+}
+
+// Repository is the repository which the event comes from.
+type Repository struct {
+	// Owner is the owner, group or project key of the repository.
+	Owner string
+	// Name is the name or slug of the repository.
+	Name string
+	// FullName is the full path of the repository, e.g. kubesphere/devops.
+	FullName string
+	// URLs are the web and clone addresses of the repository.
+	URLs []string
+}
+
+// Event is an event from the SCM provider in a common form.
+type Event struct {
+	// Provider is the SCM provider which sends the event.
+	Provider Provider
+	// Type is the type of the event defined by the provider, e.g. push and pull_request.
+	Type string
+	// Repository is the repository which the event comes from.
+	Repository Repository
+	// References are the references to run, the deleted references are excluded.
+	References []Reference
+	// Sender is the user who triggered the event.
+	Sender string
+	// DeliveryID is the unique ID of the delivery, which stays the same when the event is redelivered.
+	DeliveryID string
+}
+
+// detectProvider detects the SCM provider by the headers of the event.
+func detectProvider(header http.Header) (provider Provider, eventType string, err error) {
+	switch {
+	case header.Get("X-Gitea-Event") != "":
+		return Gitea, header.Get("X-Gitea-Event"), nil
+	case header.Get("X-Gitlab-Event") != "":
+		return GitLab, header.Get("X-Gitlab-Event"), nil
+	case header.Get("X-GitHub-Event") != "":
+		return GitHub, header.Get("X-GitHub-Event"), nil
+	case header.Get("X-Event-Key") != "":
+		return BitbucketServer, header.Get("X-Event-Key"), nil
+	}
+	return "", "", errors.New("unknown SCM provider, the event should come from GitHub, GitLab, Bitbucket Server or Gitea")
+}
+
+// parseEvent parses the event from the payload. The returned event is nil if it doesn't trigger any PipelineRun,
+// e.g. ping events and closed pull requests.
+func parseEvent(header http.Header, body []byte) (*Event, error) {
+	provider, eventType, err := detectProvider(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var event *Event
+	switch provider {
+	case GitHub, Gitea:
+		event, err = parseGitHubEvent(eventType, body)
+	case GitLab:
+		event, err = parseGitLabEvent(eventType, body)
+	case BitbucketServer:
+		event, err = parseBitbucketServerEvent(eventType, body)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s event %q, error: %v", provider, eventType, err)
+	}
+	if event == nil || len(event.References) == 0 {
+		return nil, nil
+	}
+	event.Provider = provider
+	event.Type = eventType
+	event.DeliveryID = getDeliveryID(provider, header)
+	return event, nil
+}
+
+// getDeliveryID returns the unique ID of the delivery sent by the SCM provider, it's empty if there is no such header.
+func getDeliveryID(provider Provider, header http.Header) string {
+	switch provider {
+	case GitHub:
+		return header.Get("X-GitHub-Delivery")
+	case Gitea:
+		return header.Get("X-Gitea-Delivery")
+	case GitLab:
+		return header.Get("X-Gitlab-Event-UUID")
+	case BitbucketServer:
+		return header.Get("X-Request-Id")
+	}
+	return ""
+}
+
+// parseGitRef converts refs/heads/* and refs/tags/* into a reference
+func parseGitRef(ref, commit string) (Reference, bool) {
+	switch {
+	case strings.HasPrefix(ref, "refs/heads/"):
+		return Reference{Type: v1alpha3.Branch, Name: strings.TrimPrefix(ref, "refs/heads/"), Commit: commit}, true
+	case strings.HasPrefix(ref, "refs/tags/"):
+		return Reference{Type: v1alpha3.Tag, Name: strings.TrimPrefix(ref, "refs/tags/"), Commit: commit}, true
+	}
+	return Reference{}, false
+}
+
+// the payloads of GitHub and Gitea are almost the same
+type githubUser struct {
+	Login    string `json:"login"`
+	Username string `json:"username"`
+}
+
+func (u *githubUser) name() string {
+	if u.Login != "" {
+		return u.Login
+	}
+	return u.Username
+}
+
+type githubRepository struct {
+	Name     string     `json:"name"`
+	FullName string     `json:"full_name"`
+	Owner    githubUser `json:"owner"`
+	HTMLURL  string     `json:"html_url"`
+	CloneURL string     `json:"clone_url"`
+	SSHURL   string     `json:"ssh_url"`
+}
+
+func (r *githubRepository) toRepository() Repository {
+	return Repository{
+		Owner:    r.Owner.name(),
+		Name:     r.Name,
+		FullName: r.FullName,
+		URLs:     []string{r.HTMLURL, r.CloneURL, r.SSHURL},
+	}
+}
+
+type githubPushEvent struct {
+	Ref        string           `json:"ref"`
+	After      string           `json:"after"`
+	Deleted    bool             `json:"deleted"`
+	Repository githubRepository `json:"repository"`
+	Sender     githubUser       `json:"sender"`
+}
+
+type githubPullRequestEvent struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	} `json:"pull_request"`
+	Repository githubRepository `json:"repository"`
+	Sender     githubUser       `json:"sender"`
+}
+
+func parseGitHubEvent(eventType string, body []byte) (*Event, error) {
+	switch eventType {
+	case "push":
+		payload := &githubPushEvent{}
+		if err := json.Unmarshal(body, payload); err != nil {
+			return nil, err
+		}
+		event := &Event{Repository: payload.Repository.toRepository(), Sender: payload.Sender.name()}
+		if ref, ok := parseGitRef(payload.Ref, payload.After); ok && !payload.Deleted && payload.After != zeroCommit {
+			event.References = append(event.References, ref)
+		}
+		return event, nil
+	case "pull_request":
+		payload := &githubPullRequestEvent{}
+		if err := json.Unmarshal(body, payload); err != nil {
+			return nil, err
+		}
+		event := &Event{Repository: payload.Repository.toRepository(), Sender: payload.Sender.name()}
+		// Gitea uses "synchronized" instead of "synchronize"
+		switch payload.Action {
+		case "opened", "reopened", "synchronize", "synchronized":
+			event.References = append(event.References, Reference{
+				Type:   v1alpha3.PullRequest,
+				Name:   fmt.Sprintf("PR-%d", payload.Number),
+				Commit: payload.PullRequest.Head.SHA,
+			})
+		}
+		return event, nil
+	}
+	return nil, nil
+}
+
+type gitlabProject struct {
+	Name              string `json:"name"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	WebURL            string `json:"web_url"`
+	GitHTTPURL        string `json:"git_http_url"`
+	GitSSHURL         string `json:"git_ssh_url"`
+}
+
+func (p *gitlabProject) toRepository() Repository {
+	repo := Repository{
+		Name:     p.Name,
+		FullName: p.PathWithNamespace,
+		URLs:     []string{p.WebURL, p.GitHTTPURL, p.GitSSHURL},
+	}
+	if index := strings.LastIndex(p.PathWithNamespace, "/"); index >= 0 {
+		repo.Owner = p.PathWithNamespace[:index]
+		repo.Name = p.PathWithNamespace[index+1:]
+	}
+	return repo
+}
+
+type gitlabPushEvent struct {
+	Ref          string        `json:"ref"`
+	After        string        `json:"after"`
+	UserUsername string        `json:"user_username"`
+	Project      gitlabProject `json:"project"`
+}
+
+type gitlabMergeRequestEvent struct {
+	User struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Project          gitlabProject `json:"project"`
+	ObjectAttributes struct {
+		IID        int    `json:"iid"`
+		Action     string `json:"action"`
+		OldRev     string `json:"oldrev"`
+		LastCommit struct {
+			ID string `json:"id"`
+		} `json:"last_commit"`
+	} `json:"object_attributes"`
+}
+
+func parseGitLabEvent(eventType string, body []byte) (*Event, error) {
+	switch eventType {
+	case "Push Hook", "Tag Push Hook":
+		payload := &gitlabPushEvent{}
+		if err := json.Unmarshal(body, payload); err != nil {
+			return nil, err
+		}
+		event := &Event{Repository: payload.Project.toRepository(), Sender: payload.UserUsername}
+		if ref, ok := parseGitRef(payload.Ref, payload.After); ok && payload.After != zeroCommit {
+			event.References = append(event.References, ref)
+		}
+		return event, nil
+	case "Merge Request Hook":
+		payload := &gitlabMergeRequestEvent{}
+		if err := json.Unmarshal(body, payload); err != nil {
+			return nil, err
+		}
+		event := &Event{Repository: payload.Project.toRepository(), Sender: payload.User.Username}
+		attributes := payload.ObjectAttributes
+		// the merge request is updated without new commits if there is no oldrev, e.g. the title is changed
+		if attributes.Action == "open" || attributes.Action == "reopen" ||
+			(attributes.Action == "update" && attributes.OldRev != "") {
+			event.References = append(event.References, Reference{
+				Type:   v1alpha3.MergeRequest,
+				Name:   fmt.Sprintf("MR-%d", attributes.IID),
+				Commit: attributes.LastCommit.ID,
+			})
+		}
+		return event, nil
+	}
+	return nil, nil
+}
+
+type bitbucketUser struct {
+	Name string `json:"name"`
+}
+
+type bitbucketLink struct {
+	Href string `json:"href"`
+}
+
+type bitbucketRepository struct {
+	Slug    string `json:"slug"`
+	Project struct {
+		Key string `json:"key"`
+	} `json:"project"`
+	Links struct {
+		Clone []bitbucketLink `json:"clone"`
+		Self  []bitbucketLink `json:"self"`
+	} `json:"links"`
+}
+
+func (r *bitbucketRepository) toRepository() Repository {
+	repo := Repository{
+		Owner:    r.Project.Key,
+		Name:     r.Slug,
+		FullName: r.Project.Key + "/" + r.Slug,
+	}
+	for _, link := range append(r.Links.Clone, r.Links.Self...) {
+		repo.URLs = append(repo.URLs, link.Href)
+	}
+	return repo
+}
+
+type bitbucketRefsChangedEvent struct {
+	Actor      bitbucketUser       `json:"actor"`
+	Repository bitbucketRepository `json:"repository"`
+	Changes    []struct {
+		Ref struct {
+			ID string `json:"id"`
+		} `json:"ref"`
+		ToHash string `json:"toHash"`
+		Type   string `json:"type"`
+	} `json:"changes"`
+}
+
+type bitbucketPullRequestEvent struct {
+	Actor       bitbucketUser `json:"actor"`
+	PullRequest struct {
+		ID      int `json:"id"`
+		FromRef struct {
+			LatestCommit string `json:"latestCommit"`
+		} `json:"fromRef"`
+		ToRef struct {
+			Repository bitbucketRepository `json:"repository"`
+		} `json:"toRef"`
+	} `json:"pullRequest"`
+}
+
+func parseBitbucketServerEvent(eventType string, body []byte) (*Event, error) {
+	switch eventType {
+	case "repo:refs_changed":
+		payload := &bitbucketRefsChangedEvent{}
+		if err := json.Unmarshal(body, payload); err != nil {
+			return nil, err
+		}
+		event := &Event{Repository: payload.Repository.toRepository(), Sender: payload.Actor.Name}
+		for _, change := range payload.Changes {
+			if change.Type == "DELETE" {
+				continue
+			}
+			if ref, ok := parseGitRef(change.Ref.ID, change.ToHash); ok {
+				event.References = append(event.References, ref)
+			}
+		}
+		return event, nil
+	case "pr:opened", "pr:from_ref_updated":
+		payload := &bitbucketPullRequestEvent{}
+		if err := json.Unmarshal(body, payload); err != nil {
+			return nil, err
+		}
+		return &Event{
+			Repository: payload.PullRequest.ToRef.Repository.toRepository(),
+			References: []Reference{{
+				Type:   v1alpha3.PullRequest,
+				Name:   fmt.Sprintf("PR-%d", payload.PullRequest.ID),
+				Commit: payload.PullRequest.FromRef.LatestCommit,
+			}},
+			Sender: payload.Actor.Name,
+		}, nil
+	}
+	return nil, nil
+}
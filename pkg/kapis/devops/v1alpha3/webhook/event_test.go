@@ -0,0 +1,152 @@
+package webhook
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func TestParseEvent(t *testing.T) {
+	tests := []struct {
+		name           string
+		header         map[string]string
+		body           string
+		wantErr        bool
+		wantProvider   Provider
+		wantRepository Repository
+		wantReferences []Reference
+	}{{
+		name:    "Unknown provider",
+		body:    `{}`,
+		wantErr: true,
+	}, {
+		name:   "GitHub ping",
+		header: map[string]string{"X-GitHub-Event": "ping"},
+		body:   `{"zen":"Keep it logically awesome."}`,
+	}, {
+		name:   "GitHub push",
+		header: map[string]string{"X-GitHub-Event": "push"},
+		body: `{"ref":"refs/heads/main","after":"6113728f27ae82c7b1a177c8d03f9e96e0adf246",
+"repository":{"name":"devops","full_name":"kubesphere/devops","owner":{"login":"kubesphere"},
+"html_url":"https://github.com/kubesphere/devops","clone_url":"https://github.com/kubesphere/devops.git",
+"ssh_url":"git@github.com:kubesphere/devops.git"},"sender":{"login":"alice"}}`,
+		wantProvider: GitHub,
+		wantRepository: Repository{
+			Owner:    "kubesphere",
+			Name:     "devops",
+			FullName: "kubesphere/devops",
+			URLs: []string{"https://github.com/kubesphere/devops", "https://github.com/kubesphere/devops.git",
+				"git@github.com:kubesphere/devops.git"},
+		},
+		wantReferences: []Reference{{
+			Type: v1alpha3.Branch, Name: "main", Commit: "6113728f27ae82c7b1a177c8d03f9e96e0adf246",
+		}},
+	}, {
+		name:   "GitHub deleted branch",
+		header: map[string]string{"X-GitHub-Event": "push"},
+		body:   `{"ref":"refs/heads/feature","after":"0000000000000000000000000000000000000000","deleted":true}`,
+	}, {
+		name:   "GitHub closed pull request",
+		header: map[string]string{"X-GitHub-Event": "pull_request"},
+		body:   `{"action":"closed","number":1}`,
+	}, {
+		name:   "Gitea pull request",
+		header: map[string]string{"X-Gitea-Event": "pull_request", "X-GitHub-Event": "pull_request"},
+		body: `{"action":"synchronized","number":7,"pull_request":{"head":{"sha":"abc"}},
+"repository":{"name":"devops","full_name":"kubesphere/devops","owner":{"username":"kubesphere"}}}`,
+		wantProvider: Gitea,
+		wantRepository: Repository{
+			Owner:    "kubesphere",
+			Name:     "devops",
+			FullName: "kubesphere/devops",
+			URLs:     []string{"", "", ""},
+		},
+		wantReferences: []Reference{{Type: v1alpha3.PullRequest, Name: "PR-7", Commit: "abc"}},
+	}, {
+		name:   "GitLab tag push",
+		header: map[string]string{"X-Gitlab-Event": "Tag Push Hook"},
+		body: `{"object_kind":"tag_push","ref":"refs/tags/v1.0.0","after":"abc","user_username":"alice",
+"project":{"name":"devops","path_with_namespace":"kubesphere/ci/devops"}}`,
+		wantProvider: GitLab,
+		wantRepository: Repository{
+			Owner:    "kubesphere/ci",
+			Name:     "devops",
+			FullName: "kubesphere/ci/devops",
+			URLs:     []string{"", "", ""},
+		},
+		wantReferences: []Reference{{Type: v1alpha3.Tag, Name: "v1.0.0", Commit: "abc"}},
+	}, {
+		name:   "GitLab merge request without new commits",
+		header: map[string]string{"X-Gitlab-Event": "Merge Request Hook"},
+		body:   `{"object_attributes":{"iid":3,"action":"update"}}`,
+	}, {
+		name:   "GitLab merge request",
+		header: map[string]string{"X-Gitlab-Event": "Merge Request Hook"},
+		body: `{"user":{"username":"alice"},"project":{"name":"devops","path_with_namespace":"kubesphere/devops"},
+"object_attributes":{"iid":3,"action":"update","oldrev":"abc","last_commit":{"id":"def"}}}`,
+		wantProvider: GitLab,
+		wantRepository: Repository{
+			Owner:    "kubesphere",
+			Name:     "devops",
+			FullName: "kubesphere/devops",
+			URLs:     []string{"", "", ""},
+		},
+		wantReferences: []Reference{{Type: v1alpha3.MergeRequest, Name: "MR-3", Commit: "def"}},
+	}, {
+		name:   "Bitbucket Server refs changed",
+		header: map[string]string{"X-Event-Key": "repo:refs_changed"},
+		body: `{"actor":{"name":"alice"},"repository":{"slug":"devops","project":{"key":"KS"},
+"links":{"clone":[{"href":"ssh://git@bitbucket.example.com:7999/ks/devops.git"}]}},
+"changes":[{"ref":{"id":"refs/heads/main"},"toHash":"abc","type":"UPDATE"},
+{"ref":{"id":"refs/heads/old"},"toHash":"0000000000000000000000000000000000000000","type":"DELETE"},
+{"ref":{"id":"refs/tags/v1.0.0"},"toHash":"def","type":"ADD"}]}`,
+		wantProvider: BitbucketServer,
+		wantRepository: Repository{
+			Owner:    "KS",
+			Name:     "devops",
+			FullName: "KS/devops",
+			URLs:     []string{"ssh://git@bitbucket.example.com:7999/ks/devops.git"},
+		},
+		wantReferences: []Reference{
+			{Type: v1alpha3.Branch, Name: "main", Commit: "abc"},
+			{Type: v1alpha3.Tag, Name: "v1.0.0", Commit: "def"},
+		},
+	}, {
+		name:    "Invalid payload",
+		header:  map[string]string{"X-GitHub-Event": "push"},
+		body:    `{"ref":`,
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for key, value := range tt.header {
+				header.Set(key, value)
+			}
+			event, err := parseEvent(header, []byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantReferences == nil {
+				if event != nil {
+					t.Errorf("parseEvent() = %+v, want nil", event)
+				}
+				return
+			}
+			if event == nil {
+				t.Fatalf("parseEvent() = nil, want an event")
+			}
+			if event.Provider != tt.wantProvider {
+				t.Errorf("parseEvent() provider = %v, want %v", event.Provider, tt.wantProvider)
+			}
+			if !reflect.DeepEqual(event.Repository, tt.wantRepository) {
+				t.Errorf("parseEvent() repository = %+v, want %+v", event.Repository, tt.wantRepository)
+			}
+			if !reflect.DeepEqual(event.References, tt.wantReferences) {
+				t.Errorf("parseEvent() references = %+v, want %+v", event.References, tt.wantReferences)
+			}
+		})
+	}
+}
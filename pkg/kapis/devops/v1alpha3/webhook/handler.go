@@ -0,0 +1,189 @@
+package webhook
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/emicklei/go-restful"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"kubesphere.io/devops/pkg/api"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/kapis/devops/v1alpha3/pipelinerun"
+)
+
+// maxPayloadSize is the max size of the event payload, which is the same as GitHub
+const maxPayloadSize = 25 * 1024 * 1024
+
+// Result is the result of handling an SCM event.
+type Result struct {
+	// PipelineRuns are the PipelineRuns created for the event, in the form of namespace/name.
+	PipelineRuns []string `json:"pipelineRuns" description:"PipelineRuns created for the event, in the form of namespace/name"`
+}
+
+type handler struct {
+	client       client.Client
+	kubeClient   kubernetes.Interface
+	devopsClient devops.Interface
+	// allowUnsigned allows the events for the Pipelines without a webhook secret
+	allowUnsigned bool
+}
+
+func newHandler(c client.Client, kubeClient kubernetes.Interface, devopsClient devops.Interface, allowUnsigned bool) *handler {
+	return &handler{client: c, kubeClient: kubeClient, devopsClient: devopsClient, allowUnsigned: allowUnsigned}
+}
+
+func (h *handler) receiveSCMEvent(request *restful.Request, response *restful.Response) {
+	body, err := ioutil.ReadAll(io.LimitReader(request.Request.Body, maxPayloadSize))
+	if err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+	event, err := parseEvent(request.Request.Header, body)
+	if err != nil {
+		api.HandleBadRequest(response, request, err)
+		return
+	}
+	result := &Result{PipelineRuns: []string{}}
+	if event == nil {
+		// nothing to run, e.g. a ping event or a deleted branch
+		_ = response.WriteEntity(result)
+		return
+	}
+
+	ctx := context.Background()
+	pipelines := &v1alpha3.PipelineList{}
+	if err := h.client.List(ctx, pipelines); err != nil {
+		api.HandleError(request, response, err)
+		return
+	}
+
+	rejected := 0
+	var errs []error
+	for i := range pipelines.Items {
+		pipeline := &pipelines.Items[i]
+		refs := matchReferences(pipeline, event)
+		if len(refs) == 0 {
+			continue
+		}
+		if err := h.verify(ctx, pipeline, event, request.Request.Header, body); err != nil {
+			klog.Warningf("reject the %s event %q for Pipeline %s/%s, error: %v",
+				event.Provider, event.Type, pipeline.Namespace, pipeline.Name, err)
+			rejected++
+			continue
+		}
+
+		// Jenkins doesn't have the jobs of new branches, tags or pull requests until it indexes the multi-branch
+		// Pipeline. The PipelineRuns are requeued by the controller until the jobs exist.
+		h.scanBranches(pipeline)
+
+		// the kind is required by the owner reference, but it might be empty in a list
+		pipeline.SetGroupVersionKind(v1alpha3.GroupVersion.WithKind(v1alpha3.ResourceKindPipeline))
+		for _, ref := range refs {
+			pr := pipelinerun.CreatePipelineRun(pipeline, nil, &v1alpha3.SCM{RefType: ref.Type, RefName: ref.Name})
+			if event.DeliveryID != "" {
+				// the redelivered event must not create the PipelineRuns again
+				pr.GenerateName = ""
+				pr.Name = getPipelineRunName(pipeline.Name, event.DeliveryID, ref)
+			}
+			switch err := h.client.Create(ctx, pr); {
+			case apierrors.IsAlreadyExists(err):
+				klog.V(4).Infof("PipelineRun %s/%s was created for the delivery %s", pr.Namespace, pr.Name, event.DeliveryID)
+			case err != nil:
+				klog.Errorf("failed to create PipelineRun for the %s %s of Pipeline %s/%s, error: %v",
+					ref.Type, ref.Name, pipeline.Namespace, pipeline.Name, err)
+				errs = append(errs, err)
+				continue
+			default:
+				klog.V(4).Infof("created PipelineRun %s/%s for the %s %s of %s triggered by %s", pr.Namespace, pr.Name,
+					ref.Type, ref.Name, event.Repository.FullName, event.Sender)
+			}
+			result.PipelineRuns = append(result.PipelineRuns, pr.Namespace+"/"+pr.Name)
+		}
+	}
+
+	if len(errs) > 0 {
+		// the SCM provider redelivers the event, and the PipelineRuns created already are not created again
+		api.HandleInternalError(response, request, utilerrors.NewAggregate(errs))
+		return
+	}
+	if rejected > 0 && len(result.PipelineRuns) == 0 {
+		api.HandleForbidden(response, request, fmt.Errorf("the %s event doesn't pass the signature verification "+
+			"of %d matched Pipelines, or they have no webhook secret", event.Provider, rejected))
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusCreated, result)
+}
+
+// scanBranches asks Jenkins to index the multi-branch Pipeline, so that it creates the jobs of new references.
+func (h *handler) scanBranches(pipeline *v1alpha3.Pipeline) {
+	if h.devopsClient == nil {
+		return
+	}
+	// the scan is asynchronous in Jenkins, the existing jobs are still able to run if it fails
+	if _, err := h.devopsClient.ScanBranch(pipeline.Namespace, pipeline.Name, &devops.HttpParameters{
+		Method: http.MethodPost,
+		Url:    &url.URL{RawQuery: "delay=0"},
+	}); err != nil {
+		klog.Warningf("failed to scan the branches of Pipeline %s/%s, error: %v", pipeline.Namespace, pipeline.Name, err)
+	}
+}
+
+// getPipelineRunName returns the name of the PipelineRun created for the reference in the delivery of an event. The
+// name is always the same for the same delivery.
+func getPipelineRunName(pipelineName, deliveryID string, ref Reference) string {
+	hash := sha256.Sum256([]byte(deliveryID + "/" + string(ref.Type) + "/" + ref.Name))
+	return pipelineName + "-" + hex.EncodeToString(hash[:])[:10]
+}
+
+// verify verifies the signature of the event with the webhook secret of the Pipeline, or the one of its DevOps project.
+// The event is rejected if there is no webhook secret, unless unsigned events are allowed.
+func (h *handler) verify(ctx context.Context, pipeline *v1alpha3.Pipeline, event *Event, header http.Header, body []byte) error {
+	secretName, err := h.getWebhookSecretName(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	if secretName == "" {
+		if h.allowUnsigned {
+			return nil
+		}
+		return fmt.Errorf("neither the Pipeline nor its DevOps project has the annotation %s",
+			v1alpha3.PipelineSCMWebhookSecretAnnoKey)
+	}
+	secret, err := h.kubeClient.CoreV1().Secrets(pipeline.Namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get the webhook secret %s, error: %v", secretName, err)
+	}
+	if secret.Type != v1alpha3.SecretTypeSecretText {
+		return fmt.Errorf("the type of the webhook secret %s is %s, want %s", secretName, secret.Type,
+			v1alpha3.SecretTypeSecretText)
+	}
+	return verifySignature(event.Provider, header, body, secret.Data[v1alpha3.SecretTextSecretKey])
+}
+
+// getWebhookSecretName returns the name of the webhook secret annotated on the Pipeline, or on the namespace of its
+// DevOps project. It returns an empty name if neither of them is annotated.
+func (h *handler) getWebhookSecretName(ctx context.Context, pipeline *v1alpha3.Pipeline) (string, error) {
+	if secretName := pipeline.Annotations[v1alpha3.PipelineSCMWebhookSecretAnnoKey]; secretName != "" {
+		return secretName, nil
+	}
+	namespace, err := h.kubeClient.CoreV1().Namespaces().Get(ctx, pipeline.Namespace, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get the namespace %s, error: %v", pipeline.Namespace, err)
+	}
+	return namespace.Annotations[v1alpha3.PipelineSCMWebhookSecretAnnoKey], nil
+}
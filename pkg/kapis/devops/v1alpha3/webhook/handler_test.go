@@ -0,0 +1,215 @@
+package webhook
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	"kubesphere.io/devops/pkg/client/devops"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReceiveSCMEvent(t *testing.T) {
+	sch := runtime.NewScheme()
+	if err := v1alpha3.AddToScheme(sch); err != nil {
+		t.Fatalf("unable to add v1alpha3 into scheme, err = %v", err)
+	}
+	newPipeline := func(namespace, secret string) *v1alpha3.Pipeline {
+		pipeline := newMultiBranchPipeline(&v1alpha3.MultiBranchPipeline{
+			SourceType:   v1alpha3.SourceTypeGithub,
+			GitHubSource: &v1alpha3.GithubSource{Owner: "kubesphere", Repo: "devops", DiscoverBranches: 1},
+		})
+		pipeline.Namespace = namespace
+		if secret != "" {
+			pipeline.Annotations = map[string]string{v1alpha3.PipelineSCMWebhookSecretAnnoKey: secret}
+		}
+		return pipeline
+	}
+	newSecret := func(namespace string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "webhook", Namespace: namespace},
+			Type:       v1alpha3.SecretTypeSecretText,
+			Data:       map[string][]byte{v1alpha3.SecretTextSecretKey: []byte("secret")},
+		}
+	}
+	basicAuth := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "basic-auth", Namespace: "devops-b"},
+		Type:       v1alpha3.SecretTypeBasicAuth,
+		Data:       map[string][]byte{v1alpha3.BasicAuthUsernameKey: []byte("admin")},
+	}
+	kubeClient := k8sfake.NewSimpleClientset(newSecret("devops-b"), newSecret("devops-c"), basicAuth, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "devops-c",
+			Annotations: map[string]string{v1alpha3.PipelineSCMWebhookSecretAnnoKey: "webhook"},
+		},
+	})
+	body := `{"ref":"refs/heads/main","after":"abc","repository":{"name":"devops","full_name":"kubesphere/devops",
+"owner":{"login":"kubesphere"}},"sender":{"login":"alice"}}`
+
+	tests := []struct {
+		name          string
+		pipelines     []runtime.Object
+		signature     string
+		allowUnsigned bool
+		wantStatus    int
+		wantRuns      map[string]int
+	}{{
+		name:       "Reject the event for the Pipeline without a webhook secret",
+		pipelines:  []runtime.Object{newPipeline("devops-a", "")},
+		signature:  "sha256=" + sign(sha256.New, []byte(body), []byte("secret")),
+		wantStatus: http.StatusForbidden,
+		wantRuns:   map[string]int{"devops-a": 0},
+	}, {
+		name:          "Create PipelineRuns for the unprotected Pipeline if unsigned events are allowed",
+		pipelines:     []runtime.Object{newPipeline("devops-a", "")},
+		allowUnsigned: true,
+		wantStatus:    http.StatusCreated,
+		wantRuns:      map[string]int{"devops-a": 1},
+	}, {
+		name:          "Skip the Pipeline if the signature is invalid",
+		pipelines:     []runtime.Object{newPipeline("devops-a", ""), newPipeline("devops-b", "webhook")},
+		signature:     "sha256=" + sign(sha256.New, []byte(body), []byte("another")),
+		allowUnsigned: true,
+		wantStatus:    http.StatusCreated,
+		wantRuns:      map[string]int{"devops-a": 1, "devops-b": 0},
+	}, {
+		name:       "Create PipelineRuns for the protected Pipeline",
+		pipelines:  []runtime.Object{newPipeline("devops-b", "webhook")},
+		signature:  "sha256=" + sign(sha256.New, []byte(body), []byte("secret")),
+		wantStatus: http.StatusCreated,
+		wantRuns:   map[string]int{"devops-b": 1},
+	}, {
+		name:       "Create PipelineRuns for the Pipeline protected by its DevOps project",
+		pipelines:  []runtime.Object{newPipeline("devops-c", "")},
+		signature:  "sha256=" + sign(sha256.New, []byte(body), []byte("secret")),
+		wantStatus: http.StatusCreated,
+		wantRuns:   map[string]int{"devops-c": 1},
+	}, {
+		name:       "Reject the event signed with an empty key for the Pipeline referring to a basic-auth secret",
+		pipelines:  []runtime.Object{newPipeline("devops-b", "basic-auth")},
+		signature:  "sha256=" + sign(sha256.New, []byte(body), nil),
+		wantStatus: http.StatusForbidden,
+		wantRuns:   map[string]int{"devops-b": 0},
+	}, {
+		name:       "All Pipelines reject the event",
+		pipelines:  []runtime.Object{newPipeline("devops-b", "webhook")},
+		wantStatus: http.StatusForbidden,
+		wantRuns:   map[string]int{"devops-b": 0},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewFakeClientWithScheme(sch, tt.pipelines...)
+			scanner := &fakeScanner{}
+			h := newHandler(c, kubeClient, scanner, tt.allowUnsigned)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/scm", strings.NewReader(body))
+			req.Header.Set("X-GitHub-Event", "push")
+			if tt.signature != "" {
+				req.Header.Set("X-Hub-Signature-256", tt.signature)
+			}
+			recorder := httptest.NewRecorder()
+			response := restful.NewResponse(recorder)
+			response.SetRequestAccepts(restful.MIME_JSON)
+			h.receiveSCMEvent(restful.NewRequest(req), response)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("receiveSCMEvent() status = %v, want %v, body %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+			for namespace, want := range tt.wantRuns {
+				runs := &v1alpha3.PipelineRunList{}
+				if err := c.List(context.Background(), runs, client.InNamespace(namespace)); err != nil {
+					t.Fatalf("failed to list PipelineRuns, error %v", err)
+				}
+				if len(runs.Items) != want {
+					t.Errorf("the number of PipelineRuns in %s = %d, want %d", namespace, len(runs.Items), want)
+					continue
+				}
+				if want > 0 && !scanner.scanned[namespace+"/demo"] {
+					t.Errorf("the Pipeline in %s was not scanned", namespace)
+				}
+				for _, run := range runs.Items {
+					if scm := run.Spec.SCM; scm == nil || scm.RefType != v1alpha3.Branch || scm.RefName != "main" {
+						t.Errorf("the SCM of PipelineRun = %+v, want branch main", scm)
+					}
+				}
+			}
+		})
+	}
+}
+
+type fakeScanner struct {
+	devops.Interface
+	scanned map[string]bool
+}
+
+func (s *fakeScanner) ScanBranch(projectName, pipelineName string, _ *devops.HttpParameters) ([]byte, error) {
+	if s.scanned == nil {
+		s.scanned = map[string]bool{}
+	}
+	s.scanned[projectName+"/"+pipelineName] = true
+	return nil, nil
+}
+
+func TestReceiveRedeliveredSCMEvent(t *testing.T) {
+	sch := runtime.NewScheme()
+	if err := v1alpha3.AddToScheme(sch); err != nil {
+		t.Fatalf("unable to add v1alpha3 into scheme, err = %v", err)
+	}
+	pipeline := newMultiBranchPipeline(&v1alpha3.MultiBranchPipeline{
+		SourceType:   v1alpha3.SourceTypeGithub,
+		GitHubSource: &v1alpha3.GithubSource{Owner: "kubesphere", Repo: "devops", DiscoverBranches: 1},
+	})
+	pipeline.Namespace = "devops-a"
+	c := fake.NewFakeClientWithScheme(sch, pipeline)
+	h := newHandler(c, k8sfake.NewSimpleClientset(), &fakeScanner{}, true)
+	body := `{"ref":"refs/heads/main","after":"abc","repository":{"name":"devops","full_name":"kubesphere/devops",
+"owner":{"login":"kubesphere"}},"sender":{"login":"alice"}}`
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/scm", strings.NewReader(body))
+		req.Header.Set("X-GitHub-Event", "push")
+		req.Header.Set("X-GitHub-Delivery", "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+		recorder := httptest.NewRecorder()
+		response := restful.NewResponse(recorder)
+		response.SetRequestAccepts(restful.MIME_JSON)
+		h.receiveSCMEvent(restful.NewRequest(req), response)
+		if recorder.Code != http.StatusCreated {
+			t.Fatalf("receiveSCMEvent() status = %v, want %v, body %s", recorder.Code, http.StatusCreated, recorder.Body)
+		}
+	}
+
+	runs := &v1alpha3.PipelineRunList{}
+	if err := c.List(context.Background(), runs, client.InNamespace("devops-a")); err != nil {
+		t.Fatalf("failed to list PipelineRuns, error %v", err)
+	}
+	if len(runs.Items) != 1 {
+		t.Errorf("the number of PipelineRuns = %d, want 1", len(runs.Items))
+	}
+}
+
+func Test_getPipelineRunName(t *testing.T) {
+	main := Reference{Type: v1alpha3.Branch, Name: "main"}
+	name := getPipelineRunName("pipeline", "delivery-1", main)
+	if !strings.HasPrefix(name, "pipeline-") || len(name) != len("pipeline-")+10 {
+		t.Errorf("getPipelineRunName() = %s, want pipeline- followed by 10 characters", name)
+	}
+	if another := getPipelineRunName("pipeline", "delivery-1", main); another != name {
+		t.Errorf("getPipelineRunName() = %s, want %s for the same delivery", another, name)
+	}
+	if another := getPipelineRunName("pipeline", "delivery-2", main); another == name {
+		t.Errorf("getPipelineRunName() = %s for another delivery, want a different name", another)
+	}
+	if another := getPipelineRunName("pipeline", "delivery-1", Reference{Type: v1alpha3.Tag, Name: "main"}); another == name {
+		t.Errorf("getPipelineRunName() = %s for another reference, want a different name", another)
+	}
+}
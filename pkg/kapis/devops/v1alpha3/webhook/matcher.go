@@ -0,0 +1,168 @@
+package webhook
+
+import (
+	"regexp"
+	"strings"
+
+	"k8s.io/klog"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+// matchReferences returns the references of the event which the Pipeline should run. Only the multi-branch Pipelines
+// whose SCM source refers to the repository of the event are matched, and the references are filtered by the
+// discovery options and the regex filter of the source, which is the same as Jenkins does.
+func matchReferences(pipeline *v1alpha3.Pipeline, event *Event) []Reference {
+	if pipeline.Spec.Type != v1alpha3.MultiBranchPipelineType || pipeline.Spec.MultiBranchPipeline == nil {
+		return nil
+	}
+	source := pipeline.Spec.MultiBranchPipeline
+
+	var (
+		matched      bool
+		discovery    discoveryOptions
+		regexFilter  string
+		providerType = Provider(source.SourceType)
+	)
+	switch {
+	case source.SourceType == v1alpha3.SourceTypeGit && source.GitSource != nil:
+		matched = matchURL(source.GitSource.Url, event.Repository.URLs)
+		discovery = discoveryOptions{
+			branches: source.GitSource.DiscoverBranches,
+			tags:     source.GitSource.DiscoverTags,
+		}
+		regexFilter = source.GitSource.RegexFilter
+	case providerType != event.Provider:
+		// the sources of SCM providers only match the events from the same provider
+	case source.GitHubSource != nil && event.Provider == GitHub:
+		matched = matchOwnerAndName(source.GitHubSource.Owner, source.GitHubSource.Repo, event.Repository)
+		discovery = discoveryOptions{
+			branches:     source.GitHubSource.DiscoverBranches != 0,
+			tags:         source.GitHubSource.DiscoverTags,
+			pullRequests: source.GitHubSource.DiscoverPRFromOrigin != 0 || source.GitHubSource.DiscoverPRFromForks != nil,
+		}
+		regexFilter = source.GitHubSource.RegexFilter
+	case source.GitlabSource != nil && event.Provider == GitLab:
+		matched = matchOwnerAndName(source.GitlabSource.Owner, source.GitlabSource.Repo, event.Repository)
+		discovery = discoveryOptions{
+			branches:     source.GitlabSource.DiscoverBranches != 0,
+			tags:         source.GitlabSource.DiscoverTags,
+			pullRequests: source.GitlabSource.DiscoverPRFromOrigin != 0 || source.GitlabSource.DiscoverPRFromForks != nil,
+		}
+		regexFilter = source.GitlabSource.RegexFilter
+	case source.BitbucketServerSource != nil && event.Provider == BitbucketServer:
+		matched = matchOwnerAndName(source.BitbucketServerSource.Owner, source.BitbucketServerSource.Repo, event.Repository)
+		discovery = discoveryOptions{
+			branches: source.BitbucketServerSource.DiscoverBranches != 0,
+			tags:     source.BitbucketServerSource.DiscoverTags,
+			pullRequests: source.BitbucketServerSource.DiscoverPRFromOrigin != 0 ||
+				source.BitbucketServerSource.DiscoverPRFromForks != nil,
+		}
+		regexFilter = source.BitbucketServerSource.RegexFilter
+	case source.GiteaSource != nil && event.Provider == Gitea:
+		matched = matchOwnerAndName(source.GiteaSource.Owner, source.GiteaSource.Repo, event.Repository)
+		discovery = discoveryOptions{
+			branches:     source.GiteaSource.DiscoverBranches != 0,
+			tags:         source.GiteaSource.DiscoverTags,
+			pullRequests: source.GiteaSource.DiscoverPRFromOrigin != 0 || source.GiteaSource.DiscoverPRFromForks != nil,
+		}
+		regexFilter = source.GiteaSource.RegexFilter
+	}
+	if !matched {
+		return nil
+	}
+
+	var filter *regexp.Regexp
+	if regexFilter != "" {
+		var err error
+		if filter, err = regexp.Compile("^(?:" + regexFilter + ")$"); err != nil {
+			klog.V(4).Infof("ignore the invalid regex filter %q of Pipeline %s/%s, error %v",
+				regexFilter, pipeline.Namespace, pipeline.Name, err)
+			filter = nil
+		}
+	}
+
+	var refs []Reference
+	for _, ref := range event.References {
+		if !discovery.discovers(ref.Type) {
+			continue
+		}
+		if filter != nil && !filter.MatchString(ref.Name) {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// discoveryOptions indicates which types of references are discovered by the source
+type discoveryOptions struct {
+	branches     bool
+	tags         bool
+	pullRequests bool
+}
+
+func (o discoveryOptions) discovers(refType v1alpha3.RefType) bool {
+	switch refType {
+	case v1alpha3.Branch:
+		return o.branches
+	case v1alpha3.Tag:
+		return o.tags
+	case v1alpha3.PullRequest, v1alpha3.MergeRequest:
+		return o.pullRequests
+	}
+	return false
+}
+
+// matchOwnerAndName checks if the owner and the repo of the source refer to the repository.
+// The repo of GitLab source might be the full path, e.g. group/subgroup/project.
+func matchOwnerAndName(owner, repo string, repository Repository) bool {
+	if strings.Contains(repo, "/") {
+		return strings.EqualFold(repo, repository.FullName)
+	}
+	return strings.EqualFold(owner, repository.Owner) && strings.EqualFold(repo, repository.Name)
+}
+
+// matchURL checks if the URL refers to one of the URLs, no matter it's an HTTP or SSH URL
+func matchURL(url string, urls []string) bool {
+	expected := normalizeURL(url)
+	if expected == "" {
+		return false
+	}
+	for _, item := range urls {
+		if normalizeURL(item) == expected {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeURL converts the URL of a repository into the form host/path, e.g. all of
+// https://github.com/kubesphere/devops.git, git@github.com:kubesphere/devops.git and
+// ssh://git@github.com:22/kubesphere/devops are converted into github.com/kubesphere/devops.
+func normalizeURL(url string) string {
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return ""
+	}
+
+	if index := strings.Index(url, "://"); index >= 0 {
+		url = url[index+3:]
+	} else if index := strings.Index(url, ":"); index >= 0 && !strings.Contains(url[:index], "/") {
+		// scp-like SSH URL, e.g. git@github.com:kubesphere/devops.git
+		url = url[:index] + "/" + url[index+1:]
+	}
+
+	host, path := url, ""
+	if index := strings.Index(url, "/"); index >= 0 {
+		host, path = url[:index], url[index+1:]
+	}
+	if index := strings.LastIndex(host, "@"); index >= 0 {
+		host = host[index+1:]
+	}
+	if index := strings.Index(host, ":"); index >= 0 {
+		host = host[:index]
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	return strings.ToLower(host + "/" + path)
+}
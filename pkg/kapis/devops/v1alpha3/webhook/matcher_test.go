@@ -0,0 +1,153 @@
+package webhook
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+func newMultiBranchPipeline(source *v1alpha3.MultiBranchPipeline) *v1alpha3.Pipeline {
+	return &v1alpha3.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "devops-a"},
+		Spec: v1alpha3.PipelineSpec{
+			Type:                v1alpha3.MultiBranchPipelineType,
+			MultiBranchPipeline: source,
+		},
+	}
+}
+
+func TestMatchReferences(t *testing.T) {
+	main := Reference{Type: v1alpha3.Branch, Name: "main"}
+	tag := Reference{Type: v1alpha3.Tag, Name: "v1.0.0"}
+	pr := Reference{Type: v1alpha3.PullRequest, Name: "PR-1"}
+	githubEvent := &Event{
+		Provider: GitHub,
+		Repository: Repository{
+			Owner:    "kubesphere",
+			Name:     "devops",
+			FullName: "kubesphere/devops",
+			URLs:     []string{"https://github.com/kubesphere/devops", "git@github.com:kubesphere/devops.git"},
+		},
+		References: []Reference{main, tag, pr},
+	}
+	tests := []struct {
+		name     string
+		pipeline *v1alpha3.Pipeline
+		event    *Event
+		want     []Reference
+	}{{
+		name: "GitHub source discovers branches and pull requests",
+		pipeline: newMultiBranchPipeline(&v1alpha3.MultiBranchPipeline{
+			SourceType: v1alpha3.SourceTypeGithub,
+			GitHubSource: &v1alpha3.GithubSource{
+				Owner:                "KubeSphere",
+				Repo:                 "devops",
+				DiscoverBranches:     1,
+				DiscoverPRFromOrigin: 2,
+			},
+		}),
+		event: githubEvent,
+		want:  []Reference{main, pr},
+	}, {
+		name: "GitHub source of another repository",
+		pipeline: newMultiBranchPipeline(&v1alpha3.MultiBranchPipeline{
+			SourceType:   v1alpha3.SourceTypeGithub,
+			GitHubSource: &v1alpha3.GithubSource{Owner: "kubesphere", Repo: "kubesphere", DiscoverBranches: 1},
+		}),
+		event: githubEvent,
+	}, {
+		name: "GitLab source doesn't match GitHub events",
+		pipeline: newMultiBranchPipeline(&v1alpha3.MultiBranchPipeline{
+			SourceType:   v1alpha3.SourceTypeGitlab,
+			GitlabSource: &v1alpha3.GitlabSource{Owner: "kubesphere", Repo: "devops", DiscoverBranches: 1},
+		}),
+		event: githubEvent,
+	}, {
+		name: "Git source matches by URL and filters by regex",
+		pipeline: newMultiBranchPipeline(&v1alpha3.MultiBranchPipeline{
+			SourceType: v1alpha3.SourceTypeGit,
+			GitSource: &v1alpha3.GitSource{
+				Url:              "ssh://git@github.com/kubesphere/devops.git",
+				DiscoverBranches: true,
+				DiscoverTags:     true,
+				RegexFilter:      "v.*|release-.*",
+			},
+		}),
+		event: githubEvent,
+		want:  []Reference{tag},
+	}, {
+		name: "GitLab source with the full path",
+		pipeline: newMultiBranchPipeline(&v1alpha3.MultiBranchPipeline{
+			SourceType: v1alpha3.SourceTypeGitlab,
+			GitlabSource: &v1alpha3.GitlabSource{
+				Owner:                "kubesphere",
+				Repo:                 "kubesphere/ci/devops",
+				DiscoverPRFromOrigin: 1,
+			},
+		}),
+		event: &Event{
+			Provider:   GitLab,
+			Repository: Repository{Owner: "kubesphere/ci", Name: "devops", FullName: "kubesphere/ci/devops"},
+			References: []Reference{main, {Type: v1alpha3.MergeRequest, Name: "MR-1"}},
+		},
+		want: []Reference{{Type: v1alpha3.MergeRequest, Name: "MR-1"}},
+	}, {
+		name: "Gitea source discovers tags and pull requests from forks",
+		pipeline: newMultiBranchPipeline(&v1alpha3.MultiBranchPipeline{
+			SourceType: v1alpha3.SourceTypeGitea,
+			GiteaSource: &v1alpha3.GiteaSource{
+				ServerUrl:           "https://gitea.example.com",
+				Owner:               "kubesphere",
+				Repo:                "devops",
+				DiscoverTags:        true,
+				DiscoverPRFromForks: &v1alpha3.DiscoverPRFromForks{Strategy: 1, Trust: 1},
+			},
+		}),
+		event: &Event{
+			Provider:   Gitea,
+			Repository: Repository{Owner: "kubesphere", Name: "devops", FullName: "kubesphere/devops"},
+			References: []Reference{main, tag, pr},
+		},
+		want: []Reference{tag, pr},
+	}, {
+		name: "Gitea source doesn't match GitHub events",
+		pipeline: newMultiBranchPipeline(&v1alpha3.MultiBranchPipeline{
+			SourceType:  v1alpha3.SourceTypeGitea,
+			GiteaSource: &v1alpha3.GiteaSource{Owner: "kubesphere", Repo: "devops", DiscoverBranches: 1},
+		}),
+		event: githubEvent,
+	}, {
+		name:     "Pipeline without SCM",
+		pipeline: &v1alpha3.Pipeline{Spec: v1alpha3.PipelineSpec{Type: v1alpha3.NoScmPipelineType}},
+		event:    githubEvent,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchReferences(tt.pipeline, tt.event); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matchReferences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://github.com/kubesphere/devops", want: "github.com/kubesphere/devops"},
+		{url: "https://alice@GitHub.com/kubesphere/devops.git/", want: "github.com/kubesphere/devops"},
+		{url: "git@github.com:kubesphere/devops.git", want: "github.com/kubesphere/devops"},
+		{url: "ssh://git@gitlab.example.com:2222/group/sub/devops.git", want: "gitlab.example.com/group/sub/devops"},
+		{url: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := normalizeURL(tt.url); got != tt.want {
+				t.Errorf("normalizeURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
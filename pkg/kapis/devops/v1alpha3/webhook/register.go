@@ -0,0 +1,30 @@
+package webhook
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/client-go/kubernetes"
+	"kubesphere.io/devops/pkg/client/devops"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RegisterRoutes registers the routes of receiving SCM events into the web service. The events for the Pipelines
+// without a webhook secret are rejected, unless allowUnsigned is true.
+func RegisterRoutes(ws *restful.WebService, c client.Client, kubeClient kubernetes.Interface, devopsClient devops.Interface,
+	allowUnsigned bool) {
+	handler := newHandler(c, kubeClient, devopsClient, allowUnsigned)
+	ws.Route(ws.POST("/webhooks/scm").
+		To(handler.receiveSCMEvent).
+		Doc("Receive push and pull request events from GitHub, GitLab, Bitbucket Server or Gitea, then create "+
+			"PipelineRuns for the multi-branch Pipelines whose SCM source refers to the repository. The event is verified "+
+			"with the secret-text credential which the annotation pipeline.devops.kubesphere.io/scm-webhook-secret of "+
+			"the Pipeline, or of the namespace of its DevOps project, refers to. Pipelines without the annotation "+
+			"reject the event by default. The multi-branch Pipelines are scanned, so that Jenkins creates the jobs of new "+
+			"references. PipelineRuns are named after the delivery of the event, a redelivered event doesn't create "+
+			"them again").
+		Returns(http.StatusCreated, http.StatusText(http.StatusCreated), Result{}).
+		Returns(http.StatusOK, "The event doesn't trigger any PipelineRun", Result{}).
+		Returns(http.StatusForbidden, "The event doesn't pass the signature verification, or the Pipelines have no webhook secret", nil).
+		Returns(http.StatusInternalServerError, "Some of the PipelineRuns failed to be created, the event should be redelivered", nil))
+}
@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+// verifySignature verifies the event with the secret shared with the SCM provider.
+// GitHub, Gitea and Bitbucket Server sign the payload with HMAC, GitLab sends the secret as a token.
+func verifySignature(provider Provider, header http.Header, body, secret []byte) error {
+	switch provider {
+	case GitHub:
+		if signature := header.Get("X-Hub-Signature-256"); signature != "" {
+			return verifyHMAC(sha256.New, strings.TrimPrefix(signature, "sha256="), body, secret)
+		}
+		if signature := header.Get("X-Hub-Signature"); signature != "" {
+			return verifyHMAC(sha1.New, strings.TrimPrefix(signature, "sha1="), body, secret)
+		}
+	case Gitea:
+		if signature := header.Get("X-Gitea-Signature"); signature != "" {
+			return verifyHMAC(sha256.New, signature, body, secret)
+		}
+	case BitbucketServer:
+		if signature := header.Get("X-Hub-Signature"); signature != "" {
+			return verifyHMAC(sha256.New, strings.TrimPrefix(signature, "sha256="), body, secret)
+		}
+	case GitLab:
+		if token := header.Get("X-Gitlab-Token"); token != "" {
+			if subtle.ConstantTimeCompare([]byte(token), secret) != 1 {
+				return errors.New("the token of the event doesn't match the secret")
+			}
+			return nil
+		}
+	}
+	return errors.New("the event is not signed")
+}
+
+func verifyHMAC(newHash func() hash.Hash, signature string, body, secret []byte) error {
+	if len(secret) == 0 {
+		// anyone is able to sign the payload with an empty key
+		return errors.New("the webhook secret is empty")
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return errors.New("the signature of the event is not a hex string")
+	}
+	mac := hmac.New(newHash, secret)
+	_, _ = mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return errors.New("the signature of the event doesn't match the secret")
+	}
+	return nil
+}
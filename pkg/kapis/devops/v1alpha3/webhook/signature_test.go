@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"testing"
+)
+
+func sign(newHash func() hash.Hash, body, secret []byte) string {
+	mac := hmac.New(newHash, secret)
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	secret := []byte("secret")
+	tests := []struct {
+		name     string
+		provider Provider
+		header   map[string]string
+		wantErr  bool
+	}{{
+		name:     "GitHub sha256",
+		provider: GitHub,
+		header: map[string]string{
+			"X-Hub-Signature-256": "sha256=2c7d9eb3cc20a45d5b9ad2b4a5e0f4f6a7b3ab1e7a2ed1b4e1f1b74e0bbc4a8f",
+		},
+		wantErr: true,
+	}, {
+		name:     "GitHub sha1",
+		provider: GitHub,
+		header:   map[string]string{"X-Hub-Signature": "sha1=" + sign(sha1.New, body, secret)},
+	}, {
+		name:     "GitHub sha256 is preferred",
+		provider: GitHub,
+		header: map[string]string{
+			"X-Hub-Signature-256": "sha256=" + sign(sha256.New, body, secret),
+			"X-Hub-Signature":     "sha1=invalid",
+		},
+	}, {
+		name:     "Gitea",
+		provider: Gitea,
+		header:   map[string]string{"X-Gitea-Signature": sign(sha256.New, body, secret)},
+	}, {
+		name:     "Bitbucket Server",
+		provider: BitbucketServer,
+		header:   map[string]string{"X-Hub-Signature": "sha256=" + sign(sha256.New, body, secret)},
+	}, {
+		name:     "Bitbucket Server with another secret",
+		provider: BitbucketServer,
+		header:   map[string]string{"X-Hub-Signature": "sha256=" + sign(sha256.New, body, []byte("another"))},
+		wantErr:  true,
+	}, {
+		name:     "GitLab",
+		provider: GitLab,
+		header:   map[string]string{"X-Gitlab-Token": "secret"},
+	}, {
+		name:     "GitLab with wrong token",
+		provider: GitLab,
+		header:   map[string]string{"X-Gitlab-Token": "secrets"},
+		wantErr:  true,
+	}, {
+		name:     "Unsigned event",
+		provider: GitHub,
+		wantErr:  true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for key, value := range tt.header {
+				header.Set(key, value)
+			}
+			if err := verifySignature(tt.provider, header, body, secret); (err != nil) != tt.wantErr {
+				t.Errorf("verifySignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifySignatureWithEmptySecret(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	for _, provider := range []Provider{GitHub, Gitea, BitbucketServer} {
+		header := http.Header{}
+		header.Set("X-Hub-Signature-256", "sha256="+sign(sha256.New, body, nil))
+		header.Set("X-Hub-Signature", "sha256="+sign(sha256.New, body, nil))
+		header.Set("X-Gitea-Signature", sign(sha256.New, body, nil))
+		if err := verifySignature(provider, header, body, nil); err == nil {
+			t.Errorf("verifySignature() of %s passed the payload signed with an empty key", provider)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devops
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+)
+
+// The places in a Pipeline where a credential is referred to
+const (
+	CredentialRefGitSource             = "git_source"
+	CredentialRefGithubSource          = "github_source"
+	CredentialRefGitlabSource          = "gitlab_source"
+	CredentialRefSvnSource             = "svn_source"
+	CredentialRefSingleSvnSource       = "single_svn_source"
+	CredentialRefBitbucketServerSource = "bitbucket_server_source"
+	CredentialRefGiteaSource           = "gitea_source"
+	CredentialRefJenkinsfile           = "jenkinsfile"
+)
+
+// CredentialUsage describes which Pipelines use a credential
+type CredentialUsage struct {
+	// Credential is the name of the credential
+	Credential string `json:"credential"`
+	// Pipelines are the Pipelines which refer to the credential, sorted by name
+	Pipelines []PipelineCredentialRef `json:"pipelines"`
+}
+
+// PipelineCredentialRef describes where a Pipeline refers to a credential
+type PipelineCredentialRef struct {
+	// Name is the name of the Pipeline
+	Name string `json:"name"`
+	// RefersFrom are the places where the credential is referred to, e.g. git_source, jenkinsfile
+	RefersFrom []string `json:"refersFrom"`
+}
+
+var (
+	// jenkinsfileCredentialPatterns match the credential IDs of the steps, e.g.
+	// usernamePassword(credentialsId: 'github', ...), kubernetesDeploy(kubeconfigId: 'kubeconfig', ...),
+	// credentials('github') in the environment directive
+	jenkinsfileCredentialPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\b(?:credentialsId|kubeconfigId)\s*:\s*(?:'([^']*)'|"([^"]*)")`),
+		regexp.MustCompile(`\bcredentials\s*\(\s*(?:'([^']*)'|"([^"]*)")\s*\)`),
+	}
+	// jenkinsfileVariablePattern matches the assignments of string variables, e.g. GITHUB_CREDENTIAL_ID = 'github'
+	jenkinsfileVariablePattern = regexp.MustCompile(`(?m)^\s*(?:def\s+)?([A-Za-z_]\w*)\s*=\s*(?:'([^']*)'|"([^"$]*)")\s*$`)
+	// jenkinsfileReferencePattern matches a value which is a single variable, e.g. $GITHUB_CREDENTIAL_ID, ${env.ID}
+	jenkinsfileReferencePattern = regexp.MustCompile(`^\$\{?(?:env\.)?([A-Za-z_]\w*)\}?$`)
+)
+
+// GetPipelineCredentials returns the credentials referred to by the Pipeline, and the places where they're referred to.
+// The Jenkinsfile of a multi-branch Pipeline lives in the SCM, so only the credential of the SCM is known.
+func GetPipelineCredentials(pipeline *v1alpha3.Pipeline) map[string][]string {
+	refs := make(map[string][]string)
+	add := func(credential, from string) {
+		if credential == "" {
+			return
+		}
+		for _, item := range refs[credential] {
+			if item == from {
+				return
+			}
+		}
+		refs[credential] = append(refs[credential], from)
+	}
+
+	if p := pipeline.Spec.Pipeline; p != nil {
+		for _, credential := range ParseJenkinsfileCredentials(p.Jenkinsfile) {
+			add(credential, CredentialRefJenkinsfile)
+		}
+	}
+	if p := pipeline.Spec.MultiBranchPipeline; p != nil {
+		if p.GitSource != nil {
+			add(p.GitSource.CredentialId, CredentialRefGitSource)
+		}
+		if p.GitHubSource != nil {
+			add(p.GitHubSource.CredentialId, CredentialRefGithubSource)
+		}
+		if p.GitlabSource != nil {
+			add(p.GitlabSource.CredentialId, CredentialRefGitlabSource)
+		}
+		if p.SvnSource != nil {
+			add(p.SvnSource.CredentialId, CredentialRefSvnSource)
+		}
+		if p.SingleSvnSource != nil {
+			add(p.SingleSvnSource.CredentialId, CredentialRefSingleSvnSource)
+		}
+		if p.BitbucketServerSource != nil {
+			add(p.BitbucketServerSource.CredentialId, CredentialRefBitbucketServerSource)
+		}
+		if p.GiteaSource != nil {
+			add(p.GiteaSource.CredentialId, CredentialRefGiteaSource)
+		}
+	}
+	return refs
+}
+
+// ParseJenkinsfileCredentials returns the sorted credential IDs referred to by the Jenkinsfile. A reference to a
+// variable is resolved if the variable is assigned a string literal in the Jenkinsfile, otherwise it's ignored.
+func ParseJenkinsfileCredentials(jenkinsfile string) []string {
+	if jenkinsfile == "" {
+		return nil
+	}
+	variables := make(map[string]string)
+	for _, match := range jenkinsfileVariablePattern.FindAllStringSubmatch(jenkinsfile, -1) {
+		variables[match[1]] = match[2] + match[3]
+	}
+
+	found := make(map[string]bool)
+	for _, pattern := range jenkinsfileCredentialPatterns {
+		for _, match := range pattern.FindAllStringSubmatch(jenkinsfile, -1) {
+			value := strings.TrimSpace(match[1] + match[2])
+			if ref := jenkinsfileReferencePattern.FindStringSubmatch(value); ref != nil {
+				value = variables[ref[1]]
+			}
+			// the value cannot be known before running, e.g. "${PREFIX}-github"
+			if value == "" || strings.Contains(value, "$") {
+				continue
+			}
+			found[value] = true
+		}
+	}
+
+	credentials := make([]string, 0, len(found))
+	for credential := range found {
+		credentials = append(credentials, credential)
+	}
+	sort.Strings(credentials)
+	return credentials
+}
+
+// getCredentialUsage returns the usage of the credential in the Pipelines
+func getCredentialUsage(credential string, pipelines []v1alpha3.Pipeline) *CredentialUsage {
+	usage := &CredentialUsage{
+		Credential: credential,
+		Pipelines:  []PipelineCredentialRef{},
+	}
+	for i := range pipelines {
+		if refersFrom, ok := GetPipelineCredentials(&pipelines[i])[credential]; ok {
+			usage.Pipelines = append(usage.Pipelines, PipelineCredentialRef{
+				Name:       pipelines[i].Name,
+				RefersFrom: refersFrom,
+			})
+		}
+	}
+	sort.Slice(usage.Pipelines, func(i, j int) bool {
+		return usage.Pipelines[i].Name < usage.Pipelines[j].Name
+	})
+	return usage
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ksfake "kubesphere.io/devops/pkg/client/clientset/versioned/fake"
+	"kubesphere.io/devops/pkg/client/devops/fake"
+)
+
+func TestParseJenkinsfileCredentials(t *testing.T) {
+	tests := []struct {
+		name        string
+		jenkinsfile string
+		want        []string
+	}{{
+		name: "empty Jenkinsfile",
+	}, {
+		name: "steps with credentials",
+		jenkinsfile: `pipeline {
+  agent any
+  environment {
+    GITHUB = credentials('github-token')
+  }
+  stages {
+    stage('deploy') {
+      steps {
+        withCredentials([usernamePassword(credentialsId : "dockerhub", usernameVariable: 'USER', passwordVariable: 'PASS')]) {
+          sh 'docker login -u $USER -p $PASS'
+        }
+        git(url: 'https://github.com/kubesphere/devops', credentialsId: 'github-token', branch: 'master')
+      }
+    }
+  }
+}`,
+		want: []string{"dockerhub", "github-token"},
+	}, {
+		name: "credentials in variables",
+		jenkinsfile: `pipeline {
+  environment {
+    KUBECONFIG_CREDENTIAL_ID = 'demo-kubeconfig'
+    DOCKER_CREDENTIAL_ID = "dockerhub-id"
+  }
+  stages {
+    stage('deploy') {
+      steps {
+        kubernetesDeploy(configs: 'deploy/**', enableConfigSubstitution: true, kubeconfigId: "$KUBECONFIG_CREDENTIAL_ID")
+        withCredentials([usernamePassword(credentialsId: "${env.DOCKER_CREDENTIAL_ID}", usernameVariable: 'USER', passwordVariable: 'PASS')]) {
+          sh 'echo $USER'
+        }
+        withCredentials([string(credentialsId: "${PREFIX}-token", variable: 'TOKEN')]) {
+          sh 'echo $TOKEN'
+        }
+        withCredentials([string(credentialsId: "$UNKNOWN", variable: 'TOKEN')]) {
+          sh 'echo $TOKEN'
+        }
+      }
+    }
+  }
+}`,
+		want: []string{"demo-kubeconfig", "dockerhub-id"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseJenkinsfileCredentials(tt.jenkinsfile)
+			if len(tt.want) == 0 {
+				assert.Empty(t, got)
+			} else {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func newCredentialUsagePipelines(nsName string) []*v1alpha3.Pipeline {
+	return []*v1alpha3.Pipeline{{
+		ObjectMeta: metav1.ObjectMeta{Name: "multi-branch", Namespace: nsName},
+		Spec: v1alpha3.PipelineSpec{
+			Type: v1alpha3.MultiBranchPipelineType,
+			MultiBranchPipeline: &v1alpha3.MultiBranchPipeline{
+				Name:         "multi-branch",
+				SourceType:   v1alpha3.SourceTypeGithub,
+				GitHubSource: &v1alpha3.GithubSource{Owner: "kubesphere", Repo: "devops", CredentialId: "github"},
+			},
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkinsfile", Namespace: nsName},
+		Spec: v1alpha3.PipelineSpec{
+			Type: v1alpha3.NoScmPipelineType,
+			Pipeline: &v1alpha3.NoScmPipeline{
+				Name:        "jenkinsfile",
+				Jenkinsfile: `node { git(url: 'https://github.com/kubesphere/devops', credentialsId: 'github') }`,
+			},
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "git", Namespace: nsName},
+		Spec: v1alpha3.PipelineSpec{
+			Type: v1alpha3.MultiBranchPipelineType,
+			MultiBranchPipeline: &v1alpha3.MultiBranchPipeline{
+				Name:       "git",
+				SourceType: v1alpha3.SourceTypeGit,
+				GitSource:  &v1alpha3.GitSource{Url: "https://gitee.com/kubesphere/devops", CredentialId: "gitee"},
+			},
+		},
+	}}
+}
+
+func TestGetCredentialUsage(t *testing.T) {
+	nsName := "test-ns"
+	project := &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Status:     v1alpha3.DevOpsProjectStatus{AdminNamespace: nsName},
+	}
+	pipelines := newCredentialUsagePipelines(nsName)
+	operator := NewDevopsOperator(fake.New(nsName), k8sfake.NewSimpleClientset(),
+		ksfake.NewSimpleClientset(project, pipelines[0], pipelines[1], pipelines[2]))
+
+	usage, err := operator.GetCredentialUsage("test", "github")
+	if assert.Nil(t, err) {
+		assert.Equal(t, &CredentialUsage{
+			Credential: "github",
+			Pipelines: []PipelineCredentialRef{{
+				Name:       "jenkinsfile",
+				RefersFrom: []string{CredentialRefJenkinsfile},
+			}, {
+				Name:       "multi-branch",
+				RefersFrom: []string{CredentialRefGithubSource},
+			}},
+		}, usage)
+	}
+
+	usage, err = operator.GetCredentialUsage("test", "unused")
+	if assert.Nil(t, err) {
+		assert.Empty(t, usage.Pipelines)
+	}
+
+	_, err = operator.GetCredentialUsage("missing", "github")
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestDeleteCredentialObj(t *testing.T) {
+	nsName := "test-ns"
+	project := &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Status:     v1alpha3.DevOpsProjectStatus{AdminNamespace: nsName},
+	}
+	newCredential := func(name string) *v1.Secret {
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: nsName},
+			Type:       v1alpha3.SecretTypeBasicAuth,
+		}
+	}
+
+	tests := []struct {
+		name         string
+		credential   string
+		force        bool
+		wantConflict bool
+	}{{
+		name:         "credential in use",
+		credential:   "gitee",
+		wantConflict: true,
+	}, {
+		name:       "force to delete credential in use",
+		credential: "gitee",
+		force:      true,
+	}, {
+		name:       "unused credential",
+		credential: "unused",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipelines := newCredentialUsagePipelines(nsName)
+			operator := NewDevopsOperator(fake.New(nsName), k8sfake.NewSimpleClientset(newCredential(tt.credential)),
+				ksfake.NewSimpleClientset(project, pipelines[0], pipelines[1], pipelines[2]))
+
+			err := operator.DeleteCredentialObj("test", tt.credential, tt.force)
+			assert.Equal(t, tt.wantConflict, errors.IsConflict(err), err)
+			if !tt.wantConflict {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
@@ -26,11 +26,13 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog"
@@ -43,6 +45,7 @@ import (
 	kubesphere "kubesphere.io/devops/pkg/client/clientset/versioned"
 	"kubesphere.io/devops/pkg/client/devops"
 	resourcesV1alpha3 "kubesphere.io/devops/pkg/models/resources/v1alpha3"
+	"kubesphere.io/devops/pkg/utils"
 )
 
 const (
@@ -61,12 +64,15 @@ type DevopsOperator interface {
 	DeletePipelineObj(projectName string, pipelineName string) error
 	UpdatePipelineObj(projectName string, pipeline *v1alpha3.Pipeline) (*v1alpha3.Pipeline, error)
 	ListPipelineObj(projectName string, query *query.Query) (api.ListResult, error)
+	ImportPipelines(projectName, sourceFolder string) (*v1alpha3.DevOpsProject, error)
 
 	CreateCredentialObj(projectName string, s *v1.Secret) (*v1.Secret, error)
 	GetCredentialObj(projectName string, secretName string) (*v1.Secret, error)
-	DeleteCredentialObj(projectName string, secretName string) error
+	DeleteCredentialObj(projectName string, secretName string, force bool) error
 	UpdateCredentialObj(projectName string, secret *v1.Secret) (*v1.Secret, error)
 	ListCredentialObj(projectName string, query *query.Query) (api.ListResult, error)
+	RotateCredentialObj(projectName string, secretName string, rotation *CredentialRotation) (*v1.Secret, error)
+	GetCredentialUsage(projectName string, secretName string) (*CredentialUsage, error)
 
 	GetPipeline(projectName, pipelineName string, req *http.Request) (*devops.Pipeline, error)
 	ListPipelines(req *http.Request) (*devops.PipelineList, error)
@@ -283,6 +289,50 @@ func (d devopsOperator) ListPipelineObj(projectName string, query *query.Query)
 	return *resourcesV1alpha3.DefaultList(result, query, resourcesV1alpha3.DefaultCompare(), resourcesV1alpha3.DefaultFilter()), nil
 }
 
+// ImportPipelines requests to import the jobs in the Jenkins folder of the DevOpsProject as Pipelines. The jobs are
+// imported from the sourceFolder instead if it's not empty. The import is done asynchronously by the DevOpsProject
+// controller.
+func (d devopsOperator) ImportPipelines(projectName, sourceFolder string) (*v1alpha3.DevOpsProject, error) {
+	projectObj, err := d.ksclient.DevopsV1alpha3().DevOpsProjects().Get(d.context, projectName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if projectObj.Annotations == nil {
+		projectObj.Annotations = make(map[string]string)
+	}
+	if sourceFolder == "" || sourceFolder == projectObj.Status.AdminNamespace {
+		delete(projectObj.Annotations, devopsv1alpha3.DevOpsProjectImportSourceFolderAnnoKey)
+	} else {
+		if err := d.validateImportSourceFolder(sourceFolder); err != nil {
+			return nil, err
+		}
+		projectObj.Annotations[devopsv1alpha3.DevOpsProjectImportSourceFolderAnnoKey] = sourceFolder
+	}
+	projectObj.Annotations[devopsv1alpha3.DevOpsProjectRequestToImportJobsAnnoKey] = "true"
+	return d.ksclient.DevopsV1alpha3().DevOpsProjects().Update(d.context, projectObj, metav1.UpdateOptions{})
+}
+
+// validateImportSourceFolder makes sure the source folder exists in Jenkins and doesn't belong to another
+// DevOpsProject, otherwise the jobs of other projects would be exposed.
+func (d devopsOperator) validateImportSourceFolder(sourceFolder string) error {
+	if errs := validation.IsDNS1123Label(sourceFolder); len(errs) > 0 {
+		return fmt.Errorf("invalid source folder %s: %s", sourceFolder, strings.Join(errs, ", "))
+	}
+	projects, err := d.ksclient.DevopsV1alpha3().DevOpsProjects().List(d.context, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, project := range projects.Items {
+		if project.Status.AdminNamespace == sourceFolder {
+			return fmt.Errorf("the source folder %s belongs to the devopsproject %s", sourceFolder, project.Name)
+		}
+	}
+	if _, err := d.devopsClient.ListProjectPipelines(sourceFolder); err != nil {
+		return fmt.Errorf("failed to list the jobs in the source folder %s, error: %v", sourceFolder, err)
+	}
+	return nil
+}
+
 //credentialobj in crd
 func (d devopsOperator) CreateCredentialObj(projectName string, secret *v1.Secret) (*v1.Secret, error) {
 	projectObj, err := d.ksclient.DevopsV1alpha3().DevOpsProjects().Get(d.context, projectName, metav1.GetOptions{})
@@ -303,11 +353,26 @@ func (d devopsOperator) GetCredentialObj(projectName string, secretName string)
 	return d.k8sclient.CoreV1().Secrets(projectObj.Status.AdminNamespace).Get(d.context, secretName, metav1.GetOptions{})
 }
 
-func (d devopsOperator) DeleteCredentialObj(projectName string, secret string) error {
+// DeleteCredentialObj deletes the credential. It's refused if any Pipeline still uses the credential, unless forced.
+func (d devopsOperator) DeleteCredentialObj(projectName string, secret string, force bool) error {
 	projectObj, err := d.ksclient.DevopsV1alpha3().DevOpsProjects().Get(d.context, projectName, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
+	if !force {
+		usage, err := d.getCredentialUsage(projectObj.Status.AdminNamespace, secret)
+		if err != nil {
+			return err
+		}
+		if len(usage.Pipelines) > 0 {
+			names := make([]string, len(usage.Pipelines))
+			for i, pipeline := range usage.Pipelines {
+				names[i] = pipeline.Name
+			}
+			return errors.NewConflict(v1.Resource("secrets"), secret,
+				fmt.Errorf("the credential is used by pipelines %s", strings.Join(names, ", ")))
+		}
+	}
 	return d.k8sclient.CoreV1().Secrets(projectObj.Status.AdminNamespace).Delete(d.context, secret, *metav1.NewDeleteOptions(0))
 }
 
@@ -322,6 +387,133 @@ func (d devopsOperator) UpdateCredentialObj(projectName string, secret *v1.Secre
 	return d.k8sclient.CoreV1().Secrets(projectObj.Status.AdminNamespace).Update(d.context, secret, metav1.UpdateOptions{})
 }
 
+// credentialDataKeys are the keys of the data of each type of credentials, the required ones are true
+var credentialDataKeys = map[v1.SecretType]map[string]bool{
+	devopsv1alpha3.SecretTypeBasicAuth: {
+		devopsv1alpha3.BasicAuthUsernameKey: true,
+		devopsv1alpha3.BasicAuthPasswordKey: true,
+	},
+	devopsv1alpha3.SecretTypeSSHAuth: {
+		devopsv1alpha3.SSHAuthUsernameKey:   true,
+		devopsv1alpha3.SSHAuthPrivateKey:    true,
+		devopsv1alpha3.SSHAuthPassphraseKey: false,
+	},
+	devopsv1alpha3.SecretTypeSecretText: {
+		devopsv1alpha3.SecretTextSecretKey: true,
+	},
+	devopsv1alpha3.SecretTypeKubeConfig: {
+		devopsv1alpha3.KubeConfigSecretKey: true,
+	},
+}
+
+// CredentialRotation is the request of replacing the data of a credential
+type CredentialRotation struct {
+	// Data is the new data of the credential, the keys depend on the type of the credential. It's merged into the
+	// existing data, e.g. only the password of a basic-auth credential is replaced if the username is omitted.
+	Data map[string][]byte `json:"data"`
+	// ExpiresAt is the expiry time of the new data. The credential never expires if it's nil.
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+}
+
+// RotateCredentialObj replaces the data of the credential. Jenkins is updated before the Secret and rolled back if the
+// Secret cannot be updated, so that the Secret and Jenkins never hold different data.
+func (d devopsOperator) RotateCredentialObj(projectName string, secretName string, rotation *CredentialRotation) (*v1.Secret, error) {
+	if len(rotation.Data) == 0 {
+		return nil, errors.NewBadRequest("the data of the credential is required")
+	}
+	projectObj, err := d.ksclient.DevopsV1alpha3().DevOpsProjects().Get(d.context, projectName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	ns := projectObj.Status.AdminNamespace
+	secret, err := d.k8sclient.CoreV1().Secrets(ns).Get(d.context, secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(string(secret.Type), devopsv1alpha3.DevOpsCredentialPrefix) {
+		return nil, errors.NewBadRequest(fmt.Sprintf("secret %s is not a devops credential", secretName))
+	}
+	if ref := secret.Annotations[devopsv1alpha3.CredentialExternalRefAnnoKey]; ref != "" {
+		return nil, errors.NewBadRequest(fmt.Sprintf("the data of credential %s is stored in %s, please rotate it there", secretName, ref))
+	}
+
+	rotated := secret.DeepCopy()
+	if rotated.Data == nil {
+		rotated.Data = make(map[string][]byte, len(rotation.Data))
+	}
+	for key, value := range rotation.Data {
+		rotated.Data[key] = value
+	}
+	if err := validateCredentialRotation(secret.Type, rotation.Data, rotated.Data); err != nil {
+		return nil, errors.NewBadRequest(err.Error())
+	}
+	if rotated.Annotations == nil {
+		rotated.Annotations = make(map[string]string)
+	}
+	rotated.Annotations[devopsv1alpha3.CredentialRotatedAtAnnoKey] = time.Now().Format(time.RFC3339)
+	if rotation.ExpiresAt != nil {
+		rotated.Annotations[devopsv1alpha3.CredentialExpiresAtAnnoKey] = rotation.ExpiresAt.Format(time.RFC3339)
+	} else {
+		delete(rotated.Annotations, devopsv1alpha3.CredentialExpiresAtAnnoKey)
+	}
+	if _, err := d.devopsClient.UpdateCredentialInProject(ns, rotated); err != nil {
+		klog.Errorf("failed to rotate credential %s/%s in Jenkins, error %v", ns, secretName, err)
+		return nil, err
+	}
+
+	// Jenkins holds the new data already, so the controller doesn't need to synchronize it again
+	rotated.Annotations[devopsv1alpha3.CredentialSyncStatusAnnoKey] = StatusSuccessful
+	rotated.Annotations[devopsv1alpha3.CredentialSyncTimeAnnoKey] = GetSyncNowTime()
+	rotated.Annotations[devopsv1alpha3.DevOpsCredentialDataHash] = utils.ComputeHash(rotated.Data)
+	delete(rotated.Annotations, devopsv1alpha3.CredentialSyncMsgAnnoKey)
+	updated, err := d.k8sclient.CoreV1().Secrets(ns).Update(d.context, rotated, metav1.UpdateOptions{})
+	if err != nil {
+		// the resourceVersion prevents overwriting the Secret modified concurrently, then Jenkins has to be rolled back
+		if _, rollbackErr := d.devopsClient.UpdateCredentialInProject(ns, secret); rollbackErr != nil {
+			klog.Errorf("failed to roll back credential %s/%s in Jenkins, error %v", ns, secretName, rollbackErr)
+		}
+		return nil, err
+	}
+	return updated, nil
+}
+
+// validateCredentialRotation makes sure the new data has no unknown key of the type of the credential, which would be
+// ignored by Jenkins silently, and the merged data still has all required keys.
+func validateCredentialRotation(secretType v1.SecretType, data, merged map[string][]byte) error {
+	keys, ok := credentialDataKeys[secretType]
+	if !ok {
+		return nil
+	}
+	for key := range data {
+		if _, ok := keys[key]; !ok {
+			return fmt.Errorf("unknown key %s for the credential of type %s", key, secretType)
+		}
+	}
+	for key, required := range keys {
+		if required && len(merged[key]) == 0 {
+			return fmt.Errorf("the key %s is required by the credential of type %s", key, secretType)
+		}
+	}
+	return nil
+}
+
+// GetCredentialUsage returns the Pipelines which use the credential according to their specs
+func (d devopsOperator) GetCredentialUsage(projectName string, secretName string) (*CredentialUsage, error) {
+	projectObj, err := d.ksclient.DevopsV1alpha3().DevOpsProjects().Get(d.context, projectName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return d.getCredentialUsage(projectObj.Status.AdminNamespace, secretName)
+}
+
+func (d devopsOperator) getCredentialUsage(namespace string, secretName string) (*CredentialUsage, error) {
+	pipelines, err := d.ksclient.DevopsV1alpha3().Pipelines(namespace).List(d.context, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return getCredentialUsage(secretName, pipelines.Items), nil
+}
+
 func (d devopsOperator) ListCredentialObj(projectName string, query *query.Query) (api.ListResult, error) {
 	projectObj, err := d.ksclient.DevopsV1alpha3().DevOpsProjects().Get(d.context, projectName, metav1.GetOptions{})
 	if err != nil {
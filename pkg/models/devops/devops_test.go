@@ -17,9 +17,18 @@ limitations under the License.
 package devops
 
 import (
+	"context"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+
+	"kubesphere.io/devops/pkg/api/devops/v1alpha3"
+	ksfake "kubesphere.io/devops/pkg/client/clientset/versioned/fake"
 	"kubesphere.io/devops/pkg/client/devops"
 	"kubesphere.io/devops/pkg/client/devops/fake"
 )
@@ -128,3 +137,164 @@ func TestGetBranchNodesDetail(t *testing.T) {
 		}
 	}
 }
+
+func TestRotateCredentialObj(t *testing.T) {
+	nsName := "test-ns"
+	project := &v1alpha3.DevOpsProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Status:     v1alpha3.DevOpsProjectStatus{AdminNamespace: nsName},
+	}
+	newCredential := func() *v1.Secret {
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "token",
+				Namespace:   nsName,
+				Annotations: map[string]string{v1alpha3.CredentialExpiresAtAnnoKey: "2021-10-01T00:00:00Z"},
+			},
+			Type: v1alpha3.SecretTypeSecretText,
+			Data: map[string][]byte{v1alpha3.SecretTextSecretKey: []byte("old")},
+		}
+	}
+	expiresAt := metav1.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	rotation := &CredentialRotation{
+		Data:      map[string][]byte{v1alpha3.SecretTextSecretKey: []byte("new")},
+		ExpiresAt: &expiresAt,
+	}
+
+	t.Run("rotate the credential", func(t *testing.T) {
+		k8sClient := k8sfake.NewSimpleClientset(newCredential())
+		devopsClient := fake.NewWithCredentials(nsName, newCredential())
+		operator := NewDevopsOperator(devopsClient, k8sClient, ksfake.NewSimpleClientset(project))
+
+		secret, err := operator.RotateCredentialObj("test", "token", rotation)
+		if assert.Nil(t, err) {
+			assert.Equal(t, rotation.Data, secret.Data)
+			assert.Equal(t, "2022-10-01T00:00:00Z", secret.Annotations[v1alpha3.CredentialExpiresAtAnnoKey])
+			assert.NotEmpty(t, secret.Annotations[v1alpha3.CredentialRotatedAtAnnoKey])
+			assert.Equal(t, StatusSuccessful, secret.Annotations[v1alpha3.CredentialSyncStatusAnnoKey])
+		}
+		assert.Equal(t, rotation.Data, devopsClient.Credentials[nsName]["token"].Data)
+	})
+
+	t.Run("the credential does not exist in Jenkins", func(t *testing.T) {
+		k8sClient := k8sfake.NewSimpleClientset(newCredential())
+		operator := NewDevopsOperator(fake.New(nsName), k8sClient, ksfake.NewSimpleClientset(project))
+
+		_, err := operator.RotateCredentialObj("test", "token", rotation)
+		assert.NotNil(t, err)
+		// the Secret is left as it is
+		secret, err := k8sClient.CoreV1().Secrets(nsName).Get(context.Background(), "token", metav1.GetOptions{})
+		if assert.Nil(t, err) {
+			assert.Equal(t, newCredential().Data, secret.Data)
+		}
+	})
+
+	t.Run("without data", func(t *testing.T) {
+		operator := NewDevopsOperator(fake.New(nsName), k8sfake.NewSimpleClientset(), ksfake.NewSimpleClientset(project))
+		_, err := operator.RotateCredentialObj("test", "token", &CredentialRotation{})
+		assert.NotNil(t, err)
+	})
+
+	newBasicAuth := func() *v1.Secret {
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "github", Namespace: nsName},
+			Type:       v1alpha3.SecretTypeBasicAuth,
+			Data: map[string][]byte{
+				v1alpha3.BasicAuthUsernameKey: []byte("admin"),
+				v1alpha3.BasicAuthPasswordKey: []byte("old"),
+			},
+		}
+	}
+	tests := []struct {
+		name     string
+		data     map[string][]byte
+		wantData map[string][]byte
+		wantErr  bool
+	}{{
+		name: "merge the new data into the existing data",
+		data: map[string][]byte{v1alpha3.BasicAuthPasswordKey: []byte("new")},
+		wantData: map[string][]byte{
+			v1alpha3.BasicAuthUsernameKey: []byte("admin"),
+			v1alpha3.BasicAuthPasswordKey: []byte("new"),
+		},
+	}, {
+		name:    "unknown key",
+		data:    map[string][]byte{"token": []byte("new")},
+		wantErr: true,
+	}, {
+		name:    "empty required key",
+		data:    map[string][]byte{v1alpha3.BasicAuthPasswordKey: []byte("")},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := k8sfake.NewSimpleClientset(newBasicAuth())
+			devopsClient := fake.NewWithCredentials(nsName, newBasicAuth())
+			operator := NewDevopsOperator(devopsClient, k8sClient, ksfake.NewSimpleClientset(project))
+
+			secret, err := operator.RotateCredentialObj("test", "github", &CredentialRotation{Data: tt.data})
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				// neither Jenkins nor the Secret is touched
+				assert.Equal(t, newBasicAuth().Data, devopsClient.Credentials[nsName]["github"].Data)
+				return
+			}
+			if assert.Nil(t, err) {
+				assert.Equal(t, tt.wantData, secret.Data)
+			}
+			assert.Equal(t, tt.wantData, devopsClient.Credentials[nsName]["github"].Data)
+		})
+	}
+}
+
+func TestImportPipelines(t *testing.T) {
+	newProject := func(name, nsName string) *v1alpha3.DevOpsProject {
+		return &v1alpha3.DevOpsProject{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     v1alpha3.DevOpsProjectStatus{AdminNamespace: nsName},
+		}
+	}
+	tests := []struct {
+		name           string
+		sourceFolder   string
+		wantErr        bool
+		wantAnnotation string
+	}{{
+		name: "the folder of the project",
+	}, {
+		name:         "the folder of the project is specified",
+		sourceFolder: "test-ns",
+	}, {
+		name:           "another folder",
+		sourceFolder:   "legacy",
+		wantAnnotation: "legacy",
+	}, {
+		name:         "the folder of another project",
+		sourceFolder: "another-ns",
+		wantErr:      true,
+	}, {
+		name:         "invalid folder",
+		sourceFolder: "../legacy",
+		wantErr:      true,
+	}, {
+		name:         "the folder does not exist",
+		sourceFolder: "missing",
+		wantErr:      true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ksClient := ksfake.NewSimpleClientset(newProject("test", "test-ns"), newProject("another", "another-ns"))
+			operator := NewDevopsOperator(fake.New("test-ns", "another-ns", "legacy"), k8sfake.NewSimpleClientset(), ksClient)
+
+			project, err := operator.ImportPipelines("test", tt.sourceFolder)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			if assert.Nil(t, err) {
+				assert.Equal(t, "true", project.Annotations[v1alpha3.DevOpsProjectRequestToImportJobsAnnoKey])
+				assert.Equal(t, tt.wantAnnotation, project.Annotations[v1alpha3.DevOpsProjectImportSourceFolderAnnoKey])
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devops
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/klog"
+
+	"kubesphere.io/devops/pkg/client/codequality"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/server/errors"
+)
+
+// PipelineCodeQualityGetter reports the code analyses of Pipelines from all code quality providers
+type PipelineCodeQualityGetter interface {
+	GetPipelineCodeQuality(projectId, pipelineId string) (*codequality.Result, error)
+	GetMultiBranchPipelineCodeQuality(projectId, pipelineId, branchId string) (*codequality.Result, error)
+}
+
+type pipelineCodeQualityGetter struct {
+	devops.BuildGetter
+	providers []codequality.CodeQualityProvider
+}
+
+func NewPipelineCodeQualityGetter(devopsClient devops.BuildGetter, providers ...codequality.CodeQualityProvider) PipelineCodeQualityGetter {
+	return &pipelineCodeQualityGetter{
+		BuildGetter: devopsClient,
+		providers:   providers,
+	}
+}
+
+func (g *pipelineCodeQualityGetter) GetPipelineCodeQuality(projectId, pipelineId string) (*codequality.Result, error) {
+	return g.getCodeQuality(&codequality.BuildReference{
+		ProjectID:  projectId,
+		PipelineID: pipelineId,
+	}, func(buildType string) (*devops.Build, error) {
+		return g.GetProjectPipelineBuildByType(projectId, pipelineId, buildType)
+	})
+}
+
+func (g *pipelineCodeQualityGetter) GetMultiBranchPipelineCodeQuality(projectId, pipelineId, branchId string) (*codequality.Result, error) {
+	return g.getCodeQuality(&codequality.BuildReference{
+		ProjectID:  projectId,
+		PipelineID: pipelineId,
+		Branch:     branchId,
+	}, func(buildType string) (*devops.Build, error) {
+		return g.GetMultiBranchPipelineBuildByType(projectId, pipelineId, branchId, buildType)
+	})
+}
+
+// getCodeQuality reports the last build, or the last completed build if the last build has no report yet. The failure
+// of a provider is reported along with the reports of the other providers.
+func (g *pipelineCodeQualityGetter) getCodeQuality(build *codequality.BuildReference,
+	getBuildByType func(buildType string) (*devops.Build, error)) (*codequality.Result, error) {
+	result := &codequality.Result{Reports: make([]*codequality.Report, 0)}
+	for _, buildType := range []string{devops.LastBuild, devops.LastCompletedBuild} {
+		var err error
+		if build.Build, err = getBuildByType(buildType); err != nil && errors.GetServiceErrorCode(err) != http.StatusNotFound {
+			klog.Errorf("%+v", err)
+			return nil, restful.NewError(errors.GetServiceErrorCode(err), err.Error())
+		} else if err != nil || build.Build == nil {
+			klog.V(4).Infof("the %s of pipeline %s/%s is not found, error: %v", buildType, build.ProjectID, build.PipelineID, err)
+			return result, nil
+		}
+
+		for _, provider := range g.providers {
+			providerReports, err := provider.GetReports(build)
+			if err != nil {
+				klog.Errorf("failed to get the code quality reports from %s, error: %+v", provider.Name(), err)
+				result.Errors = append(result.Errors, codequality.ProviderError{Provider: provider.Name(), Message: err.Error()})
+				continue
+			}
+			result.Reports = append(result.Reports, providerReports...)
+		}
+		if len(result.Reports) != 0 || len(result.Errors) != 0 {
+			break
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2021 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package devops
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"kubesphere.io/devops/pkg/client/codequality"
+	"kubesphere.io/devops/pkg/client/devops"
+	"kubesphere.io/devops/pkg/client/devops/fake"
+	"kubesphere.io/devops/pkg/client/sonarqube"
+)
+
+// fakeCodeQualityProvider reports the builds which are analyzed by it
+type fakeCodeQualityProvider struct {
+	name     string
+	analyzed map[int64]bool
+	err      error
+}
+
+func (p *fakeCodeQualityProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeCodeQualityProvider) GetReports(build *codequality.BuildReference) ([]*codequality.Report, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	if !p.analyzed[build.Build.Number] {
+		return nil, nil
+	}
+	report := &codequality.Report{
+		Provider:  p.name,
+		Component: build.ProjectID + "/" + build.PipelineID + "/" + build.Branch + "/" + build.RunID(),
+		Status:    codequality.StatusOK,
+	}
+	if p.name == sonarqube.ProviderName {
+		report.Details = &sonarqube.SonarStatus{}
+	}
+	return []*codequality.Report{report}, nil
+}
+
+func newFakeBuildGetter() devops.BuildGetter {
+	devopsClient := fake.New("project1")
+	devopsClient.Builds = map[string]*devops.Build{
+		"project1/pipeline1/lastBuild":               {Number: 3},
+		"project1/pipeline1/lastCompletedBuild":      {Number: 2},
+		"project1/pipeline2/main/lastBuild":          {Number: 5},
+		"project1/pipeline2/main/lastCompletedBuild": {Number: 5},
+	}
+	return devopsClient
+}
+
+func TestPipelineCodeQualityGetter(t *testing.T) {
+	getter := NewPipelineCodeQualityGetter(newFakeBuildGetter(),
+		&fakeCodeQualityProvider{name: "sonarqube", analyzed: map[int64]bool{2: true, 5: true}},
+		&fakeCodeQualityProvider{name: "sarif", analyzed: map[int64]bool{2: true}})
+
+	// the last build is still running
+	result, err := getter.GetPipelineCodeQuality("project1", "pipeline1")
+	assert.Nil(t, err)
+	assert.Equal(t, []*codequality.Report{{
+		Provider:  "sonarqube",
+		Component: "project1/pipeline1//2",
+		Status:    codequality.StatusOK,
+		Details:   &sonarqube.SonarStatus{},
+	}, {
+		Provider:  "sarif",
+		Component: "project1/pipeline1//2",
+		Status:    codequality.StatusOK,
+	}}, result.Reports)
+	assert.Empty(t, result.Errors)
+
+	result, err = getter.GetMultiBranchPipelineCodeQuality("project1", "pipeline2", "main")
+	assert.Nil(t, err)
+	assert.Equal(t, []*codequality.Report{{
+		Provider:  "sonarqube",
+		Component: "project1/pipeline2/main/5",
+		Status:    codequality.StatusOK,
+		Details:   &sonarqube.SonarStatus{},
+	}}, result.Reports)
+
+	// the pipeline has never been built
+	result, err = getter.GetPipelineCodeQuality("project1", "pipeline3")
+	assert.Nil(t, err)
+	assert.Empty(t, result.Reports)
+
+	// the failed provider doesn't hide the reports of the others
+	getter = NewPipelineCodeQualityGetter(newFakeBuildGetter(),
+		&fakeCodeQualityProvider{name: "sonarqube", err: errors.New("connection refused")},
+		&fakeCodeQualityProvider{name: "sarif", analyzed: map[int64]bool{5: true}})
+	result, err = getter.GetMultiBranchPipelineCodeQuality("project1", "pipeline2", "main")
+	assert.Nil(t, err)
+	assert.Equal(t, []*codequality.Report{{
+		Provider:  "sarif",
+		Component: "project1/pipeline2/main/5",
+		Status:    codequality.StatusOK,
+	}}, result.Reports)
+	assert.Equal(t, []codequality.ProviderError{{Provider: "sonarqube", Message: "connection refused"}}, result.Errors)
+}
+
+func TestPipelineSonarGetter(t *testing.T) {
+	getter := NewPipelineSonarGetter(newFakeBuildGetter(),
+		&fakeCodeQualityProvider{name: sonarqube.ProviderName, analyzed: map[int64]bool{2: true}},
+		&fakeCodeQualityProvider{name: "sarif", analyzed: map[int64]bool{2: true, 3: true, 5: true}})
+
+	// only the reports of SonarQube are taken into account
+	statuses, err := getter.GetPipelineSonar("project1", "pipeline1")
+	assert.Nil(t, err)
+	assert.Equal(t, []*sonarqube.SonarStatus{{}}, statuses)
+
+	statuses, err = getter.GetMultiBranchPipelineSonar("project1", "pipeline2", "main")
+	assert.Nil(t, err)
+	assert.Empty(t, statuses)
+
+	getter = NewPipelineSonarGetter(newFakeBuildGetter(),
+		&fakeCodeQualityProvider{name: sonarqube.ProviderName, err: errors.New("connection refused")})
+	_, err = getter.GetPipelineSonar("project1", "pipeline1")
+	assert.NotNil(t, err)
+}
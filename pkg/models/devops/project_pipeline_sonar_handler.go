@@ -20,11 +20,10 @@ import (
 	"net/http"
 
 	"github.com/emicklei/go-restful"
-	"k8s.io/klog"
 
+	"kubesphere.io/devops/pkg/client/codequality"
 	"kubesphere.io/devops/pkg/client/devops"
 	"kubesphere.io/devops/pkg/client/sonarqube"
-	"kubesphere.io/devops/pkg/server/errors"
 )
 
 type PipelineSonarGetter interface {
@@ -32,109 +31,50 @@ type PipelineSonarGetter interface {
 	GetMultiBranchPipelineSonar(projectId, pipelineId, branchId string) ([]*sonarqube.SonarStatus, error)
 }
 type pipelineSonarGetter struct {
-	devops.BuildGetter
-	sonarqube.SonarInterface
+	codeQualityGetter PipelineCodeQualityGetter
 }
 
-func NewPipelineSonarGetter(devopClient devops.BuildGetter, sonarClient sonarqube.SonarInterface) PipelineSonarGetter {
+// NewPipelineSonarGetter reports the analyses of SonarQube through the SonarQube provider among the code quality
+// providers, so that it's consistent with PipelineCodeQualityGetter.
+func NewPipelineSonarGetter(devopClient devops.BuildGetter, providers ...codequality.CodeQualityProvider) PipelineSonarGetter {
+	var sonarProviders []codequality.CodeQualityProvider
+	for _, provider := range providers {
+		if provider.Name() == sonarqube.ProviderName {
+			sonarProviders = append(sonarProviders, provider)
+		}
+	}
 	return &pipelineSonarGetter{
-		BuildGetter:    devopClient,
-		SonarInterface: sonarClient,
+		codeQualityGetter: NewPipelineCodeQualityGetter(devopClient, sonarProviders...),
 	}
 }
 
 func (g *pipelineSonarGetter) GetPipelineSonar(projectId, pipelineId string) ([]*sonarqube.SonarStatus, error) {
-
-	build, err := g.GetProjectPipelineBuildByType(projectId, pipelineId, devops.LastBuild)
-	if err != nil && errors.GetServiceErrorCode(err) != http.StatusNotFound {
-		klog.Errorf("%+v", err)
+	result, err := g.codeQualityGetter.GetPipelineCodeQuality(projectId, pipelineId)
+	if err != nil {
 		return nil, err
-	} else if err != nil {
-		klog.Errorf("%+v", err)
-		return nil, nil
-	}
-	var taskIds []string
-	for _, action := range build.Actions {
-		if action.ClassName == sonarqube.SonarAnalysisActionClass {
-			taskIds = append(taskIds, action.SonarTaskId)
-		}
-	}
-	var sonarStatus []*sonarqube.SonarStatus
-
-	if len(taskIds) != 0 {
-		sonarStatus, err = g.GetSonarResultsByTaskIds(taskIds...)
-		if err != nil {
-			klog.Errorf("%+v", err)
-			return nil, restful.NewError(http.StatusBadRequest, err.Error())
-		}
-	} else if len(taskIds) == 0 {
-		build, err := g.GetProjectPipelineBuildByType(projectId, pipelineId, devops.LastCompletedBuild)
-		if err != nil && errors.GetServiceErrorCode(err) != http.StatusNotFound {
-			klog.Errorf("%+v", err)
-			return nil, restful.NewError(errors.GetServiceErrorCode(err), err.Error())
-		} else if err != nil {
-			klog.Errorf("%+v", err)
-			return nil, nil
-		}
-		for _, action := range build.Actions {
-			if action.ClassName == sonarqube.SonarAnalysisActionClass {
-				taskIds = append(taskIds, action.SonarTaskId)
-			}
-		}
-		sonarStatus, err = g.GetSonarResultsByTaskIds(taskIds...)
-		if err != nil {
-			klog.Errorf("%+v", err)
-			return nil, restful.NewError(http.StatusBadRequest, err.Error())
-		}
-
 	}
-	return sonarStatus, nil
+	return getSonarStatuses(result)
 }
 
 func (g *pipelineSonarGetter) GetMultiBranchPipelineSonar(projectId, pipelineId, branchId string) ([]*sonarqube.SonarStatus, error) {
-
-	build, err := g.GetMultiBranchPipelineBuildByType(projectId, pipelineId, branchId, devops.LastBuild)
-	if err != nil && errors.GetServiceErrorCode(err) != http.StatusNotFound {
-		klog.Errorf("%+v", err)
-		return nil, restful.NewError(errors.GetServiceErrorCode(err), err.Error())
-	} else if err != nil {
-		klog.Errorf("%+v", err)
-		return nil, nil
-	}
-	var taskIds []string
-	for _, action := range build.Actions {
-		if action.ClassName == sonarqube.SonarAnalysisActionClass {
-			taskIds = append(taskIds, action.SonarTaskId)
-		}
+	result, err := g.codeQualityGetter.GetMultiBranchPipelineCodeQuality(projectId, pipelineId, branchId)
+	if err != nil {
+		return nil, err
 	}
-	var sonarStatus []*sonarqube.SonarStatus
+	return getSonarStatuses(result)
+}
 
-	if len(taskIds) != 0 {
-		sonarStatus, err = g.GetSonarResultsByTaskIds(taskIds...)
-		if err != nil {
-			klog.Errorf("%+v", err)
-			return nil, restful.NewError(http.StatusBadRequest, err.Error())
-		}
-	} else if len(taskIds) == 0 {
-		build, err := g.GetMultiBranchPipelineBuildByType(projectId, pipelineId, branchId, devops.LastCompletedBuild)
-		if err != nil && errors.GetServiceErrorCode(err) != http.StatusNotFound {
-			klog.Errorf("%+v", err)
-			return nil, restful.NewError(errors.GetServiceErrorCode(err), err.Error())
-		} else if err != nil {
-			klog.Errorf("%+v", err)
-			return nil, nil
-		}
-		for _, action := range build.Actions {
-			if action.ClassName == sonarqube.SonarAnalysisActionClass {
-				taskIds = append(taskIds, action.SonarTaskId)
-			}
-		}
-		sonarStatus, err = g.GetSonarResultsByTaskIds(taskIds...)
-		if err != nil {
-			klog.Errorf("%+v", err)
-			return nil, restful.NewError(http.StatusBadRequest, err.Error())
+// getSonarStatuses returns the original results of SonarQube in the code quality reports. SonarQube is the only
+// provider, so its failure fails the request.
+func getSonarStatuses(result *codequality.Result) ([]*sonarqube.SonarStatus, error) {
+	if len(result.Errors) != 0 {
+		return nil, restful.NewError(http.StatusBadRequest, result.Errors[0].Message)
+	}
+	sonarStatuses := make([]*sonarqube.SonarStatus, 0, len(result.Reports))
+	for _, report := range result.Reports {
+		if status, ok := report.Details.(*sonarqube.SonarStatus); ok {
+			sonarStatuses = append(sonarStatuses, status)
 		}
-
 	}
-	return sonarStatus, nil
+	return sonarStatuses, nil
 }
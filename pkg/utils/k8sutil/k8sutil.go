@@ -17,6 +17,8 @@ limitations under the License.
 package k8sutil
 
 import (
+	"reflect"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -29,3 +31,14 @@ func IsControlledBy(ownerReferences []metav1.OwnerReference, kind string, name s
 	}
 	return false
 }
+
+// IsStatusOnlyUpdate returns whether only the status of the object was changed, which neither increases the generation
+// nor changes the metadata handled by controllers, e.g. labels, annotations and finalizers.
+func IsStatusOnlyUpdate(oldObj, newObj metav1.Object) bool {
+	return oldObj.GetGeneration() == newObj.GetGeneration() &&
+		reflect.DeepEqual(oldObj.GetDeletionTimestamp(), newObj.GetDeletionTimestamp()) &&
+		reflect.DeepEqual(oldObj.GetLabels(), newObj.GetLabels()) &&
+		reflect.DeepEqual(oldObj.GetAnnotations(), newObj.GetAnnotations()) &&
+		reflect.DeepEqual(oldObj.GetFinalizers(), newObj.GetFinalizers()) &&
+		reflect.DeepEqual(oldObj.GetOwnerReferences(), newObj.GetOwnerReferences())
+}